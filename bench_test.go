@@ -1,6 +1,8 @@
 package parser_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"unicode"
 
@@ -169,6 +171,33 @@ func BenchmarkMap(b *testing.B) {
 	}
 }
 
+func BenchmarkVerify(b *testing.B) {
+	input := "8080/tcp"
+
+	port := func(input string) (int, error) { return len(input), nil }
+	valid := func(n int) bool { return n > 0 }
+
+	for b.Loop() {
+		_, _, err := parser.Verify(parser.Map(parser.Take(4), port), valid, "invalid port")(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBind(b *testing.B) {
+	input := "5hello"
+
+	length := func(input string) (int, error) { return int(input[0] - '0'), nil }
+
+	for b.Loop() {
+		_, _, err := parser.Bind(parser.Map(parser.Take(1), length), parser.Take)(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkTry(b *testing.B) {
 	input := "123456)(*&^%"
 
@@ -207,3 +236,126 @@ func BenchmarkCount(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkFoldMany(b *testing.B) {
+	input := "123456789rest"
+
+	zero := func() int { return 0 }
+	sum := func(acc, digit int) int { return acc + digit }
+
+	for b.Loop() {
+		_, _, err := parser.FoldMany(digit, zero, sum)(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSkipMany(b *testing.B) {
+	input := "123456789rest"
+
+	for b.Loop() {
+		_, _, err := parser.SkipMany(digit)(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSkipCount(b *testing.B) {
+	input := "abcabcabc"
+
+	for b.Loop() {
+		_, _, err := parser.SkipCount(parser.Exact("abc"), 3)(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// errNotDigit is returned from digit, it's declared up front so that the terminating
+// failure of the repeating combinators doesn't allocate and skew their benchmarks.
+var errNotDigit = errors.New("not a digit")
+
+// digit is a non-allocating parser that parses a single ascii digit as its integer value.
+func digit(input string) (int, string, error) {
+	if input == "" || input[0] < '0' || input[0] > '9' {
+		return 0, "", errNotDigit
+	}
+
+	return int(input[0] - '0'), input[1:], nil
+}
+
+func BenchmarkFindAll(b *testing.B) {
+	input := "conn from 10.0.0.1 port 443 to 192.168.1.254 port 8080"
+
+	for b.Loop() {
+		for _, err := range parser.FindAll(parser.TakeWhile(unicode.IsDigit), input) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkFindAllLarge(b *testing.B) {
+	// About 40KB, mostly text the parsers fail on, so every char is a failed attempt
+	input := strings.Repeat("connection refused by remote host, retrying in a moment... port 8080\n", 600)
+
+	b.SetBytes(int64(len(input)))
+
+	for b.Loop() {
+		for _, err := range parser.FindAll(parser.Exact("port "), input) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSplit(b *testing.B) {
+	input := "apples , pears;bananas ;  kiwis, oranges"
+
+	sep := parser.Chain(
+		parser.SkipMany(parser.OneOf(" \t")),
+		parser.OneOf(",;"),
+		parser.SkipMany(parser.OneOf(" \t")),
+	)
+
+	for b.Loop() {
+		for piece := range parser.Split(input, sep) {
+			_ = piece
+		}
+	}
+}
+
+func BenchmarkSplitLarge(b *testing.B) {
+	// About 40KB, with long fields between separators
+	input := strings.Repeat("the quick brown fox jumps over the lazy dog again and again;", 700)
+
+	sep := parser.TakeWhileBetween(1, 1, func(r rune) bool { return r == ';' || r == ',' })
+
+	b.SetBytes(int64(len(input)))
+
+	for b.Loop() {
+		for piece := range parser.Split(input, sep) {
+			_ = piece
+		}
+	}
+}
+
+func BenchmarkRule(b *testing.B) {
+	input := "10-4-3-2-1"
+
+	expr := &parser.Rule[string]{}
+	digits := parser.TakeWhile(unicode.IsDigit)
+
+	expr.Define(parser.Try(parser.Recognize(parser.Chain(expr.Parse, parser.Char('-'), digits)), digits))
+
+	for b.Loop() {
+		_, _, err := expr.Parse(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrBudgetExceeded is the error returned by [Run] when a parse goes over one of its [Limits].
+var ErrBudgetExceeded = errors.New("parse budget exceeded")
+
+// contextCheckInterval is how many steps a [Budget] takes between checking its context, as
+// checking it on every step would cost more than most parsers do.
+const contextCheckInterval = 256
+
+// Limits bounds the work done by a parse started with [Run], a limit of 0 means no limit.
+type Limits struct {
+	MaxSteps       int // Maximum number of steps, see [Budget.Step]
+	MaxDepth       int // Maximum nesting of parsers wrapped with [Guard]
+	MaxRepetitions int // Maximum number of repetitions in a single [CountWithin] or [FoldManyWithin]
+	MaxInputSize   int // Maximum length of the input in bytes
+}
+
+// Budget tracks the work done by a single parse started with [Run], failing it once it goes over
+// the [Limits] or the run's context is done.
+//
+// Parsers don't know about budgets, not even [Lazy] and [Rule], so nothing is bounded unless the
+// grammar opts in by wrapping the parsers that could do unbounded work: [Guard] the ones at points
+// of recursion or backtracking, and use [CountWithin] and [FoldManyWithin] in place of [Count] and
+// [FoldMany]. Hand written parsers can call [Budget.Step] directly.
+//
+// Once a budget is exceeded it stays exceeded, every guarded parser fails straight away, so that
+// the failure can't be swallowed by a [Try] moving on to its next alternative. A nil *Budget has
+// no limits, so the same grammar can be used with and without one.
+type Budget struct {
+	ctx    context.Context //nolint:containedctx // A Budget lives for exactly one Run, like a request
+	err    error           // Why the budget was exceeded, once it has been
+	limits Limits          // The limits for the run
+	steps  int             // Steps taken so far
+	depth  int             // Current nesting of guarded parsers
+}
+
+// Step records a single step of work, returning an error wrapping [ErrBudgetExceeded] if that's
+// more than [Limits.MaxSteps] allows, or the context's error if it's done.
+//
+// [Guard] takes a step each time its parser is applied and [CountWithin] and [FoldManyWithin] take
+// one per repetition, hand written parsers can call Step from their own loops and recursion. The
+// context is checked every few hundred steps, rather than on every one.
+func (b *Budget) Step() error {
+	if b == nil {
+		return nil
+	}
+
+	if b.err != nil {
+		return b.err
+	}
+
+	b.steps++
+	if b.limits.MaxSteps > 0 && b.steps > b.limits.MaxSteps {
+		return b.exceed("more than %d steps", b.limits.MaxSteps)
+	}
+
+	if (b.steps-1)%contextCheckInterval == 0 {
+		if err := b.ctx.Err(); err != nil {
+			b.err = err
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Err returns the reason the budget was exceeded, or nil if it hasn't been.
+func (b *Budget) Err() error {
+	if b == nil {
+		return nil
+	}
+
+	return b.err
+}
+
+// exceed marks the budget as exceeded, returning the error.
+func (b *Budget) exceed(format string, args ...any) error {
+	b.err = fmt.Errorf("%w: %s", ErrBudgetExceeded, fmt.Sprintf(format, args...))
+	return b.err
+}
+
+// Run applies the [Parser] returned by build to input, failing if the parse goes over any of the
+// limits or the context is done before it finishes.
+//
+// build is called once with the [Budget] for the run, and should construct the grammar with the
+// budget's guards in the places that need them, see [Budget]. Parsers that aren't guarded don't
+// count towards the limits, so aren't bounded by them.
+//
+// If the budget is exceeded, the error wraps [ErrBudgetExceeded], and if the context is done, the
+// error is the context's error. Either way, it's returned whatever the grammar did with the failure,
+// so it's safe to use with grammars that backtrack. If the parser returns any other error, Run will
+// bubble up this error to the caller.
+//
+// If build is nil or returns a nil parser, an error will be returned.
+func Run[T any](
+	ctx context.Context,
+	limits Limits,
+	input string,
+	build func(budget *Budget) Parser[T],
+) (T, string, error) {
+	var zero T
+
+	if build == nil {
+		return zero, "", errors.New("Run: build must be a non-nil function")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return zero, "", err
+	}
+
+	if limits.MaxInputSize > 0 && len(input) > limits.MaxInputSize {
+		return zero, "", fmt.Errorf("%w: input larger than %d bytes", ErrBudgetExceeded, limits.MaxInputSize)
+	}
+
+	budget := &Budget{ctx: ctx, limits: limits}
+
+	parser := build(budget)
+	if parser == nil {
+		return zero, "", errors.New("Run: build returned a nil parser")
+	}
+
+	value, remainder, err := parser(input)
+	if budget.err != nil {
+		return zero, "", budget.err
+	}
+
+	if err != nil {
+		return zero, "", fmt.Errorf("Run: parser returned error: %w", err)
+	}
+
+	return value, remainder, nil
+}
+
+// Guard returns a [Parser] that takes a step from the budget each time it's applied, and counts
+// towards [Limits.MaxDepth] while its parser is running, otherwise behaving exactly like parser.
+//
+// Guard the parsers that could do an unbounded amount of work for a malicious input, typically the
+// recursive references to a grammar's rules (often in a [Lazy]) and the alternatives in a [Try].
+//
+// If the budget is exceeded, an error wrapping [ErrBudgetExceeded] (or the context's error) will be
+// returned. If parser is nil, an error will be returned.
+func Guard[T any](budget *Budget, parser Parser[T]) Parser[T] {
+	return func(input string) (T, string, error) {
+		var zero T
+
+		if parser == nil {
+			return zero, "", errors.New("Guard: parser must not be nil")
+		}
+
+		if budget == nil {
+			return parser(input)
+		}
+
+		if err := budget.Step(); err != nil {
+			return zero, "", err
+		}
+
+		budget.depth++
+		defer func() { budget.depth-- }()
+
+		if limit := budget.limits.MaxDepth; limit > 0 && budget.depth > limit {
+			return zero, "", budget.exceed("nested more than %d deep", limit)
+		}
+
+		return parser(input)
+	}
+}
+
+// CountWithin returns a [Parser] that applies another parser a certain number of times like [Count],
+// taking a step from the budget for each application.
+//
+// A count greater than [Limits.MaxRepetitions] fails up front, before doing any work, which matters
+// when the count comes from the input, like the length prefix of a list.
+//
+// If the budget is exceeded, an error wrapping [ErrBudgetExceeded] (or the context's error) will be
+// returned. If any of the applications fail, an error will be returned.
+func CountWithin[T any](budget *Budget, parser Parser[T], count int) Parser[[]T] {
+	return func(input string) ([]T, string, error) {
+		if limit := budget.maxRepetitions(); limit > 0 && count > limit && budget.Err() == nil {
+			return nil, "", budget.exceed("more than %d repetitions", limit)
+		}
+
+		return Count(Guard(budget, parser), count)(input)
+	}
+}
+
+// FoldManyWithin returns a [Parser] that applies another parser repeatedly until it fails, folding
+// each parsed value into an accumulator like [FoldMany], taking a step from the budget for each
+// application.
+//
+// Unlike FoldMany, it fails with an error wrapping [ErrBudgetExceeded] if the parser succeeds more
+// than [Limits.MaxRepetitions] times in a row, or if the budget is otherwise exceeded.
+//
+// If init or step is nil, an error will be returned.
+func FoldManyWithin[T, A any](budget *Budget, parser Parser[T], init func() A, step func(A, T) A) Parser[A] {
+	return func(input string) (A, string, error) {
+		var zero A
+
+		if init == nil {
+			return zero, "", errors.New("FoldManyWithin: init must be a non-nil function")
+		}
+
+		if step == nil {
+			return zero, "", errors.New("FoldManyWithin: step must be a non-nil function")
+		}
+
+		acc := init()
+		remainder := input
+		repetitions := 0
+
+		for {
+			if err := budget.Step(); err != nil {
+				return zero, "", err
+			}
+
+			value, rest, err := parser(remainder)
+			if err != nil || len(rest) == len(remainder) {
+				// Either the parser has failed or it made no progress, either way we're done
+				break
+			}
+
+			repetitions++
+			if limit := budget.maxRepetitions(); limit > 0 && repetitions > limit {
+				return zero, "", budget.exceed("more than %d repetitions", limit)
+			}
+
+			acc = step(acc, value)
+			remainder = rest
+		}
+
+		// The parser failing might have been the budget running out, rather than the end
+		if err := budget.Err(); err != nil {
+			return zero, "", err
+		}
+
+		return acc, remainder, nil
+	}
+}
+
+// maxRepetitions returns the repetition limit of the budget, or 0 for none.
+func (b *Budget) maxRepetitions() int {
+	if b == nil {
+		return 0
+	}
+
+	return b.limits.MaxRepetitions
+}
@@ -0,0 +1,400 @@
+package parser_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// exponential builds a grammar that backtracks exponentially on a run of a's that isn't
+// closed properly, S = a S b | a S c | a, guarding the recursive references to S.
+func exponential(budget *parser.Budget) parser.Parser[string] {
+	var s parser.Parser[string]
+
+	rule := parser.Lazy(func() parser.Parser[string] { return s })
+	join := func(parts []string) (string, error) { return strings.Join(parts, ""), nil }
+
+	s = parser.Try(
+		parser.Map(parser.Chain(parser.Exact("a"), parser.Guard(budget, rule), parser.Exact("b")), join),
+		parser.Map(parser.Chain(parser.Exact("a"), parser.Guard(budget, rule), parser.Exact("c")), join),
+		parser.Exact("a"),
+	)
+
+	return s
+}
+
+// nested builds a grammar of balanced brackets, N = "[" N "]" | "", guarding the recursion.
+func nested(budget *parser.Budget) parser.Parser[int] {
+	var n parser.Parser[int]
+
+	open := parser.Map(parser.Char('['), func(string) (int, error) { return 0, nil })
+	closing := parser.Map(parser.Char(']'), func(string) (int, error) { return 0, nil })
+
+	n = parser.Try(
+		parser.Map(
+			parser.Chain(open, parser.Guard(budget, parser.Lazy(func() parser.Parser[int] { return n })), closing),
+			func(depths []int) (int, error) { return depths[1] + 1, nil },
+		),
+		func(input string) (int, string, error) { return 0, input, nil },
+	)
+
+	return n
+}
+
+// letters builds a parser folding any number of single letters into a count, within the budget.
+func letters(budget *parser.Budget) parser.Parser[int] {
+	return parser.FoldManyWithin(budget, parser.Char('x'), func() int { return 0 }, func(n int, _ string) int {
+		return n + 1
+	})
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		build         func(budget *parser.Budget) parser.Parser[int] // Builds the grammar
+		name          string                                         // Identifying test case name
+		input         string                                         // Input to the parser
+		wantRemainder string                                         // Expected remainder
+		wantErrMsg    string                                         // Expected error message, if any
+		limits        parser.Limits                                  // Limits for the run
+		wantValue     int                                            // Expected value
+		wantErr       bool                                           // Whether it should have returned an error
+		wantExceeded  bool                                           // Whether the error should wrap ErrBudgetExceeded
+	}{
+		{
+			name:          "no limits",
+			build:         nested,
+			input:         "[[[]]] rest",
+			limits:        parser.Limits{},
+			wantValue:     3,
+			wantRemainder: " rest",
+			wantErr:       false,
+		},
+		{
+			name:          "within limits",
+			build:         nested,
+			input:         "[[[]]]",
+			limits:        parser.Limits{MaxSteps: 10, MaxDepth: 3, MaxInputSize: 6},
+			wantValue:     3,
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "too deep",
+			build:         nested,
+			input:         "[[[[]]]]",
+			limits:        parser.Limits{MaxDepth: 3},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantExceeded:  true,
+			wantErrMsg:    "parse budget exceeded: nested more than 3 deep",
+		},
+		{
+			name:          "too many steps",
+			build:         nested,
+			input:         "[[[[]]]]",
+			limits:        parser.Limits{MaxSteps: 2},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantExceeded:  true,
+			wantErrMsg:    "parse budget exceeded: more than 2 steps",
+		},
+		{
+			name:          "input too large",
+			build:         nested,
+			input:         "[[[[]]]]",
+			limits:        parser.Limits{MaxInputSize: 4},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantExceeded:  true,
+			wantErrMsg:    "parse budget exceeded: input larger than 4 bytes",
+		},
+		{
+			name: "parser error",
+			build: func(budget *parser.Budget) parser.Parser[int] {
+				return parser.Map(parser.CountWithin(budget, parser.Char('x'), 2), func(xs []string) (int, error) {
+					return len(xs), nil
+				})
+			},
+			input:         "xy",
+			limits:        parser.Limits{},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Run: parser returned error: Map: parser returned error: Count: parser failed: Char: requested char (x) not found in input",
+		},
+		{
+			name:          "fold many",
+			build:         letters,
+			input:         "xxxy",
+			limits:        parser.Limits{MaxRepetitions: 3},
+			wantValue:     3,
+			wantRemainder: "y",
+			wantErr:       false,
+		},
+		{
+			name:          "fold many too many repetitions",
+			build:         letters,
+			input:         "xxxxy",
+			limits:        parser.Limits{MaxRepetitions: 3},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantExceeded:  true,
+			wantErrMsg:    "parse budget exceeded: more than 3 repetitions",
+		},
+		{
+			name:          "fold many too many steps",
+			build:         letters,
+			input:         "xxxxy",
+			limits:        parser.Limits{MaxSteps: 3},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantExceeded:  true,
+			wantErrMsg:    "parse budget exceeded: more than 3 steps",
+		},
+		{
+			name: "count within",
+			build: func(budget *parser.Budget) parser.Parser[int] {
+				return parser.Map(parser.CountWithin(budget, parser.Char('x'), 3), func(xs []string) (int, error) {
+					return len(xs), nil
+				})
+			},
+			input:         "xxxx",
+			limits:        parser.Limits{MaxRepetitions: 3},
+			wantValue:     3,
+			wantRemainder: "x",
+			wantErr:       false,
+		},
+		{
+			name: "count within too many repetitions",
+			build: func(budget *parser.Budget) parser.Parser[int] {
+				return parser.Map(parser.CountWithin(budget, parser.Char('x'), 4), func(xs []string) (int, error) {
+					return len(xs), nil
+				})
+			},
+			input:         "xxxx",
+			limits:        parser.Limits{MaxRepetitions: 3},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantExceeded:  true,
+			wantErrMsg:    "parse budget exceeded: more than 3 repetitions",
+		},
+		{
+			name:          "nil build",
+			build:         nil,
+			input:         "[]",
+			limits:        parser.Limits{},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Run: build must be a non-nil function",
+		},
+		{
+			name:          "build returns nil",
+			build:         func(*parser.Budget) parser.Parser[int] { return nil },
+			input:         "[]",
+			limits:        parser.Limits{},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Run: build returned a nil parser",
+		},
+		{
+			name:          "guard nil parser",
+			build:         func(budget *parser.Budget) parser.Parser[int] { return parser.Guard[int](budget, nil) },
+			input:         "[]",
+			limits:        parser.Limits{},
+			wantValue:     0,
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Run: parser returned error: Guard: parser must not be nil",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Run(t.Context(), tt.limits, tt.input, tt.build)
+
+			testParser(t, parserTest[int]{
+				gotErr:        err,
+				gotValue:      value,
+				wantValue:     tt.wantValue,
+				gotRemainder:  remainder,
+				wantRemainder: tt.wantRemainder,
+				wantErrMsg:    tt.wantErrMsg,
+				wantErr:       tt.wantErr,
+			})
+
+			if exceeded := errors.Is(err, parser.ErrBudgetExceeded); exceeded != tt.wantExceeded {
+				t.Errorf("errors.Is(err, ErrBudgetExceeded) = %v, wanted %v", exceeded, tt.wantExceeded)
+			}
+		})
+	}
+}
+
+func TestRunBacktracking(t *testing.T) {
+	// Without a budget this takes 3^30 steps, so it only finishes because the budget stops it
+	input := strings.Repeat("a", 30) + "d"
+
+	_, _, err := parser.Run(t.Context(), parser.Limits{MaxSteps: 10000}, input, exponential)
+	if !errors.Is(err, parser.ErrBudgetExceeded) {
+		t.Fatalf("Run returned %v, wanted ErrBudgetExceeded", err)
+	}
+
+	// The same grammar is fine with inputs it doesn't have to backtrack on
+	value, remainder, err := parser.Run(t.Context(), parser.Limits{MaxSteps: 10000}, "aaabb", exponential)
+	if err != nil {
+		t.Fatalf("Run returned an unexpected error: %v", err)
+	}
+
+	if value != "aaabb" || remainder != "" {
+		t.Errorf("Run returned (%q, %q), wanted (\"aaabb\", \"\")", value, remainder)
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	input := strings.Repeat("a", 30) + "d"
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, _, err := parser.Run(ctx, parser.Limits{}, "[]", nested)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run returned %v, wanted context.Canceled", err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+
+		_, _, err := parser.Run(ctx, parser.Limits{}, input, exponential)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Run returned %v, wanted context.DeadlineExceeded", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Run took %v to notice the deadline", elapsed)
+		}
+	})
+}
+
+func TestRunContextDeepRecursion(t *testing.T) {
+	// Deep enough that parsing all of it would take a lot of time and stack
+	const depth = 1000000
+	input := strings.Repeat("[", depth) + strings.Repeat("]", depth)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	// Cancel part way down, counting how much deeper the parse goes after that
+	const cancelAt = 1000
+	calls := 0
+
+	build := func(budget *parser.Budget) parser.Parser[int] {
+		var n parser.Parser[int]
+
+		open := parser.Map(parser.Char('['), func(string) (int, error) { return 0, nil })
+		closing := parser.Map(parser.Char(']'), func(string) (int, error) { return 0, nil })
+		rest := parser.Lazy(func() parser.Parser[int] {
+			calls++
+			if calls == cancelAt {
+				cancel()
+			}
+			return n
+		})
+
+		n = parser.Try(
+			parser.Map(
+				parser.Chain(open, parser.Guard(budget, rest), closing),
+				func(depths []int) (int, error) { return depths[1] + 1, nil },
+			),
+			func(input string) (int, string, error) { return 0, input, nil },
+		)
+
+		return n
+	}
+
+	_, _, err := parser.Run(ctx, parser.Limits{}, input, build)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v, wanted context.Canceled", err)
+	}
+
+	// The context is checked every few hundred steps, so the recursion stops soon after
+	if calls > 2*cancelAt {
+		t.Errorf("Lazy was applied %d times, the recursion didn't stop soon after cancellation", calls)
+	}
+}
+
+func TestNilBudget(t *testing.T) {
+	// A nil budget has no limits, so grammars can be used outside of Run
+	value, remainder, err := nested(nil)("[[]]")
+	if err != nil {
+		t.Fatalf("nested returned an unexpected error: %v", err)
+	}
+
+	if value != 2 || remainder != "" {
+		t.Errorf("nested returned (%d, %q), wanted (2, \"\")", value, remainder)
+	}
+
+	var budget *parser.Budget
+	if err := budget.Step(); err != nil {
+		t.Errorf("Step on a nil budget returned %v", err)
+	}
+
+	if err := budget.Err(); err != nil {
+		t.Errorf("Err on a nil budget returned %v", err)
+	}
+}
+
+func ExampleRun() {
+	// Balanced brackets, which an untrusted input could nest deep enough to blow the stack
+	build := func(budget *parser.Budget) parser.Parser[string] {
+		var brackets parser.Parser[string]
+
+		brackets = parser.Try(
+			parser.Map(
+				parser.Chain(
+					parser.Exact("["),
+					parser.Guard(budget, parser.Lazy(func() parser.Parser[string] { return brackets })),
+					parser.Exact("]"),
+				),
+				func(parts []string) (string, error) { return strings.Join(parts, ""), nil },
+			),
+			func(input string) (string, string, error) { return "", input, nil },
+		)
+
+		return brackets
+	}
+
+	limits := parser.Limits{MaxDepth: 3}
+
+	value, remainder, err := parser.Run(context.Background(), limits, "[[[]]] rest", build)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	_, _, err = parser.Run(context.Background(), limits, "[[[[]]]]", build)
+	fmt.Println(err)
+
+	// Output: Value: "[[[]]]"
+	// Remainder: " rest"
+	// parse budget exceeded: nested more than 3 deep
+}
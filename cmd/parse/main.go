@@ -0,0 +1,199 @@
+// Command parse runs a grammar against some input and prints the parse tree, for debugging grammars
+// without writing any Go.
+//
+// The grammar is in the syntax of [go.followtheprocess.codes/parser/peg] and is compiled at runtime,
+// so any annotations for parsergen are ignored.
+//
+// Usage:
+//
+//	parse [flags] grammar.peg [file ...]
+//
+// Each file is parsed in turn, or standard input if there are none (or the file is "-"). The input
+// must match the grammar in full, text left over after the start rule has matched is an error.
+//
+// The flags are:
+//
+//	-format  how to print the parse tree: text (the default), json or dot (for Graphviz)
+//	-rule    the rule to start from, defaults to the first rule in the grammar
+//	-trace   print every rule as it's tried, matches or fails to stderr
+//
+// Errors are printed to stderr with the offending line and a caret pointing at the problem, and
+// the exit code says what went wrong:
+//
+//	0  every input matched the grammar
+//	1  at least one input didn't match the grammar
+//	2  the grammar is invalid, a file couldn't be read, or the flags were wrong
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/peg"
+)
+
+// Exit codes.
+const (
+	exitOK       = 0 // Every input matched
+	exitNoMatch  = 1 // At least one input didn't match
+	exitBadUsage = 2 // Anything else went wrong
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run parses the inputs named in args (or stdin) with the grammar named in args, writing the trees to
+// stdout and any errors to stderr, and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("parse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: parse [flags] grammar.peg [file ...]")
+		flags.PrintDefaults()
+	}
+
+	format := flags.String("format", "text", "how to print the parse tree: text, json or dot")
+	rule := flags.String("rule", "", "the rule to start from, defaults to the first rule in the grammar")
+	trace := flags.Bool("trace", false, "print every rule as it's tried, matches or fails to stderr")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+
+		return exitBadUsage
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitBadUsage
+	}
+
+	printer, ok := printers[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "parse: unknown format %q, expected text, json or dot\n", *format)
+		return exitBadUsage
+	}
+
+	var tr *tracer
+	if *trace {
+		tr = &tracer{w: stderr}
+	}
+
+	p, err := compile(flags.Arg(0), *rule, tr)
+	if err != nil {
+		fmt.Fprintf(stderr, "parse: %v\n", err)
+		return exitBadUsage
+	}
+
+	inputs := flags.Args()[1:]
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+
+	code := exitOK
+
+	for _, name := range inputs {
+		input, err := read(name, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "parse: %v\n", err)
+			return exitBadUsage
+		}
+
+		if name == "-" {
+			name = "<stdin>"
+		}
+
+		if tr != nil {
+			tr.start(name, input)
+		}
+
+		tree, err := parse(p, input)
+		if err != nil {
+			// Keep the trees and errors in the order of the inputs
+			out.Flush()
+			fmt.Fprint(stderr, render(name, input, err))
+
+			code = exitNoMatch
+
+			continue
+		}
+
+		if err := printer(out, name, tree); err != nil {
+			fmt.Fprintf(stderr, "parse: %v\n", err)
+			return exitBadUsage
+		}
+	}
+
+	return code
+}
+
+// compile reads and compiles the grammar in the named file, starting from rule, or the first rule
+// if it's empty. If tr is not nil, the parser reports the rules it tries to it.
+func compile(name, rule string, tr *tracer) (parser.Parser[peg.Node], error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	grammar, err := peg.Parse(string(src))
+	if err != nil {
+		return nil, errors.New(strings.TrimSuffix(render(name, string(src), err), "\n"))
+	}
+
+	switch {
+	case rule == "" && len(grammar.Rules) != 0:
+		rule = grammar.Rules[0].Name
+	case rule != "" && !slices.ContainsFunc(grammar.Rules, func(r peg.Rule) bool { return r.Name == rule }):
+		return nil, fmt.Errorf("%s has no rule named %q", name, rule)
+	}
+
+	var trace func(peg.Event)
+	if tr != nil {
+		trace = tr.event
+	}
+
+	p, err := grammar.TraceRule(rule, trace)
+	if err != nil {
+		return nil, errors.New(strings.TrimSuffix(render(name, string(src), err), "\n"))
+	}
+
+	return p, nil
+}
+
+// parse parses the whole of input, it's an error if any of it is left over.
+func parse(p parser.Parser[peg.Node], input string) (peg.Node, error) {
+	tree, remainder, err := p(input)
+	if err != nil {
+		return peg.Node{}, err
+	}
+
+	if remainder != "" {
+		return peg.Node{}, &peg.ParseError{Msg: "unexpected text after the end of the " + tree.Rule + " rule", Offset: len(input) - len(remainder)}
+	}
+
+	return tree, nil
+}
+
+// read reads the named input, with "-" meaning stdin.
+func read(name string, stdin io.Reader) (string, error) {
+	if name == "-" {
+		input, err := io.ReadAll(stdin)
+		return string(input), err
+	}
+
+	input, err := os.ReadFile(name)
+
+	return string(input), err
+}
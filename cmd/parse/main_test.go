@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Update the golden files with the output")
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name  string   // Identifying test case name, and the name of the golden file
+		stdin string   // The text on stdin
+		args  []string // The command line arguments
+		code  int      // The expected exit code
+	}{
+		{
+			name: "text",
+			args: []string{"testdata/calc.peg", "testdata/valid.txt"},
+			code: exitOK,
+		},
+		{
+			name: "json",
+			args: []string{"-format", "json", "testdata/calc.peg", "testdata/other.txt"},
+			code: exitOK,
+		},
+		{
+			name: "dot",
+			args: []string{"-format", "dot", "testdata/calc.peg", "testdata/other.txt"},
+			code: exitOK,
+		},
+		{
+			name:  "stdin",
+			args:  []string{"testdata/calc.peg"},
+			stdin: "1*2",
+			code:  exitOK,
+		},
+		{
+			name: "rule",
+			args: []string{"-rule", "number", "testdata/calc.peg", "-"},
+			code: exitOK,
+			// The rule doesn't end with !. but the whole input must still match
+			stdin: "12 ",
+		},
+		{
+			name: "trace",
+			args: []string{"--trace", "testdata/calc.peg", "testdata/other.txt"},
+			code: exitOK,
+		},
+		{
+			name: "no match",
+			args: []string{"testdata/calc.peg", "testdata/valid.txt", "testdata/invalid.txt", "testdata/other.txt"},
+			code: exitNoMatch,
+		},
+		{
+			name:  "left over",
+			args:  []string{"-rule", "number", "testdata/calc.peg"},
+			stdin: "12 + 3",
+			code:  exitNoMatch,
+		},
+		{
+			name: "invalid grammar",
+			args: []string{"testdata/undefined.peg", "testdata/valid.txt"},
+			code: exitBadUsage,
+		},
+		{
+			name: "undefined start rule",
+			args: []string{"-rule", "missing", "testdata/calc.peg", "testdata/valid.txt"},
+			code: exitBadUsage,
+		},
+		{
+			name: "missing input",
+			args: []string{"testdata/calc.peg", "testdata/missing.txt"},
+			code: exitBadUsage,
+		},
+		{
+			name: "unknown format",
+			args: []string{"-format", "yaml", "testdata/calc.peg"},
+			code: exitBadUsage,
+		},
+		{
+			name: "no grammar",
+			args: nil,
+			code: exitBadUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			code := run(tt.args, strings.NewReader(tt.stdin), stdout, stderr)
+			if code != tt.code {
+				t.Errorf("run(%q) exited with %d, wanted %d\nstderr:\n%s", tt.args, code, tt.code, stderr)
+			}
+
+			got := stdout.String() + "--- stderr ---\n" + stderr.String()
+			golden := filepath.Join("testdata", "golden", strings.ReplaceAll(tt.name, " ", "_")+".txt")
+
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != string(want) {
+				t.Errorf("output doesn't match %s, run the tests with -update if this is intended\nGot:\n%s\nWanted:\n%s", golden, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser/peg"
+)
+
+// maxLabel is the most chars of a node's text shown in a DOT label or a trace, longer text is cut
+// short with an ellipsis.
+const maxLabel = 40
+
+// printer prints the parse tree of the named input.
+type printer func(w io.Writer, name string, tree peg.Node) error
+
+// printers are the printers for each -format.
+var printers = map[string]printer{
+	"text": printText,
+	"json": printJSON,
+	"dot":  printDOT,
+}
+
+// printText prints the tree as an outline, one node per line and indented by depth.
+func printText(w io.Writer, _ string, tree peg.Node) error {
+	_, err := io.WriteString(w, tree.String())
+	return err
+}
+
+// jsonNode is how a [peg.Node] is printed as JSON.
+type jsonNode struct {
+	Rule     string     `json:"rule"`
+	Text     string     `json:"text"`
+	Start    int        `json:"start"`
+	End      int        `json:"end"`
+	Children []jsonNode `json:"children,omitempty"`
+}
+
+// printJSON prints the tree as a JSON object, with a line for each input so the output of
+// several inputs is a stream of JSON values.
+func printJSON(w io.Writer, _ string, tree peg.Node) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(toJSON(tree))
+}
+
+// toJSON converts a node and all its children to a jsonNode.
+func toJSON(node peg.Node) jsonNode {
+	converted := jsonNode{Rule: node.Rule, Text: node.Text, Start: node.Span.Start, End: node.Span.End}
+	for _, child := range node.Children {
+		converted.Children = append(converted.Children, toJSON(child))
+	}
+
+	return converted
+}
+
+// printDOT prints the tree as a Graphviz digraph named after the input, each node labelled with
+// its rule and the text it matched.
+func printDOT(w io.Writer, name string, tree peg.Node) error {
+	s := &strings.Builder{}
+
+	fmt.Fprintf(s, "digraph %s {\n", dotQuote(name))
+	s.WriteString("\tnode [shape=box, fontname=monospace];\n")
+
+	id := 0
+
+	var walk func(node peg.Node) int
+	walk = func(node peg.Node) int {
+		self := id
+		id++
+
+		fmt.Fprintf(s, "\tn%d [label=%s];\n", self, dotQuote(node.Rule+"\n"+shorten(node.Text)))
+
+		for _, child := range node.Children {
+			fmt.Fprintf(s, "\tn%d -> n%d;\n", self, walk(child))
+		}
+
+		return self
+	}
+
+	walk(tree)
+	s.WriteString("}\n")
+
+	_, err := io.WriteString(w, s.String())
+
+	return err
+}
+
+// dotQuote returns s as a DOT string, in which only quotes, backslashes and newlines are escaped.
+func dotQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// shorten returns text as a quoted Go string, cut short if it's longer than maxLabel chars.
+func shorten(text string) string {
+	if utf8.RuneCountInString(text) <= maxLabel {
+		return strconv.Quote(text)
+	}
+
+	runes := []rune(text)
+
+	return strconv.Quote(string(runes[:maxLabel])) + "..."
+}
+
+// tracer prints the events from a traced parse, one line per event indented by the depth of the
+// rule, with the line and column in the input the rule was tried at.
+type tracer struct {
+	w     io.Writer
+	input string // The input being parsed
+	lines []int  // Offset of the start of each line in input
+}
+
+// start prepares the tracer for parsing the named input.
+func (t *tracer) start(name, input string) {
+	t.input = input
+	t.lines = append(t.lines[:0], 0)
+
+	for i := range len(input) {
+		if input[i] == '\n' {
+			t.lines = append(t.lines, i+1)
+		}
+	}
+
+	fmt.Fprintf(t.w, "trace of %s:\n", name)
+}
+
+// event prints a single event.
+func (t *tracer) event(event peg.Event) {
+	// The line is the last one that starts at or before the offset
+	offset := event.Span.Start
+	line := sort.SearchInts(t.lines, offset+1) - 1
+	column := utf8.RuneCountInString(t.input[t.lines[line]:offset]) + 1
+
+	var what string
+
+	switch event.Step {
+	case peg.StepEnter:
+		what = event.Rule
+	case peg.StepMatch:
+		what = event.Rule + " = " + shorten(t.input[event.Span.Start:event.Span.End])
+	default:
+		what = event.Rule + " failed"
+	}
+
+	fmt.Fprintf(t.w, "%-9s %s%s\n", fmt.Sprintf("%d:%d", line+1, column), strings.Repeat("  ", event.Depth-1), what)
+}
+
+// render returns err as a message pointing into the named source, along with the line of source
+// it's on with a caret under the problem.
+func render(name, src string, err error) string {
+	var (
+		msg    string
+		offset int
+	)
+
+	var (
+		parseErr  *peg.ParseError
+		syntaxErr *peg.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &parseErr):
+		msg, offset = parseErr.Msg, parseErr.Offset
+	case errors.As(err, &syntaxErr) && syntaxErr.Line != 0:
+		msg, offset = syntaxErr.Msg, syntaxErr.Offset
+	default:
+		return fmt.Sprintf("%s: %v\n", name, err)
+	}
+
+	offset = min(max(offset, 0), len(src))
+
+	lineStart := strings.LastIndexByte(src[:offset], '\n') + 1
+	lineEnd := strings.IndexByte(src[offset:], '\n')
+
+	if lineEnd == -1 {
+		lineEnd = len(src)
+	} else {
+		lineEnd += offset
+	}
+
+	line := 1 + strings.Count(src[:lineStart], "\n")
+	text := strings.TrimSuffix(src[lineStart:lineEnd], "\r")
+	before := src[lineStart:offset]
+
+	// Tabs are kept so the caret lines up however wide they're displayed
+	var indent strings.Builder
+	for _, char := range before {
+		if char == '\t' {
+			indent.WriteByte('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+
+	number := strconv.Itoa(line)
+	gutter := strings.Repeat(" ", len(number))
+
+	s := &strings.Builder{}
+	fmt.Fprintf(s, "%s:%d:%d: %s\n", name, line, utf8.RuneCountInString(before)+1, msg)
+	fmt.Fprintf(s, " %s | %s\n", number, text)
+	fmt.Fprintf(s, " %s | %s^\n", gutter, indent.String())
+
+	return s.String()
+}
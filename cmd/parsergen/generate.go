@@ -0,0 +1,579 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser/peg"
+)
+
+// maxClassChars is the most chars a class can match for it to be generated as a [parser.OneOf]
+// or [parser.NoneOf] with every char spelled out, larger classes are generated as a predicate.
+const maxClassChars = 256
+
+// endOfInput is the code for a parser of the end of the input, which is what '!.' matches.
+const endOfInput = `parser.Parser[string](func(input string) (string, string, error) {
+if input != "" {
+return "", "", errors.New("expected end of input")
+}
+
+return "", input, nil
+})`
+
+// parserPackage is the import path of the parser package the generated code is built on.
+const parserPackage = "go.followtheprocess.codes/parser"
+
+// config is everything about the generated code that doesn't come from the grammar.
+type config struct {
+	File    string // Name of the grammar file, for the header and in errors
+	Package string // Name of the package the generated code is in
+	Type    string // Name of the generated grammar type
+}
+
+// generator turns the rules of a grammar into Go source.
+type generator struct {
+	cfg     config
+	src     string              // The grammar source, for positions in errors
+	rules   map[string]peg.Rule // The rules by name
+	fields  map[string]string   // The name of the field holding each rule's parser, by rule name
+	defined map[string]bool     // The rules whose field has been assigned at this point in the constructor
+	imports map[string]bool     // Packages the generated code uses besides the parser package, by path
+	rule    peg.Rule            // The rule being generated
+}
+
+// generate parses and checks a grammar, returning the formatted Go source of a parser for it.
+func generate(src string, cfg config) ([]byte, error) {
+	grammar, err := peg.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.File, err)
+	}
+
+	// Compiling checks for undefined rules and left recursion, which would be a generated
+	// parser that never terminates
+	if _, err = grammar.Compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.File, err)
+	}
+
+	gen := &generator{
+		cfg:     cfg,
+		src:     src,
+		rules:   make(map[string]peg.Rule, len(grammar.Rules)),
+		fields:  make(map[string]string, len(grammar.Rules)),
+		defined: make(map[string]bool, len(grammar.Rules)),
+		imports: make(map[string]bool),
+	}
+
+	// Fields are the rule names starting with a lower case letter and methods with an upper case one,
+	// so rules with names that differ only in the case of the first letter would clash
+	methods := make(map[string]string, len(grammar.Rules)) // Rule names by their method name
+	fields := make(map[string]string, len(grammar.Rules))  // Rule names by their field name
+
+	for _, rule := range grammar.Rules {
+		field, method := fieldName(rule.Name), methodName(rule.Name)
+
+		if other, exists := fields[field]; exists {
+			return nil, gen.errorf(rule.Span.Start, "rules %q and %q would both generate the field %s", other, rule.Name, field)
+		}
+
+		if other, exists := methods[method]; exists && method != "" {
+			return nil, gen.errorf(rule.Span.Start, "rules %q and %q would both generate the method %s", other, rule.Name, method)
+		}
+
+		fields[field], methods[method] = rule.Name, rule.Name
+		gen.rules[rule.Name], gen.fields[rule.Name] = rule, field
+	}
+
+	// The rules are generated first, so we know what they need importing
+	constructor := &bytes.Buffer{}
+
+	fmt.Fprintf(constructor, "// New%s returns a [%s] ready to parse input, it's safe for concurrent use.\n", cfg.Type, cfg.Type)
+	fmt.Fprintf(constructor, "func New%s() *%s {\n", cfg.Type, cfg.Type)
+	fmt.Fprintf(constructor, "g := &%s{}\n\n", cfg.Type)
+
+	for _, rule := range grammar.Rules {
+		gen.rule = rule
+
+		code, err := gen.ruleParser(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(constructor, "g.%s = %s\n", gen.fields[rule.Name], code)
+
+		gen.defined[rule.Name] = true
+	}
+
+	fmt.Fprintf(constructor, "\nreturn g\n}\n")
+
+	imports, preamble, err := gen.splitPreamble(grammar.Preamble)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+
+	fmt.Fprintf(out, "// Code generated by parsergen from %s. DO NOT EDIT.\n\n", cfg.File)
+	fmt.Fprintf(out, "package %s\n\n", cfg.Package)
+	fmt.Fprintf(out, "import (\n%s\n)\n\n", imports)
+
+	if preamble != "" {
+		fmt.Fprintf(out, "%s\n\n", preamble)
+	}
+
+	fmt.Fprintf(out, "// %s is a parser for the grammar in %s, with a method for each of its rules.\n", cfg.Type, cfg.File)
+	fmt.Fprintf(out, "type %s struct {\n", cfg.Type)
+
+	for _, rule := range grammar.Rules {
+		fmt.Fprintf(out, "%s parser.Parser[%s]", gen.fields[rule.Name], goType(rule))
+
+		if gen.fields[rule.Name] != rule.Name {
+			fmt.Fprintf(out, " // The %s rule", rule.Name)
+		}
+
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintf(out, "}\n\n")
+
+	out.Write(constructor.Bytes())
+
+	for _, rule := range grammar.Rules {
+		method := methodName(rule.Name)
+		if method == "" {
+			continue
+		}
+
+		fmt.Fprintf(out, "\n// %s parses the start of input as the %s rule, returning its value and the remaining input.\n", method, rule.Name)
+		fmt.Fprintf(out, "func (g *%s) %s(input string) (%s, string, error) {\n", cfg.Type, method, goType(rule))
+		fmt.Fprintf(out, "return g.%s(input)\n}\n", gen.fields[rule.Name])
+	}
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: generated code is not valid Go, check the preamble, types and actions: %w", cfg.File, err)
+	}
+
+	return formatted, nil
+}
+
+// splitPreamble splits the preamble of a grammar into its imports and the rest of its code, returning
+// the imports merged with those the generated code needs as the contents of a single import block.
+//
+// Like goimports, the standard library imports are grouped before the others.
+func (gen *generator) splitPreamble(preamble string) (imports, code string, err error) {
+	specs := []string{strconv.Quote(parserPackage)}
+	for path := range gen.imports {
+		specs = append(specs, strconv.Quote(path))
+	}
+
+	// The imports are found by parsing the preamble as the start of a file
+	const header = "package p; "
+
+	file, err := parser.ParseFile(token.NewFileSet(), gen.cfg.File, header+preamble, parser.ImportsOnly)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: generated code is not valid Go, check the preamble, types and actions: %w", gen.cfg.File, err)
+	}
+
+	// With ImportsOnly, the only declarations are the imports, which come before anything else
+	code = preamble
+	if len(file.Decls) != 0 {
+		offset := file.FileStart + token.Pos(len(header))
+		start, end := file.Decls[0].Pos()-offset, file.Decls[len(file.Decls)-1].End()-offset
+
+		for _, spec := range file.Imports {
+			specs = append(specs, preamble[spec.Pos()-offset:spec.End()-offset])
+		}
+
+		code = preamble[:start] + preamble[end:]
+	}
+
+	var standard, others []string
+
+	for _, spec := range specs {
+		if slices.Contains(standard, spec) || slices.Contains(others, spec) {
+			continue
+		}
+
+		// The path is the last part of the spec, after any name, and a path in the standard library
+		// has no dot in its first element
+		path, _ := strconv.Unquote(spec[strings.LastIndexAny(spec, " \t")+1:])
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			others = append(others, spec)
+		} else {
+			standard = append(standard, spec)
+		}
+	}
+
+	slices.Sort(standard)
+	slices.Sort(others)
+
+	imports = strings.Join(standard, "\n")
+	if len(standard) != 0 && len(others) != 0 {
+		imports += "\n\n"
+	}
+
+	imports += strings.Join(others, "\n")
+
+	return imports, strings.TrimSpace(code), nil
+}
+
+// ruleParser returns the code for the parser of a rule.
+//
+// A rule without a type is text, its value is the input it matched. A rule with a type gets its value
+// from the actions after its alternatives, or from an alternative that's a reference to a rule of the
+// same type.
+func (gen *generator) ruleParser(rule peg.Rule) (string, error) {
+	if rule.Type == "" {
+		return gen.text(rule.Expr)
+	}
+
+	alternatives := []peg.Expr{rule.Expr}
+	if rule.Expr.Kind == peg.KindChoice {
+		alternatives = rule.Expr.Children
+	}
+
+	parsers := make([]string, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		code, err := gen.alternative(alternative)
+		if err != nil {
+			return "", err
+		}
+
+		parsers = append(parsers, code)
+	}
+
+	if len(parsers) == 1 {
+		return parsers[0], nil
+	}
+
+	return call("parser.Try", parsers...), nil
+}
+
+// alternative returns the code for one alternative of a rule with a type.
+func (gen *generator) alternative(expr peg.Expr) (string, error) {
+	switch {
+	case expr.Kind == peg.KindAction:
+		return gen.action(expr)
+	case expr.Kind == peg.KindReference && goType(gen.rules[expr.Text]) == goType(gen.rule):
+		return gen.reference(gen.rules[expr.Text]), nil
+	case goType(gen.rule) == "string":
+		return gen.text(expr)
+	default:
+		return "", gen.errorf(expr.Span.Start, "rule %q: alternative %s needs an action to produce a %s", gen.rule.Name, expr, gen.rule.Type)
+	}
+}
+
+// action returns the code for a sequence followed by an action: a parser that applies the parser of
+// each item in turn, then passes the values of the labelled items to a function whose body is the
+// action and whose parameters are the labels.
+func (gen *generator) action(expr peg.Expr) (string, error) {
+	sequence := expr.Children[0]
+
+	items := []peg.Expr{sequence}
+	if sequence.Kind == peg.KindSequence {
+		items = sequence.Children
+	}
+
+	signature := fmt.Sprintf("(%s, error)", gen.rule.Type)
+
+	if len(items) == 0 {
+		return fmt.Sprintf("parser.Map(parser.Succeed(\"\"), func(string) %s {\n%s\n})", signature, expr.Text), nil
+	}
+
+	parsers := make([]string, 0, len(items)) // The code for the parser of each item
+	values := make([]string, 0, len(items))  // The variable each item's value is assigned to, "_" if it isn't labelled
+	vars := make([]string, 0, len(items))    // The variables holding the values of the labelled items and their types
+	params := make([]string, 0, len(items))  // The labels and their types, as parameters of the action
+	args := make([]string, 0, len(items))    // The values of the labels, as the arguments to the action
+	labels := make(map[string]bool)
+
+	for i, child := range items {
+		if child.Kind != peg.KindLabel {
+			code, err := gen.text(child)
+			if err != nil {
+				return "", err
+			}
+
+			parsers = append(parsers, code)
+			values = append(values, "_")
+
+			continue
+		}
+
+		label := child.Text
+		switch {
+		case labels[label]:
+			return "", gen.errorf(child.Span.Start, "rule %q: label %q used more than once in the same sequence", gen.rule.Name, label)
+		case token.IsKeyword(label), label == "_":
+			return "", gen.errorf(child.Span.Start, "rule %q: %q can't be used as a label", gen.rule.Name, label)
+		}
+
+		labels[label] = true
+
+		code, valueType, err := gen.value(child.Children[0])
+		if err != nil {
+			return "", err
+		}
+
+		value := fmt.Sprintf("value%d", i)
+
+		parsers = append(parsers, code)
+		values = append(values, value)
+		vars = append(vars, value+" "+valueType)
+		params = append(params, label+" "+valueType)
+		args = append(args, value)
+	}
+
+	// The item parsers are built once, outside the parser that applies them. The action is a function
+	// of the labels so they're in scope in its body, and don't clash with anything else in the
+	// generated code
+	code := &strings.Builder{}
+
+	fmt.Fprintf(code, "func() parser.Parser[%s] {\n", gen.rule.Type)
+
+	for i, item := range parsers {
+		fmt.Fprintf(code, "item%d := %s\n", i, item)
+	}
+
+	fmt.Fprintf(code, "\nreturn func(input string) (%s, string, error) {\n", gen.rule.Type)
+	fmt.Fprintf(code, "var (\nzero %s\n", gen.rule.Type)
+
+	for _, v := range vars {
+		fmt.Fprintf(code, "%s\n", v)
+	}
+
+	fmt.Fprintf(code, "err error\n)\n\n")
+	fmt.Fprintf(code, "remainder := input\n\n")
+
+	for i, value := range values {
+		fmt.Fprintf(code, "if %s, remainder, err = item%d(remainder); err != nil {\nreturn zero, \"\", err\n}\n\n", value, i)
+	}
+
+	fmt.Fprintf(code, "value, err := func(%s) %s {\n%s\n}(%s)\n", strings.Join(params, ", "), signature, expr.Text, strings.Join(args, ", "))
+	fmt.Fprintf(code, "if err != nil {\nreturn zero, \"\", err\n}\n\n")
+	fmt.Fprintf(code, "return value, remainder, nil\n}\n}()")
+
+	return code.String(), nil
+}
+
+// value returns the code for the parser of a labelled expression and the type of its value.
+//
+// The value of a reference to a rule with a type is the rule's value, and repeating one collects
+// the values into a slice, or the zero value if it's optional and missing. The value of anything
+// else is the text it matched.
+func (gen *generator) value(expr peg.Expr) (code, valueType string, err error) {
+	if expr.Kind == peg.KindReference && gen.rules[expr.Text].Type != "" {
+		return gen.reference(gen.rules[expr.Text]), gen.rules[expr.Text].Type, nil
+	}
+
+	switch expr.Kind {
+	case peg.KindZeroOrMore, peg.KindOneOrMore, peg.KindOptional:
+		operand := expr.Children[0]
+		if operand.Kind != peg.KindReference || gen.rules[operand.Text].Type == "" {
+			break
+		}
+
+		rule := gen.rules[operand.Text]
+		ref := gen.reference(rule)
+
+		if expr.Kind == peg.KindOptional {
+			return fmt.Sprintf("parser.Try(%s, parser.Succeed(*new(%s)))", ref, rule.Type), rule.Type, nil
+		}
+
+		sliceType := "[]" + rule.Type
+		code = fmt.Sprintf(
+			"parser.FoldMany(%s, func() %s { return nil }, func(values %s, value %s) %s { return append(values, value) })",
+			ref, sliceType, sliceType, rule.Type, sliceType,
+		)
+
+		if expr.Kind == peg.KindOneOrMore {
+			code = fmt.Sprintf("parser.Verify(%s, func(values %s) bool { return len(values) > 0 }, %q)", code, sliceType, "expected at least one "+rule.Name)
+		}
+
+		return code, sliceType, nil
+	}
+
+	code, err = gen.text(expr)
+	if err != nil {
+		return "", "", err
+	}
+
+	return code, "string", nil
+}
+
+// text returns the code for a [parser.Parser] of string whose value is the input the expression matched.
+func (gen *generator) text(expr peg.Expr) (string, error) {
+	switch expr.Kind {
+	case peg.KindLiteral:
+		if expr.Text == "" {
+			return `parser.Succeed("")`, nil
+		}
+
+		return fmt.Sprintf("parser.Exact(%s)", strconv.Quote(expr.Text)), nil
+	case peg.KindClass:
+		return class(expr), nil
+	case peg.KindAny:
+		return "parser.Take(1)", nil
+	case peg.KindReference:
+		rule := gen.rules[expr.Text]
+		if goType(rule) != "string" {
+			return fmt.Sprintf("parser.Recognize(%s)", gen.reference(rule)), nil
+		}
+
+		return gen.reference(rule), nil
+	case peg.KindLabel:
+		return "", gen.errorf(expr.Span.Start, "rule %q: label %q must be on an item of a sequence followed by an action", gen.rule.Name, expr.Text)
+	case peg.KindAction:
+		if gen.rule.Type == "" {
+			return "", gen.errorf(expr.Span.Start, "rule %q: actions need the rule to have a type, like %s <T> <- ...", gen.rule.Name, gen.rule.Name)
+		}
+
+		return "", gen.errorf(expr.Span.Start, "rule %q: actions can only follow the alternatives of a rule, not be nested in them", gen.rule.Name)
+	}
+
+	operands := make([]string, 0, len(expr.Children))
+	for _, child := range expr.Children {
+		code, err := gen.text(child)
+		if err != nil {
+			return "", err
+		}
+
+		operands = append(operands, code)
+	}
+
+	switch expr.Kind {
+	case peg.KindSequence:
+		if len(operands) == 0 {
+			return `parser.Succeed("")`, nil
+		}
+
+		return fmt.Sprintf("parser.Recognize(%s)", call("parser.Chain", operands...)), nil
+	case peg.KindChoice:
+		return call("parser.Try", operands...), nil
+	case peg.KindZeroOrMore:
+		return fmt.Sprintf("parser.SkipMany(%s)", operands[0]), nil
+	case peg.KindOneOrMore:
+		return fmt.Sprintf("parser.Recognize(parser.Chain(%s, parser.SkipMany(%s)))", operands[0], operands[0]), nil
+	case peg.KindOptional:
+		return fmt.Sprintf(`parser.Try(%s, parser.Succeed(""))`, operands[0]), nil
+	case peg.KindAnd:
+		return fmt.Sprintf("parser.Recognize(parser.Peek(%s))", operands[0]), nil
+	case peg.KindNot:
+		if expr.Children[0].Kind == peg.KindAny {
+			// Nothing but the end of the input isn't followed by any char
+			gen.imports["errors"] = true
+			return endOfInput, nil
+		}
+
+		return fmt.Sprintf("parser.Not(%s)", operands[0]), nil
+	default:
+		return "", gen.errorf(expr.Span.Start, "rule %q: unknown expression kind %s", gen.rule.Name, expr.Kind)
+	}
+}
+
+// reference returns the code for using the parser of a rule. Rules are assigned in the order they're
+// defined, so a rule that's not been assigned yet is used through [parser.Lazy].
+func (gen *generator) reference(rule peg.Rule) string {
+	if gen.defined[rule.Name] {
+		return "g." + gen.fields[rule.Name]
+	}
+
+	return fmt.Sprintf("parser.Lazy(func() parser.Parser[%s] { return g.%s })", goType(rule), gen.fields[rule.Name])
+}
+
+// errorf returns an error at an offset in the grammar, in the file:line:column form editors understand.
+func (gen *generator) errorf(offset int, format string, args ...any) error {
+	before := gen.src[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+
+	return fmt.Errorf("%s:%d:%d: %s", gen.cfg.File, line, column, fmt.Sprintf(format, args...))
+}
+
+// class returns the code for a char class, either every char it matches spelled out or, if there are
+// too many, a predicate checking the ranges.
+func class(expr peg.Expr) string {
+	var chars strings.Builder
+
+	count := 0
+	for _, r := range expr.Ranges {
+		count += int(r.High-r.Low) + 1
+	}
+
+	if count <= maxClassChars {
+		for _, r := range expr.Ranges {
+			for char := r.Low; char <= r.High; char++ {
+				chars.WriteRune(char)
+			}
+		}
+
+		if expr.Negated {
+			return fmt.Sprintf("parser.NoneOf(%s)", strconv.Quote(chars.String()))
+		}
+
+		return fmt.Sprintf("parser.OneOf(%s)", strconv.Quote(chars.String()))
+	}
+
+	conditions := make([]string, 0, len(expr.Ranges))
+	for _, r := range expr.Ranges {
+		if r.Low == r.High {
+			conditions = append(conditions, fmt.Sprintf("r == %s", strconv.QuoteRune(r.Low)))
+			continue
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(r >= %s && r <= %s)", strconv.QuoteRune(r.Low), strconv.QuoteRune(r.High)))
+	}
+
+	condition := strings.Join(conditions, " || ")
+	if expr.Negated {
+		condition = fmt.Sprintf("!(%s)", condition)
+	}
+
+	return fmt.Sprintf("parser.TakeWhileBetween(1, 1, func(r rune) bool { return %s })", condition)
+}
+
+// call returns the code for a call of a variadic function, with each argument on its own line.
+func call(function string, args ...string) string {
+	return fmt.Sprintf("%s(\n%s,\n)", function, strings.Join(args, ",\n"))
+}
+
+// goType returns the type of a rule's value, rules without a type are text.
+func goType(rule peg.Rule) string {
+	if rule.Type == "" {
+		return "string"
+	}
+
+	return rule.Type
+}
+
+// fieldName returns the name of the field that holds a rule's parser, which is the rule's name
+// starting with a lower case letter, unless that's not allowed as a field name.
+func fieldName(name string) string {
+	first, width := utf8.DecodeRuneInString(name)
+	name = string(unicode.ToLower(first)) + name[width:]
+
+	if token.IsKeyword(name) || name == "_" {
+		return name + "Rule"
+	}
+
+	return name
+}
+
+// methodName returns the name of the method that parses a rule, or "" if the rule is unexported
+// because its name doesn't start with a letter.
+func methodName(name string) string {
+	first, width := utf8.DecodeRuneInString(name)
+	if !unicode.IsLetter(first) {
+		return ""
+	}
+
+	return string(unicode.ToUpper(first)) + name[width:]
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Update the golden files with the generated code")
+
+func TestGenerateGolden(t *testing.T) {
+	grammars, err := filepath.Glob(filepath.Join("testdata", "*.peg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(grammars) == 0 {
+		t.Fatal("no grammars in testdata")
+	}
+
+	for _, grammar := range grammars {
+		t.Run(filepath.Base(grammar), func(t *testing.T) {
+			src, err := os.ReadFile(grammar)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := generate(string(src), config{File: filepath.Base(grammar), Package: "golden", Type: "Grammar"})
+			if err != nil {
+				t.Fatalf("generate returned an unexpected error: %v", err)
+			}
+
+			golden := strings.TrimSuffix(grammar, ".peg") + ".golden"
+
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != string(want) {
+				t.Errorf("generated code doesn't match %s, run the tests with -update if this is intended\nGot:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestGenerateUpToDate(t *testing.T) {
+	// The calc example is generated with go generate, make sure it's been run since the generator changed
+	src, err := os.ReadFile(filepath.Join("internal", "calc", "calc.peg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(string(src), config{File: "calc.peg", Package: "calc", Type: "Grammar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("internal", "calc", "calc_parser.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Error("internal/calc/calc_parser.go is out of date, run go generate ./...")
+	}
+}
+
+func TestGenerateError(t *testing.T) {
+	tests := []struct {
+		name string // Identifying test case name
+		src  string // The grammar to generate a parser for
+		err  string // The expected error message
+	}{
+		{
+			name: "syntax error",
+			src:  "a <- 'x",
+			err:  "test.peg: peg: unterminated literal at line 1, column 6",
+		},
+		{
+			name: "undefined rule",
+			src:  "a <- b",
+			err:  `test.peg: peg: undefined rule "b" at line 1, column 6`,
+		},
+		{
+			name: "left recursion",
+			src:  "a <- a 'x' / 'y'",
+			err:  `test.peg: peg: rule "a" is left recursive: a -> a at line 1, column 1`,
+		},
+		{
+			name: "method clash",
+			src:  "a <- A\nA <- 'x'",
+			err:  `test.peg:2:1: rules "a" and "A" would both generate the field a`,
+		},
+		{
+			name: "field clash",
+			src:  "a <- forRule\nforRule <- for\nfor <- 'x'",
+			err:  `test.peg:3:1: rules "forRule" and "for" would both generate the field forRule`,
+		},
+		{
+			name: "action without a type",
+			src:  "a <- x:'x' { return x, nil }",
+			err:  `test.peg:1:6: rule "a": actions need the rule to have a type, like a <T> <- ...`,
+		},
+		{
+			name: "nested action",
+			src:  "a <int> <- ('x' { return 1, nil }) { return 2, nil }",
+			err:  `test.peg:1:13: rule "a": actions can only follow the alternatives of a rule, not be nested in them`,
+		},
+		{
+			name: "alternative without an action",
+			src:  "a <int> <- 'x' / b\nb <int> <- 'y' { return 1, nil }",
+			err:  `test.peg:1:12: rule "a": alternative "x" needs an action to produce a int`,
+		},
+		{
+			name: "reference to a different type",
+			src:  "a <int> <- b\nb <- 'y'",
+			err:  `test.peg:1:12: rule "a": alternative b needs an action to produce a int`,
+		},
+		{
+			name: "label without an action",
+			src:  "a <- x:'x'",
+			err:  `test.peg:1:6: rule "a": label "x" must be on an item of a sequence followed by an action`,
+		},
+		{
+			name: "nested label",
+			src:  "a <int> <- ('x' y:'y')* { return 1, nil }",
+			err:  `test.peg:1:17: rule "a": label "y" must be on an item of a sequence followed by an action`,
+		},
+		{
+			name: "duplicate label",
+			src:  "a <int> <- x:'x' x:'y' { return 1, nil }",
+			err:  `test.peg:1:18: rule "a": label "x" used more than once in the same sequence`,
+		},
+		{
+			name: "keyword label",
+			src:  "a <int> <- func:'x' { return 1, nil }",
+			err:  `test.peg:1:12: rule "a": "func" can't be used as a label`,
+		},
+		{
+			name: "invalid import",
+			src:  "{ import strconv }\na <- 'x'",
+			err:  "test.peg: generated code is not valid Go, check the preamble, types and actions: ",
+		},
+		{
+			name: "invalid action",
+			src:  "a <int> <- 'x' { return ) }",
+			err:  "test.peg: generated code is not valid Go, check the preamble, types and actions: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(tt.src, config{File: "test.peg", Package: "test", Type: "Grammar"})
+			if err == nil {
+				t.Fatalf("generate(%q) returned no error", tt.src)
+			}
+
+			if !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("\nGot:\t%v\nWanted:\t%s\n", err, tt.err)
+			}
+		})
+	}
+}
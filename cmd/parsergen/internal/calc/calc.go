@@ -0,0 +1,4 @@
+// Package calc is an example of a parser generated by parsergen, it evaluates integer arithmetic.
+package calc
+
+//go:generate go run go.followtheprocess.codes/parser/cmd/parsergen calc.peg
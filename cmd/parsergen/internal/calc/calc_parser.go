@@ -0,0 +1,379 @@
+// Code generated by parsergen from calc.peg. DO NOT EDIT.
+
+package calc
+
+import (
+	"errors"
+	"strconv"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// operation is an operator along with the operand on its right.
+type operation struct {
+	operator string
+	operand  int
+}
+
+// apply applies each operation to total in turn, from left to right.
+func apply(total int, operations []operation) (int, error) {
+	for _, op := range operations {
+		switch op.operator {
+		case "+":
+			total += op.operand
+		case "-":
+			total -= op.operand
+		case "*":
+			total *= op.operand
+		case "/":
+			if op.operand == 0 {
+				return 0, errors.New("division by zero")
+			}
+			total /= op.operand
+		}
+	}
+
+	return total, nil
+}
+
+// Grammar is a parser for the grammar in calc.peg, with a method for each of its rules.
+type Grammar struct {
+	expr              parser.Parser[int]
+	sum               parser.Parser[int]
+	_sumOperation     parser.Parser[operation]
+	product           parser.Parser[int]
+	_productOperation parser.Parser[operation]
+	factor            parser.Parser[int]
+	number            parser.Parser[int]
+	_Rule             parser.Parser[string] // The _ rule
+}
+
+// NewGrammar returns a [Grammar] ready to parse input, it's safe for concurrent use.
+func NewGrammar() *Grammar {
+	g := &Grammar{}
+
+	g.expr = func() parser.Parser[int] {
+		item0 := parser.Lazy(func() parser.Parser[string] { return g._Rule })
+		item1 := parser.Lazy(func() parser.Parser[int] { return g.sum })
+		item2 := parser.Parser[string](func(input string) (string, string, error) {
+			if input != "" {
+				return "", "", errors.New("expected end of input")
+			}
+
+			return "", input, nil
+		})
+
+		return func(input string) (int, string, error) {
+			var (
+				zero   int
+				value1 int
+				err    error
+			)
+
+			remainder := input
+
+			if _, remainder, err = item0(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if value1, remainder, err = item1(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if _, remainder, err = item2(remainder); err != nil {
+				return zero, "", err
+			}
+
+			value, err := func(value int) (int, error) {
+				return value, nil
+			}(value1)
+			if err != nil {
+				return zero, "", err
+			}
+
+			return value, remainder, nil
+		}
+	}()
+	g.sum = func() parser.Parser[int] {
+		item0 := parser.Lazy(func() parser.Parser[int] { return g.product })
+		item1 := parser.FoldMany(parser.Lazy(func() parser.Parser[operation] { return g._sumOperation }), func() []operation { return nil }, func(values []operation, value operation) []operation { return append(values, value) })
+
+		return func(input string) (int, string, error) {
+			var (
+				zero   int
+				value0 int
+				value1 []operation
+				err    error
+			)
+
+			remainder := input
+
+			if value0, remainder, err = item0(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if value1, remainder, err = item1(remainder); err != nil {
+				return zero, "", err
+			}
+
+			value, err := func(first int, rest []operation) (int, error) {
+				return apply(first, rest)
+			}(value0, value1)
+			if err != nil {
+				return zero, "", err
+			}
+
+			return value, remainder, nil
+		}
+	}()
+	g._sumOperation = func() parser.Parser[operation] {
+		item0 := parser.OneOf("+-")
+		item1 := parser.Lazy(func() parser.Parser[string] { return g._Rule })
+		item2 := parser.Lazy(func() parser.Parser[int] { return g.product })
+
+		return func(input string) (operation, string, error) {
+			var (
+				zero   operation
+				value0 string
+				value2 int
+				err    error
+			)
+
+			remainder := input
+
+			if value0, remainder, err = item0(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if _, remainder, err = item1(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if value2, remainder, err = item2(remainder); err != nil {
+				return zero, "", err
+			}
+
+			value, err := func(operator string, operand int) (operation, error) {
+				return operation{operator, operand}, nil
+			}(value0, value2)
+			if err != nil {
+				return zero, "", err
+			}
+
+			return value, remainder, nil
+		}
+	}()
+	g.product = func() parser.Parser[int] {
+		item0 := parser.Lazy(func() parser.Parser[int] { return g.factor })
+		item1 := parser.FoldMany(parser.Lazy(func() parser.Parser[operation] { return g._productOperation }), func() []operation { return nil }, func(values []operation, value operation) []operation { return append(values, value) })
+
+		return func(input string) (int, string, error) {
+			var (
+				zero   int
+				value0 int
+				value1 []operation
+				err    error
+			)
+
+			remainder := input
+
+			if value0, remainder, err = item0(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if value1, remainder, err = item1(remainder); err != nil {
+				return zero, "", err
+			}
+
+			value, err := func(first int, rest []operation) (int, error) {
+				return apply(first, rest)
+			}(value0, value1)
+			if err != nil {
+				return zero, "", err
+			}
+
+			return value, remainder, nil
+		}
+	}()
+	g._productOperation = func() parser.Parser[operation] {
+		item0 := parser.OneOf("*/")
+		item1 := parser.Lazy(func() parser.Parser[string] { return g._Rule })
+		item2 := parser.Lazy(func() parser.Parser[int] { return g.factor })
+
+		return func(input string) (operation, string, error) {
+			var (
+				zero   operation
+				value0 string
+				value2 int
+				err    error
+			)
+
+			remainder := input
+
+			if value0, remainder, err = item0(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if _, remainder, err = item1(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if value2, remainder, err = item2(remainder); err != nil {
+				return zero, "", err
+			}
+
+			value, err := func(operator string, operand int) (operation, error) {
+				return operation{operator, operand}, nil
+			}(value0, value2)
+			if err != nil {
+				return zero, "", err
+			}
+
+			return value, remainder, nil
+		}
+	}()
+	g.factor = parser.Try(
+		func() parser.Parser[int] {
+			item0 := parser.Exact("(")
+			item1 := parser.Lazy(func() parser.Parser[string] { return g._Rule })
+			item2 := g.sum
+			item3 := parser.Exact(")")
+			item4 := parser.Lazy(func() parser.Parser[string] { return g._Rule })
+
+			return func(input string) (int, string, error) {
+				var (
+					zero   int
+					value2 int
+					err    error
+				)
+
+				remainder := input
+
+				if _, remainder, err = item0(remainder); err != nil {
+					return zero, "", err
+				}
+
+				if _, remainder, err = item1(remainder); err != nil {
+					return zero, "", err
+				}
+
+				if value2, remainder, err = item2(remainder); err != nil {
+					return zero, "", err
+				}
+
+				if _, remainder, err = item3(remainder); err != nil {
+					return zero, "", err
+				}
+
+				if _, remainder, err = item4(remainder); err != nil {
+					return zero, "", err
+				}
+
+				value, err := func(value int) (int, error) {
+					return value, nil
+				}(value2)
+				if err != nil {
+					return zero, "", err
+				}
+
+				return value, remainder, nil
+			}
+		}(),
+		func() parser.Parser[int] {
+			item0 := parser.Exact("-")
+			item1 := parser.Lazy(func() parser.Parser[string] { return g._Rule })
+			item2 := parser.Lazy(func() parser.Parser[int] { return g.factor })
+
+			return func(input string) (int, string, error) {
+				var (
+					zero   int
+					value2 int
+					err    error
+				)
+
+				remainder := input
+
+				if _, remainder, err = item0(remainder); err != nil {
+					return zero, "", err
+				}
+
+				if _, remainder, err = item1(remainder); err != nil {
+					return zero, "", err
+				}
+
+				if value2, remainder, err = item2(remainder); err != nil {
+					return zero, "", err
+				}
+
+				value, err := func(value int) (int, error) {
+					return -value, nil
+				}(value2)
+				if err != nil {
+					return zero, "", err
+				}
+
+				return value, remainder, nil
+			}
+		}(),
+		parser.Lazy(func() parser.Parser[int] { return g.number }),
+	)
+	g.number = func() parser.Parser[int] {
+		item0 := parser.Recognize(parser.Chain(parser.OneOf("0123456789"), parser.SkipMany(parser.OneOf("0123456789"))))
+		item1 := parser.Lazy(func() parser.Parser[string] { return g._Rule })
+
+		return func(input string) (int, string, error) {
+			var (
+				zero   int
+				value0 string
+				err    error
+			)
+
+			remainder := input
+
+			if value0, remainder, err = item0(remainder); err != nil {
+				return zero, "", err
+			}
+
+			if _, remainder, err = item1(remainder); err != nil {
+				return zero, "", err
+			}
+
+			value, err := func(digits string) (int, error) {
+				return strconv.Atoi(digits)
+			}(value0)
+			if err != nil {
+				return zero, "", err
+			}
+
+			return value, remainder, nil
+		}
+	}()
+	g._Rule = parser.SkipMany(parser.OneOf(" \t\r\n"))
+
+	return g
+}
+
+// Expr parses the start of input as the expr rule, returning its value and the remaining input.
+func (g *Grammar) Expr(input string) (int, string, error) {
+	return g.expr(input)
+}
+
+// Sum parses the start of input as the sum rule, returning its value and the remaining input.
+func (g *Grammar) Sum(input string) (int, string, error) {
+	return g.sum(input)
+}
+
+// Product parses the start of input as the product rule, returning its value and the remaining input.
+func (g *Grammar) Product(input string) (int, string, error) {
+	return g.product(input)
+}
+
+// Factor parses the start of input as the factor rule, returning its value and the remaining input.
+func (g *Grammar) Factor(input string) (int, string, error) {
+	return g.factor(input)
+}
+
+// Number parses the start of input as the number rule, returning its value and the remaining input.
+func (g *Grammar) Number(input string) (int, string, error) {
+	return g.number(input)
+}
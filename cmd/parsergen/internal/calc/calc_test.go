@@ -0,0 +1,133 @@
+package calc_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/cmd/parsergen/internal/calc"
+)
+
+func TestExpr(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // The expression to evaluate
+		err     string // A substring of the expected error, if there was one
+		want    int    // The expected value
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{
+			name:    "number",
+			input:   "42",
+			want:    42,
+			wantErr: false,
+		},
+		{
+			name:    "precedence",
+			input:   "1 + 2 * 3",
+			want:    7,
+			wantErr: false,
+		},
+		{
+			name:    "left associative",
+			input:   "10 - 4 - 3",
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name:    "parentheses",
+			input:   " (1 + 2) * (3 - 1)\n",
+			want:    6,
+			wantErr: false,
+		},
+		{
+			name:    "negation",
+			input:   "-(2 * -3) / 2",
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name:    "division by zero",
+			input:   "1 / (2 - 2)",
+			err:     "division by zero",
+			wantErr: true,
+		},
+		{
+			name:    "trailing input",
+			input:   "1 + 2 )",
+			err:     "expected end of input",
+			wantErr: true,
+		},
+		{
+			name:    "missing operand",
+			input:   "1 +",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	grammar := calc.NewGrammar()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, remainder, err := grammar.Expr(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nExpr(%q)\nerr:\t%v\nwantErr:\t%v\n", tt.input, err, tt.wantErr)
+			}
+
+			if err != nil {
+				if !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("\nGot:\t%v\nWanted it to contain:\t%s\n", err, tt.err)
+				}
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("\nGot:\t%d\nWanted:\t%d\n", got, tt.want)
+			}
+
+			if remainder != "" {
+				t.Errorf("remainder %q, expected all input to be consumed", remainder)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	grammar := calc.NewGrammar()
+
+	// Each rule is a parser on its own, parsing the start of the input
+	got, remainder, err := grammar.Product("2 * 3 + 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != 6 || remainder != "+ 4" {
+		t.Errorf("Product(%q) = %d, %q, wanted 6, %q", "2 * 3 + 4", got, remainder, "+ 4")
+	}
+
+	// Errors from actions are passed on
+	_, _, err = grammar.Number("99999999999999999999")
+	if err == nil || !strings.Contains(err.Error(), "value out of range") {
+		t.Errorf("Number of a huge number returned %v, wanted an out of range error", err)
+	}
+}
+
+func Example() {
+	grammar := calc.NewGrammar()
+
+	value, _, err := grammar.Expr("(1 + 2) * 3 - 4 / 2")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println(value)
+
+	// Output: 7
+}
@@ -0,0 +1,109 @@
+// Command parsergen generates Go source for a parser from a grammar, built from the combinators in
+// [go.followtheprocess.codes/parser], so the grammar is checked and compiled along with the rest
+// of the program rather than at runtime like [go.followtheprocess.codes/parser/peg].
+//
+// The grammar is in the syntax of the peg package, with annotations saying what Go values the
+// rules produce:
+//
+//	{
+//	import "strconv"
+//	}
+//
+//	sum <int>    <- left:number '+' right:number { return left + right, nil }
+//	number <int> <- digits:[0-9]+ { return strconv.Atoi(digits) }
+//
+// The block of code before the first rule (the preamble) is copied into the generated file, with its
+// imports merged into the file's own, so it's the place for imports and helpers used by the actions.
+//
+// A rule with a type in angle brackets has a value of that type. Each of its alternatives is either
+// a reference to a rule of the same type, or a sequence followed by an action: the body of a function
+// returning the value and an error, in which each labelled item of the sequence is a variable. The
+// value of a label on a reference to a rule with a type is that rule's value, repeating the reference
+// with '*' or '+' collects the values in a slice, and making it optional with '?' gives the zero value
+// when it's missing. The value of any other label is the text it matched, as is the value of a rule
+// without a type.
+//
+// The generated code is a type (Grammar by default) holding the parser for every rule, a constructor
+// for it and a method for each rule whose name starts with a letter, so helper rules can be kept
+// private by starting them with an '_'.
+//
+// Usage:
+//
+//	parsergen [flags] grammar.peg
+//
+// The flags are:
+//
+//	-o        the file to write, defaults to the grammar file with a _parser.go suffix
+//	-package  the package of the generated code, defaults to $GOPACKAGE as set by go generate
+//	-type     the name of the generated type, defaults to Grammar
+//
+// So it's usually run with a go:generate directive next to the grammar:
+//
+//	//go:generate go run go.followtheprocess.codes/parser/cmd/parsergen calc.peg
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "parsergen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the parser for the grammar named in args, printing usage to stderr when asked for.
+func run(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("parsergen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: parsergen [flags] grammar.peg")
+		flags.PrintDefaults()
+	}
+
+	output := flags.String("o", "", "the file to write, defaults to the grammar file with a _parser.go suffix")
+	pkg := flags.String("package", os.Getenv("GOPACKAGE"), "the package of the generated code, defaults to $GOPACKAGE")
+	typ := flags.String("type", "Grammar", "the name of the generated type")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a single grammar file")
+	}
+
+	if *pkg == "" {
+		return errors.New("no package name, use -package or run parsergen with go generate")
+	}
+
+	path := flags.Arg(0)
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	code, err := generate(string(src), config{File: filepath.Base(path), Package: *pkg, Type: *typ})
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		*output = strings.TrimSuffix(path, filepath.Ext(path)) + "_parser.go"
+	}
+
+	return os.WriteFile(*output, code, 0o644) //nolint:gosec // Generated source is meant to be readable
+}
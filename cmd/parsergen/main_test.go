@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	grammar := filepath.Join(dir, "greeting.peg")
+
+	if err := os.WriteFile(grammar, []byte("greeting <- 'hello' / 'hi'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string   // Identifying test case name
+		env     string   // The value of $GOPACKAGE
+		output  string   // The file the code should be written to
+		err     string   // The expected error message, if there was one
+		args    []string // The command line arguments
+		wantErr bool     // Whether or not we wanted an error
+	}{
+		{
+			name:    "go generate",
+			env:     "greeting",
+			args:    []string{grammar},
+			output:  filepath.Join(dir, "greeting_parser.go"),
+			wantErr: false,
+		},
+		{
+			name:    "flags",
+			args:    []string{"-package", "greeting", "-type", "Greeter", "-o", filepath.Join(dir, "out.go"), grammar},
+			output:  filepath.Join(dir, "out.go"),
+			wantErr: false,
+		},
+		{
+			name:    "help",
+			args:    []string{"-h"},
+			wantErr: false,
+		},
+		{
+			name:    "no package",
+			args:    []string{grammar},
+			err:     "no package name, use -package or run parsergen with go generate",
+			wantErr: true,
+		},
+		{
+			name:    "no grammar",
+			args:    []string{"-package", "greeting"},
+			err:     "expected a single grammar file",
+			wantErr: true,
+		},
+		{
+			name:    "missing grammar",
+			args:    []string{"-package", "greeting", filepath.Join(dir, "missing.peg")},
+			err:     "open " + filepath.Join(dir, "missing.peg") + ": no such file or directory",
+			wantErr: true,
+		},
+		{
+			name:    "bad flag",
+			args:    []string{"-nope", grammar},
+			err:     "flag provided but not defined: -nope",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOPACKAGE", tt.env)
+
+			stderr := &bytes.Buffer{}
+
+			err := run(tt.args, stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nrun(%q)\nerr:\t%v\nwantErr:\t%v\n", tt.args, err, tt.wantErr)
+			}
+
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Errorf("\nGot:\t%v\nWanted:\t%s\n", err, tt.err)
+				}
+				return
+			}
+
+			if tt.output == "" {
+				if !strings.Contains(stderr.String(), "usage: parsergen") {
+					t.Errorf("expected usage on stderr, got %q", stderr)
+				}
+				return
+			}
+
+			code, err := os.ReadFile(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.HasPrefix(code, []byte("// Code generated by parsergen from greeting.peg. DO NOT EDIT.\n\npackage greeting\n")) {
+				t.Errorf("unexpected generated code:\n%s", code)
+			}
+		})
+	}
+}
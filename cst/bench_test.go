@@ -0,0 +1,36 @@
+package cst_test
+
+import (
+	"strings"
+	"testing"
+)
+
+// benchDocument is a lists document with many similar, commented definitions.
+var benchDocument = strings.Repeat("(define (square x) ; multiply x by itself\n  (* x x))\n\n", 200)
+
+func BenchmarkParse(b *testing.B) {
+	p := lists(benchDocument)
+
+	b.SetBytes(int64(len(benchDocument)))
+
+	for b.Loop() {
+		if _, _, err := p(benchDocument); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	tree, _, err := lists(benchDocument)(benchDocument)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(benchDocument)))
+
+	for b.Loop() {
+		if tree.String() != benchDocument {
+			b.Fatal("tree didn't print as the document")
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Package cst builds concrete syntax trees with the combinators in [parser], trees that keep every
+// byte of the input, whitespace and comments included, so that printing one gives back exactly the
+// text it was parsed from.
+//
+// That's what formatters and refactoring tools need: they change one part of a file and print the
+// rest as the author wrote it. An abstract syntax tree, like the ones the json and sexpr packages
+// produce, throws the trivia away.
+//
+// Trees are made of [Node]s of two sorts. Tokens are the leaves, a keyword or a number, say, along
+// with the trivia around them: leading trivia is everything between the previous token's trailing
+// trivia and the token, trailing trivia is what follows the token on the same line. Nodes above
+// the tokens group them into the structure of the format, an expression or a statement, and have
+// no text of their own.
+//
+// A [Builder] makes the parsers for both, [Builder.Token] and [Builder.Node], with the parsers for
+// trivia given when it's created:
+//
+//	b := cst.NewBuilder(src, leading, trailing)
+//
+//	number := b.Token("number", parser.TakeWhile(unicode.IsDigit))
+//	plus := b.Token("plus", parser.Char('+'))
+//	sum := b.Node("sum", cst.One(number), cst.One(plus), cst.One(number))
+//
+//	tree, _, err := sum(src)
+//	tree.String() == src // If sum matched all of src
+//
+// Trivia at the very end of the input doesn't precede any token, [Builder.EOF] makes a token for it.
+package cst // import "go.followtheprocess.codes/parser/cst"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// EOF is the [Kind] of the token made by [Builder.EOF].
+const EOF Kind = "EOF"
+
+// Kind is the kind of a [Node], defined by the format being parsed, like "number" or "statement".
+type Kind string
+
+// Span is a range of byte offsets in the input.
+type Span struct {
+	Start int // Offset of the first byte
+	End   int // Offset of the byte after the last one
+}
+
+// Node is a node in a concrete syntax tree, either a token, which has text and trivia but no
+// children, or a node above the tokens, which only has children.
+type Node struct {
+	Kind     Kind   // What the node is
+	Text     string // The text of a token, without its trivia
+	Leading  string // Trivia before a token
+	Trailing string // Trivia after a token, on the same line
+	Children []Node // The nodes within this one, in order
+	Span     Span   // Where the node is in the input, not including the trivia at either end
+}
+
+// String returns the text the node was parsed from, trivia and all.
+func (n Node) String() string {
+	s := &strings.Builder{}
+	n.write(s)
+
+	return s.String()
+}
+
+// write writes the text of the node and all its children to s.
+func (n Node) write(s *strings.Builder) {
+	s.WriteString(n.Leading)
+	s.WriteString(n.Text)
+
+	for _, child := range n.Children {
+		child.write(s)
+	}
+
+	s.WriteString(n.Trailing)
+}
+
+// FullSpan returns where the node is in the input including the trivia at either end, so the text
+// it covers is exactly [Node.String].
+func (n Node) FullSpan() Span {
+	if len(n.Children) == 0 {
+		return Span{Start: n.Span.Start - len(n.Leading), End: n.Span.End + len(n.Trailing)}
+	}
+
+	return Span{Start: n.Children[0].FullSpan().Start, End: n.Children[len(n.Children)-1].FullSpan().End}
+}
+
+// Tokens returns the tokens in the tree in the order they appear in the input.
+func (n Node) Tokens() []Node {
+	var tokens []Node
+
+	var walk func(node Node)
+	walk = func(node Node) {
+		if node.Children == nil {
+			tokens = append(tokens, node)
+			return
+		}
+
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+
+	walk(n)
+
+	return tokens
+}
+
+// Builder makes parsers that build a concrete syntax tree from an input, attaching the trivia
+// matched by its leading and trailing parsers to the tokens.
+//
+// Like an [parser.Indentation], it's created from the complete input so that it can work out the
+// offset of any remainder of it for the spans of the nodes, and the parsers it makes must only be
+// used on that input. They're safe to use concurrently.
+type Builder struct {
+	leading  parser.Parser[string] // Matches the trivia before a token
+	trailing parser.Parser[string] // Matches the trivia after a token
+	src      string                // The complete input
+}
+
+// NewBuilder returns a [Builder] for parsing src, with parsers for the trivia before and after a token.
+//
+// The trivia parsers should match zero or more of whatever counts as trivia, [parser.SkipMany] of
+// whitespace or a comment for leading trivia, say, and the same without newlines, followed by an
+// optional newline, for trailing trivia. A trivia parser that fails is taken to mean there's no
+// trivia, and a nil one means there's never any.
+func NewBuilder(src string, leading, trailing parser.Parser[string]) *Builder {
+	return &Builder{src: src, leading: leading, trailing: trailing}
+}
+
+// Token returns a [parser.Parser] that makes a token of the given kind from the text matched by
+// p, along with the trivia either side of it.
+//
+// If p returns an error, Token will bubble up this error to the caller.
+func (b *Builder) Token(kind Kind, p parser.Parser[string]) parser.Parser[Node] {
+	return func(input string) (Node, string, error) {
+		leading, rest := trivia(b.leading, input)
+
+		offset, err := b.offset(rest)
+		if err != nil {
+			return Node{}, "", err
+		}
+
+		text, rest, err := p(rest)
+		if err != nil {
+			return Node{}, "", fmt.Errorf("Token(%s): parser returned error: %w", kind, err)
+		}
+
+		// The parser's value could be anything, what it consumed is what the token is
+		text = input[len(leading) : len(input)-len(rest)]
+
+		trailing, rest := trivia(b.trailing, rest)
+
+		token := Node{
+			Kind:     kind,
+			Text:     text,
+			Leading:  leading,
+			Trailing: trailing,
+			Span:     Span{Start: offset, End: offset + len(text)},
+		}
+
+		return token, rest, nil
+	}
+}
+
+// EOF returns a [parser.Parser] that matches the end of the input, making a token of kind [EOF]
+// with no text and any trivia left at the end of the input as its leading trivia.
+//
+// If there's any input left after the trivia, an error will be returned.
+func (b *Builder) EOF() parser.Parser[Node] {
+	return func(input string) (Node, string, error) {
+		leading, rest := trivia(b.leading, input)
+		if rest != "" {
+			return Node{}, "", fmt.Errorf("EOF: unexpected text %q", firstLine(rest))
+		}
+
+		offset, err := b.offset(rest)
+		if err != nil {
+			return Node{}, "", err
+		}
+
+		return Node{Kind: EOF, Leading: leading, Span: Span{Start: offset, End: offset}}, "", nil
+	}
+}
+
+// Node returns a [parser.Parser] that makes a node of the given kind from the nodes made by each of
+// parts in turn, which are combined with [One], [Optional] and [Many].
+//
+// If any of the parts returns an error, Node will bubble up this error to the caller.
+func (b *Builder) Node(kind Kind, parts ...parser.Parser[[]Node]) parser.Parser[Node] {
+	return func(input string) (Node, string, error) {
+		var children []Node
+
+		rest := input
+		for _, part := range parts {
+			nodes, remainder, err := part(rest)
+			if err != nil {
+				return Node{}, "", fmt.Errorf("Node(%s): part returned error: %w", kind, err)
+			}
+
+			children = append(children, nodes...)
+			rest = remainder
+		}
+
+		node := Node{Kind: kind, Children: children}
+
+		if len(children) == 0 {
+			// Nothing to span, so it's empty at where it would have been
+			offset, err := b.offset(input)
+			if err != nil {
+				return Node{}, "", err
+			}
+
+			node.Children = []Node{} // Not nil, so it's not mistaken for a token
+			node.Span = Span{Start: offset, End: offset}
+
+			return node, rest, nil
+		}
+
+		node.Span = Span{Start: children[0].Span.Start, End: children[len(children)-1].Span.End}
+
+		return node, rest, nil
+	}
+}
+
+// One returns a part for [Builder.Node] that's the single node made by p.
+func One(p parser.Parser[Node]) parser.Parser[[]Node] {
+	return func(input string) ([]Node, string, error) {
+		node, rest, err := p(input)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return []Node{node}, rest, nil
+	}
+}
+
+// Optional returns a part for [Builder.Node] that's the node made by p, or no nodes if p fails.
+func Optional(p parser.Parser[Node]) parser.Parser[[]Node] {
+	return func(input string) ([]Node, string, error) {
+		node, rest, err := p(input)
+		if err != nil {
+			return nil, input, nil
+		}
+
+		return []Node{node}, rest, nil
+	}
+}
+
+// Many returns a part for [Builder.Node] that's all the nodes made by applying p repeatedly until
+// it fails, which may be none at all.
+func Many(p parser.Parser[Node]) parser.Parser[[]Node] {
+	return parser.FoldMany(p, func() []Node { return nil }, func(nodes []Node, node Node) []Node {
+		return append(nodes, node)
+	})
+}
+
+// offset returns the offset into the source of rest, a remainder of it.
+func (b *Builder) offset(rest string) (int, error) {
+	if len(rest) > len(b.src) {
+		return 0, errors.New("input is not part of the source the Builder was created with")
+	}
+
+	return len(b.src) - len(rest), nil
+}
+
+// trivia applies a trivia parser to input, returning the trivia and the rest of the input.
+func trivia(p parser.Parser[string], input string) (string, string) {
+	if p == nil {
+		return "", input
+	}
+
+	_, rest, err := p(input)
+	if err != nil {
+		return "", input
+	}
+
+	return input[:len(input)-len(rest)], rest
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,275 @@
+package cst_test
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/cst"
+)
+
+// Kinds of node in the lists grammar.
+const (
+	kindDocument cst.Kind = "document"
+	kindList     cst.Kind = "list"
+	kindOpen     cst.Kind = "open"
+	kindClose    cst.Kind = "close"
+	kindAtom     cst.Kind = "atom"
+)
+
+// while is [parser.TakeWhile] of the chars in set, failing rather than matching nothing if the
+// input doesn't start with one.
+func while(set string) parser.Parser[string] {
+	return parser.Verify(
+		parser.TakeWhile(func(r rune) bool { return strings.ContainsRune(set, r) }),
+		func(s string) bool { return s != "" },
+		"no chars matched",
+	)
+}
+
+// until is [parser.TakeWhile] of chars not in set, failing rather than matching nothing if the
+// input starts with one.
+func until(set string) parser.Parser[string] {
+	return parser.Verify(
+		parser.TakeWhile(func(r rune) bool { return !strings.ContainsRune(set, r) }),
+		func(s string) bool { return s != "" },
+		"no chars matched",
+	)
+}
+
+// comment is a ; comment, up to but not including the end of the line.
+var comment = parser.Recognize(parser.Chain(parser.Char(';'), parser.Try(until("\n"), parser.Succeed(""))))
+
+// leading is the trivia before a token, any whitespace and comments.
+var leading = parser.SkipMany(parser.Try(while(" \t\r\n"), comment))
+
+// trailing is the trivia after a token, spaces and a comment up to and including the end of the line.
+var trailing = parser.Recognize(parser.Chain(
+	parser.Try(while(" \t"), parser.Succeed("")),
+	parser.Try(comment, parser.Succeed("")),
+	parser.Try(parser.Char('\n'), parser.Succeed("")),
+))
+
+// lists builds a parser for a document of nested lists of atoms, like (define x (+ 1 2)), with
+// ; comments.
+func lists(src string) parser.Parser[cst.Node] {
+	b := cst.NewBuilder(src, leading, trailing)
+
+	open := b.Token(kindOpen, parser.Char('('))
+	closing := b.Token(kindClose, parser.Char(')'))
+	atom := b.Token(kindAtom, until("() \t\r\n;"))
+
+	var list parser.Parser[cst.Node]
+
+	value := parser.Try(atom, parser.Lazy(func() parser.Parser[cst.Node] { return list }))
+	list = b.Node(kindList, cst.One(open), cst.Many(value), cst.One(closing))
+
+	return b.Node(kindDocument, cst.Many(value), cst.One(b.EOF()))
+}
+
+// outline returns the tree as one line per node, indented by depth, with the text and trivia of tokens.
+func outline(node cst.Node) string {
+	s := &strings.Builder{}
+
+	var walk func(node cst.Node, depth int)
+	walk = func(node cst.Node, depth int) {
+		fmt.Fprintf(s, "%s%s %d-%d", strings.Repeat("  ", depth), node.Kind, node.Span.Start, node.Span.End)
+
+		if node.Children == nil {
+			fmt.Fprintf(s, " %q %q %q", node.Leading, node.Text, node.Trailing)
+		}
+
+		s.WriteByte('\n')
+
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+
+	walk(node, 0)
+
+	return s.String()
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string // Identifying test case name
+		input string // The document to parse
+	}{
+		{name: "empty", input: ""},
+		{name: "only trivia", input: "  ; nothing here\n\n"},
+		{name: "atom", input: "x"},
+		{name: "list", input: "(a b c)"},
+		{name: "nested", input: "(define x\n  (+ 1 2))\n"},
+		{name: "comments", input: "; leading\n(a ; trailing\n  b) ; after\n\n; the end"},
+		{name: "crlf", input: "(a\r\n b)\r\n"},
+		{name: "tabs", input: "\t(a\tb)\t"},
+		{name: "several", input: "a (b) ((c))\n\n   d"},
+		{name: "unicode", input: "(héllo wörld ; ☃\n)"},
+		{name: "empty list", input: "( ; nothing\n)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, remainder, err := lists(tt.input)(tt.input)
+			if err != nil {
+				t.Fatalf("parse returned an unexpected error: %v", err)
+			}
+
+			if remainder != "" {
+				t.Errorf("remainder %q, wanted all input consumed", remainder)
+			}
+
+			if got := tree.String(); got != tt.input {
+				t.Errorf("\nGot:\t%q\nWanted:\t%q\n", got, tt.input)
+			}
+
+			if span := tree.FullSpan(); span.Start != 0 || span.End != len(tt.input) {
+				t.Errorf("full span of the document is %+v, wanted all %d bytes", span, len(tt.input))
+			}
+
+			// Every node's full span covers exactly the text it prints
+			var check func(node cst.Node)
+			check = func(node cst.Node) {
+				span := node.FullSpan()
+				if got := tt.input[span.Start:span.End]; got != node.String() {
+					t.Errorf("%s full span %+v covers %q, but it prints %q", node.Kind, span, got, node.String())
+				}
+
+				for _, child := range node.Children {
+					check(child)
+				}
+			}
+
+			check(tree)
+		})
+	}
+}
+
+func TestTree(t *testing.T) {
+	input := "; header\n(a b) ; one\n  c\n"
+
+	tree, _, err := lists(input)(input)
+	if err != nil {
+		t.Fatalf("parse returned an unexpected error: %v", err)
+	}
+
+	want := `document 9-25
+  list 9-14
+    open 9-10 "; header\n" "(" ""
+    atom 10-11 "" "a" " "
+    atom 12-13 "" "b" ""
+    close 13-14 "" ")" " ; one\n"
+  atom 23-24 "  " "c" "\n"
+  EOF 25-25 "" "" ""
+`
+
+	if got := outline(tree); got != want {
+		t.Errorf("\nGot:\n%s\nWanted:\n%s\n", got, want)
+	}
+
+	var atoms []string
+	for _, token := range tree.Tokens() {
+		if token.Kind == kindAtom {
+			atoms = append(atoms, token.Text)
+		}
+	}
+
+	if !slices.Equal(atoms, []string{"a", "b", "c"}) {
+		t.Errorf("Tokens gave atoms %q, wanted [a b c]", atoms)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name  string // Identifying test case name
+		input string // The document to parse
+		err   string // The expected error message
+	}{
+		{
+			name:  "unclosed",
+			input: "(a b",
+			err:   `Node(document): part returned error: EOF: unexpected text "(a b"`,
+		},
+		{
+			name:  "stray close",
+			input: "a )\nb",
+			err:   `Node(document): part returned error: EOF: unexpected text ")"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := lists(tt.input)(tt.input)
+			if err == nil {
+				t.Fatalf("parse of %q returned no error", tt.input)
+			}
+
+			if err.Error() != tt.err {
+				t.Errorf("\nGot:\t%v\nWanted:\t%s\n", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	b := cst.NewBuilder("ab", nil, nil)
+
+	token := b.Token("letter", parser.Char('a'))
+
+	// Without any trivia parsers, tokens are just their text
+	node, remainder, err := token("ab")
+	if err != nil {
+		t.Fatalf("token returned an unexpected error: %v", err)
+	}
+
+	if node.Text != "a" || node.Leading != "" || node.Trailing != "" || remainder != "b" {
+		t.Errorf("token returned (%+v, %q), wanted a with no trivia and remainder b", node, remainder)
+	}
+
+	_, _, err = token("xab")
+	if err == nil || err.Error() != "input is not part of the source the Builder was created with" {
+		t.Errorf("token of input longer than the source returned %v", err)
+	}
+
+	_, _, err = token("b")
+	if err == nil || err.Error() != "Token(letter): parser returned error: Char: requested char (a) not found in input" {
+		t.Errorf("token that doesn't match returned %v", err)
+	}
+
+	// A node with no children is empty where it would have been, and isn't a token
+	empty := b.Node("empty", cst.Optional(token))
+
+	node, _, err = empty("b")
+	if err != nil {
+		t.Fatalf("empty node returned an unexpected error: %v", err)
+	}
+
+	if node.Span != (cst.Span{Start: 1, End: 1}) || node.Children == nil || len(node.Tokens()) != 0 {
+		t.Errorf("empty node is %+v, wanted no children at 1-1", node)
+	}
+}
+
+func ExampleBuilder() {
+	src := "(+ 1  2) ; three\n"
+
+	tree, _, err := lists(src)(src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Rename a token and print the tree, everything else is just as it was
+	tokens := tree.Tokens()
+	fmt.Printf("%s\n", tokens[1].Text)
+
+	tree.Children[0].Children[1].Text = "add"
+	fmt.Print(tree)
+
+	// Output:
+	// +
+	// (add 1  2) ; three
+}
@@ -0,0 +1,66 @@
+package cst_test
+
+// The fuzz tests in here check that a tree built with a Builder never loses or adds any of the
+// input, so that printing it gives back exactly what was parsed, and that the spans of every
+// node agree with the text it prints.
+
+import (
+	"testing"
+
+	"go.followtheprocess.codes/parser/cst"
+)
+
+// checkNode checks that the full span of node and every node inside it covers exactly the text it
+// prints, and that children follow on from one another with nothing in between.
+func checkNode(t *testing.T, input string, node cst.Node) {
+	t.Helper()
+
+	span := node.FullSpan()
+	if span.Start < 0 || span.End > len(input) || span.Start > span.End {
+		t.Fatalf("full span %+v of %s is outside the input %q", span, node.Kind, input)
+	}
+
+	if got := input[span.Start:span.End]; got != node.String() {
+		t.Fatalf("full span %+v of %s covers %q, but it prints %q", span, node.Kind, got, node.String())
+	}
+
+	for i, child := range node.Children {
+		if i > 0 && child.FullSpan().Start != node.Children[i-1].FullSpan().End {
+			t.Fatalf("child %d of %s doesn't start where the one before it ended", i, node.Kind)
+		}
+
+		checkNode(t, input, child)
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	seeds := []string{
+		"",
+		"(define x\n  (+ 1 2)) ; three\n",
+		"; only a comment",
+		"(a\r\n b)\t",
+		"((unclosed",
+		"stray)",
+		"(héllo ☃)",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		tree, remainder, err := lists(input)(input)
+		if err != nil {
+			return
+		}
+
+		if remainder != "" {
+			t.Fatalf("document ends with EOF but left %q of %q", remainder, input)
+		}
+
+		if got := tree.String(); got != input {
+			t.Fatalf("%q parsed to a tree that prints as %q", input, got)
+		}
+
+		checkNode(t, input, tree)
+	})
+}
@@ -0,0 +1,36 @@
+package csv_test
+
+import (
+	stdcsv "encoding/csv"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/csv"
+)
+
+// benchInput is a representative CSV document with a mix of quoted and unquoted fields.
+var benchInput = strings.Repeat("1234,alice,\"Smith, Jr.\",\"said \"\"hi\"\"\",2024-01-01\r\n", 500)
+
+func BenchmarkRows(b *testing.B) {
+	b.SetBytes(int64(len(benchInput)))
+
+	for b.Loop() {
+		for _, err := range csv.CSV.Rows(benchInput) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkStdlib is the baseline for BenchmarkRows, reading the same input with encoding/csv.
+func BenchmarkStdlib(b *testing.B) {
+	b.SetBytes(int64(len(benchInput)))
+
+	for b.Loop() {
+		reader := stdcsv.NewReader(strings.NewReader(benchInput))
+		if _, err := reader.ReadAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
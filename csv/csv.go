@@ -0,0 +1,477 @@
+// Package csv implements a CSV/TSV parser following the quoting rules of [RFC 4180], built on the
+// combinators in [parser].
+//
+// Unlike [encoding/csv], the delimiter and quote char are both configurable, and rows are streamed
+// with an iterator straight from an in-memory input. Where the two overlap, the behaviour deliberately
+// matches [encoding/csv] (with LazyQuotes off): a CRLF line ending is read as LF, blank lines are skipped
+// and quotes are only allowed in quoted fields.
+//
+// [RFC 4180]: https://www.rfc-editor.org/rfc/rfc4180
+package csv // import "go.followtheprocess.codes/parser/csv"
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+var (
+	// ErrQuote is returned when a quoted field is not terminated properly.
+	ErrQuote = errors.New("extraneous or missing quote in quoted field")
+
+	// ErrBareQuote is returned when a quote char appears in an unquoted field.
+	ErrBareQuote = errors.New("bare quote in non-quoted field")
+
+	// ErrFieldCount is returned when a row has a different number of fields to the
+	// first row and the [Format] does not allow ragged rows.
+	ErrFieldCount = errors.New("wrong number of fields")
+
+	// ErrDuplicateHeader is returned from [Format.Records] when the header row has two
+	// columns with the same name.
+	ErrDuplicateHeader = errors.New("duplicate header")
+
+	// ErrInvalidFormat is returned when the [Format] itself is not valid, e.g. the delimiter
+	// and the quote char are the same.
+	ErrInvalidFormat = errors.New("invalid format")
+)
+
+var (
+	// CSV is the standard comma separated format.
+	CSV = Format{Delimiter: ',', Quote: '"'}
+
+	// TSV is the standard tab separated format.
+	TSV = Format{Delimiter: '\t', Quote: '"'}
+)
+
+// ParseError is the error yielded when a row cannot be parsed, it wraps one of the
+// sentinel errors in this package and records the position the problem occurred.
+type ParseError struct {
+	Err    error // The underlying error
+	Line   int   // 1 indexed line number where the error occurred
+	Column int   // 1 indexed column (in utf-8 chars) where the error occurred
+}
+
+// Error implements the error interface for [ParseError].
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("csv: line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Format describes the dialect of a CSV-like input.
+//
+// The zero value is equivalent to [CSV].
+type Format struct {
+	// Delimiter is the char separating fields, it defaults to ','.
+	Delimiter rune
+
+	// Quote is the char used to quote fields, it defaults to '"'. Within a quoted field,
+	// two quote chars in a row are read as a single literal quote char.
+	Quote rune
+
+	// Comment, if set, is a char that marks a line as a comment when it is the very first
+	// char on the line. Comment lines are skipped entirely.
+	Comment rune
+
+	// Ragged allows rows to have different numbers of fields, by default every row must have
+	// the same number of fields as the first.
+	Ragged bool
+}
+
+// Rows returns an iterator over the rows in the input, each row being a slice of its
+// field values with any quoting removed.
+//
+// Iteration stops after the first error, which will be a [*ParseError] unless the
+// input is not valid utf-8 or the [Format] is invalid.
+func (f Format) Rows(input string) iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		r, err := f.reader(input)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		rest := input
+		for {
+			row, remainder, err := r.row(rest)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if row == nil {
+				// End of input
+				return
+			}
+
+			if !yield(row, nil) {
+				return
+			}
+
+			rest = remainder
+		}
+	}
+}
+
+// Records returns an iterator over the rows in the input after the first, mapping the name of each
+// column from the header row to the value of that field.
+//
+// If the [Format] allows ragged rows, any columns missing from the end of a row are simply absent
+// from the map and any extra fields beyond the header are an [ErrFieldCount].
+//
+// Iteration stops after the first error, the same as [Format.Rows].
+func (f Format) Records(input string) iter.Seq2[map[string]string, error] {
+	return func(yield func(map[string]string, error) bool) {
+		r, err := f.reader(input)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		var header []string
+
+		rest := input
+		for {
+			// Skip to where the row starts so errors about the whole row point there
+			start := r.skip(rest)
+
+			row, remainder, err := r.row(start)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if row == nil {
+				// End of input
+				return
+			}
+
+			rest = remainder
+
+			if header == nil {
+				seen := make(map[string]bool, len(row))
+				for i, name := range row {
+					if seen[name] {
+						yield(nil, r.fail(r.starts[i], fmt.Errorf("%w %q", ErrDuplicateHeader, name)))
+						return
+					}
+					seen[name] = true
+				}
+
+				header = row
+				continue
+			}
+
+			if len(row) > len(header) {
+				yield(nil, r.fail(start, fmt.Errorf("%w: got %d, header has %d", ErrFieldCount, len(row), len(header))))
+				return
+			}
+
+			record := make(map[string]string, len(row))
+			for i, value := range row {
+				record[header[i]] = value
+			}
+
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// reader returns a reader for input in the Format, or an error if the Format is invalid or
+// the input is not valid utf-8.
+func (f Format) reader(input string) (*reader, error) {
+	f = f.withDefaults()
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	if !utf8.ValidString(input) {
+		return nil, errors.New("csv: input not valid utf-8")
+	}
+
+	return newReader(f, input), nil
+}
+
+// withDefaults returns the Format with any zero values filled in.
+func (f Format) withDefaults() Format {
+	if f.Delimiter == 0 {
+		f.Delimiter = ','
+	}
+
+	if f.Quote == 0 {
+		f.Quote = '"'
+	}
+
+	return f
+}
+
+// validate checks that the format can be parsed unambiguously.
+func (f Format) validate() error {
+	valid := func(r rune) bool {
+		return r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+	}
+
+	switch {
+	case !valid(f.Delimiter):
+		return fmt.Errorf("csv: %w: delimiter %q not allowed", ErrInvalidFormat, f.Delimiter)
+	case !valid(f.Quote):
+		return fmt.Errorf("csv: %w: quote %q not allowed", ErrInvalidFormat, f.Quote)
+	case f.Comment != 0 && !valid(f.Comment):
+		return fmt.Errorf("csv: %w: comment %q not allowed", ErrInvalidFormat, f.Comment)
+	case f.Delimiter == f.Quote:
+		return fmt.Errorf("csv: %w: delimiter and quote must be different", ErrInvalidFormat)
+	case f.Comment == f.Delimiter || f.Comment == f.Quote:
+		return fmt.Errorf("csv: %w: comment must be different from delimiter and quote", ErrInvalidFormat)
+	default:
+		return nil
+	}
+}
+
+// reader holds the state needed while parsing rows from a single input.
+type reader struct {
+	delimiter parser.Parser[string] // Parses the field delimiter
+	quote     parser.Parser[string] // Parses the quote char
+	comment   parser.Parser[string] // Parses the comment char, nil if comments are not enabled
+	plain     parser.Parser[string] // Parses the contents of an unquoted field
+	inQuotes  parser.Parser[string] // Parses the contents of a quoted field up to a quote or carriage return
+	src       string                // The entire input
+	starts    []string              // The input at the start of each field of the last row, for errors about a field
+	format    Format                // The format being parsed
+	fields    int                   // Number of fields in the first row, or -1 if we haven't seen one yet
+}
+
+// newReader returns a reader for src in the given format.
+func newReader(format Format, src string) *reader {
+	r := &reader{
+		delimiter: parser.Char(format.Delimiter),
+		quote:     parser.Char(format.Quote),
+		plain:     parser.SkipMany(parser.NoneOf(string([]rune{format.Delimiter, format.Quote, '\n', '\r'}))),
+		inQuotes:  parser.SkipMany(parser.NoneOf(string([]rune{format.Quote, '\r'}))),
+		src:       src,
+		format:    format,
+		fields:    -1,
+	}
+
+	if format.Comment != 0 {
+		r.comment = parser.Char(format.Comment)
+	}
+
+	return r
+}
+
+// row parses the next row from rest, skipping any blank or comment lines before it.
+//
+// A nil row and nil error means the end of the input.
+func (r *reader) row(rest string) ([]string, string, error) {
+	rest = r.skip(rest)
+	if rest == "" {
+		return nil, "", nil
+	}
+
+	start := rest
+	r.starts = r.starts[:0]
+
+	var row []string
+	for {
+		r.starts = append(r.starts, rest)
+
+		field, remainder, err := r.field(rest)
+		if err != nil {
+			return nil, "", err
+		}
+
+		row = append(row, field)
+		rest = remainder
+
+		if _, remainder, err := r.delimiter(rest); err == nil {
+			rest = remainder
+			continue
+		}
+
+		// If it's not a delimiter, the field parsers guarantee it's the end of the line
+		_, rest, _ = lineEnding(rest)
+
+		break
+	}
+
+	if !r.format.Ragged {
+		if r.fields == -1 {
+			r.fields = len(row)
+		} else if len(row) != r.fields {
+			return nil, "", r.fail(start, fmt.Errorf("%w: got %d, want %d", ErrFieldCount, len(row), r.fields))
+		}
+	}
+
+	return row, rest, nil
+}
+
+// skip skips over any blank lines and comment lines at the start of rest.
+func (r *reader) skip(rest string) string {
+	for rest != "" {
+		if _, remainder, err := lineEnding(rest); err == nil {
+			rest = remainder
+			continue
+		}
+
+		if r.comment != nil {
+			if _, remainder, err := r.comment(rest); err == nil {
+				_, remainder, _ = toLineEnding(remainder)
+				_, rest, _ = lineEnding(remainder)
+				continue
+			}
+		}
+
+		break
+	}
+
+	return rest
+}
+
+// field parses a single quoted or unquoted field.
+func (r *reader) field(rest string) (string, string, error) {
+	if _, remainder, err := r.quote(rest); err == nil {
+		return r.quoted(rest, remainder)
+	}
+
+	return r.unquoted(rest)
+}
+
+// unquoted parses an unquoted field, which runs up to the next delimiter or line ending.
+func (r *reader) unquoted(input string) (string, string, error) {
+	rest := input
+	for {
+		_, rest, _ = r.plain(rest)
+
+		// A lone carriage return that isn't part of a line ending is just data
+		if isLoneCR(rest) {
+			rest = rest[1:]
+			continue
+		}
+
+		break
+	}
+
+	if _, _, err := r.quote(rest); err == nil {
+		return "", "", r.fail(rest, ErrBareQuote)
+	}
+
+	return input[:len(input)-len(rest)], rest, nil
+}
+
+// quoted parses a quoted field, input is the start of the field (including the opening quote)
+// and rest is everything after the opening quote.
+//
+// If the field contains no escaped quotes or CRLF line endings, the value is a slice of the
+// input so no allocation takes place.
+func (r *reader) quoted(input, rest string) (string, string, error) {
+	var builder *strings.Builder // Only allocated if we need to unescape something
+
+	content := rest // The start of the field contents
+	for {
+		chunk, remainder, _ := r.inQuotes(rest)
+		if builder != nil {
+			builder.WriteString(chunk)
+		}
+		rest = remainder
+
+		if rest == "" {
+			// Hit the end of the input without a closing quote
+			return "", "", r.fail(input, ErrQuote)
+		}
+
+		if strings.HasPrefix(rest, "\r\n") {
+			// CRLF is read as LF, even inside a quoted field
+			if builder == nil {
+				builder = &strings.Builder{}
+				builder.WriteString(content[:len(content)-len(rest)])
+			}
+			builder.WriteByte('\n')
+			rest = rest[2:]
+			continue
+		}
+
+		if strings.HasPrefix(rest, "\r") {
+			// A lone carriage return is just data
+			if builder != nil {
+				builder.WriteByte('\r')
+			}
+			rest = rest[1:]
+			continue
+		}
+
+		// Must be a quote
+		value := content[:len(content)-len(rest)]
+		_, rest, _ = r.quote(rest)
+
+		if _, remainder, err := r.quote(rest); err == nil {
+			// An escaped quote
+			if builder == nil {
+				builder = &strings.Builder{}
+				builder.WriteString(value)
+			}
+			builder.WriteRune(r.format.Quote)
+			rest = remainder
+			continue
+		}
+
+		// The closing quote, must be followed by a delimiter, line ending or the end of input
+		_, _, delimErr := r.delimiter(rest)
+		_, _, lineErr := lineEnding(rest)
+		if rest != "" && delimErr != nil && lineErr != nil {
+			return "", "", r.fail(rest, ErrQuote)
+		}
+
+		if builder != nil {
+			return builder.String(), rest, nil
+		}
+
+		return value, rest, nil
+	}
+}
+
+// fail returns a [ParseError] wrapping err at the start of rest.
+func (r *reader) fail(rest string, err error) error {
+	offset := len(r.src) - len(rest)
+	before := r.src[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	return &ParseError{
+		Err:    err,
+		Line:   1 + strings.Count(before, "\n"),
+		Column: 1 + utf8.RuneCountInString(before[lineStart:]),
+	}
+}
+
+// isLoneCR reports whether rest starts with a carriage return that is not part of a
+// line ending, like [encoding/csv] a carriage return right at the end of the input is
+// treated as a line ending.
+func isLoneCR(rest string) bool {
+	return len(rest) > 1 && rest[0] == '\r' && rest[1] != '\n'
+}
+
+var (
+	// lineEnding parses a LF, CRLF or a carriage return at the very end of the input.
+	lineEnding = parser.Try(
+		parser.Char('\n'),
+		parser.Bind(parser.Char('\r'), func(string) parser.Parser[string] {
+			return func(input string) (string, string, error) {
+				if input == "" {
+					return "", "", nil
+				}
+				return parser.Char('\n')(input)
+			}
+		}),
+	)
+
+	// toLineEnding parses everything up to (but not including) the next line ending.
+	toLineEnding = parser.SkipMany(parser.NoneOf("\n"))
+)
@@ -0,0 +1,447 @@
+package csv_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"go.followtheprocess.codes/parser/csv"
+)
+
+func TestRows(t *testing.T) {
+	tests := []struct {
+		name    string     // Identifying test case name
+		input   string     // Input to parse
+		err     string     // The expected error message, if there was one
+		want    [][]string // The expected rows
+		format  csv.Format // The format to parse with
+		wantErr bool       // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			format:  csv.CSV,
+			want:    nil,
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "simple",
+			input:   "a,b,c\n1,2,3\n",
+			format:  csv.CSV,
+			want:    [][]string{{"a", "b", "c"}, {"1", "2", "3"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "zero value format",
+			input:   "a,b\n1,2",
+			format:  csv.Format{},
+			want:    [][]string{{"a", "b"}, {"1", "2"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "no trailing newline",
+			input:   "a,b,c",
+			format:  csv.CSV,
+			want:    [][]string{{"a", "b", "c"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "crlf",
+			input:   "a,b\r\n1,2\r\n",
+			format:  csv.CSV,
+			want:    [][]string{{"a", "b"}, {"1", "2"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "blank lines skipped",
+			input:   "a,b\n\n\r\n1,2\n\n",
+			format:  csv.CSV,
+			want:    [][]string{{"a", "b"}, {"1", "2"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "empty fields",
+			input:   ",,\n",
+			format:  csv.CSV,
+			want:    [][]string{{"", "", ""}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "quoted",
+			input:   `"a,b","say ""hi""",c` + "\n",
+			format:  csv.CSV,
+			want:    [][]string{{"a,b", `say "hi"`, "c"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "quoted newlines",
+			input:   "\"multi\r\nline\nfield\",x\n",
+			format:  csv.CSV,
+			want:    [][]string{{"multi\nline\nfield", "x"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "lone carriage return",
+			input:   "a\rb,c\r",
+			format:  csv.CSV,
+			want:    [][]string{{"a\rb", "c"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "utf8",
+			input:   "日本,ð\n語,\"þ日\"\n",
+			format:  csv.CSV,
+			want:    [][]string{{"日本", "ð"}, {"語", "þ日"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "tsv",
+			input:   "a\tb,c\n1\t2,3\n",
+			format:  csv.TSV,
+			want:    [][]string{{"a", "b,c"}, {"1", "2,3"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "custom quote",
+			input:   "'a;b';c\n'it''s';d",
+			format:  csv.Format{Delimiter: ';', Quote: '\''},
+			want:    [][]string{{"a;b", "c"}, {"it's", "d"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "comments",
+			input:   "# header comment\na,b\n#another\n1,2\n",
+			format:  csv.Format{Comment: '#'},
+			want:    [][]string{{"a", "b"}, {"1", "2"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "comment char mid line",
+			input:   "a,#b\n",
+			format:  csv.Format{Comment: '#'},
+			want:    [][]string{{"a", "#b"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "ragged allowed",
+			input:   "a,b,c\n1\n1,2,3,4\n",
+			format:  csv.Format{Ragged: true},
+			want:    [][]string{{"a", "b", "c"}, {"1"}, {"1", "2", "3", "4"}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "ragged not allowed",
+			input:   "a,b,c\n1,2\n",
+			format:  csv.CSV,
+			want:    [][]string{{"a", "b", "c"}},
+			wantErr: true,
+			err:     "csv: line 2, column 1: wrong number of fields: got 2, want 3",
+		},
+		{
+			name:    "bare quote",
+			input:   "a,b\"c\n",
+			format:  csv.CSV,
+			want:    nil,
+			wantErr: true,
+			err:     "csv: line 1, column 4: bare quote in non-quoted field",
+		},
+		{
+			name:    "unterminated quote",
+			input:   "a,b\n1,\"2\n",
+			format:  csv.CSV,
+			want:    [][]string{{"a", "b"}},
+			wantErr: true,
+			err:     "csv: line 2, column 3: extraneous or missing quote in quoted field",
+		},
+		{
+			name:    "junk after closing quote",
+			input:   "\"日本\"x,b\n",
+			format:  csv.CSV,
+			want:    nil,
+			wantErr: true,
+			err:     "csv: line 1, column 5: extraneous or missing quote in quoted field",
+		},
+		{
+			name:    "bad utf8",
+			input:   "\xf8\xa1\xa1\xa1\xa1",
+			format:  csv.CSV,
+			want:    nil,
+			wantErr: true,
+			err:     "csv: input not valid utf-8",
+		},
+		{
+			name:    "delimiter same as quote",
+			input:   "a,b",
+			format:  csv.Format{Delimiter: '"'},
+			want:    nil,
+			wantErr: true,
+			err:     "csv: invalid format: delimiter and quote must be different",
+		},
+		{
+			name:    "newline delimiter",
+			input:   "a,b",
+			format:  csv.Format{Delimiter: '\n'},
+			want:    nil,
+			wantErr: true,
+			err:     "csv: invalid format: delimiter '\\n' not allowed",
+		},
+		{
+			name:    "comment same as delimiter",
+			input:   "a,b",
+			format:  csv.Format{Comment: ','},
+			want:    nil,
+			wantErr: true,
+			err:     "csv: invalid format: comment must be different from delimiter and quote",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			var err error
+			for row, e := range tt.format.Rows(tt.input) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, row)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nRows:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorUnwrap(t *testing.T) {
+	for _, err := range csv.CSV.Rows("a,\"b\n") {
+		if err == nil {
+			continue
+		}
+
+		var parseErr *csv.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("Error was not a *csv.ParseError, got %T", err)
+		}
+
+		if !errors.Is(err, csv.ErrQuote) {
+			t.Errorf("Error %v was not csv.ErrQuote", err)
+		}
+
+		if parseErr.Line != 1 || parseErr.Column != 3 {
+			t.Errorf("Wrong position, got line %d, column %d", parseErr.Line, parseErr.Column)
+		}
+
+		return
+	}
+
+	t.Fatal("Rows did not return an error")
+}
+
+func TestRecordsFieldCountError(t *testing.T) {
+	format := csv.Format{Ragged: true, Comment: '#'}
+
+	for _, err := range format.Records("name,age\n# a comment\nalice,30,extra\n") {
+		if err == nil {
+			continue
+		}
+
+		var parseErr *csv.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("Error was not a *csv.ParseError, got %T", err)
+		}
+
+		if !errors.Is(err, csv.ErrFieldCount) {
+			t.Errorf("Error %v was not csv.ErrFieldCount", err)
+		}
+
+		if parseErr.Line != 3 || parseErr.Column != 1 {
+			t.Errorf("Wrong position, got line %d, column %d", parseErr.Line, parseErr.Column)
+		}
+
+		return
+	}
+
+	t.Fatal("Records did not return an error")
+}
+
+func TestRecords(t *testing.T) {
+	tests := []struct {
+		name    string              // Identifying test case name
+		input   string              // Input to parse
+		err     string              // The expected error message, if there was one
+		want    []map[string]string // The expected records
+		format  csv.Format          // The format to parse with
+		wantErr bool                // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			format:  csv.CSV,
+			want:    nil,
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "header only",
+			input:   "name,age\n",
+			format:  csv.CSV,
+			want:    nil,
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:   "simple",
+			input:  "name,age\nalice,30\nbob,25\n",
+			format: csv.CSV,
+			want: []map[string]string{
+				{"name": "alice", "age": "30"},
+				{"name": "bob", "age": "25"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:   "ragged short row",
+			input:  "name,age\nalice\n",
+			format: csv.Format{Ragged: true},
+			want: []map[string]string{
+				{"name": "alice"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "ragged long row",
+			input:   "name,age\nalice,30,extra\n",
+			format:  csv.Format{Ragged: true},
+			want:    nil,
+			wantErr: true,
+			err:     "csv: line 2, column 1: wrong number of fields: got 3, header has 2",
+		},
+		{
+			name:    "ragged long row after blank lines",
+			input:   "name,age\nalice,30\n\n\nbob,25,extra\n",
+			format:  csv.Format{Ragged: true},
+			want:    []map[string]string{{"name": "alice", "age": "30"}},
+			wantErr: true,
+			err:     "csv: line 5, column 1: wrong number of fields: got 3, header has 2",
+		},
+		{
+			name:    "duplicate header",
+			input:   "name,age,name\n",
+			format:  csv.CSV,
+			want:    nil,
+			wantErr: true,
+			err:     `csv: line 1, column 10: duplicate header "name"`,
+		},
+		{
+			name:    "duplicate header after comment and blank lines",
+			input:   "# c\n\nname,name\n",
+			format:  csv.Format{Comment: '#'},
+			want:    nil,
+			wantErr: true,
+			err:     `csv: line 3, column 6: duplicate header "name"`,
+		},
+		{
+			name:    "parse error",
+			input:   "name,age\n\"alice,30\n",
+			format:  csv.CSV,
+			want:    nil,
+			wantErr: true,
+			err:     "csv: line 2, column 1: extraneous or missing quote in quoted field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []map[string]string
+			var err error
+			for record, e := range tt.format.Records(tt.input) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, record)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nRecords:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func ExampleFormat_Rows() {
+	input := "name,quote\nalice,\"she said \"\"hello\"\"\"\nbob,\"multi\nline\"\n"
+
+	for row, err := range csv.CSV.Rows(input) {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		fmt.Printf("%q\n", row)
+	}
+
+	// Output: ["name" "quote"]
+	// ["alice" "she said \"hello\""]
+	// ["bob" "multi\nline"]
+}
+
+func ExampleFormat_Records() {
+	input := "# exported users\nname\tage\nalice\t30\nbob\t25\n"
+
+	format := csv.Format{Delimiter: '\t', Comment: '#'}
+
+	for record, err := range format.Records(input) {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		fmt.Printf("%s is %s\n", record["name"], record["age"])
+	}
+
+	// Output: alice is 30
+	// bob is 25
+}
@@ -0,0 +1,73 @@
+package csv_test
+
+// The fuzz tests in here differentially test the parser against encoding/csv, which
+// shares the same quoting rules when it's configured equivalently.
+
+import (
+	stdcsv "encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser/csv"
+)
+
+var corpus = [...]string{
+	"",
+	"a,b,c\n1,2,3\n",
+	"a,b\r\n1,2\r\n",
+	"\"quoted\",\"with \"\"escapes\"\"\"\n",
+	"\"multi\r\nline\",x\n",
+	"a\rb,c\r",
+	"a,b\"c\n",
+	"\"日本\"x,b\n",
+	"# comment\na,b\n\n1,2",
+	"日a本b語ç日ð本Ê語þ日¥本¼語i日©",
+	",,\n,,\n",
+	"a,b,c\n1,2\n",
+}
+
+func FuzzRows(f *testing.F) {
+	for _, item := range corpus {
+		f.Add(item, false, false)
+		f.Add(item, true, true)
+	}
+
+	f.Fuzz(func(t *testing.T, input string, comments, ragged bool) {
+		if !utf8.ValidString(input) {
+			t.Skip("encoding/csv accepts invalid utf-8")
+		}
+
+		format := csv.Format{Ragged: ragged}
+		reader := stdcsv.NewReader(strings.NewReader(input))
+		reader.FieldsPerRecord = 0
+		if ragged {
+			reader.FieldsPerRecord = -1
+		}
+		if comments {
+			format.Comment = '#'
+			reader.Comment = '#'
+		}
+
+		want, wantErr := reader.ReadAll()
+
+		var got [][]string
+		var err error
+		for row, e := range format.Rows(input) {
+			if e != nil {
+				err = e
+				break
+			}
+			got = append(got, row)
+		}
+
+		if (err != nil) != (wantErr != nil) {
+			t.Fatalf("Rows and encoding/csv disagree on %q\nRows:\t%v\nencoding/csv:\t%v\n", input, err, wantErr)
+		}
+
+		if err == nil && !reflect.DeepEqual(got, want) {
+			t.Fatalf("Rows and encoding/csv produced different rows for %q\nRows:\t%q\nencoding/csv:\t%q\n", input, got, want)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package datetime_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/datetime"
+)
+
+// benchLog is a chunk of log text with timestamps and durations scattered through it.
+var benchLog = strings.Repeat(
+	"2024-01-01T12:00:00.123Z GET /users/42 200 took 12.5ms (upstream 10ms, retries 0)\n", 200,
+)
+
+func BenchmarkRFC3339(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := datetime.RFC3339("2006-01-02T15:04:05.999999999-07:00"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCommonLog(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := datetime.CommonLog("10/Oct/2000:13:55:36 -0700"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGoDuration(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := datetime.GoDuration("1h30m15.5s"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkISODuration(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := datetime.ISODuration("P1Y2M3DT4H5M6.5S"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindAll(b *testing.B) {
+	b.SetBytes(int64(len(benchLog)))
+
+	for b.Loop() {
+		for _, err := range parser.FindAll(datetime.RFC3339, benchLog) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
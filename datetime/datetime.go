@@ -0,0 +1,547 @@
+// Package datetime implements prefix parsers for timestamps, dates and durations, built on
+// the combinators in [parser].
+//
+// Unlike [time.Parse] and [time.ParseDuration], which need to be handed exactly the text of
+// a timestamp or duration, the parsers in this package recognise one at the start of their
+// input and return the remainder, so they can find them embedded in larger text:
+//
+//	for match, err := range parser.FindAll(datetime.RFC3339, logs) {
+//		...
+//	}
+//
+// Each parser is a plain function with the [parser.Parser] signature, so they can be passed
+// directly to any combinator.
+//
+// Once the input looks like the requested format, every field is range checked (so month 13,
+// February 30th or minute 61 are all errors) and failures are reported as an [*Error]
+// giving the byte offset of the offending field.
+package datetime // import "go.followtheprocess.codes/parser/datetime"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// Error is the error returned by all the parsers in this package.
+type Error struct {
+	// Msg describes what went wrong.
+	Msg string
+
+	// Offset is the byte offset into the parser's input where the problem was found.
+	Offset int
+}
+
+// Error implements the error interface for *Error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("datetime: offset %d: %s", e.Offset, e.Msg)
+}
+
+// RFC3339 parses an RFC 3339 timestamp like 2006-01-02T15:04:05.999Z07:00 from the start of input.
+//
+// The accepted syntax is the same as [time.RFC3339Nano] in [time.Parse]: the fractional
+// seconds are optional, may be separated by either '.' or ',', and are truncated to
+// nanosecond precision. Unlike [time.Parse], the hours and minutes of the zone offset are
+// range checked too.
+//
+// A 'Z' or zero offset gives a time in [time.UTC], any other offset a [time.FixedZone].
+func RFC3339(input string) (time.Time, string, error) {
+	s := scan{input: input, rest: input}
+
+	date, err := s.date(errNoRFC3339)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(tee, "'T' after date"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	hour, err := s.field(twoDigits, "hour", 0, 23)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(colon, "':' after hour"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	minute, err := s.field(twoDigits, "minute", 0, 59)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(colon, "':' after minute"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	second, err := s.field(twoDigits, "second", 0, 59)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	nanos, err := s.fraction()
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	loc, err := s.zone()
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	return time.Date(date.year, date.month, date.day, hour, minute, second, nanos, loc), s.rest, nil
+}
+
+// Date parses an ISO 8601 calendar date like 2006-01-02 from the start of input, returning
+// midnight UTC on that day.
+//
+// Only the extended format with hyphens between the fields is supported, as a run of
+// 8 digits is too ambiguous to pick out of larger text.
+func Date(input string) (time.Time, string, error) {
+	s := scan{input: input, rest: input}
+
+	date, err := s.date(errNoDate)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	return time.Date(date.year, date.month, date.day, 0, 0, 0, 0, time.UTC), s.rest, nil
+}
+
+// Week parses an ISO 8601 week date like 2006-W01 or 2006-W01-1 from the start of input,
+// returning midnight UTC on that day.
+//
+// The weekday runs from 1 (Monday) to 7 (Sunday) and defaults to Monday if it's missing.
+// Week 53 is only allowed in years that have one, and, as with [time.Time.ISOWeek], the
+// first few days of week 1 may fall in the previous calendar year.
+func Week(input string) (time.Time, string, error) {
+	s := scan{input: input, rest: input}
+
+	if _, _, err := fourDigits(input); err != nil {
+		return time.Time{}, "", errNoWeek
+	}
+
+	year, err := s.field(fourDigits, "year", 0, 9999)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(hyphen, "'-' after year"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(weekDesignator, "'W' after year"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	// The 28th of December is always in the last week of its year
+	_, weeks := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+
+	week, err := s.field(twoDigits, "week", 1, weeks)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	// A hyphen without a digit after it isn't ours, it's left in the remainder
+	day := 1
+	if _, afterHyphen, err := hyphen(s.rest); err == nil {
+		if _, _, err = oneDigit(afterHyphen); err == nil {
+			s.rest = afterHyphen
+
+			day, err = s.field(oneDigit, "weekday", 1, 7)
+			if err != nil {
+				return time.Time{}, "", err
+			}
+		}
+	}
+
+	// The 4th of January is always in week 1, so step back to the Monday of that week
+	// and count forward from there
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+
+	return monday.AddDate(0, 0, (week-1)*7+day-1), s.rest, nil
+}
+
+// CommonLog parses a timestamp in the format used by the Apache and nginx access logs, like
+// 10/Oct/2000:13:55:36 -0700, from the start of input.
+//
+// The month name is matched case insensitively. A zero offset gives a time in [time.UTC],
+// any other offset a [time.FixedZone].
+func CommonLog(input string) (time.Time, string, error) {
+	s := scan{input: input, rest: input}
+
+	if _, _, err := twoDigits(input); err != nil {
+		return time.Time{}, "", errNoCommonLog
+	}
+
+	// The day can't be range checked until we know the month and year, so any 2 digits do for now
+	day, err := s.field(twoDigits, "day", 0, 99)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(slash, "'/' after day"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	monthOffset := s.offset()
+
+	name, rest, err := monthName(s.rest)
+	if err != nil {
+		return time.Time{}, "", s.errorf(monthOffset, "expected 3 letter month name")
+	}
+
+	month, ok := months[strings.ToLower(name)]
+	if !ok {
+		return time.Time{}, "", s.errorf(monthOffset, "unknown month %q", name)
+	}
+
+	s.rest = rest
+
+	if err = s.char(slash, "'/' after month"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	year, err := s.field(fourDigits, "year", 0, 9999)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if limit := daysIn(year, month); day < 1 || day > limit {
+		return time.Time{}, "", s.errorf(0, "day %02d out of range [1, %d] for %s %d", day, limit, month, year)
+	}
+
+	if err = s.char(colon, "':' after year"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	hour, err := s.field(twoDigits, "hour", 0, 23)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(colon, "':' after hour"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	minute, err := s.field(twoDigits, "minute", 0, 59)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(colon, "':' after minute"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	second, err := s.field(twoDigits, "second", 0, 59)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	if err = s.char(space, "' ' before zone offset"); err != nil {
+		return time.Time{}, "", err
+	}
+
+	seconds, err := s.offsetSeconds(false)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	return time.Date(year, month, day, hour, minute, second, 0, location(seconds)), s.rest, nil
+}
+
+// calendarDate is a range checked year, month and day.
+type calendarDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// scan tracks the progress of one of the parsers through its input, so that errors
+// can report where they happened.
+type scan struct {
+	input string // The entire input to the parser
+	rest  string // The input still left to parse
+}
+
+// offset returns the byte offset of the scan into the input.
+func (s *scan) offset() int {
+	return len(s.input) - len(s.rest)
+}
+
+// errorf returns an [*Error] at the given offset.
+func (s *scan) errorf(offset int, format string, args ...any) error {
+	return &Error{Msg: fmt.Sprintf(format, args...), Offset: offset}
+}
+
+// field parses a fixed width number with digits, checking it falls within [lower, upper].
+func (s *scan) field(digits parser.Parser[string], name string, lower, upper int) (int, error) {
+	start := s.offset()
+
+	text, rest, err := digits(s.rest)
+	if err != nil {
+		return 0, s.errorf(start, "expected %s", name)
+	}
+
+	// Can't fail, it's a handful of ASCII digits
+	n, _ := strconv.Atoi(text)
+	if n < lower || n > upper {
+		return 0, s.errorf(start, "%s %s out of range [%d, %d]", name, text, lower, upper)
+	}
+
+	s.rest = rest
+
+	return n, nil
+}
+
+// char parses a single separator char, returning an error saying what was expected if it's missing.
+func (s *scan) char(sep parser.Parser[string], expected string) error {
+	_, rest, err := sep(s.rest)
+	if err != nil {
+		return s.errorf(s.offset(), "expected %s", expected)
+	}
+
+	s.rest = rest
+
+	return nil
+}
+
+// date parses a YYYY-MM-DD date, returning noMatch if the input doesn't even start with a year.
+func (s *scan) date(noMatch error) (calendarDate, error) {
+	// Checking up front avoids building an error we'd only throw away
+	if _, _, err := fourDigits(s.rest); err != nil {
+		return calendarDate{}, noMatch
+	}
+
+	year, err := s.field(fourDigits, "year", 0, 9999)
+	if err != nil {
+		return calendarDate{}, err
+	}
+
+	if err = s.char(hyphen, "'-' after year"); err != nil {
+		return calendarDate{}, err
+	}
+
+	month, err := s.field(twoDigits, "month", 1, 12)
+	if err != nil {
+		return calendarDate{}, err
+	}
+
+	if err = s.char(hyphen, "'-' after month"); err != nil {
+		return calendarDate{}, err
+	}
+
+	dayOffset := s.offset()
+
+	day, err := s.field(twoDigits, "day", 1, 31)
+	if err != nil {
+		return calendarDate{}, err
+	}
+
+	if limit := daysIn(year, time.Month(month)); day > limit {
+		return calendarDate{}, s.errorf(dayOffset, "day %02d out of range [1, %d] for %s %d", day, limit, time.Month(month), year)
+	}
+
+	return calendarDate{year: year, month: time.Month(month), day: day}, nil
+}
+
+// fraction parses optional fractional seconds, returning them in nanoseconds.
+func (s *scan) fraction() (int, error) {
+	_, afterSep, err := decimalSep(s.rest)
+	if err != nil {
+		return 0, nil //nolint:nilerr // No fraction is fine, it's optional
+	}
+
+	s.rest = afterSep
+
+	start := s.offset()
+
+	digits, rest, err := decimal(s.rest)
+	if err != nil {
+		return 0, s.errorf(start, "expected digits after decimal separator")
+	}
+
+	s.rest = rest
+
+	return nanoseconds(digits), nil
+}
+
+// zone parses an RFC 3339 zone, either 'Z' or an offset like +07:00.
+func (s *scan) zone() (*time.Location, error) {
+	if _, rest, err := zulu(s.rest); err == nil {
+		s.rest = rest
+		return time.UTC, nil
+	}
+
+	seconds, err := s.offsetSeconds(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return location(seconds), nil
+}
+
+// offsetSeconds parses a zone offset like +07:00 (or +0700 if colonSeparated is false), returning
+// it in seconds east of UTC.
+func (s *scan) offsetSeconds(colonSeparated bool) (int, error) {
+	start := s.offset()
+
+	signChar, rest, err := sign(s.rest)
+	if err != nil {
+		if colonSeparated {
+			return 0, s.errorf(start, "expected 'Z' or zone offset")
+		}
+
+		return 0, s.errorf(start, "expected zone offset")
+	}
+
+	s.rest = rest
+
+	hours, err := s.field(twoDigits, "zone offset hour", 0, 23)
+	if err != nil {
+		return 0, err
+	}
+
+	if colonSeparated {
+		if err = s.char(colon, "':' in zone offset"); err != nil {
+			return 0, err
+		}
+	}
+
+	minutes, err := s.field(twoDigits, "zone offset minute", 0, 59)
+	if err != nil {
+		return 0, err
+	}
+
+	seconds := hours*3600 + minutes*60
+	if signChar == "-" {
+		seconds = -seconds
+	}
+
+	return seconds, nil
+}
+
+// location returns the [*time.Location] for a zone offset in seconds.
+func location(seconds int) *time.Location {
+	if seconds == 0 {
+		return time.UTC
+	}
+
+	return time.FixedZone("", seconds)
+}
+
+// daysIn returns the number of days in month in the given year.
+func daysIn(year int, month time.Month) int {
+	// Day 0 of next month normalises to the last day of this one
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nanoseconds converts the digits after a decimal point in seconds to nanoseconds,
+// truncating anything beyond the 9th digit.
+func nanoseconds(digits string) int {
+	const precision = 9
+
+	if len(digits) > precision {
+		digits = digits[:precision]
+	}
+
+	// Can't fail, it's at most 9 ASCII digits
+	n, _ := strconv.Atoi(digits)
+	for range precision - len(digits) {
+		n *= 10
+	}
+
+	return n
+}
+
+// months maps lower case 3 letter month names to their month.
+var months = map[string]time.Month{
+	"jan": time.January,
+	"feb": time.February,
+	"mar": time.March,
+	"apr": time.April,
+	"may": time.May,
+	"jun": time.June,
+	"jul": time.July,
+	"aug": time.August,
+	"sep": time.September,
+	"oct": time.October,
+	"nov": time.November,
+	"dec": time.December,
+}
+
+// digits returns a [parser.Parser] that parses exactly n decimal digits. Any further
+// digits are left in the remainder.
+func digits(n int) parser.Parser[string] {
+	take := parser.TakeWhileBetween(n, n, isDigit)
+
+	// Not starting with a digit is by far the most common failure when searching, so it's
+	// checked first without the core building an error
+	return func(input string) (string, string, error) {
+		if input == "" || !isDigit(rune(input[0])) {
+			return "", "", errNoMatch
+		}
+
+		return take(input)
+	}
+}
+
+// isDigit reports whether r is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isLetter reports whether r is an ASCII letter.
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// isSign reports whether r is a '+' or '-'.
+func isSign(r rune) bool {
+	return r == '+' || r == '-'
+}
+
+// isDecimalSep reports whether r separates the whole and fractional part of a number,
+// ISO 8601 allows either.
+func isDecimalSep(r rune) bool {
+	return r == '.' || r == ','
+}
+
+var (
+	oneDigit   = digits(1)
+	twoDigits  = digits(2)
+	fourDigits = digits(4)
+
+	// decimal parses a run of decimal digits.
+	decimal = parser.TakeWhile(isDigit)
+
+	// monthName parses the 3 letter abbreviation of a month, checking it is
+	// actually a month is up to the caller.
+	monthName = parser.TakeWhileBetween(3, 3, isLetter)
+
+	sign       = parser.TakeWhileBetween(1, 1, isSign)
+	decimalSep = parser.TakeWhileBetween(1, 1, isDecimalSep)
+
+	hyphen         = parser.Char('-')
+	colon          = parser.Char(':')
+	slash          = parser.Char('/')
+	space          = parser.Char(' ')
+	tee            = parser.Char('T')
+	zulu           = parser.Char('Z')
+	weekDesignator = parser.Char('W')
+)
+
+// Errors for when the input doesn't start with anything like the requested format, these
+// are the common case when searching through text, so they are preallocated.
+var (
+	errNoMatch     = &Error{Msg: "no match", Offset: 0}
+	errNoRFC3339   = &Error{Msg: "expected an RFC 3339 timestamp", Offset: 0}
+	errNoDate      = &Error{Msg: "expected a date", Offset: 0}
+	errNoWeek      = &Error{Msg: "expected a week date", Offset: 0}
+	errNoCommonLog = &Error{Msg: "expected a common log timestamp", Offset: 0}
+)
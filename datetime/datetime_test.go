@@ -0,0 +1,258 @@
+package datetime_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/datetime"
+)
+
+func TestRFC3339(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected time, formatted as time.RFC3339Nano
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "utc", input: "2006-01-02T15:04:05Z", want: "2006-01-02T15:04:05Z", rest: ""},
+		{name: "offset", input: "2006-01-02T15:04:05+07:00", want: "2006-01-02T15:04:05+07:00", rest: ""},
+		{name: "negative offset", input: "2006-01-02T15:04:05-03:30", want: "2006-01-02T15:04:05-03:30", rest: ""},
+		{name: "zero offset", input: "2006-01-02T15:04:05-00:00", want: "2006-01-02T15:04:05Z", rest: ""},
+		{name: "fraction", input: "2006-01-02T15:04:05.123Z", want: "2006-01-02T15:04:05.123Z", rest: ""},
+		{name: "comma fraction", input: "2006-01-02T15:04:05,5Z", want: "2006-01-02T15:04:05.5Z", rest: ""},
+		{name: "truncated fraction", input: "2006-01-02T15:04:05.1234567899Z", want: "2006-01-02T15:04:05.123456789Z", rest: ""},
+		{name: "leap day", input: "2024-02-29T00:00:00Z", want: "2024-02-29T00:00:00Z", rest: ""},
+		{name: "with remainder", input: "2006-01-02T15:04:05Z INFO started", want: "2006-01-02T15:04:05Z", rest: " INFO started"},
+		{name: "empty", input: "", wantErr: true, err: "datetime: offset 0: expected an RFC 3339 timestamp"},
+		{name: "letters", input: "hello", wantErr: true, err: "datetime: offset 0: expected an RFC 3339 timestamp"},
+		{name: "short month", input: "2006-1-02T15:04:05Z", wantErr: true, err: "datetime: offset 5: expected month"},
+		{name: "month 13", input: "2006-13-02T15:04:05Z", wantErr: true, err: "datetime: offset 5: month 13 out of range [1, 12]"},
+		{name: "month 0", input: "2006-00-02T15:04:05Z", wantErr: true, err: "datetime: offset 5: month 00 out of range [1, 12]"},
+		{
+			name:    "february 30th",
+			input:   "2006-02-30T15:04:05Z",
+			wantErr: true,
+			err:     "datetime: offset 8: day 30 out of range [1, 28] for February 2006",
+		},
+		{
+			name:    "not a leap year",
+			input:   "2100-02-29T15:04:05Z",
+			wantErr: true,
+			err:     "datetime: offset 8: day 29 out of range [1, 28] for February 2100",
+		},
+		{name: "space separator", input: "2006-01-02 15:04:05Z", wantErr: true, err: "datetime: offset 10: expected 'T' after date"},
+		{name: "hour 24", input: "2006-01-02T24:00:00Z", wantErr: true, err: "datetime: offset 11: hour 24 out of range [0, 23]"},
+		{name: "minute 60", input: "2006-01-02T15:60:05Z", wantErr: true, err: "datetime: offset 14: minute 60 out of range [0, 59]"},
+		{name: "leap second", input: "2006-01-02T15:04:60Z", wantErr: true, err: "datetime: offset 17: second 60 out of range [0, 59]"},
+		{name: "empty fraction", input: "2006-01-02T15:04:05.Z", wantErr: true, err: "datetime: offset 20: expected digits after decimal separator"},
+		{name: "missing zone", input: "2006-01-02T15:04:05", wantErr: true, err: "datetime: offset 19: expected 'Z' or zone offset"},
+		{
+			name:    "zone hour 24",
+			input:   "2006-01-02T15:04:05+24:00",
+			wantErr: true,
+			err:     "datetime: offset 20: zone offset hour 24 out of range [0, 23]",
+		},
+		{name: "zone without colon", input: "2006-01-02T15:04:05+0700", wantErr: true, err: "datetime: offset 22: expected ':' in zone offset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := datetime.RFC3339(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestDate(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected time, formatted as time.RFC3339Nano
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "simple", input: "2024-03-15", want: "2024-03-15T00:00:00Z", rest: ""},
+		{name: "leap day", input: "2024-02-29 was a thursday", want: "2024-02-29T00:00:00Z", rest: " was a thursday"},
+		{name: "followed by time", input: "2024-03-15T12:00:00Z", want: "2024-03-15T00:00:00Z", rest: "T12:00:00Z"},
+		{name: "empty", input: "", wantErr: true, err: "datetime: offset 0: expected a date"},
+		{name: "basic format", input: "20240315", wantErr: true, err: "datetime: offset 4: expected '-' after year"},
+		{name: "five digit year", input: "12024-03-15", wantErr: true, err: "datetime: offset 4: expected '-' after year"},
+		{name: "missing day", input: "2024-03", wantErr: true, err: "datetime: offset 7: expected '-' after month"},
+		{name: "day 0", input: "2024-03-00", wantErr: true, err: "datetime: offset 8: day 00 out of range [1, 31]"},
+		{name: "april 31st", input: "2024-04-31", wantErr: true, err: "datetime: offset 8: day 31 out of range [1, 30] for April 2024"},
+		{
+			name:    "not a leap year",
+			input:   "2023-02-29",
+			wantErr: true,
+			err:     "datetime: offset 8: day 29 out of range [1, 28] for February 2023",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := datetime.Date(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestWeek(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected time, formatted as time.RFC3339Nano
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "monday by default", input: "2024-W01", want: "2024-01-01T00:00:00Z", rest: ""},
+		{name: "with weekday", input: "2021-W01-1", want: "2021-01-04T00:00:00Z", rest: ""},
+		{name: "starts in previous year", input: "2025-W01-1", want: "2024-12-30T00:00:00Z", rest: ""},
+		{name: "week 53", input: "2020-W53-7", want: "2021-01-03T00:00:00Z", rest: ""},
+		{name: "with remainder", input: "2024-W05 sprint", want: "2024-01-29T00:00:00Z", rest: " sprint"},
+		{name: "hyphen without weekday", input: "2024-W05-planning", want: "2024-01-29T00:00:00Z", rest: "-planning"},
+		{name: "empty", input: "", wantErr: true, err: "datetime: offset 0: expected a week date"},
+		{name: "calendar date", input: "2024-01-01", wantErr: true, err: "datetime: offset 5: expected 'W' after year"},
+		{name: "short week", input: "2024-W1", wantErr: true, err: "datetime: offset 6: expected week"},
+		{name: "week 0", input: "2024-W00", wantErr: true, err: "datetime: offset 6: week 00 out of range [1, 52]"},
+		{name: "no week 53", input: "2021-W53", wantErr: true, err: "datetime: offset 6: week 53 out of range [1, 52]"},
+		{name: "weekday 8", input: "2024-W10-8", wantErr: true, err: "datetime: offset 9: weekday 8 out of range [1, 7]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := datetime.Week(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestCommonLog(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected time, formatted as time.RFC3339Nano
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "simple", input: "10/Oct/2000:13:55:36 -0700", want: "2000-10-10T13:55:36-07:00", rest: ""},
+		{name: "utc", input: "10/oct/2000:13:55:36 +0000] \"GET /\"", want: "2000-10-10T13:55:36Z", rest: "] \"GET /\""},
+		{name: "upper case month", input: "01/JAN/2024:00:00:00 +0100", want: "2024-01-01T00:00:00+01:00", rest: ""},
+		{name: "empty", input: "", wantErr: true, err: "datetime: offset 0: expected a common log timestamp"},
+		{name: "day 0", input: "00/Oct/2000:13:55:36 -0700", wantErr: true, err: "datetime: offset 0: day 00 out of range [1, 31] for October 2000"},
+		{name: "april 31st", input: "31/Apr/2000:13:55:36 -0700", wantErr: true, err: "datetime: offset 0: day 31 out of range [1, 30] for April 2000"},
+		{name: "unknown month", input: "10/Okt/2000:13:55:36 -0700", wantErr: true, err: `datetime: offset 3: unknown month "Okt"`},
+		{name: "numeric month", input: "10/10/2000:13:55:36 -0700", wantErr: true, err: "datetime: offset 3: expected 3 letter month name"},
+		{name: "full month", input: "10/October/2000:13:55:36 -0700", wantErr: true, err: "datetime: offset 6: expected '/' after month"},
+		{name: "space after year", input: "10/Oct/2000 13:55:36 -0700", wantErr: true, err: "datetime: offset 11: expected ':' after year"},
+		{name: "hour 25", input: "10/Oct/2000:25:55:36 -0700", wantErr: true, err: "datetime: offset 12: hour 25 out of range [0, 23]"},
+		{name: "no space", input: "10/Oct/2000:13:55:36-0700", wantErr: true, err: "datetime: offset 20: expected ' ' before zone offset"},
+		{name: "zulu", input: "10/Oct/2000:13:55:36 Z", wantErr: true, err: "datetime: offset 21: expected zone offset"},
+		{name: "zone minute 60", input: "10/Oct/2000:13:55:36 -0760", wantErr: true, err: "datetime: offset 24: zone offset minute 60 out of range [0, 59]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := datetime.CommonLog(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	_, _, err := datetime.RFC3339("2006-01-32T15:04:05Z")
+
+	var dateErr *datetime.Error
+	if !errors.As(err, &dateErr) {
+		t.Fatalf("Error was not a *datetime.Error, got %T", err)
+	}
+
+	if dateErr.Offset != 8 {
+		t.Errorf("Offset = %d, wanted 8", dateErr.Offset)
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	text := "deployed 2024-03-15T09:30:00Z, rolled back 2024-03-15T10:02:17.5+01:00 (not 2024-13-01T00:00:00Z)"
+
+	var got []string
+	for match, err := range parser.FindAll(datetime.RFC3339, text) {
+		if err != nil {
+			t.Fatalf("FindAll returned an unexpected error: %v", err)
+		}
+		got = append(got, match.Value.Format(time.RFC3339Nano))
+	}
+
+	want := []string{"2024-03-15T09:30:00Z", "2024-03-15T10:02:17.5+01:00"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("\nGot:\t%v\nWanted:\t%v\n", got, want)
+	}
+}
+
+func ExampleRFC3339() {
+	line := "2024-03-15T09:30:00.250+01:00 INFO server started"
+
+	timestamp, rest, err := datetime.RFC3339(line)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(timestamp.UTC())
+	fmt.Printf("%q\n", rest)
+
+	// Output: 2024-03-15 08:30:00.25 +0000 UTC
+	// " INFO server started"
+}
+
+func ExampleCommonLog() {
+	line := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`
+
+	match, err := parser.FindFirst(datetime.CommonLog, line)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(match.Value.Format(time.RFC1123Z))
+
+	// Output: Tue, 10 Oct 2000 13:55:36 -0700
+}
+
+func ExampleError() {
+	_, _, err := datetime.Date("2023-02-29")
+	fmt.Println(err)
+
+	// Output: datetime: offset 8: day 29 out of range [1, 28] for February 2023
+}
+
+// check compares the result of one of the timestamp parsers with what we wanted.
+func check(t *testing.T, got time.Time, rest string, err error, want, wantRest, wantErr string, errExpected bool) {
+	t.Helper()
+
+	if (err != nil) != errExpected {
+		t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, errExpected)
+	}
+
+	if err != nil {
+		if msg := err.Error(); msg != wantErr {
+			t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, wantErr)
+		}
+		return
+	}
+
+	if formatted := got.Format(time.RFC3339Nano); formatted != want {
+		t.Errorf("\nValue:\t%s\nWanted:\t%s\n", formatted, want)
+	}
+
+	if rest != wantRest {
+		t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", rest, wantRest)
+	}
+}
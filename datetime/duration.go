@@ -0,0 +1,372 @@
+package datetime
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// Period is an ISO 8601 duration.
+//
+// The years, months, weeks and days are kept separate from the time, as their length
+// depends on when they are applied (a month can be 28 to 31 days, and a day 23 or 25
+// hours across a daylight saving change), see [Period.AddTo].
+type Period struct {
+	Years  int
+	Months int
+	Weeks  int
+	Days   int
+
+	// Time is the total of the hours, minutes and seconds.
+	Time time.Duration
+}
+
+// AddTo returns t plus the period, adding the calendar components with [time.Time.AddDate]
+// before adding on the Time.
+func (p Period) AddTo(t time.Time) time.Time {
+	return t.AddDate(p.Years, p.Months, p.Weeks*7+p.Days).Add(p.Time)
+}
+
+// ISODuration parses an ISO 8601 duration like P1Y2M3DT4H5M6.5S or P2W from the start of input.
+//
+// Components must appear in the order years, months, weeks, days then, after a 'T',
+// hours, minutes and seconds. Any of them may be left out, but there must be at least one,
+// and a 'T' must be followed by at least one time component.
+//
+// Only the last time component may have a fractional part (separated by '.' or ','), as
+// years, months, weeks and days don't have a fixed length to take a fraction of.
+func ISODuration(input string) (Period, string, error) {
+	s := scan{input: input, rest: input}
+
+	if err := s.char(periodDesignator, "'P'"); err != nil {
+		return Period{}, "", errNoISODuration
+	}
+
+	var (
+		period Period
+		found  bool // Whether we've seen any component at all
+	)
+
+	designators := "YMWD"
+
+	for {
+		start := s.offset()
+
+		whole, afterWhole, err := decimal(s.rest)
+		if err != nil {
+			break
+		}
+
+		s.rest = afterWhole
+
+		if _, _, err = decimalSep(s.rest); err == nil {
+			return Period{}, "", s.errorf(start, "only the last time component may have a fraction")
+		}
+
+		designator, err := s.designator(designators)
+		if err != nil {
+			return Period{}, "", err
+		}
+
+		n, err := strconv.Atoi(whole)
+		if err != nil {
+			return Period{}, "", s.errorf(start, "%s too large", designatorNames[designator])
+		}
+
+		switch designator {
+		case 'Y':
+			period.Years = n
+		case 'M':
+			period.Months = n
+		case 'W':
+			period.Weeks = n
+		case 'D':
+			period.Days = n
+		}
+
+		designators = designators[strings.IndexByte(designators, designator)+1:]
+		found = true
+	}
+
+	if _, afterT, err := tee(s.rest); err == nil {
+		s.rest = afterT
+
+		total, err := s.timeComponents()
+		if err != nil {
+			return Period{}, "", err
+		}
+
+		period.Time = total
+		found = true
+	}
+
+	if !found {
+		return Period{}, "", s.errorf(s.offset(), "expected a duration component after 'P'")
+	}
+
+	return period, s.rest, nil
+}
+
+// GoDuration parses a duration in the format understood by [time.ParseDuration], like
+// 1h30m or -1.5s, from the start of input.
+//
+// The duration is a possibly signed sequence of decimal numbers, each with an optional
+// fraction and a unit suffix. Valid units are "ns", "us" (or "µs"), "ms", "s", "m" and "h".
+// As with [time.ParseDuration], a lone "0" doesn't need a unit.
+//
+// A unit is the whole run of letters after a number, so "5sec" is an error rather than 5s
+// with "ec" remaining.
+func GoDuration(input string) (time.Duration, string, error) {
+	s := scan{input: input, rest: input}
+
+	if _, rest, err := sign(s.rest); err == nil {
+		s.rest = rest
+	}
+
+	// Where the first component would start, so we can tell if there are any
+	first := s.offset()
+
+	for {
+		start := s.offset()
+
+		number, afterNumber, err := goNumber(s.rest)
+		if err != nil {
+			break
+		}
+
+		// The special case of a lone zero without a unit
+		if number == "0" && start == first {
+			if _, _, err = unit(afterNumber); err != nil {
+				s.rest = afterNumber
+				return 0, s.rest, nil
+			}
+		}
+
+		s.rest = afterNumber
+		unitOffset := s.offset()
+
+		name, afterUnit, err := unit(s.rest)
+		if err != nil {
+			return 0, "", s.errorf(unitOffset, "missing unit after %s", number)
+		}
+
+		if _, ok := units[name]; !ok {
+			return 0, "", s.errorf(unitOffset, "unknown unit %q", name)
+		}
+
+		s.rest = afterUnit
+	}
+
+	if s.offset() == first {
+		if first == 0 {
+			return 0, "", errNoGoDuration
+		}
+
+		return 0, "", s.errorf(first, "expected a number after sign")
+	}
+
+	// The grammar is right, so now the standard library can do the arithmetic, guaranteeing
+	// the exact same result. The only thing it can object to now is the size of the duration.
+	d, err := time.ParseDuration(input[:s.offset()])
+	if err != nil {
+		return 0, "", s.errorf(0, "duration out of range")
+	}
+
+	return d, s.rest, nil
+}
+
+// designator parses the designator after a number in an ISO 8601 duration, which must be
+// one of allowed. Designators that are valid in the other half of the duration or out of
+// order get a more specific error.
+func (s *scan) designator(allowed string) (byte, error) {
+	start := s.offset()
+
+	text, rest, err := designatorChar(s.rest)
+	if err != nil {
+		return 0, s.errorf(start, "expected a designator after number")
+	}
+
+	designator := text[0]
+	if strings.IndexByte(allowed, designator) == -1 {
+		if _, ok := designatorNames[designator]; ok {
+			return 0, s.errorf(start, "unexpected designator '%c'", designator)
+		}
+
+		return 0, s.errorf(start, "unknown designator '%c'", designator)
+	}
+
+	s.rest = rest
+
+	return designator, nil
+}
+
+// timeComponents parses the hours, minutes and seconds after the 'T' in an ISO 8601
+// duration, returning their total.
+func (s *scan) timeComponents() (time.Duration, error) {
+	var total time.Duration
+
+	designators := "HMS"
+	afterT := s.offset()
+
+	for {
+		start := s.offset()
+
+		whole, afterWhole, err := decimal(s.rest)
+		if err != nil {
+			break
+		}
+
+		s.rest = afterWhole
+
+		var fraction string
+		if _, afterSep, err := decimalSep(s.rest); err == nil {
+			fraction, s.rest, err = decimal(afterSep)
+			if err != nil {
+				return 0, s.errorf(len(s.input)-len(afterSep), "expected digits after decimal separator")
+			}
+		}
+
+		designator, err := s.designator(designators)
+		if err != nil {
+			return 0, err
+		}
+
+		value, ok := scale(whole, fraction, timeUnits[designator])
+		if !ok || total > math.MaxInt64-value {
+			return 0, s.errorf(start, "duration too large")
+		}
+
+		total += value
+		designators = designators[strings.IndexByte(designators, designator)+1:]
+
+		if fraction != "" {
+			// Nothing can follow a fraction
+			if _, _, err = decimal(s.rest); err == nil {
+				return 0, s.errorf(start, "only the last time component may have a fraction")
+			}
+
+			break
+		}
+	}
+
+	if s.offset() == afterT {
+		return 0, s.errorf(afterT, "expected a time component after 'T'")
+	}
+
+	return total, nil
+}
+
+// scale returns whole.fraction lots of length, truncating to the nearest nanosecond. It returns
+// false if the result doesn't fit in a [time.Duration].
+func scale(whole, fraction string, length time.Duration) (time.Duration, bool) {
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || n > math.MaxInt64/int64(length) {
+		return 0, false
+	}
+
+	total := time.Duration(n) * length
+
+	// Each digit of the fraction is worth a tenth of the one before, and once that
+	// rounds down to nothing the rest can't make a difference
+	for _, digit := range []byte(fraction) {
+		length /= 10
+		if length == 0 {
+			break
+		}
+
+		total += time.Duration(digit-'0') * length
+	}
+
+	if total < 0 {
+		return 0, false
+	}
+
+	return total, true
+}
+
+// goNumber parses the number part of a component of a Go duration: digits with an optional
+// fraction, where at least one side of the '.' must have digits.
+func goNumber(input string) (string, string, error) {
+	_, rest, wholeErr := decimal(input)
+	if wholeErr != nil {
+		rest = input
+	}
+
+	if _, afterDot, err := dot(rest); err == nil {
+		_, afterFraction, fractionErr := decimal(afterDot)
+		switch {
+		case fractionErr == nil:
+			rest = afterFraction
+		case wholeErr == nil:
+			// "5." is fine, a trailing dot with nothing after
+			rest = afterDot
+		}
+	}
+
+	if len(rest) == len(input) {
+		return "", "", errNoMatch
+	}
+
+	return input[:len(input)-len(rest)], rest, nil
+}
+
+// isUnitChar reports whether r can be part of the unit of a Go duration.
+func isUnitChar(r rune) bool {
+	return isLetter(r) || r == 'µ' || r == 'μ'
+}
+
+// isUpper reports whether r is an ASCII upper case letter.
+func isUpper(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+// units is the set of units allowed in a Go duration.
+var units = map[string]struct{}{
+	"ns": {},
+	"us": {},
+	"µs": {}, // U+00B5 micro sign
+	"μs": {}, // U+03BC Greek small letter mu
+	"ms": {},
+	"s":  {},
+	"m":  {},
+	"h":  {},
+}
+
+// designatorNames maps the designators in an ISO 8601 duration to what they mean,
+// 'M' is both months and minutes but the name is only needed for the date half.
+var designatorNames = map[byte]string{
+	'Y': "years",
+	'M': "months",
+	'W': "weeks",
+	'D': "days",
+	'H': "hours",
+	'S': "seconds",
+}
+
+// timeUnits maps the designators in the time half of an ISO 8601 duration to their length.
+var timeUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+}
+
+var (
+	// unit parses the unit of a Go duration component.
+	unit = parser.TakeWhile(isUnitChar)
+
+	// designatorChar parses the single upper case letter that follows a number in an ISO 8601 duration.
+	designatorChar = parser.TakeWhileBetween(1, 1, isUpper)
+
+	dot              = parser.Char('.')
+	periodDesignator = parser.Char('P')
+)
+
+// Errors for when the input doesn't start with anything like a duration, see the others
+// in datetime.go.
+var (
+	errNoISODuration = &Error{Msg: "expected an ISO 8601 duration", Offset: 0}
+	errNoGoDuration  = &Error{Msg: "expected a duration", Offset: 0}
+)
@@ -0,0 +1,203 @@
+package datetime_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/datetime"
+)
+
+func TestGoDuration(t *testing.T) {
+	tests := []struct {
+		name    string        // Identifying test case name
+		input   string        // Input to parse
+		rest    string        // The expected remainder
+		err     string        // The expected error message, if there was one
+		want    time.Duration // The expected duration
+		wantErr bool          // Whether or not we wanted an error
+	}{
+		{name: "simple", input: "1h30m", want: 90 * time.Minute, rest: ""},
+		{name: "fraction", input: "1.5h", want: 90 * time.Minute, rest: ""},
+		{name: "negative", input: "-1.5s", want: -1500 * time.Millisecond, rest: ""},
+		{name: "plus", input: "+5s", want: 5 * time.Second, rest: ""},
+		{name: "no whole part", input: ".5s", want: 500 * time.Millisecond, rest: ""},
+		{name: "trailing dot", input: "5.s", want: 5 * time.Second, rest: ""},
+		{name: "micro sign", input: "1µs", want: time.Microsecond, rest: ""},
+		{name: "greek mu", input: "1μs", want: time.Microsecond, rest: ""},
+		{name: "all units", input: "1h1m1s1ms1us1ns", want: time.Hour + time.Minute + time.Second + time.Millisecond + time.Microsecond + time.Nanosecond},
+		{name: "lone zero", input: "0", want: 0, rest: ""},
+		{name: "signed zero", input: "-0", want: 0, rest: ""},
+		{name: "zero with remainder", input: "0 retries", want: 0, rest: " retries"},
+		{name: "with remainder", input: "300ms, retrying", want: 300 * time.Millisecond, rest: ", retrying"},
+		{name: "sign ends duration", input: "1h-1m", want: time.Hour, rest: "-1m"},
+		{name: "max", input: "2562047h47m16.854775807s", want: time.Duration(1<<63 - 1), rest: ""},
+		{name: "empty", input: "", wantErr: true, err: "datetime: offset 0: expected a duration"},
+		{name: "dot", input: ".", wantErr: true, err: "datetime: offset 0: expected a duration"},
+		{name: "sign only", input: "-", wantErr: true, err: "datetime: offset 1: expected a number after sign"},
+		{name: "missing unit", input: "1", wantErr: true, err: "datetime: offset 1: missing unit after 1"},
+		{name: "missing second unit", input: "1h30", wantErr: true, err: "datetime: offset 4: missing unit after 30"},
+		{name: "double zero", input: "00", wantErr: true, err: "datetime: offset 2: missing unit after 00"},
+		{name: "unknown unit", input: "5sec", wantErr: true, err: `datetime: offset 1: unknown unit "sec"`},
+		{name: "too large", input: "9223372036854775808ns", wantErr: true, err: "datetime: offset 0: duration out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := datetime.GoDuration(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("\nValue:\t%s\nWanted:\t%s\n", got, tt.want)
+			}
+
+			if rest != tt.rest {
+				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestISODuration(t *testing.T) {
+	tests := []struct {
+		name    string          // Identifying test case name
+		input   string          // Input to parse
+		rest    string          // The expected remainder
+		err     string          // The expected error message, if there was one
+		want    datetime.Period // The expected period
+		wantErr bool            // Whether or not we wanted an error
+	}{
+		{
+			name:  "everything",
+			input: "P1Y2M3DT4H5M6.5S",
+			want: datetime.Period{
+				Years:  1,
+				Months: 2,
+				Days:   3,
+				Time:   4*time.Hour + 5*time.Minute + 6500*time.Millisecond,
+			},
+		},
+		{name: "weeks", input: "P2W", want: datetime.Period{Weeks: 2}},
+		{name: "month", input: "P1M", want: datetime.Period{Months: 1}},
+		{name: "minute", input: "PT1M", want: datetime.Period{Time: time.Minute}},
+		{name: "fractional hours", input: "PT1.5H", want: datetime.Period{Time: 90 * time.Minute}},
+		{name: "comma fraction", input: "PT0,25S", want: datetime.Period{Time: 250 * time.Millisecond}},
+		{name: "hours over a day", input: "PT36H", want: datetime.Period{Time: 36 * time.Hour}},
+		{name: "with remainder", input: "P1DT2H of downtime", want: datetime.Period{Days: 1, Time: 2 * time.Hour}, rest: " of downtime"},
+		{name: "empty", input: "", wantErr: true, err: "datetime: offset 0: expected an ISO 8601 duration"},
+		{name: "word", input: "hello", wantErr: true, err: "datetime: offset 0: expected an ISO 8601 duration"},
+		{name: "just P", input: "P", wantErr: true, err: "datetime: offset 1: expected a duration component after 'P'"},
+		{name: "just PT", input: "PT", wantErr: true, err: "datetime: offset 2: expected a time component after 'T'"},
+		{name: "missing designator", input: "P1", wantErr: true, err: "datetime: offset 2: expected a designator after number"},
+		{name: "hours in date", input: "P1H", wantErr: true, err: "datetime: offset 2: unexpected designator 'H'"},
+		{name: "out of order", input: "P1D1Y", wantErr: true, err: "datetime: offset 4: unexpected designator 'Y'"},
+		{name: "repeated", input: "PT1H1H", wantErr: true, err: "datetime: offset 5: unexpected designator 'H'"},
+		{name: "unknown designator", input: "P1X", wantErr: true, err: "datetime: offset 2: unknown designator 'X'"},
+		{name: "fractional days", input: "P1.5D", wantErr: true, err: "datetime: offset 1: only the last time component may have a fraction"},
+		{name: "fraction not last", input: "PT1.5H30M", wantErr: true, err: "datetime: offset 2: only the last time component may have a fraction"},
+		{name: "empty fraction", input: "PT1.S", wantErr: true, err: "datetime: offset 4: expected digits after decimal separator"},
+		{name: "too many hours", input: "PT9999999999H", wantErr: true, err: "datetime: offset 2: duration too large"},
+		{name: "too many years", input: "P99999999999999999999Y", wantErr: true, err: "datetime: offset 1: years too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := datetime.ISODuration(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("\nValue:\t%+v\nWanted:\t%+v\n", got, tt.want)
+			}
+
+			if rest != tt.rest {
+				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestPeriodAddTo(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		period string // The ISO 8601 duration to add
+		want   string // The expected time, formatted as time.RFC3339
+	}{
+		{period: "P1D", want: "2024-02-01T12:00:00Z"},
+		{period: "P1W", want: "2024-02-07T12:00:00Z"},
+		{period: "P1Y", want: "2025-01-31T12:00:00Z"},
+		{period: "P1M", want: "2024-03-02T12:00:00Z"}, // February 31st normalises, like time.AddDate
+		{period: "PT12H", want: "2024-02-01T00:00:00Z"},
+		{period: "P1DT1.5H", want: "2024-02-01T13:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			period, _, err := datetime.ISODuration(tt.period)
+			if err != nil {
+				t.Fatalf("ISODuration returned an unexpected error: %v", err)
+			}
+
+			if got := period.AddTo(start).Format(time.RFC3339); got != tt.want {
+				t.Errorf("AddTo = %s, wanted %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func ExampleGoDuration() {
+	text := "GET /health took 1.2ms, GET /users took 350ms and GET /report took 2m3.5s"
+
+	var total time.Duration
+	for match, err := range parser.FindAll(datetime.GoDuration, text) {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		total += match.Value
+	}
+
+	fmt.Println(total)
+
+	// Output: 2m3.8512s
+}
+
+func ExampleISODuration() {
+	period, rest, err := datetime.ISODuration("P1DT2H30M remaining")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	start := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC)
+
+	fmt.Println(period.Days, period.Time)
+	fmt.Println(period.AddTo(start))
+	fmt.Printf("%q\n", rest)
+
+	// Output: 1 2h30m0s
+	// 2024-03-10 14:30:00 +0000 UTC
+	// " remaining"
+}
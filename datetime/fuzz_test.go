@@ -0,0 +1,197 @@
+package datetime_test
+
+// The fuzz tests in here check that the parsers never panic and, where the standard library
+// has an equivalent, that they agree with it about what is and isn't valid.
+//
+// The parsers in this package only need to match a prefix of their input, so agreement
+// means: whenever the standard library accepts the whole input, so must we (consuming all
+// of it, with the same result), and whenever we consume the whole input, the standard
+// library must accept it.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser/datetime"
+)
+
+// seeds are inputs for all the fuzz tests.
+var seeds = []string{
+	"2006-01-02T15:04:05Z",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2024-02-29",
+	"2020-W53-7",
+	"10/Oct/2000:13:55:36 -0700",
+	"1h30m",
+	"-1.5µs",
+	"P1Y2M3DT4H5M6.5S",
+	"PT0,5S",
+}
+
+// sameTime reports whether a and b are the same instant with the same zone offset.
+func sameTime(a, b time.Time) bool {
+	_, offsetA := a.Zone()
+	_, offsetB := b.Zone()
+
+	return a.Equal(b) && offsetA == offsetB
+}
+
+// zoneRangeError reports whether err is one of our range errors for a zone offset, which
+// the standard library doesn't check.
+func zoneRangeError(err error) bool {
+	var dateErr *datetime.Error
+	return errors.As(err, &dateErr) && strings.HasPrefix(dateErr.Msg, "zone offset")
+}
+
+func FuzzRFC3339(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := datetime.RFC3339(input)
+		want, stdErr := time.Parse(time.RFC3339Nano, input)
+
+		if stdErr == nil && !zoneRangeError(err) {
+			if err != nil || rest != "" {
+				t.Fatalf("time accepted %q as %s, but RFC3339 returned (%s, %q, %v)", input, want, got, rest, err)
+			}
+
+			if !sameTime(got, want) {
+				t.Fatalf("RFC3339(%q) = %s, time got %s", input, got, want)
+			}
+		}
+
+		if err == nil && rest == "" && stdErr != nil {
+			t.Fatalf("RFC3339 accepted %q as %s, but time rejected it: %v", input, got, stdErr)
+		}
+	})
+}
+
+func FuzzDate(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := datetime.Date(input)
+		want, stdErr := time.Parse(time.DateOnly, input)
+
+		if stdErr == nil {
+			if err != nil || rest != "" {
+				t.Fatalf("time accepted %q as %s, but Date returned (%s, %q, %v)", input, want, got, rest, err)
+			}
+
+			if !got.Equal(want) {
+				t.Fatalf("Date(%q) = %s, time got %s", input, got, want)
+			}
+		}
+
+		if err == nil && rest == "" && stdErr != nil {
+			t.Fatalf("Date accepted %q as %s, but time rejected it: %v", input, got, stdErr)
+		}
+	})
+}
+
+func FuzzCommonLog(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := datetime.CommonLog(input)
+		want, stdErr := time.Parse("02/Jan/2006:15:04:05 -0700", input)
+
+		if stdErr == nil && !zoneRangeError(err) {
+			if err != nil || rest != "" {
+				t.Fatalf("time accepted %q as %s, but CommonLog returned (%s, %q, %v)", input, want, got, rest, err)
+			}
+
+			if !sameTime(got, want) {
+				t.Fatalf("CommonLog(%q) = %s, time got %s", input, got, want)
+			}
+		}
+
+		if err == nil && rest == "" && stdErr != nil {
+			t.Fatalf("CommonLog accepted %q as %s, but time rejected it: %v", input, got, stdErr)
+		}
+	})
+}
+
+func FuzzGoDuration(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := datetime.GoDuration(input)
+		want, stdErr := time.ParseDuration(input)
+
+		if stdErr == nil {
+			if err != nil || rest != "" {
+				t.Fatalf("time accepted %q as %s, but GoDuration returned (%s, %q, %v)", input, want, got, rest, err)
+			}
+
+			if got != want {
+				t.Fatalf("GoDuration(%q) = %s, time got %s", input, got, want)
+			}
+		}
+
+		if err == nil && rest == "" && stdErr != nil {
+			t.Fatalf("GoDuration accepted %q as %s, but time rejected it: %v", input, got, stdErr)
+		}
+	})
+}
+
+func FuzzWeek(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	// There's nothing in the standard library to parse a week date, but the result
+	// must always fall in the week we asked for
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := datetime.Week(input)
+		if err != nil {
+			return
+		}
+
+		matched := input[:len(input)-len(rest)]
+		year, week := got.ISOWeek()
+		weekday := (int(got.Weekday())+6)%7 + 1
+
+		want := fmt.Sprintf("%04d-W%02d-%d", year, week, weekday)
+		if len(matched) < len(want) {
+			// No weekday given, so it's the Monday
+			want = strings.TrimSuffix(want, "-1")
+		}
+
+		if matched != want {
+			t.Fatalf("Week(%q) = %s, which is %s", input, got, want)
+		}
+	})
+}
+
+func FuzzISODuration(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		period, rest, err := datetime.ISODuration(input)
+		if err != nil {
+			return
+		}
+
+		if period.Years < 0 || period.Months < 0 || period.Weeks < 0 || period.Days < 0 || period.Time < 0 {
+			t.Fatalf("ISODuration(%q) = %+v, nothing should be negative", input, period)
+		}
+
+		if !strings.HasSuffix(input, rest) {
+			t.Fatalf("ISODuration(%q) returned remainder %q, which isn't a suffix of the input", input, rest)
+		}
+	})
+}
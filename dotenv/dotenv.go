@@ -0,0 +1,435 @@
+// Package dotenv implements a parser for .env files, built on the combinators in [parser].
+//
+// The supported syntax is:
+//
+//	# Comments take up a whole line, or follow an unquoted value after whitespace
+//	KEY=value
+//	export EXPORTED=the export prefix is allowed and ignored
+//	SPACES = whitespace around the '=' is fine # and this is a comment
+//	SINGLE='literal, no escapes or ${INTERPOLATION}'
+//	DOUBLE="escapes like \n and \" are processed, as is ${KEY}"
+//	MULTI="quoted values
+//	may span multiple lines"
+//	DEFAULT=${UNSET:-fallback}
+//
+// Interpolation of ${VAR}, $VAR and ${VAR:-default} happens in unquoted and double quoted values.
+// Variables are looked up from keys defined earlier in the file first, then from [Options.Lookup].
+// Undefined variables expand to the empty string.
+package dotenv // import "go.followtheprocess.codes/parser/dotenv"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// Position is a location in the source of a .env file.
+type Position struct {
+	Line   int // 1 indexed line number
+	Column int // 1 indexed column (in utf-8 chars)
+}
+
+// String implements [fmt.Stringer] for [Position].
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Entry is a single variable definition.
+type Entry struct {
+	Key   string   // The variable name
+	Value string   // The value with quotes removed, escapes processed and variables interpolated
+	Pos   Position // Position of the start of the key
+}
+
+// SyntaxError is the error returned when a .env file cannot be parsed.
+type SyntaxError struct {
+	Msg string   // Description of the problem
+	Pos Position // Where the problem occurred
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("dotenv: %s: %s", e.Pos, e.Msg)
+}
+
+// Options configures the .env parser, the zero value is ready to use.
+type Options struct {
+	// Lookup is called to resolve interpolated variables that are not defined earlier
+	// in the file, [os.LookupEnv] is a common choice. If nil, only variables from the
+	// file itself are used.
+	Lookup func(key string) (string, bool)
+}
+
+// Parse parses a .env file with the default [Options].
+func Parse(input string) ([]Entry, error) {
+	return Options{}.Parse(input)
+}
+
+// Parse parses a .env file, returning the entries in the order they appeared.
+//
+// If a key is defined more than once, every definition is returned, use [Map] to get
+// the final value of each key.
+//
+// Any syntax error will be a [*SyntaxError].
+func (o Options) Parse(input string) ([]Entry, error) {
+	if !utf8.ValidString(input) {
+		return nil, errors.New("dotenv: input not valid utf-8")
+	}
+
+	p := &fileParser{
+		src:     input,
+		options: o,
+		defined: make(map[string]string),
+	}
+
+	rest := input
+	for {
+		rest = p.skip(rest)
+		if rest == "" {
+			break
+		}
+
+		entry, remainder, err := p.entry(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		p.entries = append(p.entries, entry)
+		p.defined[entry.Key] = entry.Value
+		rest = remainder
+	}
+
+	return p.entries, nil
+}
+
+// Map returns a map of each key to its final value, later definitions of a
+// key overwrite earlier ones.
+func Map(entries []Entry) map[string]string {
+	env := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		env[entry.Key] = entry.Value
+	}
+
+	return env
+}
+
+// fileParser holds the state needed while parsing a single file.
+type fileParser struct {
+	defined map[string]string // Variables defined so far in the file
+	src     string            // The entire input
+	entries []Entry           // The entries parsed so far
+	options Options           // The parser options
+}
+
+// skip skips over whitespace, blank lines and comment lines.
+func (p *fileParser) skip(rest string) string {
+	for {
+		_, rest, _ = space(rest)
+
+		if _, remainder, err := newline(rest); err == nil {
+			rest = remainder
+			continue
+		}
+
+		if _, remainder, err := commentStart(rest); err == nil {
+			_, rest, _ = toEOL(remainder)
+			continue
+		}
+
+		return rest
+	}
+}
+
+// entry parses a single KEY=value definition.
+func (p *fileParser) entry(input string) (Entry, string, error) {
+	rest := input
+	if _, remainder, err := export(rest); err == nil {
+		rest = remainder
+	}
+
+	keyStart := rest
+
+	key, rest, err := name(rest)
+	if err != nil {
+		return Entry{}, "", p.fail(keyStart, "expected variable name")
+	}
+
+	_, rest, _ = space(rest)
+	if _, rest, err = parser.Char('=')(rest); err != nil {
+		return Entry{}, "", p.fail(keyStart[len(key):], fmt.Sprintf("expected '=' after %s", key))
+	}
+
+	_, rest, _ = space(rest)
+
+	var value string
+	switch {
+	case strings.HasPrefix(rest, "'"):
+		value, rest, err = p.singleQuoted(rest)
+	case strings.HasPrefix(rest, `"`):
+		value, rest, err = p.doubleQuoted(rest)
+	default:
+		value, rest, err = p.unquoted(rest)
+	}
+
+	if err != nil {
+		return Entry{}, "", err
+	}
+
+	entry := Entry{
+		Key:   key,
+		Value: value,
+		Pos:   p.position(keyStart),
+	}
+
+	return entry, rest, nil
+}
+
+// singleQuoted parses a single quoted value, the contents are taken literally.
+func (p *fileParser) singleQuoted(input string) (string, string, error) {
+	contents, rest, err := parser.TakeTo("'")(input[1:])
+	if err != nil {
+		return "", "", p.fail(input, "unterminated single quoted value")
+	}
+
+	rest, err = p.endOfValue(rest[1:])
+	if err != nil {
+		return "", "", err
+	}
+
+	return contents, rest, nil
+}
+
+// doubleQuoted parses a double quoted value, processing escapes and interpolating variables.
+func (p *fileParser) doubleQuoted(input string) (string, string, error) {
+	var builder strings.Builder
+
+	rest := input[1:]
+	for {
+		chunk, remainder, _ := doubleQuotedChars(rest)
+		builder.WriteString(chunk)
+		rest = remainder
+
+		switch {
+		case rest == "":
+			return "", "", p.fail(input, "unterminated double quoted value")
+		case rest[0] == '"':
+			rest, err := p.endOfValue(rest[1:])
+			if err != nil {
+				return "", "", err
+			}
+			return builder.String(), rest, nil
+		case rest[0] == '\\':
+			escaped, remainder, err := escape(rest)
+			if err != nil {
+				// Not a recognised escape, keep the backslash as is
+				builder.WriteByte('\\')
+				rest = rest[1:]
+				continue
+			}
+			builder.WriteString(escaped)
+			rest = remainder
+		default:
+			// Must be a '$'
+			expanded, remainder := p.interpolate(rest)
+			builder.WriteString(expanded)
+			rest = remainder
+		}
+	}
+}
+
+// unquoted parses an unquoted value, which runs to the end of the line or the start
+// of a comment, with trailing whitespace trimmed.
+func (p *fileParser) unquoted(input string) (string, string, error) {
+	var builder strings.Builder
+
+	rest := input
+	for {
+		chunk, remainder, _ := unquotedChars(rest)
+		builder.WriteString(chunk)
+		rest = remainder
+
+		if rest != "" && rest[0] == '$' {
+			expanded, remainder := p.interpolate(rest)
+			builder.WriteString(expanded)
+			rest = remainder
+			continue
+		}
+
+		// A '#' only starts a comment at the start of the value or after whitespace,
+		// otherwise it's part of the value
+		value := builder.String()
+		if rest != "" && rest[0] == '#' && value != "" && !strings.HasSuffix(value, " ") && !strings.HasSuffix(value, "\t") {
+			builder.WriteByte('#')
+			rest = rest[1:]
+			continue
+		}
+
+		break
+	}
+
+	// Whatever's left on the line is a comment (or nothing)
+	_, rest, _ = toEOL(rest)
+
+	return strings.TrimRight(builder.String(), " \t\r"), rest, nil
+}
+
+// endOfValue checks that only whitespace or a comment follows a quoted value on the
+// same line.
+func (p *fileParser) endOfValue(rest string) (string, error) {
+	_, rest, _ = space(rest)
+
+	if _, remainder, err := commentStart(rest); err == nil {
+		_, rest, _ = toEOL(remainder)
+		return rest, nil
+	}
+
+	if rest != "" {
+		if _, _, err := newline(rest); err != nil {
+			return "", p.fail(rest, fmt.Sprintf("unexpected %q after quoted value", firstChar(rest)))
+		}
+	}
+
+	return rest, nil
+}
+
+// interpolate expands the variable reference at the start of input, which must start
+// with a '$'. If it's not a valid reference, the '$' is returned literally.
+func (p *fileParser) interpolate(input string) (string, string) {
+	rest := input[1:]
+
+	if _, remainder, err := parser.Char('{')(rest); err == nil {
+		key, remainder, err := name(remainder)
+		if err != nil {
+			return "$", rest
+		}
+
+		fallback := ""
+		if _, afterDefault, err := defaultOp(remainder); err == nil {
+			fallback, remainder, _ = defaultValue(afterDefault)
+		}
+
+		if _, remainder, err = parser.Char('}')(remainder); err != nil {
+			return "$", rest
+		}
+
+		if value, ok := p.lookup(key); ok && value != "" {
+			return value, remainder
+		}
+
+		return fallback, remainder
+	}
+
+	key, remainder, err := name(rest)
+	if err != nil {
+		return "$", rest
+	}
+
+	value, _ := p.lookup(key)
+
+	return value, remainder
+}
+
+// lookup finds the value of a variable, first from the file then from the options.
+func (p *fileParser) lookup(key string) (string, bool) {
+	if value, ok := p.defined[key]; ok {
+		return value, true
+	}
+
+	if p.options.Lookup != nil {
+		return p.options.Lookup(key)
+	}
+
+	return "", false
+}
+
+// position returns the [Position] of the start of rest in the source.
+func (p *fileParser) position(rest string) Position {
+	before := p.src[:len(p.src)-len(rest)]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	return Position{
+		Line:   1 + strings.Count(before, "\n"),
+		Column: 1 + utf8.RuneCountInString(before[lineStart:]),
+	}
+}
+
+// fail returns a [SyntaxError] at the start of rest.
+func (p *fileParser) fail(rest, msg string) error {
+	return &SyntaxError{Msg: msg, Pos: p.position(rest)}
+}
+
+// escape parses a backslash escape sequence in a double quoted value.
+func escape(input string) (string, string, error) {
+	_, rest, err := parser.Char('\\')(input)
+	if err != nil {
+		return "", "", err
+	}
+
+	char, rest, err := parser.OneOf(`nrt"\$`)(rest)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch char {
+	case "n":
+		return "\n", rest, nil
+	case "r":
+		return "\r", rest, nil
+	case "t":
+		return "\t", rest, nil
+	default:
+		return char, rest, nil
+	}
+}
+
+// firstChar returns the first utf-8 char in s.
+func firstChar(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+const (
+	// nameStart are the chars allowed to start a variable name.
+	nameStart = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_"
+
+	// nameChars are the chars allowed in the rest of a variable name.
+	nameChars = nameStart + "0123456789"
+)
+
+var (
+	// name parses a variable name.
+	name = parser.Map(
+		parser.Chain(parser.OneOf(nameStart), parser.SkipMany(parser.OneOf(nameChars))),
+		func(parts []string) (string, error) { return parts[0] + parts[1], nil },
+	)
+
+	// export parses the optional "export" prefix, which must be followed by whitespace.
+	export = parser.Chain(parser.Exact("export"), parser.AnyOf(" \t"))
+
+	// space parses optional whitespace within a line.
+	space = parser.SkipMany(parser.OneOf(" \t"))
+
+	// newline parses a LF or CRLF line ending.
+	newline = parser.Try(parser.Exact("\n"), parser.Exact("\r\n"))
+
+	// commentStart parses the start of a comment.
+	commentStart = parser.Char('#')
+
+	// toEOL parses everything up to the end of the line.
+	toEOL = parser.SkipMany(parser.NoneOf("\n"))
+
+	// doubleQuotedChars parses chars in a double quoted value that need no special handling.
+	doubleQuotedChars = parser.SkipMany(parser.NoneOf(`"\$`))
+
+	// unquotedChars parses chars in an unquoted value that need no special handling.
+	unquotedChars = parser.SkipMany(parser.NoneOf("\n#$"))
+
+	// defaultOp parses the ":-" separating a variable from its default value.
+	defaultOp = parser.Chain(parser.Char(':'), parser.Char('-'))
+
+	// defaultValue parses the default value in a ${VAR:-default} reference.
+	defaultValue = parser.SkipMany(parser.NoneOf("}\n"))
+)
@@ -0,0 +1,218 @@
+package dotenv_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"go.followtheprocess.codes/parser/dotenv"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string         // Identifying test case name
+		input   string         // Input to parse
+		err     string         // The expected error message, if there was one
+		options dotenv.Options // The parser options
+		want    []dotenv.Entry // The expected entries
+		wantErr bool           // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			want:    nil,
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "simple",
+			input: "A=1\nB = two\r\n  export C=3\n",
+			want: []dotenv.Entry{
+				{Key: "A", Value: "1", Pos: dotenv.Position{Line: 1, Column: 1}},
+				{Key: "B", Value: "two", Pos: dotenv.Position{Line: 2, Column: 1}},
+				{Key: "C", Value: "3", Pos: dotenv.Position{Line: 3, Column: 10}},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "comments",
+			input: "# a comment\n\nA=value # trailing\nB=no#comment\nC= # empty\n",
+			want: []dotenv.Entry{
+				{Key: "A", Value: "value", Pos: dotenv.Position{Line: 3, Column: 1}},
+				{Key: "B", Value: "no#comment", Pos: dotenv.Position{Line: 4, Column: 1}},
+				{Key: "C", Value: "", Pos: dotenv.Position{Line: 5, Column: 1}},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "single quoted",
+			input: "A='literal ${B} \\n' # comment\nB='multi\nline'\n",
+			want: []dotenv.Entry{
+				{Key: "A", Value: `literal ${B} \n`, Pos: dotenv.Position{Line: 1, Column: 1}},
+				{Key: "B", Value: "multi\nline", Pos: dotenv.Position{Line: 2, Column: 1}},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "double quoted",
+			input: `A="tab\there \"quoted\" \$HOME \q"` + "\nB=\"multi\nline\"\n",
+			want: []dotenv.Entry{
+				{Key: "A", Value: "tab\there \"quoted\" $HOME \\q", Pos: dotenv.Position{Line: 1, Column: 1}},
+				{Key: "B", Value: "multi\nline", Pos: dotenv.Position{Line: 2, Column: 1}},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "interpolation",
+			input: "USER=me\nA=${USER}/x\nB=\"$USER-${MISSING:-default}\"\nC=$MISSING.$\nD=${USER:-unused}\n",
+			want: []dotenv.Entry{
+				{Key: "USER", Value: "me", Pos: dotenv.Position{Line: 1, Column: 1}},
+				{Key: "A", Value: "me/x", Pos: dotenv.Position{Line: 2, Column: 1}},
+				{Key: "B", Value: "me-default", Pos: dotenv.Position{Line: 3, Column: 1}},
+				{Key: "C", Value: ".$", Pos: dotenv.Position{Line: 4, Column: 1}},
+				{Key: "D", Value: "me", Pos: dotenv.Position{Line: 5, Column: 1}},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "lookup",
+			input: "A=$HOME\nHOME=/override\nB=$HOME\n",
+			options: dotenv.Options{
+				Lookup: func(key string) (string, bool) {
+					if key == "HOME" {
+						return "/home/me", true
+					}
+					return "", false
+				},
+			},
+			want: []dotenv.Entry{
+				{Key: "A", Value: "/home/me", Pos: dotenv.Position{Line: 1, Column: 1}},
+				{Key: "HOME", Value: "/override", Pos: dotenv.Position{Line: 2, Column: 1}},
+				{Key: "B", Value: "/override", Pos: dotenv.Position{Line: 3, Column: 1}},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "bad key",
+			input:   "A=1\n1BAD=2\n",
+			want:    nil,
+			wantErr: true,
+			err:     "dotenv: 2:1: expected variable name",
+		},
+		{
+			name:    "missing equals",
+			input:   "KEY value\n",
+			want:    nil,
+			wantErr: true,
+			err:     "dotenv: 1:4: expected '=' after KEY",
+		},
+		{
+			name:    "unterminated single",
+			input:   "A=1\nB='oops\n",
+			want:    nil,
+			wantErr: true,
+			err:     "dotenv: 2:3: unterminated single quoted value",
+		},
+		{
+			name:    "unterminated double",
+			input:   `A="oops`,
+			want:    nil,
+			wantErr: true,
+			err:     "dotenv: 1:3: unterminated double quoted value",
+		},
+		{
+			name:    "junk after quotes",
+			input:   `A="日本" junk`,
+			want:    nil,
+			wantErr: true,
+			err:     `dotenv: 1:8: unexpected 'j' after quoted value`,
+		},
+		{
+			name:    "bad utf8",
+			input:   "\xf8\xa1\xa1\xa1\xa1",
+			want:    nil,
+			wantErr: true,
+			err:     "dotenv: input not valid utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.Parse(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nEntries:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	_, err := dotenv.Parse("A=1\n\nB='unterminated\n")
+
+	var syntaxErr *dotenv.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Error was not a *dotenv.SyntaxError, got %T", err)
+	}
+
+	want := dotenv.Position{Line: 3, Column: 3}
+	if syntaxErr.Pos != want {
+		t.Errorf("\nPosition:\t%v\nWanted:\t%v\n", syntaxErr.Pos, want)
+	}
+}
+
+func TestMap(t *testing.T) {
+	entries, err := dotenv.Parse("A=1\nB=2\nA=3\n")
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	got := dotenv.Map(entries)
+	want := map[string]string{"A": "3", "B": "2"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nMap:\t%v\nWanted:\t%v\n", got, want)
+	}
+}
+
+func ExampleParse() {
+	input := `# Database settings
+DB_HOST=localhost
+DB_PORT=5432
+DB_URL="postgres://${DB_HOST}:${DB_PORT}/app"
+GREETING='Hello, $USER'
+`
+
+	entries, err := dotenv.Parse(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	for _, entry := range entries {
+		fmt.Printf("%s = %q (line %d)\n", entry.Key, entry.Value, entry.Pos.Line)
+	}
+
+	// Output: DB_HOST = "localhost" (line 2)
+	// DB_PORT = "5432" (line 3)
+	// DB_URL = "postgres://localhost:5432/app" (line 4)
+	// GREETING = "Hello, $USER" (line 5)
+}
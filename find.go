@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// Match is a single match of a [Parser] found somewhere in the input by [FindAll] or [FindFirst].
+type Match[T any] struct {
+	Value T      // The value returned from the parser
+	Text  string // The portion of the input consumed by the parser
+	Start int    // The byte offset in the input at which the match starts
+	End   int    // The byte offset in the input at which the match ends (exclusive)
+}
+
+// FindAll returns an iterator over every non-overlapping match of a [Parser] anywhere in the input,
+// rather than just at the start.
+//
+// The parser is attempted at the start of the input, and at every subsequent utf-8 char boundary
+// after a failed attempt. After a successful match, scanning resumes immediately after the match so
+// matches never overlap. A parser that succeeds without consuming any input is treated as not having
+// matched, so FindAll never yields empty matches.
+//
+// If the input is not valid utf-8, a single error is yielded and iteration stops. An empty input
+// or an input with no matches yields nothing.
+//
+// The input is checked once up front, and as the parsers in this package only look at as much of
+// the input as they need to, the cost of a search is in proportion to the length of the input for
+// parsers that only look at a bounded amount of it, like [Exact] or [TakeWhile] of a set of chars.
+func FindAll[T any](parser Parser[T], input string) iter.Seq2[Match[T], error] {
+	return func(yield func(Match[T], error) bool) {
+		if !utf8.ValidString(input) {
+			yield(Match[T]{}, errors.New("FindAll: input not valid utf-8"))
+			return
+		}
+
+		pos := 0 // Byte offset in input we're currently attempting a match at
+		for pos < len(input) {
+			value, remainder, err := parser(input[pos:])
+			end := len(input) - len(remainder)
+
+			if err != nil || end <= pos {
+				// No match here, move on to the next char
+				_, width := utf8.DecodeRuneInString(input[pos:])
+				pos += width
+				continue
+			}
+
+			match := Match[T]{
+				Value: value,
+				Text:  input[pos:end],
+				Start: pos,
+				End:   end,
+			}
+
+			if !yield(match, nil) {
+				return
+			}
+
+			pos = end
+		}
+	}
+}
+
+// FindFirst returns the first match of a [Parser] anywhere in the input, following the same
+// scanning rules as [FindAll].
+//
+// If the input is not valid utf-8 or there are no matches, an error will be returned.
+func FindFirst[T any](parser Parser[T], input string) (Match[T], error) {
+	for match, err := range FindAll(parser, input) {
+		if err != nil {
+			return Match[T]{}, fmt.Errorf("FindFirst: %w", err)
+		}
+
+		return match, nil
+	}
+
+	return Match[T]{}, errors.New("FindFirst: no match found in input")
+}
+
+// ReplaceAll returns a copy of the input with every match of a [Parser] replaced with the return
+// value of repl, following the same scanning rules as [FindAll].
+//
+// The replacement function is passed the full [Match], so it has access to the typed value returned
+// from the parser as well as the original text.
+//
+// If the input is not valid utf-8 or repl is nil, an error will be returned. If there are no matches,
+// the input is returned unchanged.
+func ReplaceAll[T any](parser Parser[T], input string, repl func(Match[T]) string) (string, error) {
+	if repl == nil {
+		return "", errors.New("ReplaceAll: repl must be a non-nil function")
+	}
+
+	var builder strings.Builder
+
+	found := false // Whether we've replaced anything
+	last := 0      // The end of the previous match
+	for match, err := range FindAll(parser, input) {
+		if err != nil {
+			return "", fmt.Errorf("ReplaceAll: %w", err)
+		}
+
+		builder.WriteString(input[last:match.Start])
+		builder.WriteString(repl(match))
+		last = match.End
+		found = true
+	}
+
+	if !found {
+		// Nothing was replaced
+		return input, nil
+	}
+
+	builder.WriteString(input[last:])
+
+	return builder.String(), nil
+}
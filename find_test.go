@@ -0,0 +1,363 @@
+package parser_test
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// ipv4ish is a loose parser for things that look like IPv4 addresses, it's not trying
+// to be correct just to give FindAll and friends something structured to look for.
+var ipv4ish = parser.Map(
+	parser.Chain(
+		parser.TakeWhileBetween(1, 3, unicode.IsDigit),
+		parser.Char('.'),
+		parser.TakeWhileBetween(1, 3, unicode.IsDigit),
+		parser.Char('.'),
+		parser.TakeWhileBetween(1, 3, unicode.IsDigit),
+		parser.Char('.'),
+		parser.TakeWhileBetween(1, 3, unicode.IsDigit),
+	),
+	func(parts []string) (string, error) { return strings.Join(parts, ""), nil },
+)
+
+func TestFindAll(t *testing.T) {
+	tests := []struct {
+		p       parser.Parser[string]  // The parser to search for
+		name    string                 // Identifying test case name
+		input   string                 // Input to search
+		err     string                 // The expected error message, if there was one
+		want    []parser.Match[string] // The expected matches
+		wantErr bool                   // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty input",
+			input:   "",
+			p:       parser.Exact("abc"),
+			want:    nil,
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "bad utf8",
+			input:   "\xf8\xa1\xa1\xa1\xa1",
+			p:       parser.Exact("abc"),
+			want:    nil,
+			wantErr: true,
+			err:     "FindAll: input not valid utf-8",
+		},
+		{
+			name:    "no matches",
+			input:   "nothing to see here",
+			p:       parser.Exact("abc"),
+			want:    nil,
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "match at start",
+			input: "abc and then some",
+			p:     parser.Exact("abc"),
+			want: []parser.Match[string]{
+				{Value: "abc", Text: "abc", Start: 0, End: 3},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "non overlapping",
+			input: "aaaaa",
+			p:     parser.Exact("aa"),
+			want: []parser.Match[string]{
+				{Value: "aa", Text: "aa", Start: 0, End: 2},
+				{Value: "aa", Text: "aa", Start: 2, End: 4},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "utf8 offsets",
+			input: "日ð本x日ð本",
+			p:     parser.Char('本'),
+			want: []parser.Match[string]{
+				{Value: "本", Text: "本", Start: 5, End: 8},
+				{Value: "本", Text: "本", Start: 14, End: 17},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "ip addresses in log line",
+			input: "conn from 10.0.0.1:443 to 192.168.1.254 (via 1.2.3)",
+			p:     ipv4ish,
+			want: []parser.Match[string]{
+				{Value: "10.0.0.1", Text: "10.0.0.1", Start: 10, End: 18},
+				{Value: "192.168.1.254", Text: "192.168.1.254", Start: 26, End: 39},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "empty matches skipped",
+			input: "abc",
+			p: func(input string) (string, string, error) {
+				// Always succeeds but never consumes anything
+				return "", input, nil
+			},
+			want:    nil,
+			wantErr: false,
+			err:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []parser.Match[string]
+			var err error
+			for match, e := range parser.FindAll(tt.p, tt.input) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, match)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nMatches:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAllBreak(t *testing.T) {
+	input := "a1b2c3d4"
+
+	var got []string
+	for match, err := range parser.FindAll(parser.TakeWhile(unicode.IsDigit), input) {
+		if err != nil {
+			t.Fatalf("FindAll returned an unexpected error: %v", err)
+		}
+		got = append(got, match.Value)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	want := []string{"1", "2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nValues:\t%#v\nWanted:\t%#v\n", got, want)
+	}
+}
+
+func TestFindFirst(t *testing.T) {
+	tests := []struct {
+		name    string               // Identifying test case name
+		input   string               // Input to search
+		err     string               // The expected error message, if there was one
+		want    parser.Match[string] // The expected match
+		wantErr bool                 // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty input",
+			input:   "",
+			want:    parser.Match[string]{},
+			wantErr: true,
+			err:     "FindFirst: no match found in input",
+		},
+		{
+			name:    "bad utf8",
+			input:   "\xf8\xa1\xa1\xa1\xa1",
+			want:    parser.Match[string]{},
+			wantErr: true,
+			err:     "FindFirst: FindAll: input not valid utf-8",
+		},
+		{
+			name:    "no match",
+			input:   "no addresses here 1.2.3",
+			want:    parser.Match[string]{},
+			wantErr: true,
+			err:     "FindFirst: no match found in input",
+		},
+		{
+			name:    "first of many",
+			input:   "src=10.1.1.1 dst=10.2.2.2",
+			want:    parser.Match[string]{Value: "10.1.1.1", Text: "10.1.1.1", Start: 4, End: 12},
+			wantErr: false,
+			err:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.FindFirst(ipv4ish, tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			if got != tt.want {
+				t.Errorf("\nMatch:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	redact := func(match parser.Match[string]) string { return "<ip>" }
+
+	tests := []struct {
+		repl    func(parser.Match[string]) string // The replacement function
+		name    string                            // Identifying test case name
+		input   string                            // Input to replace in
+		want    string                            // Expected output
+		err     string                            // The expected error message, if there was one
+		wantErr bool                              // Whether or not we wanted an error
+	}{
+		{
+			name:    "nil repl",
+			input:   "10.0.0.1",
+			repl:    nil,
+			want:    "",
+			wantErr: true,
+			err:     "ReplaceAll: repl must be a non-nil function",
+		},
+		{
+			name:    "bad utf8",
+			input:   "\xf8\xa1\xa1\xa1\xa1",
+			repl:    redact,
+			want:    "",
+			wantErr: true,
+			err:     "ReplaceAll: FindAll: input not valid utf-8",
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			repl:    redact,
+			want:    "",
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "no matches",
+			input:   "nothing to redact",
+			repl:    redact,
+			want:    "nothing to redact",
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "redact addresses",
+			input:   "conn from 10.0.0.1:443 to 192.168.1.254",
+			repl:    redact,
+			want:    "conn from <ip>:443 to <ip>",
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "use offsets",
+			input: "1.1.1.1 and 2.2.2.2",
+			repl: func(match parser.Match[string]) string {
+				return match.Value + "@" + strconv.Itoa(match.Start)
+			},
+			want:    "1.1.1.1@0 and 2.2.2.2@12",
+			wantErr: false,
+			err:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.ReplaceAll(ipv4ish, tt.input, tt.repl)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			if got != tt.want {
+				t.Errorf("\nGot:\t%q\nWanted:\t%q\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func ExampleFindAll() {
+	input := "temperatures: 21, 19, 23 and 25"
+
+	number := parser.Map(parser.TakeWhile(unicode.IsDigit), strconv.Atoi)
+
+	for match, err := range parser.FindAll(number, input) {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		fmt.Printf("%d at [%d:%d]\n", match.Value, match.Start, match.End)
+	}
+
+	// Output: 21 at [14:16]
+	// 19 at [18:20]
+	// 23 at [22:24]
+	// 25 at [29:31]
+}
+
+func ExampleFindFirst() {
+	input := "the answer is 42, not 41"
+
+	number := parser.Map(parser.TakeWhile(unicode.IsDigit), strconv.Atoi)
+
+	match, err := parser.FindFirst(number, input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value %[1]d is type %[1]T\n", match.Value)
+	fmt.Printf("Text: %q\n", match.Text)
+
+	// Output: Value 42 is type int
+	// Text: "42"
+}
+
+func ExampleReplaceAll() {
+	input := "3 apples and 12 pears"
+
+	number := parser.Map(parser.TakeWhile(unicode.IsDigit), strconv.Atoi)
+
+	doubled, err := parser.ReplaceAll(number, input, func(match parser.Match[int]) string {
+		return strconv.Itoa(match.Value * 2)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Println(doubled)
+
+	// Output: 6 apples and 24 pears
+}
@@ -6,6 +6,7 @@ package parser_test
 import (
 	"math/rand/v2"
 	"reflect"
+	"strings"
 	"testing"
 	"unicode"
 
@@ -169,6 +170,27 @@ func FuzzOptional(f *testing.F) {
 	})
 }
 
+func FuzzBlock(f *testing.F) {
+	for _, item := range corpus {
+		f.Add(item)
+	}
+	f.Add("a\n  b\n  c\n    d\ne\n")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		value, remainder, err := outlineParser(input)(input)
+		if err != nil {
+			if value != nil || remainder != "" {
+				t.Fatalf("Block returned value %v and remainder %q with error %v", value, remainder, err)
+			}
+			return
+		}
+
+		if !strings.HasSuffix(input, remainder) || len(remainder) == len(input) {
+			t.Fatalf("Block returned remainder %q that doesn't follow the parsed items in %q", remainder, input)
+		}
+	})
+}
+
 // fuzzParser is a helper that asserts empty value and remainders were returned if the
 // err was not nil.
 func fuzzParser[T any](t *testing.T, value T, remainder string, err error) {
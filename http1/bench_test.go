@@ -0,0 +1,53 @@
+package http1_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/http1"
+)
+
+// benchRequest is a typical browser request head.
+const benchRequest = "GET /docs/index.html?lang=en HTTP/1.1\r\n" +
+	"Host: example.com\r\n" +
+	"User-Agent: Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0\r\n" +
+	"Accept: text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8\r\n" +
+	"Accept-Language: en-GB,en;q=0.5\r\n" +
+	"Accept-Encoding: gzip, deflate, br\r\n" +
+	"Connection: keep-alive\r\n" +
+	"\r\n"
+
+// benchLargeBody is a request head followed by a large body, parsing the head should
+// take about as long as without the body.
+var benchLargeBody = benchRequest + strings.Repeat("x", 1<<20)
+
+// benchChunked is a chunked body of many small chunks.
+var benchChunked = strings.Repeat("1a;ext=1\r\nabcdefghijklmnopqrstuvwxyz\r\n", 1000) + "0\r\n\r\n"
+
+func BenchmarkParseRequest(b *testing.B) {
+	b.SetBytes(int64(len(benchRequest)))
+
+	for b.Loop() {
+		if _, _, err := http1.ParseRequest(benchRequest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseRequestLargeBody(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := http1.ParseRequest(benchLargeBody); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseChunked(b *testing.B) {
+	b.SetBytes(int64(len(benchChunked)))
+
+	for b.Loop() {
+		if _, _, err := http1.ParseChunked(benchChunked); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package http1
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// Chunk is a single chunk of a message body sent with chunked transfer coding.
+type Chunk struct {
+	// Extensions is any chunk extensions as written, including the leading ';', like ";name=value".
+	Extensions string
+
+	// Data is the chunk data, which is empty for the last chunk.
+	Data string
+
+	// Size is the size of the chunk data in bytes. The last chunk has a size of 0.
+	Size int64
+}
+
+// ChunkedBody is an entire message body sent with chunked transfer coding.
+type ChunkedBody struct {
+	Data    string // The data from all the chunks joined together
+	Trailer Header // Any trailer fields after the last chunk
+}
+
+// ParseChunk parses a single chunk, its size line, data and the CRLF after the data, from
+// the start of input.
+//
+// If the chunk is the last chunk (with a size of 0), the remainder starts with the trailer
+// section, which can be parsed with [ParseHeader]. Unlike the rest of this package, the
+// chunk data may be arbitrary bytes.
+func ParseChunk(input string) (Chunk, string, error) {
+	s := scan{input: input, rest: input}
+
+	chunk, err := s.chunk()
+	if err != nil {
+		return Chunk{}, "", err
+	}
+
+	return chunk, s.rest, nil
+}
+
+// ParseChunked parses an entire chunked body with the default [Options].
+func ParseChunked(input string) (ChunkedBody, string, error) {
+	return Options{}.ParseChunked(input)
+}
+
+// ParseChunked parses an entire chunked body from the start of input: every chunk up to and
+// including the last one, then the trailer section. The remainder is whatever follows the
+// message, like the next request on a persistent connection.
+func (o Options) ParseChunked(input string) (ChunkedBody, string, error) {
+	s := scan{input: input, rest: input}
+
+	var data strings.Builder
+	for {
+		chunk, err := s.chunk()
+		if err != nil {
+			return ChunkedBody{}, "", err
+		}
+
+		if chunk.Size == 0 {
+			break
+		}
+
+		data.WriteString(chunk.Data)
+	}
+
+	trailer, err := s.header(o)
+	if err != nil {
+		return ChunkedBody{}, "", err
+	}
+
+	return ChunkedBody{Data: data.String(), Trailer: trailer}, s.rest, nil
+}
+
+// chunk parses a single chunk.
+func (s *scan) chunk() (Chunk, error) {
+	text, start, err := s.line()
+	if err != nil {
+		return Chunk{}, err
+	}
+
+	digits, extensions, err := hexDigits(text)
+	if err != nil || digits == "" {
+		return Chunk{}, s.errorf(start, "expected chunk size")
+	}
+
+	size, err := strconv.ParseInt(digits, 16, 64)
+	if err != nil {
+		return Chunk{}, s.errorf(start, "chunk size %s is too large", digits)
+	}
+
+	if err = checkExtensions(extensions); err != nil {
+		return Chunk{}, s.errorf(start+len(digits), "invalid chunk extension: %v", err)
+	}
+
+	chunk := Chunk{Size: size, Extensions: extensions}
+	if size == 0 {
+		return chunk, nil
+	}
+
+	dataStart := s.offset()
+	if int64(len(s.rest)) < size {
+		return Chunk{}, ErrIncomplete
+	}
+
+	chunk.Data = s.rest[:size]
+	after := s.rest[size:]
+
+	// The data is arbitrary and could be large, so only look at the 2 bytes the CRLF should be
+	if _, _, err := lineEnd(after[:min(len(after), len("\r\n"))]); err == nil {
+		s.rest = after[len("\r\n"):]
+		return chunk, nil
+	}
+
+	// Waiting on the CRLF, or part of it
+	if after == "" || after == "\r" {
+		return Chunk{}, ErrIncomplete
+	}
+
+	return Chunk{}, s.errorf(dataStart+int(size), "expected CRLF after %d bytes of chunk data", size)
+}
+
+// checkExtensions checks the chunk extensions after a chunk size, the grammar for which is:
+//
+//	chunk-ext = *( BWS ";" BWS chunk-ext-name [ BWS "=" BWS chunk-ext-val ] )
+//
+// Where a chunk-ext-val is a token or a quoted string.
+func checkExtensions(text string) error {
+	rest := text
+	for rest != "" {
+		_, afterSemicolon, err := semicolon(trimLeft(rest))
+		if err != nil {
+			return errExpectedSemicolon
+		}
+
+		name, afterName, err := token(trimLeft(afterSemicolon))
+		if err != nil || name == "" {
+			return errExpectedName
+		}
+
+		rest = afterName
+
+		_, afterEquals, err := equals(trimLeft(afterName))
+		if err != nil {
+			continue
+		}
+
+		afterEquals = trimLeft(afterEquals)
+
+		if value, afterValue, err := token(afterEquals); err == nil && value != "" {
+			rest = afterValue
+			continue
+		}
+
+		afterValue, err := quotedString(afterEquals)
+		if err != nil {
+			return err
+		}
+
+		rest = afterValue
+	}
+
+	return nil
+}
+
+// quotedString parses a quoted string from the start of text, returning the remainder.
+func quotedString(text string) (string, error) {
+	_, rest, err := quote(text)
+	if err != nil {
+		return "", errExpectedValue
+	}
+
+	for {
+		_, afterChars, err := quotedChars(rest)
+		if err == nil {
+			rest = afterChars
+		}
+
+		if _, afterQuote, err := quote(rest); err == nil {
+			return afterQuote, nil
+		}
+
+		// A quoted pair is a backslash followed by any char, it's the only other
+		// thing that can be in a quoted string
+		_, afterBackslash, err := backslash(rest)
+		if err != nil {
+			return "", errUnterminatedQuote
+		}
+
+		if _, afterEscaped, err := parser.Take(1)(afterBackslash); err == nil {
+			rest = afterEscaped
+			continue
+		}
+
+		return "", errUnterminatedQuote
+	}
+}
+
+// trimLeft trims leading spaces and tabs, the "BWS" in the chunk extension grammar.
+func trimLeft(text string) string {
+	return strings.TrimLeft(text, " \t")
+}
+
+// isHex reports whether r is an ASCII hex digit.
+func isHex(r rune) bool {
+	return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// isQuotedChar reports whether r can appear unescaped in a quoted string, "qdtext" in RFC 9110.
+func isQuotedChar(r rune) bool {
+	return r == '\t' || r == ' ' || (r > ' ' && r < 0x7f && r != '"' && r != '\\') || r >= 0x80
+}
+
+var (
+	hexDigits   = parser.TakeWhile(isHex)
+	quotedChars = parser.TakeWhile(isQuotedChar)
+	semicolon   = parser.Char(';')
+	equals      = parser.Char('=')
+	quote       = parser.Char('"')
+	backslash   = parser.Char('\\')
+)
+
+// Errors for the chunk extension grammar, these are wrapped with the position in an [*Error].
+var (
+	errExpectedSemicolon = errors.New("expected ';'")
+	errExpectedName      = errors.New("expected extension name")
+	errExpectedValue     = errors.New("expected token or quoted string after '='")
+	errUnterminatedQuote = errors.New("unterminated quoted string")
+)
@@ -0,0 +1,170 @@
+package http1_test
+
+// The fuzz tests in here check that the parsers never panic, that every strict prefix of
+// something they accept is reported as incomplete (so a caller reading from a socket never
+// sees a spurious error), and that they agree with net/http about anything they both accept.
+//
+// net/http is more lenient in places (it accepts bare LF line endings and obsolete line
+// folding for example) and stricter in others (it validates the request target and some
+// well known fields), so neither is a superset of the other and only the results are compared.
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser/http1"
+)
+
+// checkPrefixes checks that every strict prefix of input[:consumed] is incomplete according to parse.
+func checkPrefixes[T any](t *testing.T, parse func(string) (T, string, error), input string, consumed int) {
+	t.Helper()
+
+	for i := range consumed {
+		if _, _, err := parse(input[:i]); !errors.Is(err, http1.ErrIncomplete) {
+			t.Fatalf("parsing prefix %q of %q returned %v, wanted ErrIncomplete", input[:i], input, err)
+		}
+	}
+}
+
+func FuzzRequest(f *testing.F) {
+	seeds := []string{
+		"GET / HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		"POST /submit?a=b HTTP/1.1\r\nHost: example.com\r\nContent-Length: 5\r\n\r\nhello",
+		"OPTIONS * HTTP/1.1\r\nHost: x\r\n\r\n",
+		"CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n\r\n",
+		"GET http://example.com/ HTTP/1.0\r\nAccept: */*\r\nAccept: text/html\r\n\r\n",
+		"GET / HTTP/1.1\r\nX-Folded: a\r\n b\r\n\r\n",
+		"GET / HTTP/1.1\nHost: bare.lf\n\n",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if !utf8.ValidString(input) {
+			return
+		}
+
+		request, rest, err := http1.ParseRequest(input)
+		if err != nil {
+			return
+		}
+
+		checkPrefixes(t, http1.ParseRequest, input, len(input)-len(rest))
+
+		std, err := http.ReadRequest(bufio.NewReader(strings.NewReader(input)))
+		if err != nil {
+			return
+		}
+
+		if std.Method != request.Method || std.RequestURI != request.Target || std.Proto != request.Version {
+			t.Fatalf(
+				"request line of %q = (%q, %q, %q), net/http got (%q, %q, %q)",
+				input, request.Method, request.Target, request.Version, std.Method, std.RequestURI, std.Proto,
+			)
+		}
+
+		for _, field := range request.Header {
+			// net/http moves the Host field out of the header, but prefers the host
+			// from the request target if there is one
+			if strings.EqualFold(field.Name, "Host") {
+				if std.URL.Host == "" && std.Host != field.Value {
+					t.Fatalf("Host of %q = %q, net/http got %q", input, field.Value, std.Host)
+				}
+				continue
+			}
+
+			values := std.Header.Values(field.Name)
+			found := false
+			for _, value := range values {
+				if value == field.Value {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				t.Fatalf("field %s: %q of %q not in net/http values %q", field.Name, field.Value, input, values)
+			}
+		}
+	})
+}
+
+func FuzzResponse(f *testing.F) {
+	seeds := []string{
+		"HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n",
+		"HTTP/1.1 404 Not Found\r\n\r\n",
+		"HTTP/1.0 204\r\n\r\n",
+		"HTTP/1.1 301 Moved\r\nLocation: /new\r\nVia: a,\r\n b\r\n\r\n",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if !utf8.ValidString(input) {
+			return
+		}
+
+		for _, options := range []http1.Options{{}, {ObsFold: true}} {
+			response, rest, err := options.ParseResponse(input)
+			if err != nil {
+				continue
+			}
+
+			checkPrefixes(t, options.ParseResponse, input, len(input)-len(rest))
+
+			std, err := http.ReadResponse(bufio.NewReader(strings.NewReader(input)), nil)
+			if err != nil {
+				continue
+			}
+
+			if std.StatusCode != response.Code || std.Proto != response.Version {
+				t.Fatalf(
+					"status line of %q = (%d, %q), net/http got (%d, %q)",
+					input, response.Code, response.Version, std.StatusCode, std.Proto,
+				)
+			}
+		}
+	})
+}
+
+func FuzzChunked(f *testing.F) {
+	seeds := []string{
+		"5\r\nhello\r\n0\r\n\r\n",
+		"4\r\nWiki\r\n7;ext\r\npedia i\r\nB\r\nn \r\nchunks.\r\n0\r\nChecksum: abc\r\n\r\n",
+		"3;a=\"q\\\"\"\r\nabc\r\n0\r\n\r\n",
+		"0000000000000000001\r\nx\r\n0\r\n\r\n",
+		"5\nhello\n0\n\n",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		// Only the chunk data is allowed to be invalid utf-8, the chunk-size lines and
+		// trailers have to be valid, but there's no way to filter on just those
+		body, rest, err := http1.ParseChunked(input)
+		if err != nil {
+			return
+		}
+
+		checkPrefixes(t, http1.ParseChunked, input, len(input)-len(rest))
+
+		// The chunked reader stops after the last chunk, leaving the trailer section
+		std, err := io.ReadAll(httputil.NewChunkedReader(strings.NewReader(input)))
+		if err != nil {
+			return
+		}
+
+		if string(std) != body.Data {
+			t.Fatalf("data of %q = %q, net/http/httputil got %q", input, body.Data, std)
+		}
+	})
+}
@@ -0,0 +1,440 @@
+// Package http1 implements prefix parsers for the heads of HTTP/1.1 messages and for chunked
+// transfer coding, following RFC 9112, built on the combinators in [parser].
+//
+// The parsers are designed to be fed straight from partial socket reads: if the input is a
+// valid start of whatever is being parsed but stops short, the error is [ErrIncomplete], so
+// the caller knows to read some more and try again, rather than that the message is malformed:
+//
+//	buf = append(buf, chunk...)
+//	request, body, err := http1.ParseRequest(string(buf))
+//	if errors.Is(err, http1.ErrIncomplete) {
+//		continue // Read some more
+//	}
+//
+// All other errors are an [*Error] giving the byte offset of the problem.
+//
+// Unlike [net/http], nothing is canonicalised or merged: header fields are returned in order
+// with their names as written, the request target is left as it is, and so on. Since the
+// combinators operate on utf-8, any field value bytes above 0x7F (obs-text in RFC 9112)
+// must form valid utf-8.
+package http1 // import "go.followtheprocess.codes/parser/http1"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// ErrIncomplete is returned when the input is a valid start of what is being parsed but
+// ends before it's complete.
+var ErrIncomplete = errors.New("http1: incomplete input")
+
+// Error is the error returned by all the parsers in this package for malformed input.
+type Error struct {
+	// Msg describes what went wrong.
+	Msg string
+
+	// Offset is the byte offset into the parser's input where the problem was found.
+	Offset int
+}
+
+// Error implements the error interface for *Error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("http1: offset %d: %s", e.Offset, e.Msg)
+}
+
+// Field is a single header (or trailer) field.
+type Field struct {
+	Name  string // The field name as written, not canonicalised
+	Value string // The field value with surrounding whitespace removed
+}
+
+// Header is a header or trailer section, in the order the fields appeared.
+type Header []Field
+
+// Get returns the value of the first field with the given name, compared case insensitively,
+// and whether there was one.
+func (h Header) Get(name string) (string, bool) {
+	for _, field := range h {
+		if strings.EqualFold(field.Name, name) {
+			return field.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Values returns the values of every field with the given name, compared case insensitively.
+func (h Header) Values(name string) []string {
+	var values []string
+	for _, field := range h {
+		if strings.EqualFold(field.Name, name) {
+			values = append(values, field.Value)
+		}
+	}
+
+	return values
+}
+
+// Request is the head of an HTTP request: the request line and header section.
+type Request struct {
+	Method  string // The method, like GET
+	Target  string // The request target, like /index.html?q=1, exactly as written
+	Version string // The protocol version, like HTTP/1.1
+	Header  Header // The header fields
+}
+
+// Response is the head of an HTTP response: the status line and header section.
+type Response struct {
+	Version string // The protocol version, like HTTP/1.1
+	Reason  string // The reason phrase, like Not Found, which may be empty
+	Header  Header // The header fields
+	Code    int    // The 3 digit status code, like 404
+}
+
+// Options configures the parsers that read header sections, the zero value is ready to use
+// and follows RFC 9112 strictly.
+type Options struct {
+	// ObsFold allows obsolete line folding, where a field value is continued onto the next
+	// line by starting it with a space or tab. Each fold is replaced by a single space, as
+	// RFC 9112 section 5.2 requires. When false, a folded line is an error.
+	ObsFold bool
+}
+
+// ParseRequestLine parses a request line like "GET /index.html HTTP/1.1\r\n" from the start
+// of input, returning a [Request] without a Header.
+func ParseRequestLine(input string) (Request, string, error) {
+	s := scan{input: input, rest: input}
+
+	request, err := s.requestLine()
+	if err != nil {
+		return Request{}, "", err
+	}
+
+	return request, s.rest, nil
+}
+
+// ParseStatusLine parses a status line like "HTTP/1.1 404 Not Found\r\n" from the start of
+// input, returning a [Response] without a Header.
+//
+// RFC 9112 requires a space after the status code even if the reason phrase is empty, but
+// as it's so commonly left out, it's optional here.
+func ParseStatusLine(input string) (Response, string, error) {
+	s := scan{input: input, rest: input}
+
+	response, err := s.statusLine()
+	if err != nil {
+		return Response{}, "", err
+	}
+
+	return response, s.rest, nil
+}
+
+// ParseRequest parses the head of a request with the default [Options].
+func ParseRequest(input string) (Request, string, error) {
+	return Options{}.ParseRequest(input)
+}
+
+// ParseRequest parses the head of a request, the request line and header section up to and
+// including the empty line that ends it, from the start of input. The remainder is the start
+// of the message body, if there is one.
+func (o Options) ParseRequest(input string) (Request, string, error) {
+	s := scan{input: input, rest: input}
+
+	request, err := s.requestLine()
+	if err != nil {
+		return Request{}, "", err
+	}
+
+	if request.Header, err = s.header(o); err != nil {
+		return Request{}, "", err
+	}
+
+	return request, s.rest, nil
+}
+
+// ParseResponse parses the head of a response with the default [Options].
+func ParseResponse(input string) (Response, string, error) {
+	return Options{}.ParseResponse(input)
+}
+
+// ParseResponse parses the head of a response, the status line and header section up to
+// and including the empty line that ends it, from the start of input. The remainder is the
+// start of the message body, if there is one.
+func (o Options) ParseResponse(input string) (Response, string, error) {
+	s := scan{input: input, rest: input}
+
+	response, err := s.statusLine()
+	if err != nil {
+		return Response{}, "", err
+	}
+
+	if response.Header, err = s.header(o); err != nil {
+		return Response{}, "", err
+	}
+
+	return response, s.rest, nil
+}
+
+// ParseHeader parses a header section with the default [Options].
+func ParseHeader(input string) (Header, string, error) {
+	return Options{}.ParseHeader(input)
+}
+
+// ParseHeader parses a header (or trailer) section, any number of field lines followed by
+// an empty line, from the start of input.
+func (o Options) ParseHeader(input string) (Header, string, error) {
+	s := scan{input: input, rest: input}
+
+	header, err := s.header(o)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return header, s.rest, nil
+}
+
+// scan tracks the progress of one of the parsers through its input, so that errors can
+// report where they happened.
+type scan struct {
+	input string // The entire input to the parser
+	rest  string // The input still left to parse
+}
+
+// offset returns the byte offset of the scan into the input.
+func (s *scan) offset() int {
+	return len(s.input) - len(s.rest)
+}
+
+// errorf returns an [*Error] at the given offset.
+func (s *scan) errorf(offset int, format string, args ...any) error {
+	return &Error{Msg: fmt.Sprintf(format, args...), Offset: offset}
+}
+
+// line consumes the next line, returning its text without the CRLF and the offset it started at.
+func (s *scan) line() (string, int, error) {
+	start := s.offset()
+
+	end := strings.IndexByte(s.rest, '\n')
+	if end == -1 {
+		return "", 0, ErrIncomplete
+	}
+
+	// Only looking as far as the next LF keeps each line proportional to its own length,
+	// rather than the length of everything after it, like a large body
+	text, crlf, err := lineText(s.rest[:end+1])
+	if err != nil {
+		if !utf8.ValidString(s.rest[:end+1]) {
+			return "", 0, s.errorf(start, "line is not valid utf-8")
+		}
+
+		return "", 0, s.errorf(start+end, "line ends in a bare LF rather than CRLF")
+	}
+
+	if _, _, err = lineEnd(crlf); err != nil {
+		return "", 0, s.errorf(start+end, "line ends in a bare LF rather than CRLF")
+	}
+
+	if i := strings.IndexFunc(text, isBadControl); i != -1 {
+		return "", 0, s.errorf(start+i, "unexpected control character %q", text[i])
+	}
+
+	s.rest = s.rest[end+1:]
+
+	return text, start, nil
+}
+
+// requestLine parses a request line.
+func (s *scan) requestLine() (Request, error) {
+	text, start, err := s.line()
+	if err != nil {
+		return Request{}, err
+	}
+
+	method, rest, err := token(text)
+	if err != nil || method == "" {
+		return Request{}, s.errorf(start, "expected method")
+	}
+
+	// A failed parser doesn't return the remainder, so each step needs its own
+	_, afterMethod, err := space(rest)
+	if err != nil {
+		return Request{}, s.errorf(start+len(text)-len(rest), "expected ' ' after method")
+	}
+
+	target, afterTarget, err := visible(afterMethod)
+	if err != nil || target == "" {
+		return Request{}, s.errorf(start+len(text)-len(afterMethod), "expected request target")
+	}
+
+	_, beforeVersion, err := space(afterTarget)
+	if err != nil {
+		return Request{}, s.errorf(start+len(text)-len(afterTarget), "expected ' ' after request target")
+	}
+
+	version, rest, err := httpVersion(beforeVersion)
+	if err != nil {
+		return Request{}, s.errorf(start+len(text)-len(beforeVersion), "expected HTTP version like HTTP/1.1")
+	}
+
+	if rest != "" {
+		return Request{}, s.errorf(start+len(text)-len(rest), "unexpected %q after HTTP version", rest[0])
+	}
+
+	return Request{Method: method, Target: target, Version: version}, nil
+}
+
+// statusLine parses a status line.
+func (s *scan) statusLine() (Response, error) {
+	text, start, err := s.line()
+	if err != nil {
+		return Response{}, err
+	}
+
+	version, rest, err := httpVersion(text)
+	if err != nil {
+		return Response{}, s.errorf(start, "expected HTTP version like HTTP/1.1")
+	}
+
+	_, beforeCode, err := space(rest)
+	if err != nil {
+		return Response{}, s.errorf(start+len(text)-len(rest), "expected ' ' after HTTP version")
+	}
+
+	digits, afterCode, err := statusCode(beforeCode)
+	if err != nil {
+		return Response{}, s.errorf(start+len(text)-len(beforeCode), "expected 3 digit status code")
+	}
+
+	// Can't fail, it's 3 ASCII digits
+	code, _ := strconv.Atoi(digits)
+
+	var reason string
+	if afterCode != "" {
+		_, afterSpace, err := space(afterCode)
+		if err != nil {
+			return Response{}, s.errorf(start+len(text)-len(afterCode), "expected ' ' after status code")
+		}
+
+		reason = afterSpace
+	}
+
+	return Response{Version: version, Code: code, Reason: reason}, nil
+}
+
+// header parses a header section up to and including the empty line that ends it.
+func (s *scan) header(options Options) (Header, error) {
+	var header Header
+
+	for {
+		text, start, err := s.line()
+		if err != nil {
+			return nil, err
+		}
+
+		if text == "" {
+			return header, nil
+		}
+
+		if isWhitespace(rune(text[0])) {
+			if header == nil || !options.ObsFold {
+				return nil, s.errorf(start, "unexpected whitespace at the start of a line (obsolete line folding)")
+			}
+
+			// Continue the previous field's value, separated by a single space
+			last := &header[len(header)-1]
+			last.Value = trimWhitespace(last.Value + " " + trimWhitespace(text))
+
+			continue
+		}
+
+		field, err := s.field(text, start)
+		if err != nil {
+			return nil, err
+		}
+
+		header = append(header, field)
+	}
+}
+
+// field parses the text of a single field line, which started at start.
+func (s *scan) field(text string, start int) (Field, error) {
+	name, rest, err := token(text)
+	if err != nil || name == "" {
+		return Field{}, s.errorf(start, "expected field name")
+	}
+
+	if _, afterColon, err := colon(rest); err == nil {
+		return Field{Name: name, Value: trimWhitespace(afterColon)}, nil
+	}
+
+	switch {
+	case rest == "":
+		return Field{}, s.errorf(start+len(text), "expected ':' after field name")
+	case isWhitespace(rune(rest[0])):
+		return Field{}, s.errorf(start+len(text)-len(rest), "whitespace between field name and ':'")
+	default:
+		return Field{}, s.errorf(start+len(text)-len(rest), "unexpected %q in field name", rest[0])
+	}
+}
+
+// trimWhitespace trims the optional whitespace (spaces and tabs) from both ends of text.
+func trimWhitespace(text string) string {
+	return strings.Trim(text, " \t")
+}
+
+// isTokenChar reports whether r can be part of a token, like a method or field name.
+func isTokenChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
+
+// isVisible reports whether r is a visible ASCII char, "VCHAR" in RFC 5234.
+func isVisible(r rune) bool {
+	return r > ' ' && r < 0x7f
+}
+
+// isDigit reports whether r is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isWhitespace reports whether r is a space or a tab, the only whitespace HTTP allows in a line.
+func isWhitespace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// isBadControl reports whether r is a control char that can't appear in a line, which
+// is all of them except tab.
+func isBadControl(r rune) bool {
+	return (r < ' ' && r != '\t') || r == 0x7f
+}
+
+var (
+	// lineText parses the text of a line up to its CRLF, and lineEnd the CRLF itself.
+	lineText = parser.TakeTo("\r\n")
+	lineEnd  = parser.Exact("\r\n")
+
+	token   = parser.TakeWhile(isTokenChar)
+	visible = parser.TakeWhile(isVisible)
+	space   = parser.Char(' ')
+	colon   = parser.Char(':')
+
+	// statusCode parses exactly 3 digits, any more are left over and so an error.
+	statusCode = parser.TakeWhileBetween(3, 3, isDigit)
+
+	// httpVersion parses a version like HTTP/1.1, returning the whole thing.
+	httpVersion = parser.Map(
+		parser.Chain(
+			parser.Exact("HTTP/"),
+			parser.TakeWhileBetween(1, 1, isDigit),
+			parser.Exact("."),
+			parser.TakeWhileBetween(1, 1, isDigit),
+		),
+		func(parts []string) (string, error) { return strings.Join(parts, ""), nil },
+	)
+)
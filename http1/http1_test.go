@@ -0,0 +1,444 @@
+package http1_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go.followtheprocess.codes/parser/http1"
+)
+
+func TestParseRequestLine(t *testing.T) {
+	tests := []struct {
+		name    string        // Identifying test case name
+		input   string        // Input to parse
+		want    http1.Request // The expected request
+		rest    string        // The expected remainder
+		err     string        // The expected error message, if there was one
+		wantErr bool          // Whether or not we wanted an error
+	}{
+		{
+			name:  "simple",
+			input: "GET /index.html HTTP/1.1\r\nHost: example.com\r\n",
+			want:  http1.Request{Method: "GET", Target: "/index.html", Version: "HTTP/1.1"},
+			rest:  "Host: example.com\r\n",
+		},
+		{
+			name:  "absolute form",
+			input: "GET http://example.com/a?b=c HTTP/1.0\r\n",
+			want:  http1.Request{Method: "GET", Target: "http://example.com/a?b=c", Version: "HTTP/1.0"},
+		},
+		{
+			name:  "authority form",
+			input: "CONNECT example.com:443 HTTP/1.1\r\n",
+			want:  http1.Request{Method: "CONNECT", Target: "example.com:443", Version: "HTTP/1.1"},
+		},
+		{
+			name:  "asterisk form",
+			input: "OPTIONS * HTTP/1.1\r\n",
+			want:  http1.Request{Method: "OPTIONS", Target: "*", Version: "HTTP/1.1"},
+		},
+		{
+			name:  "extension method",
+			input: "PROPFIND /dav HTTP/1.1\r\n",
+			want:  http1.Request{Method: "PROPFIND", Target: "/dav", Version: "HTTP/1.1"},
+		},
+		{name: "empty", input: "", wantErr: true, err: "http1: incomplete input"},
+		{name: "no line end", input: "GET / HTTP/1.1", wantErr: true, err: "http1: incomplete input"},
+		{name: "half line end", input: "GET / HTTP/1.1\r", wantErr: true, err: "http1: incomplete input"},
+		{name: "bare LF", input: "GET / HTTP/1.1\n", wantErr: true, err: "http1: offset 14: line ends in a bare LF rather than CRLF"},
+		{name: "no method", input: " / HTTP/1.1\r\n", wantErr: true, err: "http1: offset 0: expected method"},
+		{name: "two spaces", input: "GET  / HTTP/1.1\r\n", wantErr: true, err: "http1: offset 4: expected request target"},
+		{name: "tab separated", input: "GET\t/ HTTP/1.1\r\n", wantErr: true, err: "http1: offset 3: expected ' ' after method"},
+		{name: "no version", input: "GET /\r\n", wantErr: true, err: "http1: offset 5: expected ' ' after request target"},
+		{name: "lower case version", input: "GET / http/1.1\r\n", wantErr: true, err: "http1: offset 6: expected HTTP version like HTTP/1.1"},
+		{name: "two digit version", input: "GET / HTTP/1.10\r\n", wantErr: true, err: `http1: offset 14: unexpected '0' after HTTP version`},
+		{name: "trailing space", input: "GET / HTTP/1.1 \r\n", wantErr: true, err: `http1: offset 14: unexpected ' ' after HTTP version`},
+		{name: "nul in target", input: "GET /\x00 HTTP/1.1\r\n", wantErr: true, err: `http1: offset 5: unexpected control character '\x00'`},
+		{name: "invalid utf-8", input: "GET /\xff HTTP/1.1\r\n", wantErr: true, err: "http1: offset 0: line is not valid utf-8"},
+		{name: "non ascii target", input: "GET /café HTTP/1.1\r\n", wantErr: true, err: "http1: offset 8: expected ' ' after request target"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := http1.ParseRequestLine(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseStatusLine(t *testing.T) {
+	tests := []struct {
+		name    string         // Identifying test case name
+		input   string         // Input to parse
+		want    http1.Response // The expected response
+		rest    string         // The expected remainder
+		err     string         // The expected error message, if there was one
+		wantErr bool           // Whether or not we wanted an error
+	}{
+		{
+			name:  "simple",
+			input: "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n",
+			want:  http1.Response{Version: "HTTP/1.1", Code: 200, Reason: "OK"},
+			rest:  "Content-Length: 0\r\n",
+		},
+		{
+			name:  "reason with spaces",
+			input: "HTTP/1.1 404 Not Found\r\n",
+			want:  http1.Response{Version: "HTTP/1.1", Code: 404, Reason: "Not Found"},
+		},
+		{
+			name:  "empty reason",
+			input: "HTTP/1.1 204 \r\n",
+			want:  http1.Response{Version: "HTTP/1.1", Code: 204},
+		},
+		{
+			name:  "no space before empty reason",
+			input: "HTTP/1.1 204\r\n",
+			want:  http1.Response{Version: "HTTP/1.1", Code: 204},
+		},
+		{
+			name:  "reason with tab and obs-text",
+			input: "HTTP/1.0 500 Erreur\tinterne du serveur ☹\r\n",
+			want:  http1.Response{Version: "HTTP/1.0", Code: 500, Reason: "Erreur\tinterne du serveur ☹"},
+		},
+		{name: "empty", input: "", wantErr: true, err: "http1: incomplete input"},
+		{name: "partial", input: "HTTP/1.1 200", wantErr: true, err: "http1: incomplete input"},
+		{name: "no version", input: "200 OK\r\n", wantErr: true, err: "http1: offset 0: expected HTTP version like HTTP/1.1"},
+		{name: "no space", input: "HTTP/1.1200 OK\r\n", wantErr: true, err: "http1: offset 8: expected ' ' after HTTP version"},
+		{name: "short code", input: "HTTP/1.1 20 OK\r\n", wantErr: true, err: "http1: offset 9: expected 3 digit status code"},
+		{name: "long code", input: "HTTP/1.1 2000 OK\r\n", wantErr: true, err: "http1: offset 12: expected ' ' after status code"},
+		{name: "control char in reason", input: "HTTP/1.1 200 O\x01K\r\n", wantErr: true, err: `http1: offset 14: unexpected control character '\x01'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := http1.ParseStatusLine(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name    string        // Identifying test case name
+		input   string        // Input to parse
+		want    http1.Header  // The expected header section
+		rest    string        // The expected remainder
+		err     string        // The expected error message, if there was one
+		options http1.Options // Options to parse with
+		wantErr bool          // Whether or not we wanted an error
+	}{
+		{
+			name:  "empty section",
+			input: "\r\nbody",
+			want:  nil,
+			rest:  "body",
+		},
+		{
+			name:  "fields",
+			input: "Host: example.com\r\nAccept:*/*\r\nX-Empty:\r\n\r\nbody",
+			want:  http1.Header{{Name: "Host", Value: "example.com"}, {Name: "Accept", Value: "*/*"}, {Name: "X-Empty", Value: ""}},
+			rest:  "body",
+		},
+		{
+			name:  "surrounding whitespace",
+			input: "Name: \t value with  inner   spaces \t\r\n\r\n",
+			want:  http1.Header{{Name: "Name", Value: "value with  inner   spaces"}},
+		},
+		{
+			name:  "repeated and case preserved",
+			input: "set-cookie: a=1\r\nSet-Cookie: b=2\r\n\r\n",
+			want:  http1.Header{{Name: "set-cookie", Value: "a=1"}, {Name: "Set-Cookie", Value: "b=2"}},
+		},
+		{
+			name:    "obs-fold",
+			input:   "Subject: a long\r\n   folded\r\n\tvalue\r\nNext: x\r\n\r\n",
+			options: http1.Options{ObsFold: true},
+			want:    http1.Header{{Name: "Subject", Value: "a long folded value"}, {Name: "Next", Value: "x"}},
+		},
+		{
+			name:    "obs-fold empty value",
+			input:   "Subject:\r\n folded\r\n\r\n",
+			options: http1.Options{ObsFold: true},
+			want:    http1.Header{{Name: "Subject", Value: "folded"}},
+		},
+		{
+			name:    "obs-fold first line",
+			input:   " folded\r\n\r\n",
+			options: http1.Options{ObsFold: true},
+			wantErr: true,
+			err:     "http1: offset 0: unexpected whitespace at the start of a line (obsolete line folding)",
+		},
+		{
+			name:    "obs-fold not allowed",
+			input:   "Subject: a long\r\n folded\r\n\r\n",
+			wantErr: true,
+			err:     "http1: offset 17: unexpected whitespace at the start of a line (obsolete line folding)",
+		},
+		{name: "empty", input: "", wantErr: true, err: "http1: incomplete input"},
+		{name: "no empty line", input: "Host: example.com\r\n", wantErr: true, err: "http1: incomplete input"},
+		{name: "partial empty line", input: "Host: example.com\r\n\r", wantErr: true, err: "http1: incomplete input"},
+		{name: "no colon", input: "Host example.com\r\n\r\n", wantErr: true, err: "http1: offset 4: whitespace between field name and ':'"},
+		{name: "just a name", input: "Host\r\n\r\n", wantErr: true, err: "http1: offset 4: expected ':' after field name"},
+		{name: "space before colon", input: "Host : example.com\r\n\r\n", wantErr: true, err: "http1: offset 4: whitespace between field name and ':'"},
+		{name: "bad name char", input: "Ho(st: example.com\r\n\r\n", wantErr: true, err: `http1: offset 2: unexpected '(' in field name`},
+		{name: "no name", input: ": value\r\n\r\n", wantErr: true, err: "http1: offset 0: expected field name"},
+		{name: "bare CR in value", input: "A: b\rc\r\n\r\n", wantErr: true, err: `http1: offset 4: unexpected control character '\r'`},
+		{name: "bare LF", input: "A: b\n\r\n", wantErr: true, err: "http1: offset 4: line ends in a bare LF rather than CRLF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := tt.options.ParseHeader(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	input := "POST /submit HTTP/1.1\r\nHost: example.com\r\nContent-Length: 5\r\n\r\nhelloGET / HTTP/1.1\r\n"
+
+	got, rest, err := http1.ParseRequest(input)
+	want := http1.Request{
+		Method:  "POST",
+		Target:  "/submit",
+		Version: "HTTP/1.1",
+		Header:  http1.Header{{Name: "Host", Value: "example.com"}, {Name: "Content-Length", Value: "5"}},
+	}
+	check(t, got, rest, err, want, "helloGET / HTTP/1.1\r\n", "", false)
+
+	// Every strict prefix of the head is incomplete
+	head := len(input) - len(rest)
+	for i := range head {
+		if _, _, err := http1.ParseRequest(input[:i]); !errors.Is(err, http1.ErrIncomplete) {
+			t.Fatalf("ParseRequest(%q) returned %v, wanted ErrIncomplete", input[:i], err)
+		}
+	}
+
+	// Errors in the header section are relative to the start of the request
+	_, _, err = http1.ParseRequest("GET / HTTP/1.1\r\nHost : x\r\n\r\n")
+	check(t, http1.Request{}, "", err, http1.Request{}, "", "http1: offset 20: whitespace between field name and ':'", true)
+}
+
+func TestParseResponse(t *testing.T) {
+	input := "HTTP/1.1 301 Moved Permanently\r\nLocation: /new\r\nVia: 1.1 a,\r\n 1.1 b\r\n\r\n"
+
+	_, _, err := http1.ParseResponse(input)
+	check(t, http1.Response{}, "", err, http1.Response{}, "", "http1: offset 61: unexpected whitespace at the start of a line (obsolete line folding)", true)
+
+	got, rest, err := http1.Options{ObsFold: true}.ParseResponse(input)
+	want := http1.Response{
+		Version: "HTTP/1.1",
+		Code:    301,
+		Reason:  "Moved Permanently",
+		Header:  http1.Header{{Name: "Location", Value: "/new"}, {Name: "Via", Value: "1.1 a, 1.1 b"}},
+	}
+	check(t, got, rest, err, want, "", "", false)
+}
+
+func TestHeaderGet(t *testing.T) {
+	header := http1.Header{{Name: "Accept", Value: "text/html"}, {Name: "X-Empty"}, {Name: "accept", Value: "*/*"}}
+
+	if value, ok := header.Get("ACCEPT"); !ok || value != "text/html" {
+		t.Errorf("Get(ACCEPT) = (%q, %v), wanted (\"text/html\", true)", value, ok)
+	}
+
+	if value, ok := header.Get("x-empty"); !ok || value != "" {
+		t.Errorf("Get(x-empty) = (%q, %v), wanted (\"\", true)", value, ok)
+	}
+
+	if value, ok := header.Get("Missing"); ok {
+		t.Errorf("Get(Missing) = (%q, %v), wanted (\"\", false)", value, ok)
+	}
+
+	if values := header.Values("Accept"); !reflect.DeepEqual(values, []string{"text/html", "*/*"}) {
+		t.Errorf("Values(Accept) = %q, wanted [text/html */*]", values)
+	}
+}
+
+func TestParseChunk(t *testing.T) {
+	tests := []struct {
+		name    string      // Identifying test case name
+		input   string      // Input to parse
+		want    http1.Chunk // The expected chunk
+		rest    string      // The expected remainder
+		err     string      // The expected error message, if there was one
+		wantErr bool        // Whether or not we wanted an error
+	}{
+		{
+			name:  "simple",
+			input: "5\r\nhello\r\n0\r\n\r\n",
+			want:  http1.Chunk{Size: 5, Data: "hello"},
+			rest:  "0\r\n\r\n",
+		},
+		{
+			name:  "hex size",
+			input: "1A\r\nabcdefghijklmnopqrstuvwxyz\r\n",
+			want:  http1.Chunk{Size: 26, Data: "abcdefghijklmnopqrstuvwxyz"},
+		},
+		{
+			name:  "leading zeros",
+			input: "00000000000000000003\r\nabc\r\n",
+			want:  http1.Chunk{Size: 3, Data: "abc"},
+		},
+		{
+			name:  "binary data",
+			input: "4\r\n\xff\r\n\x00\r\n",
+			want:  http1.Chunk{Size: 4, Data: "\xff\r\n\x00"},
+		},
+		{
+			name:  "last chunk",
+			input: "0\r\nExpires: never\r\n\r\n",
+			want:  http1.Chunk{Size: 0},
+			rest:  "Expires: never\r\n\r\n",
+		},
+		{
+			name:  "extensions",
+			input: "3;name=value ; flag;q=\"a \\\"quoted\\\" value\"\r\nabc\r\n",
+			want:  http1.Chunk{Size: 3, Data: "abc", Extensions: ";name=value ; flag;q=\"a \\\"quoted\\\" value\""},
+		},
+		{
+			name:  "extension with whitespace",
+			input: "3 ; name = value\r\nabc\r\n",
+			want:  http1.Chunk{Size: 3, Data: "abc", Extensions: " ; name = value"},
+		},
+		{name: "empty", input: "", wantErr: true, err: "http1: incomplete input"},
+		{name: "partial size line", input: "5\r", wantErr: true, err: "http1: incomplete input"},
+		{name: "partial data", input: "5\r\nhel", wantErr: true, err: "http1: incomplete input"},
+		{name: "no CRLF after data", input: "5\r\nhello", wantErr: true, err: "http1: incomplete input"},
+		{name: "partial CRLF after data", input: "5\r\nhello\r", wantErr: true, err: "http1: incomplete input"},
+		{name: "data too long", input: "5\r\nhello world\r\n", wantErr: true, err: "http1: offset 8: expected CRLF after 5 bytes of chunk data"},
+		{name: "no size", input: "\r\nhello\r\n", wantErr: true, err: "http1: offset 0: expected chunk size"},
+		{name: "negative size", input: "-5\r\nhello\r\n", wantErr: true, err: "http1: offset 0: expected chunk size"},
+		{name: "size too large", input: "8000000000000000\r\n", wantErr: true, err: "http1: offset 0: chunk size 8000000000000000 is too large"},
+		{name: "junk after size", input: "5x\r\nhello\r\n", wantErr: true, err: "http1: offset 1: invalid chunk extension: expected ';'"},
+		{name: "extension without name", input: "5;=x\r\nhello\r\n", wantErr: true, err: "http1: offset 1: invalid chunk extension: expected extension name"},
+		{name: "extension without value", input: "5;a=\r\nhello\r\n", wantErr: true, err: "http1: offset 1: invalid chunk extension: expected token or quoted string after '='"},
+		{name: "unterminated quote", input: "5;a=\"x\r\nhello\r\n", wantErr: true, err: "http1: offset 1: invalid chunk extension: unterminated quoted string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := http1.ParseChunk(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseChunked(t *testing.T) {
+	input := "4\r\nWiki\r\n7;ext\r\npedia i\r\nB\r\nn \r\nchunks.\r\n0\r\nChecksum: abc\r\n\r\nHTTP/1.1 200 OK\r\n"
+
+	got, rest, err := http1.ParseChunked(input)
+	want := http1.ChunkedBody{
+		Data:    "Wikipedia in \r\nchunks.",
+		Trailer: http1.Header{{Name: "Checksum", Value: "abc"}},
+	}
+	check(t, got, rest, err, want, "HTTP/1.1 200 OK\r\n", "", false)
+
+	body := len(input) - len(rest)
+	for i := range body {
+		if _, _, err := http1.ParseChunked(input[:i]); !errors.Is(err, http1.ErrIncomplete) {
+			t.Fatalf("ParseChunked(%q) returned %v, wanted ErrIncomplete", input[:i], err)
+		}
+	}
+
+	// Errors in later chunks are relative to the start of the body
+	_, _, err = http1.ParseChunked("4\r\nWiki\r\nzz\r\n")
+	check(t, http1.ChunkedBody{}, "", err, http1.ChunkedBody{}, "", "http1: offset 9: expected chunk size", true)
+}
+
+func TestErrorType(t *testing.T) {
+	_, _, err := http1.ParseRequest("GET / HTTP/1.1\r\nHost: example.com\n")
+
+	var httpErr *http1.Error
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Error was not a *http1.Error, got %T", err)
+	}
+
+	if httpErr.Offset != 33 {
+		t.Errorf("Offset = %d, wanted 33", httpErr.Offset)
+	}
+
+	if errors.Is(err, http1.ErrIncomplete) {
+		t.Error("Malformed input should not be ErrIncomplete")
+	}
+}
+
+func ExampleParseRequest() {
+	// As if from a socket read that stopped in the middle of the body
+	input := "POST /upload HTTP/1.1\r\nHost: example.com\r\nContent-Length: 11\r\n\r\nhello"
+
+	request, body, err := http1.ParseRequest(input)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	length, _ := request.Header.Get("content-length")
+	fmt.Println(request.Method, request.Target, request.Version)
+	fmt.Printf("Content-Length: %s, have %q so far\n", length, body)
+
+	// Output: POST /upload HTTP/1.1
+	// Content-Length: 11, have "hello" so far
+}
+
+func ExampleParseChunk() {
+	body := "5\r\nhello\r\n6\r\n world\r\n0\r\n\r\n"
+
+	for {
+		chunk, rest, err := http1.ParseChunk(body)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		body = rest
+
+		if chunk.Size == 0 {
+			break
+		}
+
+		fmt.Printf("%d bytes: %q\n", chunk.Size, chunk.Data)
+	}
+
+	// The last chunk is followed by the trailer section, empty here
+	trailer, _, err := http1.ParseHeader(body)
+	fmt.Println(len(trailer), err)
+
+	// Output: 5 bytes: "hello"
+	// 6 bytes: " world"
+	// 0 <nil>
+}
+
+func ExampleError() {
+	_, _, err := http1.ParseRequest("GET / HTTP/1.1\r\nHost : example.com\r\n\r\n")
+	fmt.Println(err)
+
+	// Output: http1: offset 20: whitespace between field name and ':'
+}
+
+// check compares the result of one of the parsers with what we wanted.
+func check[T any](t *testing.T, got T, rest string, err error, want T, wantRest, wantErr string, errExpected bool) {
+	t.Helper()
+
+	if (err != nil) != errExpected {
+		t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, errExpected)
+	}
+
+	if err != nil {
+		if msg := err.Error(); msg != wantErr {
+			t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, wantErr)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nValue:\t%+v\nWanted:\t%+v\n", got, want)
+	}
+
+	if rest != wantRest {
+		t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", rest, wantRest)
+	}
+}
@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Indentation tracks the columns of the enclosing blocks while parsing an indentation sensitive
+// format, like YAML or a Python-like DSL, with [Indented], [SameColumn] and [Block].
+//
+// A [Parser] only ever sees the input that's left to parse, so an Indentation is created from the
+// complete input, which lets it work out the column that any remainder of it starts at. It holds
+// the state of a single parse, so a new one is needed for each input, and the parsers built from
+// it must not be used concurrently.
+type Indentation struct {
+	src    string // The complete input
+	levels []int  // Columns of the enclosing blocks, innermost last
+	offset int    // Byte offset of the last column lookup, so the next one can start from it
+	column int    // Column at offset
+}
+
+// NewIndentation returns an [Indentation] for parsing src, with no enclosing blocks.
+func NewIndentation(src string) *Indentation {
+	return &Indentation{src: src}
+}
+
+// Level returns the column of the innermost enclosing block, or 0 if there isn't one.
+func (i *Indentation) Level() int {
+	if len(i.levels) == 0 {
+		return 0
+	}
+
+	return i.levels[len(i.levels)-1]
+}
+
+// Column returns the 0 indexed column (in utf-8 chars) at which rest starts.
+//
+// rest must be a suffix of the input the [Indentation] was created with, which any remainder
+// returned by a parser applied to that input will be. Only the length of rest is checked, so an
+// error is returned if it's longer than the input but any other string gives a meaningless column.
+//
+// Columns are cheapest to look up in the order they appear in the input, each lookup only has
+// to scan the input between it and the previous one.
+func (i *Indentation) Column(rest string) (int, error) {
+	if len(rest) > len(i.src) {
+		return 0, errors.New("input is not part of the source the Indentation was created with")
+	}
+
+	offset := len(i.src) - len(rest)
+
+	var column int
+	if offset < i.offset {
+		// Gone backwards, count from the start of the line instead
+		lineStart := strings.LastIndexByte(i.src[:offset], '\n') + 1
+		column = utf8.RuneCountInString(i.src[lineStart:offset])
+	} else {
+		between := i.src[i.offset:offset]
+		if newline := strings.LastIndexByte(between, '\n'); newline >= 0 {
+			column = utf8.RuneCountInString(between[newline+1:])
+		} else {
+			column = i.column + utf8.RuneCountInString(between)
+		}
+	}
+
+	i.offset, i.column = offset, column
+
+	return column, nil
+}
+
+// push makes column the level of the innermost block.
+func (i *Indentation) push(column int) {
+	i.levels = append(i.levels, column)
+}
+
+// pop returns to the enclosing block.
+func (i *Indentation) pop() {
+	i.levels = i.levels[:len(i.levels)-1]
+}
+
+// Indented returns a [Parser] that skips any spaces at the start of the input, checks the next char
+// is in a column beyond the innermost enclosing block (see [Indentation.Level]), then applies another
+// parser with that column as the innermost block's level.
+//
+// It's the way to parse something nested inside a block, like the body of a YAML mapping entry
+// that starts on the line after its key. Only spaces count towards the indentation, tabs don't.
+//
+// If indent or parser is nil, an error will be returned.
+func Indented[T any](indent *Indentation, parser Parser[T]) Parser[T] {
+	return func(input string) (T, string, error) {
+		var zero T
+
+		if indent == nil {
+			return zero, "", errors.New("Indented: indent must not be nil")
+		}
+
+		if parser == nil {
+			return zero, "", errors.New("Indented: parser must not be nil")
+		}
+
+		rest := strings.TrimLeft(input, " ")
+
+		column, err := indent.Column(rest)
+		if err != nil {
+			return zero, "", fmt.Errorf("Indented: %w", err)
+		}
+
+		if level := indent.Level(); column <= level {
+			return zero, "", fmt.Errorf("Indented: column %d is not indented beyond column %d", column, level)
+		}
+
+		indent.push(column)
+		defer indent.pop()
+
+		value, remainder, err := parser(rest)
+		if err != nil {
+			return zero, "", &nestedError{prefix: "Indented: parser returned error: ", err: err}
+		}
+
+		return value, remainder, nil
+	}
+}
+
+// SameColumn returns a [Parser] that skips any spaces at the start of the input, then succeeds only
+// if the next char is in the same column as the innermost enclosing block (see [Indentation.Level]).
+//
+// The value is the spaces skipped, and the remainder starts with the char in that column. It's
+// mostly useful for writing block-like combinators of your own, [Block] already checks each of
+// its items is lined up.
+//
+// If indent is nil, an error will be returned.
+func SameColumn(indent *Indentation) Parser[string] {
+	return func(input string) (string, string, error) {
+		if indent == nil {
+			return "", "", errors.New("SameColumn: indent must not be nil")
+		}
+
+		rest := strings.TrimLeft(input, " ")
+
+		column, err := indent.Column(rest)
+		if err != nil {
+			return "", "", fmt.Errorf("SameColumn: %w", err)
+		}
+
+		if level := indent.Level(); column != level {
+			return "", "", fmt.Errorf("SameColumn: expected column %d, got column %d", level, column)
+		}
+
+		return input[:len(input)-len(rest)], rest, nil
+	}
+}
+
+// Block returns a [Parser] that parses one or more items lined up in the same column, like the
+// entries of a YAML mapping or the statements in the body of a Python function.
+//
+// The column of the first item, after skipping any spaces, sets the level of the block. It must
+// not be before the innermost enclosing block, use [Indented] if it needs to be nested further in.
+// While each item is parsed, the block is the innermost one, so items can contain nested blocks.
+//
+// Each item parser is applied at the first char of the item, after its indentation, and should
+// consume everything up to the start of the next line, including any blank lines or comments, as
+// what counts as those depends on the format. The block ends at the first line that starts in a
+// different column, when an item fails to parse, or when an item consumes no input. The remainder
+// is then everything after the last item, so the caller can decide whether what's left is the end
+// of an enclosing block or an error.
+//
+// If the first item fails to parse, or indent or item is nil, an error will be returned.
+func Block[T any](indent *Indentation, item Parser[T]) Parser[[]T] {
+	return func(input string) ([]T, string, error) {
+		if indent == nil {
+			return nil, "", errors.New("Block: indent must not be nil")
+		}
+
+		if item == nil {
+			return nil, "", errors.New("Block: item must not be nil")
+		}
+
+		rest := strings.TrimLeft(input, " ")
+
+		column, err := indent.Column(rest)
+		if err != nil {
+			return nil, "", fmt.Errorf("Block: %w", err)
+		}
+
+		if level := indent.Level(); column < level {
+			return nil, "", fmt.Errorf("Block: column %d is before the enclosing block at column %d", column, level)
+		}
+
+		indent.push(column)
+		defer indent.pop()
+
+		value, remainder, err := item(rest)
+		if err != nil {
+			return nil, "", &nestedError{prefix: "Block: item returned error: ", err: err}
+		}
+
+		values := []T{value}
+
+		for len(remainder) != len(rest) {
+			next := strings.TrimLeft(remainder, " ")
+			if next == "" {
+				break
+			}
+
+			if column, err := indent.Column(next); err != nil || column != indent.Level() {
+				break
+			}
+
+			value, after, err := item(next)
+			if err != nil || len(after) == len(next) {
+				// Either the item has failed or it made no progress, either way the block is over
+				break
+			}
+
+			values = append(values, value)
+			rest, remainder = next, after
+		}
+
+		return values, remainder, nil
+	}
+}
+
+// nestedError is the error returned by [Indented] and [Block] when the parser they apply fails.
+//
+// Blocks are usually nested inside one another, and building the message at each level like
+// [fmt.Errorf] does would take time quadratic in the depth, so it's only built when asked for.
+type nestedError struct {
+	err    error  // The error from the parser
+	prefix string // What to put before its message
+}
+
+// Error implements the error interface for nestedError.
+func (e *nestedError) Error() string {
+	return e.prefix + e.err.Error()
+}
+
+// Unwrap returns the error from the parser.
+func (e *nestedError) Unwrap() error {
+	return e.err
+}
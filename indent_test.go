@@ -0,0 +1,461 @@
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// line parses a word and the newline after it, if there is one.
+var line parser.Parser[string] = func(input string) (string, string, error) {
+	end := strings.IndexFunc(input, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end == -1 {
+		end = len(input)
+	}
+
+	if end == 0 {
+		return "", "", errors.New("expected a word")
+	}
+
+	return input[:end], strings.TrimPrefix(input[end:], "\n"), nil
+}
+
+// outline is a tree of words, where a word's children are the block of words
+// indented under it.
+type outline struct {
+	word     string
+	children []outline
+}
+
+// String renders the outline on one line, with children in brackets.
+func (o outline) String() string {
+	if len(o.children) == 0 {
+		return o.word
+	}
+
+	children := make([]string, 0, len(o.children))
+	for _, child := range o.children {
+		children = append(children, child.String())
+	}
+
+	return o.word + "(" + strings.Join(children, " ") + ")"
+}
+
+// outlineParser returns a parser for a block of outline items in src.
+func outlineParser(src string) parser.Parser[[]outline] {
+	indent := parser.NewIndentation(src)
+
+	var item parser.Parser[outline]
+
+	children := parser.Indented(indent, parser.Block(indent, parser.Lazy(func() parser.Parser[outline] { return item })))
+
+	item = func(input string) (outline, string, error) {
+		word, rest, err := line(input)
+		if err != nil {
+			return outline{}, "", err
+		}
+
+		nested, remainder, err := children(rest)
+		if err != nil {
+			// No children, which is fine
+			return outline{word: word}, rest, nil
+		}
+
+		return outline{word: word, children: nested}, remainder, nil
+	}
+
+	return parser.Block(indent, item)
+}
+
+func TestIndented(t *testing.T) {
+	tests := []struct {
+		name          string // Identifying test case name
+		src           string // The complete input the Indentation is created with
+		input         string // Input to the parser, a suffix of src
+		wantValue     string // Expected value
+		wantRemainder string // Expected remainder
+		wantErrMsg    string // Expected error message, if any
+		wantErr       bool   // Whether it should have returned an error
+	}{
+		{
+			name:          "indented",
+			src:           "  word\nrest",
+			input:         "  word\nrest",
+			wantValue:     "word",
+			wantRemainder: "rest",
+			wantErr:       false,
+		},
+		{
+			name:          "not indented",
+			src:           "word\nrest",
+			input:         "word\nrest",
+			wantValue:     "",
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Indented: column 0 is not indented beyond column 0",
+		},
+		{
+			name:          "mid line",
+			src:           "- word",
+			input:         " word",
+			wantValue:     "word",
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "utf8 columns",
+			src:           "語 日",
+			input:         " 日",
+			wantValue:     "日",
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "tabs are not indentation",
+			src:           "\tword",
+			input:         "\tword",
+			wantValue:     "",
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Indented: column 0 is not indented beyond column 0",
+		},
+		{
+			name:          "parser fails",
+			src:           "  123",
+			input:         "  123",
+			wantValue:     "",
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Indented: parser returned error: expected a word",
+		},
+		{
+			name:          "not part of source",
+			src:           "word",
+			input:         "  longer than word",
+			wantValue:     "",
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Indented: input is not part of the source the Indentation was created with",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Indented(parser.NewIndentation(tt.src), line)(tt.input)
+
+			testParser(t, parserTest[string]{
+				gotErr:        err,
+				gotValue:      value,
+				wantValue:     tt.wantValue,
+				gotRemainder:  remainder,
+				wantRemainder: tt.wantRemainder,
+				wantErrMsg:    tt.wantErrMsg,
+				wantErr:       tt.wantErr,
+			})
+		})
+	}
+}
+
+func TestIndentedNil(t *testing.T) {
+	_, _, err := parser.Indented(nil, line)("  word")
+	if err == nil || err.Error() != "Indented: indent must not be nil" {
+		t.Errorf("Indented with nil indent returned %v", err)
+	}
+
+	_, _, err = parser.Indented[string](parser.NewIndentation("  word"), nil)("  word")
+	if err == nil || err.Error() != "Indented: parser must not be nil" {
+		t.Errorf("Indented with nil parser returned %v", err)
+	}
+}
+
+func TestSameColumn(t *testing.T) {
+	tests := []struct {
+		name          string // Identifying test case name
+		input         string // Input to the parser, nested inside a block at column 2
+		wantValue     string // Expected value
+		wantRemainder string // Expected remainder
+		wantErrMsg    string // Expected error message, if any
+		wantErr       bool   // Whether it should have returned an error
+	}{
+		{
+			name:          "same column",
+			input:         "  word",
+			wantValue:     "  ",
+			wantRemainder: "word",
+			wantErr:       false,
+		},
+		{
+			name:          "before",
+			input:         " word",
+			wantValue:     "",
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "SameColumn: expected column 2, got column 1",
+		},
+		{
+			name:          "after",
+			input:         "    word",
+			wantValue:     "",
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "SameColumn: expected column 2, got column 4",
+		},
+		{
+			name:          "empty line",
+			input:         "",
+			wantValue:     "",
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "SameColumn: expected column 2, got column 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Put the input on the line after one that sets the block's level to column 2
+			src := "  block\n" + tt.input
+			indent := parser.NewIndentation(src)
+
+			var value, remainder string
+			var err error
+
+			_, _, blockErr := parser.Indented(indent, func(input string) (string, string, error) {
+				value, remainder, err = parser.SameColumn(indent)(tt.input)
+				return "", "", nil
+			})(src)
+			if blockErr != nil {
+				t.Fatalf("Indented returned an unexpected error: %v", blockErr)
+			}
+
+			testParser(t, parserTest[string]{
+				gotErr:        err,
+				gotValue:      value,
+				wantValue:     tt.wantValue,
+				gotRemainder:  remainder,
+				wantRemainder: tt.wantRemainder,
+				wantErrMsg:    tt.wantErrMsg,
+				wantErr:       tt.wantErr,
+			})
+		})
+	}
+}
+
+func TestSameColumnNil(t *testing.T) {
+	_, _, err := parser.SameColumn(nil)("word")
+	if err == nil || err.Error() != "SameColumn: indent must not be nil" {
+		t.Errorf("SameColumn with nil indent returned %v", err)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	tests := []struct {
+		name          string   // Identifying test case name
+		input         string   // Input to the parser
+		wantRemainder string   // Expected remainder
+		wantErrMsg    string   // Expected error message, if any
+		want          []string // Expected items
+		wantErr       bool     // Whether it should have returned an error
+	}{
+		{
+			name:          "one item",
+			input:         "word",
+			want:          []string{"word"},
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "several items",
+			input:         "one\ntwo\nthree\n",
+			want:          []string{"one", "two", "three"},
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "indented block",
+			input:         "  one\n  two\nthree",
+			want:          []string{"one", "two"},
+			wantRemainder: "three",
+			wantErr:       false,
+		},
+		{
+			name:          "ends at deeper line",
+			input:         "one\n  two",
+			want:          []string{"one"},
+			wantRemainder: "  two",
+			wantErr:       false,
+		},
+		{
+			name:          "ends at failed item",
+			input:         "one\n123",
+			want:          []string{"one"},
+			wantRemainder: "123",
+			wantErr:       false,
+		},
+		{
+			name:          "ends at blank line",
+			input:         "one\n\ntwo",
+			want:          []string{"one"},
+			wantRemainder: "\ntwo",
+			wantErr:       false,
+		},
+		{
+			name:          "utf8",
+			input:         "日ð本\nÊ語þ",
+			want:          []string{"日ð本", "Ê語þ"},
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "first item fails",
+			input:         "123",
+			want:          nil,
+			wantRemainder: "",
+			wantErr:       true,
+			wantErrMsg:    "Block: item returned error: expected a word",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, remainder, err := parser.Block(parser.NewIndentation(tt.input), line)(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.wantErrMsg {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.wantErrMsg)
+				}
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("\nValue:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+
+			if remainder != tt.wantRemainder {
+				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, tt.wantRemainder)
+			}
+		})
+	}
+}
+
+func TestBlockNested(t *testing.T) {
+	tests := []struct {
+		name          string // Identifying test case name
+		input         string // Outline to parse
+		want          string // Expected outline, as rendered by outline.String
+		wantRemainder string // Expected remainder
+	}{
+		{
+			name:          "flat",
+			input:         "a\nb\nc",
+			want:          "a b c",
+			wantRemainder: "",
+		},
+		{
+			name:          "nested",
+			input:         "a\n  b\n  c\n    d\ne\n",
+			want:          "a(b c(d)) e",
+			wantRemainder: "",
+		},
+		{
+			name:          "dedent to enclosing block",
+			input:         "a\n    b\n      c\n    d\n  e",
+			want:          "a(b(c) d)",
+			wantRemainder: "  e",
+		},
+		{
+			name:          "nested block before enclosing block",
+			input:         "  a\n    b\n c",
+			want:          "a(b)",
+			wantRemainder: " c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, remainder, err := outlineParser(tt.input)(tt.input)
+			if err != nil {
+				t.Fatalf("outline parser returned an unexpected error: %v", err)
+			}
+
+			got := outline{children: items}.String()
+			got = strings.TrimSuffix(strings.TrimPrefix(got, "("), ")")
+
+			if got != tt.want {
+				t.Errorf("\nOutline:\t%s\nWanted:\t%s\n", got, tt.want)
+			}
+
+			if remainder != tt.wantRemainder {
+				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, tt.wantRemainder)
+			}
+		})
+	}
+}
+
+func TestBlockNil(t *testing.T) {
+	_, _, err := parser.Block(nil, line)("word")
+	if err == nil || err.Error() != "Block: indent must not be nil" {
+		t.Errorf("Block with nil indent returned %v", err)
+	}
+
+	_, _, err = parser.Block[string](parser.NewIndentation("word"), nil)("word")
+	if err == nil || err.Error() != "Block: item must not be nil" {
+		t.Errorf("Block with nil item returned %v", err)
+	}
+}
+
+func TestIndentationColumn(t *testing.T) {
+	src := "ab\n日本語 x\ny"
+	indent := parser.NewIndentation(src)
+
+	// Deliberately out of order, to cover looking columns up backwards
+	for _, offset := range []int{0, 3, 13, 12, 1, 16, 6} {
+		want := strings.LastIndexByte(src[:offset], '\n') + 1
+		want = len([]rune(src[want:offset]))
+
+		got, err := indent.Column(src[offset:])
+		if err != nil {
+			t.Fatalf("Column returned an unexpected error: %v", err)
+		}
+
+		if got != want {
+			t.Errorf("Column at offset %d = %d, wanted %d", offset, got, want)
+		}
+	}
+
+	if _, err := indent.Column(src + "more"); err == nil {
+		t.Error("Column of a string longer than the source should have returned an error")
+	}
+}
+
+func ExampleBlock() {
+	input := "fruits\n  apple\n  pear\nveg\n  carrot\nrest..."
+
+	indent := parser.NewIndentation(input)
+
+	// A heading is a word followed by the block of words indented under it
+	heading := parser.Map(
+		parser.Chain(line, parser.Map(parser.Indented(indent, parser.Block(indent, line)), func(words []string) (string, error) {
+			return strings.Join(words, ", "), nil
+		})),
+		func(parts []string) (string, error) { return parts[0] + ": " + parts[1], nil },
+	)
+
+	value, remainder, err := parser.Block(indent, heading)(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: ["fruits: apple, pear" "veg: carrot"]
+	// Remainder: "rest..."
+}
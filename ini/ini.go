@@ -0,0 +1,306 @@
+// Package ini implements a parser for INI configuration files, built on the combinators in [parser].
+//
+// The supported syntax is:
+//
+//	; Comments start with a ';' or '#' as the first non-whitespace char on a line
+//	global = keys before any section header belong to the unnamed section
+//
+//	[section]
+//	key = value
+//	long = values can be continued onto following lines
+//	  by indenting them, the lines are joined with a newline
+//
+//	  indented = keys may be indented too, only lines indented more
+//	    deeply than the key before them continue its value
+//
+// A section header may be followed by a comment on the same line, but key lines may not.
+// Keys and values have surrounding whitespace trimmed, everything after the first '=' on
+// a line is the value so values may themselves contain '=', ';' and '#'.
+//
+// The parsed [File] keeps sections and keys in the order they appeared, along with the
+// [Position] of each in the source.
+package ini // import "go.followtheprocess.codes/parser/ini"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// DuplicatePolicy controls what happens when a key appears more than once in the same section.
+type DuplicatePolicy int
+
+const (
+	// DuplicateError makes a duplicate key a parse error, this is the default.
+	DuplicateError DuplicatePolicy = iota
+
+	// DuplicateFirst keeps the first occurrence of a key and ignores any others.
+	DuplicateFirst
+
+	// DuplicateLast keeps the last occurrence of a key, overwriting the value (and position)
+	// of any earlier ones.
+	DuplicateLast
+
+	// DuplicateKeep keeps every occurrence of a key, in the order they appeared.
+	DuplicateKeep
+)
+
+// Position is a location in the source of an INI file.
+type Position struct {
+	Line   int // 1 indexed line number
+	Column int // 1 indexed column (in utf-8 chars)
+}
+
+// String implements [fmt.Stringer] for [Position].
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Key is a single key value pair.
+type Key struct {
+	Name  string   // The key name
+	Value string   // The value, continuation lines are joined with '\n'
+	Pos   Position // Position of the start of the key name
+}
+
+// Section is a named group of keys.
+type Section struct {
+	Name string   // The section name, empty for the keys before the first section header
+	Keys []Key    // The keys in the section, in the order they appeared
+	Pos  Position // Position of the section header, zero for the unnamed section
+}
+
+// Get returns the value of the first key in the section with the given name.
+func (s *Section) Get(name string) (string, bool) {
+	for _, key := range s.Keys {
+		if key.Name == name {
+			return key.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// File is a parsed INI file.
+type File struct {
+	// Sections contains every section in the order they first appeared, the first section
+	// is always the unnamed global section (which may have no keys).
+	//
+	// If a section header appears more than once, the keys from each are merged into
+	// the first.
+	Sections []Section
+}
+
+// Section returns the section with the given name, the global section is named "".
+func (f *File) Section(name string) (*Section, bool) {
+	for i := range f.Sections {
+		if f.Sections[i].Name == name {
+			return &f.Sections[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// SyntaxError is the error returned when an INI file cannot be parsed.
+type SyntaxError struct {
+	Msg string   // Description of the problem
+	Pos Position // Where the problem occurred
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("ini: %s: %s", e.Pos, e.Msg)
+}
+
+// Options configures the INI parser, the zero value is ready to use.
+type Options struct {
+	Duplicates DuplicatePolicy // What to do with duplicate keys in a section
+}
+
+// Parse parses an INI file with the default [Options].
+func Parse(input string) (*File, error) {
+	return Options{}.Parse(input)
+}
+
+// Parse parses an INI file.
+//
+// Any syntax error will be a [*SyntaxError] pointing to the offending line.
+func (o Options) Parse(input string) (*File, error) {
+	if !utf8.ValidString(input) {
+		return nil, errors.New("ini: input not valid utf-8")
+	}
+
+	p := &fileParser{
+		options: o,
+		file:    &File{Sections: []Section{{}}},
+	}
+
+	line := 0
+	for text := range parser.Split(input, newline) {
+		line++
+		if err := p.line(text, line); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.commit(); err != nil {
+		return nil, err
+	}
+
+	return p.file, nil
+}
+
+// fileParser holds the state needed while parsing a single file line by line.
+type fileParser struct {
+	pending *Key    // The most recent key, held back until we know it has no more continuation lines
+	file    *File   // The file being built
+	options Options // The parser options
+	section int     // Index of the current section in file.Sections
+}
+
+// line parses a single line of the file.
+func (p *fileParser) line(text string, line int) error {
+	indent, rest, _ := whitespace(text)
+	rest = strings.TrimRight(rest, " \t\r")
+
+	if rest == "" {
+		// Blank lines end any continuation
+		return p.commit()
+	}
+
+	column := 1 + utf8.RuneCountInString(indent)
+	pos := Position{Line: line, Column: column}
+
+	if _, _, err := comment(rest); err == nil {
+		return nil
+	}
+
+	// Like Python's configparser, only a line indented more deeply than the key it follows
+	// continues its value, so keys can themselves be indented
+	if p.pending != nil && column > p.pending.Pos.Column {
+		p.pending.Value += "\n" + rest
+		return nil
+	}
+
+	if err := p.commit(); err != nil {
+		return err
+	}
+
+	if _, _, err := parser.Char('[')(rest); err == nil {
+		return p.header(rest, pos)
+	}
+
+	return p.key(rest, pos)
+}
+
+// header parses a section header like "[name]".
+func (p *fileParser) header(rest string, pos Position) error {
+	name, remainder, err := sectionName(rest)
+	if err != nil {
+		return &SyntaxError{Msg: "unterminated section header, expected ']'", Pos: pos}
+	}
+
+	trailing, _, _ := whitespace(remainder)
+	if _, _, err := comment(remainder[len(trailing):]); remainder != "" && err != nil {
+		pos.Column += utf8.RuneCountInString(rest) - utf8.RuneCountInString(remainder)
+		return &SyntaxError{Msg: fmt.Sprintf("unexpected %q after section header", remainder), Pos: pos}
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return &SyntaxError{Msg: "empty section name", Pos: pos}
+	}
+
+	for i, section := range p.file.Sections {
+		if section.Name == name {
+			p.section = i
+			return nil
+		}
+	}
+
+	p.file.Sections = append(p.file.Sections, Section{Name: name, Pos: pos})
+	p.section = len(p.file.Sections) - 1
+
+	return nil
+}
+
+// key parses a "key = value" line.
+func (p *fileParser) key(rest string, pos Position) error {
+	name, remainder, err := parser.TakeTo("=")(rest)
+	if err != nil {
+		return &SyntaxError{Msg: fmt.Sprintf("expected '=' after key %q", rest), Pos: pos}
+	}
+
+	name = strings.TrimRight(name, " \t")
+	if name == "" {
+		return &SyntaxError{Msg: "missing key before '='", Pos: pos}
+	}
+
+	value := strings.TrimSpace(remainder[1:])
+
+	p.pending = &Key{Name: name, Value: value, Pos: pos}
+
+	return nil
+}
+
+// commit adds the pending key (if there is one) to the current section, applying
+// the duplicate policy.
+func (p *fileParser) commit() error {
+	if p.pending == nil {
+		return nil
+	}
+
+	key := *p.pending
+	p.pending = nil
+
+	section := &p.file.Sections[p.section]
+
+	existing := -1
+	for i := range section.Keys {
+		if section.Keys[i].Name == key.Name {
+			existing = i
+			break
+		}
+	}
+
+	if existing == -1 {
+		section.Keys = append(section.Keys, key)
+		return nil
+	}
+
+	switch p.options.Duplicates {
+	case DuplicateFirst:
+		// Nothing to do, we already have the first one
+	case DuplicateLast:
+		section.Keys[existing] = key
+	case DuplicateKeep:
+		section.Keys = append(section.Keys, key)
+	default:
+		msg := fmt.Sprintf("duplicate key %q in section %q, first defined at %s", key.Name, section.Name, section.Keys[existing].Pos)
+		return &SyntaxError{Msg: msg, Pos: key.Pos}
+	}
+
+	return nil
+}
+
+var (
+	// newline splits the file into lines, any carriage returns from CRLF line endings
+	// are trimmed off with the trailing whitespace.
+	newline = parser.Char('\n')
+
+	// whitespace parses optional leading whitespace on a line.
+	whitespace = parser.SkipMany(parser.OneOf(" \t"))
+
+	// comment recognises the start of a comment line.
+	comment = parser.OneOf(";#")
+
+	// sectionName parses a section header returning the name between the brackets.
+	sectionName = parser.Map(
+		parser.Chain(parser.Char('['), parser.TakeTo("]"), parser.Char(']')),
+		func(parts []string) (string, error) { return parts[1], nil },
+	)
+)
@@ -0,0 +1,349 @@
+package ini_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"go.followtheprocess.codes/parser/ini"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		want    *ini.File   // The expected parsed file
+		name    string      // Identifying test case name
+		input   string      // Input to parse
+		err     string      // The expected error message, if there was one
+		options ini.Options // The parser options
+		wantErr bool        // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			want:    &ini.File{Sections: []ini.Section{{}}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "global keys",
+			input: "a = 1\nb=2\r\n",
+			want: &ini.File{
+				Sections: []ini.Section{
+					{
+						Keys: []ini.Key{
+							{Name: "a", Value: "1", Pos: ini.Position{Line: 1, Column: 1}},
+							{Name: "b", Value: "2", Pos: ini.Position{Line: 2, Column: 1}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "sections and comments",
+			input: "; top comment\n[server] ; the server\nhost = localhost\n# port comment\n\n  port = 8080\n\n[ client ]\nurl = http://x/?a=b;c#d\n",
+			want: &ini.File{
+				Sections: []ini.Section{
+					{},
+					{
+						Name: "server",
+						Pos:  ini.Position{Line: 2, Column: 1},
+						Keys: []ini.Key{
+							{Name: "host", Value: "localhost", Pos: ini.Position{Line: 3, Column: 1}},
+							{Name: "port", Value: "8080", Pos: ini.Position{Line: 6, Column: 3}},
+						},
+					},
+					{
+						Name: "client",
+						Pos:  ini.Position{Line: 8, Column: 1},
+						Keys: []ini.Key{
+							{Name: "url", Value: "http://x/?a=b;c#d", Pos: ini.Position{Line: 9, Column: 1}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "bare comment lines",
+			input: "#\na=1\n;\n  #\n",
+			want: &ini.File{
+				Sections: []ini.Section{
+					{
+						Keys: []ini.Key{
+							{Name: "a", Value: "1", Pos: ini.Position{Line: 2, Column: 1}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "header followed by bare comment",
+			input: "[s] ;\nb = 2\n",
+			want: &ini.File{
+				Sections: []ini.Section{
+					{},
+					{
+						Name: "s",
+						Pos:  ini.Position{Line: 1, Column: 1},
+						Keys: []ini.Key{
+							{Name: "b", Value: "2", Pos: ini.Position{Line: 2, Column: 1}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "continuation lines",
+			input: "[s]\ndesc = first line\n  second line\n\tthird line\nnext = 1\n",
+			want: &ini.File{
+				Sections: []ini.Section{
+					{},
+					{
+						Name: "s",
+						Pos:  ini.Position{Line: 1, Column: 1},
+						Keys: []ini.Key{
+							{Name: "desc", Value: "first line\nsecond line\nthird line", Pos: ini.Position{Line: 2, Column: 1}},
+							{Name: "next", Value: "1", Pos: ini.Position{Line: 5, Column: 1}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "indented keys",
+			input: "[s]\n  a = 1\n  b = first line\n    second line\nc = 3\n  d = 4\n",
+			want: &ini.File{
+				Sections: []ini.Section{
+					{},
+					{
+						Name: "s",
+						Pos:  ini.Position{Line: 1, Column: 1},
+						Keys: []ini.Key{
+							{Name: "a", Value: "1", Pos: ini.Position{Line: 2, Column: 3}},
+							{Name: "b", Value: "first line\nsecond line", Pos: ini.Position{Line: 3, Column: 3}},
+							{Name: "c", Value: "3\nd = 4", Pos: ini.Position{Line: 5, Column: 1}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "repeated section merged",
+			input: "[a]\nx = 1\n[b]\n[a]\ny = 2\n",
+			want: &ini.File{
+				Sections: []ini.Section{
+					{},
+					{
+						Name: "a",
+						Pos:  ini.Position{Line: 1, Column: 1},
+						Keys: []ini.Key{
+							{Name: "x", Value: "1", Pos: ini.Position{Line: 2, Column: 1}},
+							{Name: "y", Value: "2", Pos: ini.Position{Line: 5, Column: 1}},
+						},
+					},
+					{Name: "b", Pos: ini.Position{Line: 3, Column: 1}},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "duplicate error",
+			input:   "[s]\nx = 1\nx = 2\n",
+			want:    nil,
+			wantErr: true,
+			err:     `ini: 3:1: duplicate key "x" in section "s", first defined at 2:1`,
+		},
+		{
+			name:    "duplicate first",
+			input:   "x = 1\nx = 2\n",
+			options: ini.Options{Duplicates: ini.DuplicateFirst},
+			want: &ini.File{
+				Sections: []ini.Section{
+					{Keys: []ini.Key{{Name: "x", Value: "1", Pos: ini.Position{Line: 1, Column: 1}}}},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "duplicate last",
+			input:   "x = 1\nx = 2\n  more\n",
+			options: ini.Options{Duplicates: ini.DuplicateLast},
+			want: &ini.File{
+				Sections: []ini.Section{
+					{Keys: []ini.Key{{Name: "x", Value: "2\nmore", Pos: ini.Position{Line: 2, Column: 1}}}},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "duplicate keep",
+			input:   "x = 1\nx = 2\n",
+			options: ini.Options{Duplicates: ini.DuplicateKeep},
+			want: &ini.File{
+				Sections: []ini.Section{
+					{
+						Keys: []ini.Key{
+							{Name: "x", Value: "1", Pos: ini.Position{Line: 1, Column: 1}},
+							{Name: "x", Value: "2", Pos: ini.Position{Line: 2, Column: 1}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "missing equals",
+			input:   "[s]\njust a line\n",
+			want:    nil,
+			wantErr: true,
+			err:     `ini: 2:1: expected '=' after key "just a line"`,
+		},
+		{
+			name:    "missing key",
+			input:   "  = value\n",
+			want:    nil,
+			wantErr: true,
+			err:     "ini: 1:3: missing key before '='",
+		},
+		{
+			name:    "unterminated header",
+			input:   "[section\n",
+			want:    nil,
+			wantErr: true,
+			err:     "ini: 1:1: unterminated section header, expected ']'",
+		},
+		{
+			name:    "junk after header",
+			input:   "[日本] junk\n",
+			want:    nil,
+			wantErr: true,
+			err:     `ini: 1:5: unexpected " junk" after section header`,
+		},
+		{
+			name:    "empty header",
+			input:   "[  ]\n",
+			want:    nil,
+			wantErr: true,
+			err:     "ini: 1:1: empty section name",
+		},
+		{
+			name:    "bad utf8",
+			input:   "\xf8\xa1\xa1\xa1\xa1",
+			want:    nil,
+			wantErr: true,
+			err:     "ini: input not valid utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.Parse(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nFile:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	_, err := ini.Parse("[s]\nbad line\n")
+
+	var syntaxErr *ini.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Error was not a *ini.SyntaxError, got %T", err)
+	}
+
+	want := ini.Position{Line: 2, Column: 1}
+	if syntaxErr.Pos != want {
+		t.Errorf("\nPosition:\t%v\nWanted:\t%v\n", syntaxErr.Pos, want)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	file, err := ini.Parse("name = global\n[db]\nhost = localhost\n")
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	section, ok := file.Section("db")
+	if !ok {
+		t.Fatal("Section(db) not found")
+	}
+
+	if value, ok := section.Get("host"); !ok || value != "localhost" {
+		t.Errorf("Get(host) = (%q, %v), wanted (%q, true)", value, ok, "localhost")
+	}
+
+	if _, ok := section.Get("missing"); ok {
+		t.Error("Get(missing) returned ok for a missing key")
+	}
+
+	if _, ok := file.Section("missing"); ok {
+		t.Error("Section(missing) returned ok for a missing section")
+	}
+
+	global, ok := file.Section("")
+	if !ok {
+		t.Fatal("global section not found")
+	}
+
+	if value, _ := global.Get("name"); value != "global" {
+		t.Errorf("Get(name) = %q, wanted %q", value, "global")
+	}
+}
+
+func ExampleParse() {
+	input := `; Database settings
+[database]
+host = localhost
+port = 5432
+
+[server]
+motd = Welcome to the server,
+  please be nice
+`
+
+	file, err := ini.Parse(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	for _, section := range file.Sections {
+		for _, key := range section.Keys {
+			fmt.Printf("[%s] %s = %q (line %d)\n", section.Name, key.Name, key.Value, key.Pos.Line)
+		}
+	}
+
+	// Output: [database] host = "localhost" (line 3)
+	// [database] port = "5432" (line 4)
+	// [server] motd = "Welcome to the server,\nplease be nice" (line 7)
+}
@@ -0,0 +1,64 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.followtheprocess.codes/parser/json"
+)
+
+func BenchmarkParse(b *testing.B) {
+	data := readBenchData(b)
+
+	b.SetBytes(int64(len(data)))
+
+	for b.Loop() {
+		_, err := json.Parse(string(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseAny(b *testing.B) {
+	data := readBenchData(b)
+
+	b.SetBytes(int64(len(data)))
+
+	for b.Loop() {
+		value, err := json.Parse(string(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = value.Any()
+	}
+}
+
+// BenchmarkStdlib is the baseline for BenchmarkParse and BenchmarkParseAny, decoding
+// the same document into an any with encoding/json.
+func BenchmarkStdlib(b *testing.B) {
+	data := readBenchData(b)
+
+	b.SetBytes(int64(len(data)))
+
+	for b.Loop() {
+		var value any
+		if err := stdjson.Unmarshal(data, &value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// readBenchData reads the benchmark document from testdata.
+func readBenchData(b *testing.B) []byte {
+	b.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "bench.json"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return data
+}
@@ -0,0 +1,53 @@
+package json_test
+
+// The fuzz tests in here check that the parser never panics and that it agrees
+// with encoding/json about what is and isn't valid JSON.
+
+import (
+	stdjson "encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser/json"
+)
+
+func FuzzParse(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("testdata", "conformance", "*.json"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(contents))
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, err := json.Parse(input)
+
+		var want any
+		stdErr := stdjson.Unmarshal([]byte(input), &want)
+
+		// encoding/json accepts invalid utf-8 inside strings, RFC 8259 doesn't
+		if !utf8.ValidString(input) {
+			if err == nil {
+				t.Fatalf("Parse accepted invalid utf-8: %q", input)
+			}
+			return
+		}
+
+		if (err != nil) != (stdErr != nil) {
+			t.Fatalf("Parse and encoding/json disagree on %q\nParse:\t%v\nencoding/json:\t%v\n", input, err, stdErr)
+		}
+
+		if err == nil && !reflect.DeepEqual(got.Any(), want) {
+			t.Fatalf("Parse and encoding/json produced different values for %q\nParse:\t%#v\nencoding/json:\t%#v\n", input, got.Any(), want)
+		}
+	})
+}
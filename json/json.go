@@ -0,0 +1,645 @@
+// Package json implements an [RFC 8259] JSON parser built on the combinators in [parser].
+//
+// It exists both as a reference grammar showing how recursion, alternation and error reporting
+// compose for a real format, and as a realistic workload for benchmarking the core combinators.
+//
+// Unlike [encoding/json], parsing produces a [Value] tree that records the exact [Span] of the
+// input each value came from, and object members are kept in their original order (including duplicates).
+//
+// [RFC 8259]: https://www.rfc-editor.org/rfc/rfc8259
+package json // import "go.followtheprocess.codes/parser/json"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// MaxDepth is the maximum nesting depth of arrays and objects that [Parse] will accept, this
+// stops deeply nested (and likely malicious) input from exhausting the stack.
+const MaxDepth = 10000
+
+// Kind is the kind of a JSON [Value].
+type Kind int
+
+const (
+	KindNull   Kind = iota // The JSON null literal
+	KindBool               // true or false
+	KindNumber             // A JSON number
+	KindString             // A JSON string
+	KindArray              // A JSON array
+	KindObject             // A JSON object
+)
+
+// String implements [fmt.Stringer] for [Kind].
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Span is a half open range of byte offsets [Start, End) into the parsed input.
+type Span struct {
+	Start int // Byte offset of the first byte
+	End   int // Byte offset one past the last byte
+}
+
+// Value is a single parsed JSON value.
+//
+// Only the fields relevant to the value's [Kind] are populated, e.g. a [KindString] will only
+// have Text set.
+type Value struct {
+	Text   string   // The unescaped contents of a string
+	Array  []Value  // The elements of an array
+	Object []Member // The members of an object, in the order they appeared
+	Span   Span     // Where in the input the value came from
+	Number float64  // The value of a number
+	Kind   Kind     // The kind of value
+	Bool   bool     // The value of a bool
+}
+
+// Member is a single key value pair in a JSON object.
+type Member struct {
+	Key     string // The unescaped key
+	Value   Value  // The value
+	KeySpan Span   // Where in the input the key came from, including the quotes
+}
+
+// Any converts the [Value] into the same representation [encoding/json] uses when
+// unmarshalling into an any, i.e. nil, bool, float64, string, []any or map[string]any.
+//
+// As with [encoding/json], if an object has duplicate keys, the last one wins.
+func (v Value) Any() any {
+	switch v.Kind {
+	case KindNull:
+		return nil
+	case KindBool:
+		return v.Bool
+	case KindNumber:
+		return v.Number
+	case KindString:
+		return v.Text
+	case KindArray:
+		array := make([]any, 0, len(v.Array))
+		for _, element := range v.Array {
+			array = append(array, element.Any())
+		}
+		return array
+	case KindObject:
+		object := make(map[string]any, len(v.Object))
+		for _, member := range v.Object {
+			object[member.Key] = member.Value.Any()
+		}
+		return object
+	default:
+		return nil
+	}
+}
+
+// SyntaxError is the error returned when the input is not valid JSON.
+//
+// Line and Column are calculated from the Offset by [Parse], errors from the
+// parser returned by [Parser] only have the Offset set.
+type SyntaxError struct {
+	Msg    string // Description of the problem
+	Offset int    // Byte offset in the input at which the error occurred
+	Line   int    // 1 indexed line number of Offset
+	Column int    // 1 indexed column (in utf-8 chars) of Offset
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("json: %s at offset %d", e.Msg, e.Offset)
+	}
+
+	return fmt.Sprintf("json: %s at line %d, column %d", e.Msg, e.Line, e.Column)
+}
+
+// Parse parses a complete JSON document.
+//
+// Leading and trailing whitespace is allowed, but anything else after the top level value
+// is an error. Any error returned will be a [*SyntaxError].
+func Parse(data string) (Value, error) {
+	if !utf8.ValidString(data) {
+		offset := invalidUTF8Offset(data)
+		return Value{}, newSyntaxError(data, offset, "input not valid utf-8")
+	}
+
+	value, remainder, err := Parser(data)(data)
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			syntaxErr = &SyntaxError{Msg: err.Error(), Offset: len(data) - len(remainder)}
+		}
+
+		syntaxErr.locate(data)
+
+		return Value{}, syntaxErr
+	}
+
+	_, remainder, _ = whitespace(remainder)
+	if remainder != "" {
+		offset := len(data) - len(remainder)
+		return Value{}, newSyntaxError(data, offset, fmt.Sprintf("invalid character %q after top-level value", firstChar(remainder)))
+	}
+
+	return value, nil
+}
+
+// Parser returns a [parser.Parser] that parses a single JSON value (with optional
+// leading whitespace) from the start of input, returning any remaining input after
+// the value.
+//
+// src is the complete document the input is a suffix of, it's used to calculate the [Span]
+// of each value and the position of any errors.
+func Parser(src string) parser.Parser[Value] {
+	g := &grammar{src: src}
+
+	var value parser.Parser[Value]
+
+	// Arrays and objects contain values, which may themselves be arrays and objects
+	// so we need to refer to value before it's constructed
+	nested := parser.Lazy(func() parser.Parser[Value] { return value })
+
+	value = g.value(nested)
+
+	return value
+}
+
+// grammar holds the state needed to build the JSON parsers for a particular document.
+type grammar struct {
+	src   string // The entire document
+	depth int    // Current nesting depth of arrays and objects
+}
+
+// offset returns the byte offset in the source of a suffix of it.
+func (g *grammar) offset(rest string) int {
+	return len(g.src) - len(rest)
+}
+
+// fail returns a new [SyntaxError] at the start of rest.
+func (g *grammar) fail(rest, format string, args ...any) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Offset: g.offset(rest)}
+}
+
+// value returns the parser for any JSON value, nested is the (lazy) parser to use for
+// elements of arrays and objects.
+func (g *grammar) value(nested parser.Parser[Value]) parser.Parser[Value] {
+	array := g.array(nested)
+	object := g.object(nested)
+	str := g.string()
+	number := g.number()
+	literal := parser.Try(
+		g.literal("true", Value{Kind: KindBool, Bool: true}),
+		g.literal("false", Value{Kind: KindBool, Bool: false}),
+		g.literal("null", Value{Kind: KindNull}),
+	)
+
+	return func(input string) (Value, string, error) {
+		_, rest, _ := whitespace(input)
+		if rest == "" {
+			return Value{}, "", g.fail(rest, "unexpected end of input, expected value")
+		}
+
+		// We could just Try each of these in turn, but dispatching on the first char
+		// means we get an error from the parser that was actually relevant
+		switch c := rest[0]; {
+		case c == '[':
+			return array(rest)
+		case c == '{':
+			return object(rest)
+		case c == '"':
+			return str(rest)
+		case c == '-' || isDigit(c):
+			return number(rest)
+		case c == 't' || c == 'f' || c == 'n':
+			value, remainder, err := literal(rest)
+			if err != nil {
+				return Value{}, "", g.fail(rest, "invalid literal, expected true, false or null")
+			}
+			return value, remainder, nil
+		default:
+			return Value{}, "", g.fail(rest, "invalid character %q looking for beginning of value", firstChar(rest))
+		}
+	}
+}
+
+// literal returns a parser that parses a keyword literal like true or null, returning value.
+func (g *grammar) literal(keyword string, value Value) parser.Parser[Value] {
+	return func(input string) (Value, string, error) {
+		if !strings.HasPrefix(input, keyword) {
+			// Deliberately cheap, this is only ever used in a Try which discards it
+			return Value{}, "", errNotLiteral
+		}
+
+		value.Span = Span{Start: g.offset(input), End: g.offset(input) + len(keyword)}
+
+		return value, input[len(keyword):], nil
+	}
+}
+
+// number returns a parser for a JSON number.
+//
+//	number = [ minus ] int [ frac ] [ exp ]
+func (g *grammar) number() parser.Parser[Value] {
+	minus := parser.Char('-')
+	zero := parser.Char('0')
+	nonZero := parser.OneOf("123456789")
+	digits := parser.SkipMany(parser.OneOf("0123456789"))
+	exponent := parser.OneOf("eE")
+	sign := parser.OneOf("+-")
+
+	return func(input string) (Value, string, error) {
+		rest := input
+		if _, remainder, err := minus(rest); err == nil {
+			rest = remainder
+		}
+
+		// int = zero / ( digit1-9 *DIGIT )
+		if _, remainder, err := nonZero(rest); err == nil {
+			_, rest, _ = digits(remainder)
+		} else if _, remainder, err := zero(rest); err == nil {
+			rest = remainder
+		} else {
+			return Value{}, "", g.fail(rest, "invalid number, expected digit")
+		}
+
+		// frac = decimal-point 1*DIGIT
+		if rest != "" && rest[0] == '.' {
+			fraction, remainder, _ := digits(rest[1:])
+			if fraction == "" {
+				return Value{}, "", g.fail(remainder, "invalid number, expected digit after decimal point")
+			}
+			rest = remainder
+		}
+
+		// exp = e [ minus / plus ] 1*DIGIT
+		if _, remainder, err := exponent(rest); err == nil {
+			if _, afterSign, err := sign(remainder); err == nil {
+				remainder = afterSign
+			}
+
+			power, afterPower, _ := digits(remainder)
+			if power == "" {
+				return Value{}, "", g.fail(afterPower, "invalid number, expected digit in exponent")
+			}
+			rest = afterPower
+		}
+
+		text := input[:len(input)-len(rest)]
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return Value{}, "", g.fail(input, "number %s out of range", text)
+		}
+
+		value := Value{
+			Kind:   KindNumber,
+			Number: n,
+			Span:   Span{Start: g.offset(input), End: g.offset(rest)},
+		}
+
+		return value, rest, nil
+	}
+}
+
+// string returns a parser for a JSON string.
+func (g *grammar) string() parser.Parser[Value] {
+	return func(input string) (Value, string, error) {
+		text, rest, err := g.quoted(input)
+		if err != nil {
+			return Value{}, "", err
+		}
+
+		value := Value{
+			Kind: KindString,
+			Text: text,
+			Span: Span{Start: g.offset(input), End: g.offset(rest)},
+		}
+
+		return value, rest, nil
+	}
+}
+
+// quoted parses a quoted JSON string, returning the unescaped contents.
+//
+// If the string contains no escapes, the returned contents are a slice of the
+// input so no allocation takes place.
+func (g *grammar) quoted(input string) (string, string, error) {
+	if _, _, err := parser.Char('"')(input); err != nil {
+		return "", "", g.fail(input, "expected '\"' at start of string")
+	}
+
+	rest := input[1:]
+
+	var builder *strings.Builder // Only allocated if we actually need to unescape
+
+	for {
+		chunk, remainder, _ := plain(rest)
+		if builder != nil {
+			builder.WriteString(chunk)
+		}
+		rest = remainder
+
+		if rest == "" {
+			return "", "", g.fail(rest, "unexpected end of input in string")
+		}
+
+		switch c := rest[0]; {
+		case c == '"':
+			end := len(input) - len(rest)
+			if builder == nil {
+				return input[1:end], rest[1:], nil
+			}
+			return builder.String(), rest[1:], nil
+
+		case c == '\\':
+			if builder == nil {
+				builder = &strings.Builder{}
+				builder.WriteString(input[1 : len(input)-len(rest)])
+			}
+
+			if len(rest) < 2 {
+				return "", "", g.fail(rest, "unexpected end of input in string escape")
+			}
+
+			switch escape := rest[1]; escape {
+			case '"', '\\', '/':
+				builder.WriteByte(escape)
+				rest = rest[2:]
+			case 'b':
+				builder.WriteByte('\b')
+				rest = rest[2:]
+			case 'f':
+				builder.WriteByte('\f')
+				rest = rest[2:]
+			case 'n':
+				builder.WriteByte('\n')
+				rest = rest[2:]
+			case 'r':
+				builder.WriteByte('\r')
+				rest = rest[2:]
+			case 't':
+				builder.WriteByte('\t')
+				rest = rest[2:]
+			case 'u':
+				digits, remainder, err := hex(rest[2:])
+				if err != nil {
+					return "", "", g.fail(rest, "invalid unicode escape")
+				}
+
+				r := decodeHex(digits)
+				rest = remainder
+
+				if utf16.IsSurrogate(r) {
+					// Might be the first half of a surrogate pair, if the next thing is
+					// the second half, combine them
+					r = utf8.RuneError
+					if strings.HasPrefix(rest, `\u`) {
+						if low, remainder, err := hex(rest[2:]); err == nil {
+							if combined := utf16.DecodeRune(decodeHex(digits), decodeHex(low)); combined != utf8.RuneError {
+								r = combined
+								rest = remainder
+							}
+						}
+					}
+				}
+
+				builder.WriteRune(r)
+			default:
+				return "", "", g.fail(rest, "invalid escape character %q in string", firstChar(rest[1:]))
+			}
+
+		default:
+			// Must be a control char
+			return "", "", g.fail(rest, "invalid control character %q in string", c)
+		}
+	}
+}
+
+// array returns a parser for a JSON array.
+func (g *grammar) array(element parser.Parser[Value]) parser.Parser[Value] {
+	return func(input string) (Value, string, error) {
+		g.depth++
+		defer func() { g.depth-- }()
+
+		if g.depth > MaxDepth {
+			return Value{}, "", g.fail(input, "exceeded max nesting depth of %d", MaxDepth)
+		}
+
+		_, rest, err := parser.Char('[')(input)
+		if err != nil {
+			return Value{}, "", g.fail(input, "expected '[' at start of array")
+		}
+
+		_, rest, _ = whitespace(rest)
+
+		var elements []Value
+
+		if rest == "" || rest[0] != ']' {
+			for {
+				var value Value
+				value, rest, err = element(rest)
+				if err != nil {
+					return Value{}, "", err
+				}
+
+				elements = append(elements, value)
+
+				_, rest, _ = whitespace(rest)
+				if rest != "" && rest[0] == ',' {
+					rest = rest[1:]
+					continue
+				}
+
+				break
+			}
+		}
+
+		_, remainder, err := parser.Char(']')(rest)
+		if err != nil {
+			return Value{}, "", g.unexpected(rest, "after array element")
+		}
+		rest = remainder
+
+		value := Value{
+			Kind:  KindArray,
+			Array: elements,
+			Span:  Span{Start: g.offset(input), End: g.offset(rest)},
+		}
+
+		return value, rest, nil
+	}
+}
+
+// object returns a parser for a JSON object.
+func (g *grammar) object(element parser.Parser[Value]) parser.Parser[Value] {
+	return func(input string) (Value, string, error) {
+		g.depth++
+		defer func() { g.depth-- }()
+
+		if g.depth > MaxDepth {
+			return Value{}, "", g.fail(input, "exceeded max nesting depth of %d", MaxDepth)
+		}
+
+		_, rest, err := parser.Char('{')(input)
+		if err != nil {
+			return Value{}, "", g.fail(input, "expected '{' at start of object")
+		}
+
+		_, rest, _ = whitespace(rest)
+
+		var members []Member
+
+		if rest == "" || rest[0] != '}' {
+			for {
+				_, rest, _ = whitespace(rest)
+				if rest == "" || rest[0] != '"' {
+					return Value{}, "", g.unexpected(rest, "looking for beginning of object key string")
+				}
+
+				keyStart := rest
+
+				var key string
+				key, rest, err = g.quoted(rest)
+				if err != nil {
+					return Value{}, "", err
+				}
+
+				keySpan := Span{Start: g.offset(keyStart), End: g.offset(rest)}
+
+				_, rest, _ = whitespace(rest)
+				_, remainder, err := parser.Char(':')(rest)
+				if err != nil {
+					return Value{}, "", g.unexpected(rest, "after object key")
+				}
+				rest = remainder
+
+				var value Value
+				value, rest, err = element(rest)
+				if err != nil {
+					return Value{}, "", err
+				}
+
+				members = append(members, Member{Key: key, KeySpan: keySpan, Value: value})
+
+				_, rest, _ = whitespace(rest)
+				if rest != "" && rest[0] == ',' {
+					rest = rest[1:]
+					continue
+				}
+
+				break
+			}
+		}
+
+		_, remainder, err := parser.Char('}')(rest)
+		if err != nil {
+			return Value{}, "", g.unexpected(rest, "after object key:value pair")
+		}
+		rest = remainder
+
+		value := Value{
+			Kind:   KindObject,
+			Object: members,
+			Span:   Span{Start: g.offset(input), End: g.offset(rest)},
+		}
+
+		return value, rest, nil
+	}
+}
+
+// unexpected returns a [SyntaxError] describing the unexpected char at the start
+// of rest (or the unexpected end of input).
+func (g *grammar) unexpected(rest, context string) error {
+	if rest == "" {
+		return g.fail(rest, "unexpected end of input %s", context)
+	}
+
+	return g.fail(rest, "invalid character %q %s", firstChar(rest), context)
+}
+
+// controlChars are the chars that must be escaped in a JSON string.
+const controlChars = "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f" +
+	"\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f"
+
+var (
+	// whitespace parses optional insignificant JSON whitespace.
+	whitespace = parser.SkipMany(parser.OneOf(" \t\n\r"))
+
+	// plain parses a run of chars inside a string other than the closing quote,
+	// an escape or a control char.
+	plain = parser.SkipMany(parser.NoneOf("\"\\" + controlChars))
+
+	// hex parses the 4 hex digits of a unicode escape.
+	hex = parser.SkipCount(parser.OneOf("0123456789abcdefABCDEF"), 4)
+)
+
+// errNotLiteral is returned when a keyword literal doesn't match.
+var errNotLiteral = errors.New("not a literal")
+
+// newSyntaxError builds a [SyntaxError] at offset into src.
+func newSyntaxError(src string, offset int, msg string) *SyntaxError {
+	err := &SyntaxError{Msg: msg, Offset: offset}
+	err.locate(src)
+
+	return err
+}
+
+// locate fills in the Line and Column of the error from its Offset into src.
+func (e *SyntaxError) locate(src string) {
+	before := src[:e.Offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	e.Line = 1 + strings.Count(before, "\n")
+	e.Column = 1 + utf8.RuneCountInString(before[lineStart:])
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid utf-8 sequence in s.
+func invalidUTF8Offset(s string) int {
+	for pos, char := range s {
+		if char == utf8.RuneError {
+			if _, width := utf8.DecodeRuneInString(s[pos:]); width == 1 {
+				return pos
+			}
+		}
+	}
+
+	return len(s)
+}
+
+// firstChar returns the first utf-8 char in s.
+func firstChar(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// isDigit reports whether c is an ascii digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// decodeHex decodes 4 hex digits into a rune, the digits must already have
+// been validated.
+func decodeHex(digits string) rune {
+	n, _ := strconv.ParseUint(digits, 16, 32)
+	return rune(n)
+}
@@ -0,0 +1,355 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/json"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string     // Identifying test case name
+		input   string     // The JSON document to parse
+		err     string     // The expected error message, if there was one
+		want    json.Value // The expected value
+		wantErr bool       // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: unexpected end of input, expected value at line 1, column 1",
+		},
+		{
+			name:    "null",
+			input:   "null",
+			want:    json.Value{Kind: json.KindNull, Span: json.Span{Start: 0, End: 4}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "true with whitespace",
+			input:   " \n\ttrue\r\n",
+			want:    json.Value{Kind: json.KindBool, Bool: true, Span: json.Span{Start: 3, End: 7}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "number",
+			input:   "-12.5e2",
+			want:    json.Value{Kind: json.KindNumber, Number: -1250, Span: json.Span{Start: 0, End: 7}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "string no escapes",
+			input:   `"日ð本"`,
+			want:    json.Value{Kind: json.KindString, Text: "日ð本", Span: json.Span{Start: 0, End: 10}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "string with escapes",
+			input:   `"a\tb\u00e9\ud83d\ude00"`,
+			want:    json.Value{Kind: json.KindString, Text: "a\tbé😀", Span: json.Span{Start: 0, End: 24}},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "lone surrogate",
+			input: `"\ud800x"`,
+			want: json.Value{
+				Kind: json.KindString,
+				Text: "�x",
+				Span: json.Span{Start: 0, End: 9},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "array",
+			input: `[1, "two"]`,
+			want: json.Value{
+				Kind: json.KindArray,
+				Span: json.Span{Start: 0, End: 10},
+				Array: []json.Value{
+					{Kind: json.KindNumber, Number: 1, Span: json.Span{Start: 1, End: 2}},
+					{Kind: json.KindString, Text: "two", Span: json.Span{Start: 4, End: 9}},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "object",
+			input: `{"a": [], "b": {}}`,
+			want: json.Value{
+				Kind: json.KindObject,
+				Span: json.Span{Start: 0, End: 18},
+				Object: []json.Member{
+					{
+						Key:     "a",
+						KeySpan: json.Span{Start: 1, End: 4},
+						Value:   json.Value{Kind: json.KindArray, Span: json.Span{Start: 6, End: 8}},
+					},
+					{
+						Key:     "b",
+						KeySpan: json.Span{Start: 10, End: 13},
+						Value:   json.Value{Kind: json.KindObject, Span: json.Span{Start: 15, End: 17}},
+					},
+				},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "trailing garbage",
+			input:   "[1] x",
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: invalid character 'x' after top-level value at line 1, column 5",
+		},
+		{
+			name:    "trailing comma",
+			input:   "{\n  \"a\": 1,\n}",
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: invalid character '}' looking for beginning of object key string at line 3, column 1",
+		},
+		{
+			name:    "missing colon",
+			input:   `{"a" 1}`,
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: invalid character '1' after object key at line 1, column 6",
+		},
+		{
+			name:    "unclosed array",
+			input:   `[1, 2`,
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: unexpected end of input after array element at line 1, column 6",
+		},
+		{
+			name:    "bad literal",
+			input:   `[nul]`,
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: invalid literal, expected true, false or null at line 1, column 2",
+		},
+		{
+			name:    "leading zero",
+			input:   `01`,
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: invalid character '1' after top-level value at line 1, column 2",
+		},
+		{
+			name:    "bad exponent",
+			input:   `1e+`,
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: invalid number, expected digit in exponent at line 1, column 4",
+		},
+		{
+			name:    "number out of range",
+			input:   `1e400`,
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: number 1e400 out of range at line 1, column 1",
+		},
+		{
+			name:    "bad escape",
+			input:   `"\x"`,
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: invalid escape character 'x' in string at line 1, column 2",
+		},
+		{
+			name:    "control char in string",
+			input:   "\"a\nb\"",
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: invalid control character '\\n' in string at line 1, column 3",
+		},
+		{
+			name:    "bad utf8",
+			input:   "[\"\xf8\xa1\"]",
+			want:    json.Value{},
+			wantErr: true,
+			err:     "json: input not valid utf-8 at line 1, column 3",
+		},
+		{
+			name:    "too deep",
+			input:   strings.Repeat("[", json.MaxDepth+1) + strings.Repeat("]", json.MaxDepth+1),
+			want:    json.Value{},
+			wantErr: true,
+			err:     fmt.Sprintf("json: exceeded max nesting depth of %d at line 1, column %d", json.MaxDepth, json.MaxDepth+1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Parse(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+
+				var syntaxErr *json.SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("Error was not a *json.SyntaxError, got %T", err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nValue:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	input := strings.Repeat("[", json.MaxDepth) + strings.Repeat("]", json.MaxDepth)
+
+	if _, err := json.Parse(input); err != nil {
+		t.Fatalf("Parse returned an unexpected error at max depth: %v", err)
+	}
+}
+
+func TestParser(t *testing.T) {
+	// The parser should leave anything after the value alone so it can be
+	// composed with other parsers
+	src := `  {"a": true} trailing`
+
+	value, remainder, err := json.Parser(src)(src)
+	if err != nil {
+		t.Fatalf("Parser returned an unexpected error: %v", err)
+	}
+
+	if remainder != " trailing" {
+		t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, " trailing")
+	}
+
+	if got := src[value.Span.Start:value.Span.End]; got != `{"a": true}` {
+		t.Errorf("\nSpan text:\t%q\nWanted:\t%q\n", got, `{"a": true}`)
+	}
+}
+
+// TestConformance runs the JSONTestSuite style cases in testdata/conformance, files prefixed
+// with y_ must be accepted and produce the same value as encoding/json, files prefixed with
+// n_ must be rejected.
+func TestConformance(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "conformance", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("no conformance test cases found")
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			contents, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Parse(string(contents))
+
+			switch {
+			case strings.HasPrefix(name, "y_"):
+				if err != nil {
+					t.Fatalf("Parse rejected valid JSON: %v", err)
+				}
+
+				var want any
+				if err := stdjson.Unmarshal(contents, &want); err != nil {
+					t.Fatalf("encoding/json rejected valid JSON: %v", err)
+				}
+
+				if !reflect.DeepEqual(got.Any(), want) {
+					t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got.Any(), want)
+				}
+			case strings.HasPrefix(name, "n_"):
+				if err == nil {
+					t.Fatalf("Parse accepted invalid JSON: %#v", got)
+				}
+			default:
+				t.Fatalf("conformance case %s must be prefixed with y_ or n_", name)
+			}
+		})
+	}
+}
+
+func TestKindString(t *testing.T) {
+	tests := []struct {
+		want string
+		kind json.Kind
+	}{
+		{kind: json.KindNull, want: "null"},
+		{kind: json.KindBool, want: "bool"},
+		{kind: json.KindNumber, want: "number"},
+		{kind: json.KindString, want: "string"},
+		{kind: json.KindArray, want: "array"},
+		{kind: json.KindObject, want: "object"},
+		{kind: json.Kind(42), want: "Kind(42)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.kind.String(); got != tt.want {
+				t.Errorf("\nGot:\t%q\nWanted:\t%q\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func ExampleParse() {
+	input := `{"name": "parser", "stars": 42, "tags": ["go", "parsing"]}`
+
+	value, err := json.Parse(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	for _, member := range value.Object {
+		fmt.Printf("%s (%s) at %d-%d: %s\n",
+			member.Key,
+			member.Value.Kind,
+			member.Value.Span.Start,
+			member.Value.Span.End,
+			input[member.Value.Span.Start:member.Value.Span.End],
+		)
+	}
+
+	// Output: name (string) at 9-17: "parser"
+	// stars (number) at 28-30: 42
+	// tags (array) at 40-57: ["go", "parsing"]
+}
+
+func ExampleSyntaxError() {
+	input := "{\n  \"a\": [1, 2,]\n}"
+
+	_, err := json.Parse(input)
+
+	fmt.Println(err)
+
+	// Output: json: invalid character ']' looking for beginning of value at line 2, column 14
+}
@@ -0,0 +1,57 @@
+package logfmt_test
+
+import (
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser/logfmt"
+)
+
+// benchLine is a representative logfmt line with a mix of bare, quoted and unquoted values.
+const benchLine = `time=2024-01-01T12:00:00Z level=info msg="request handled" method=GET path=/api/v1/users ` +
+	`status=200 dur=3.2ms bytes=1024 cached`
+
+func BenchmarkPairs(b *testing.B) {
+	b.SetBytes(int64(len(benchLine)))
+
+	for b.Loop() {
+		for _, err := range logfmt.Pairs(benchLine) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkPairsEscaped(b *testing.B) {
+	line := `level=warn msg="quote \"this\" please" err="line one\nline two"`
+	b.SetBytes(int64(len(line)))
+
+	for b.Loop() {
+		for _, err := range logfmt.Pairs(line) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	type entry struct {
+		Level    string        `logfmt:"level"`
+		Message  string        `logfmt:"msg"`
+		Path     string        `logfmt:"path"`
+		Duration time.Duration `logfmt:"dur"`
+		Status   int           `logfmt:"status"`
+		Cached   bool          `logfmt:"cached"`
+	}
+
+	b.SetBytes(int64(len(benchLine)))
+
+	for b.Loop() {
+		var e entry
+		if err := logfmt.Unmarshal(benchLine, &e); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Package logfmt implements a parser for logfmt structured log lines, built on the combinators in [parser].
+//
+// A logfmt line is a sequence of whitespace separated key value pairs:
+//
+//	level=info msg="hello world" dur=3ms cached
+//
+// Keys are any run of printable chars other than '=' and '"'. A value is either unquoted, running
+// to the next whitespace, or double quoted with JSON style escapes. A key with no '=' (cached in the
+// example above) is a bare key, which has an empty value and is decoded as true by [Unmarshal].
+//
+// Keys and values that need no unescaping are slices of the input line, so iterating over the
+// pairs in a line with [Pairs] does not allocate in the common case.
+package logfmt // import "go.followtheprocess.codes/parser/logfmt"
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// Pair is a single key value pair from a logfmt line.
+type Pair struct {
+	Key   string // The key
+	Value string // The value, with any quotes removed and escapes processed
+	Bare  bool   // Whether the key appeared on its own, with no '=' or value
+}
+
+// SyntaxError is the error returned when a logfmt line cannot be parsed.
+type SyntaxError struct {
+	Msg    string // Description of the problem
+	Column int    // 1 indexed column (in utf-8 chars) where the problem occurred
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("logfmt: column %d: %s", e.Column, e.Msg)
+}
+
+// Pairs returns an iterator over the key value pairs in a single logfmt line.
+//
+// Iteration stops after the first error, which will be a [*SyntaxError] for malformed
+// input. Any newlines in the line are treated as whitespace, so to parse a whole log
+// file, split it into lines first, e.g. with [parser.Split].
+func Pairs(line string) iter.Seq2[Pair, error] {
+	return func(yield func(Pair, error) bool) {
+		if !utf8.ValidString(line) {
+			yield(Pair{}, errors.New("logfmt: input not valid utf-8"))
+			return
+		}
+
+		rest := line
+		for {
+			_, rest, _ = whitespace(rest)
+			if rest == "" {
+				return
+			}
+
+			pair, remainder, err := next(line, rest)
+			if err != nil {
+				yield(Pair{}, err)
+				return
+			}
+
+			if !yield(pair, nil) {
+				return
+			}
+
+			rest = remainder
+		}
+	}
+}
+
+// next parses the pair at the start of rest, line is the entire line for error positions.
+func next(line, rest string) (Pair, string, error) {
+	key, remainder, _ := key(rest)
+	if key == "" {
+		return Pair{}, "", fail(line, rest, fmt.Sprintf("unexpected %q, expected a key", firstChar(rest)))
+	}
+
+	rest = remainder
+
+	if !strings.HasPrefix(rest, "=") {
+		// Bare key, so long as what follows is whitespace or the end of the line
+		if rest != "" && !isSpace(firstChar(rest)) {
+			return Pair{}, "", fail(line, rest, fmt.Sprintf("unexpected %q after key %q", firstChar(rest), key))
+		}
+
+		return Pair{Key: key, Bare: true}, rest, nil
+	}
+
+	rest = rest[1:]
+
+	if rest == "" || isSpace(firstChar(rest)) {
+		return Pair{Key: key}, rest, nil
+	}
+
+	if rest[0] == '"' {
+		value, remainder, err := quoted(line, rest)
+		if err != nil {
+			return Pair{}, "", err
+		}
+
+		if remainder != "" && !isSpace(firstChar(remainder)) {
+			return Pair{}, "", fail(line, remainder, fmt.Sprintf("unexpected %q after quoted value", firstChar(remainder)))
+		}
+
+		return Pair{Key: key, Value: value}, remainder, nil
+	}
+
+	value, remainder, _ := unquoted(rest)
+	if remainder != "" && !isSpace(firstChar(remainder)) {
+		return Pair{}, "", fail(line, remainder, fmt.Sprintf("unexpected %q in unquoted value", firstChar(remainder)))
+	}
+
+	return Pair{Key: key, Value: value}, remainder, nil
+}
+
+// quoted parses a double quoted value, returning the unescaped contents.
+//
+// If the value contains no escapes, the returned contents are a slice of the
+// input so no allocation takes place.
+func quoted(line, input string) (string, string, error) {
+	rest := input[1:]
+
+	var builder *strings.Builder // Only allocated if we actually need to unescape
+
+	for {
+		chunk, remainder, _ := plain(rest)
+		if builder != nil {
+			builder.WriteString(chunk)
+		}
+		rest = remainder
+
+		if rest == "" {
+			return "", "", fail(line, input, "unterminated quoted value")
+		}
+
+		switch c := rest[0]; {
+		case c == '"':
+			if builder == nil {
+				return input[1 : len(input)-len(rest)], rest[1:], nil
+			}
+			return builder.String(), rest[1:], nil
+
+		case c == '\\':
+			if builder == nil {
+				builder = &strings.Builder{}
+				builder.WriteString(input[1 : len(input)-len(rest)])
+			}
+
+			if len(rest) < 2 {
+				return "", "", fail(line, input, "unterminated quoted value")
+			}
+
+			switch escape := rest[1]; escape {
+			case '"', '\\', '/':
+				builder.WriteByte(escape)
+				rest = rest[2:]
+			case 'b':
+				builder.WriteByte('\b')
+				rest = rest[2:]
+			case 'f':
+				builder.WriteByte('\f')
+				rest = rest[2:]
+			case 'n':
+				builder.WriteByte('\n')
+				rest = rest[2:]
+			case 'r':
+				builder.WriteByte('\r')
+				rest = rest[2:]
+			case 't':
+				builder.WriteByte('\t')
+				rest = rest[2:]
+			case 'u':
+				digits, remainder, err := hex(rest[2:])
+				if err != nil {
+					return "", "", fail(line, rest, "invalid unicode escape")
+				}
+
+				r := decodeHex(digits)
+				rest = remainder
+
+				if utf16.IsSurrogate(r) {
+					r = utf8.RuneError
+					if strings.HasPrefix(rest, `\u`) {
+						if low, remainder, err := hex(rest[2:]); err == nil {
+							if combined := utf16.DecodeRune(decodeHex(digits), decodeHex(low)); combined != utf8.RuneError {
+								r = combined
+								rest = remainder
+							}
+						}
+					}
+				}
+
+				builder.WriteRune(r)
+			default:
+				return "", "", fail(line, rest, fmt.Sprintf("invalid escape character %q in quoted value", firstChar(rest[1:])))
+			}
+
+		default:
+			// Must be a control char
+			return "", "", fail(line, rest, fmt.Sprintf("invalid control character %q in quoted value", c))
+		}
+	}
+}
+
+// fail returns a [SyntaxError] at the start of rest within line.
+func fail(line, rest, msg string) error {
+	return &SyntaxError{
+		Msg:    msg,
+		Column: 1 + utf8.RuneCountInString(line[:len(line)-len(rest)]),
+	}
+}
+
+// span returns a [Parser] that consumes a run of chars for which the predicate returns true.
+//
+// Unlike [parser.TakeWhile], an empty run is not an error, so there's no need to allocate one
+// when the run ends immediately, which keeps the common case in this package allocation free.
+func span(predicate func(r rune) bool) parser.Parser[string] {
+	take := parser.TakeWhile(predicate)
+	return func(input string) (string, string, error) {
+		if input == "" || !predicate(firstChar(input)) {
+			return "", input, nil
+		}
+		return take(input)
+	}
+}
+
+// isSpace reports whether r separates pairs, any control char or space counts.
+func isSpace(r rune) bool {
+	return r <= ' '
+}
+
+// isKeyChar reports whether r may appear in a key.
+func isKeyChar(r rune) bool {
+	return r > ' ' && r != '=' && r != '"'
+}
+
+// isValueChar reports whether r may appear in an unquoted value.
+func isValueChar(r rune) bool {
+	return r > ' ' && r != '"'
+}
+
+// isPlain reports whether r needs no special handling inside a quoted value.
+func isPlain(r rune) bool {
+	return r >= ' ' && r != '"' && r != '\\'
+}
+
+// firstChar returns the first utf-8 char in s.
+func firstChar(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// decodeHex decodes 4 hex digits into a rune.
+func decodeHex(digits string) rune {
+	var r rune
+	for i := range len(digits) {
+		c := digits[i]
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		default:
+			c = c - 'A' + 10
+		}
+		r = r<<4 | rune(c)
+	}
+
+	return r
+}
+
+var (
+	// whitespace parses optional whitespace between pairs.
+	whitespace = span(isSpace)
+
+	// key parses a key, which may be empty.
+	key = span(isKeyChar)
+
+	// unquoted parses an unquoted value, which may contain '='.
+	unquoted = span(isValueChar)
+
+	// plain parses a run of chars inside a quoted value other than the closing quote,
+	// an escape or a control char.
+	plain = span(isPlain)
+
+	// hex parses the 4 hex digits of a unicode escape.
+	hex = parser.SkipCount(parser.OneOf("0123456789abcdefABCDEF"), 4)
+)
@@ -0,0 +1,363 @@
+package logfmt_test
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser/logfmt"
+)
+
+func TestPairs(t *testing.T) {
+	tests := []struct {
+		name    string        // Identifying test case name
+		input   string        // Line to parse
+		err     string        // The expected error message, if there was one
+		want    []logfmt.Pair // The expected pairs, up to the error if there was one
+		wantErr bool          // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			want:    nil,
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "simple",
+			input: "level=info msg=hello dur=3ms",
+			want: []logfmt.Pair{
+				{Key: "level", Value: "info"},
+				{Key: "msg", Value: "hello"},
+				{Key: "dur", Value: "3ms"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "whitespace",
+			input: "  a=1 \t b=2\r\n",
+			want: []logfmt.Pair{
+				{Key: "a", Value: "1"},
+				{Key: "b", Value: "2"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "quoted",
+			input: `msg="hello world" empty="" url="http://x/?a=b"`,
+			want: []logfmt.Pair{
+				{Key: "msg", Value: "hello world"},
+				{Key: "empty", Value: ""},
+				{Key: "url", Value: "http://x/?a=b"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "escapes",
+			input: `msg="say \"hi\"\n\ttab é 😀 \\"`,
+			want: []logfmt.Pair{
+				{Key: "msg", Value: "say \"hi\"\n\ttab é 😀 \\"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "bare and empty",
+			input: "cached a= b=2 retry",
+			want: []logfmt.Pair{
+				{Key: "cached", Bare: true},
+				{Key: "a", Value: ""},
+				{Key: "b", Value: "2"},
+				{Key: "retry", Bare: true},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "unquoted with equals",
+			input: "query=a=b path=/日本",
+			want: []logfmt.Pair{
+				{Key: "query", Value: "a=b"},
+				{Key: "path", Value: "/日本"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "missing key",
+			input:   "a=1 =2",
+			want:    []logfmt.Pair{{Key: "a", Value: "1"}},
+			wantErr: true,
+			err:     `logfmt: column 5: unexpected '=', expected a key`,
+		},
+		{
+			name:    "unterminated",
+			input:   `日本=1 msg="oops`,
+			want:    []logfmt.Pair{{Key: "日本", Value: "1"}},
+			wantErr: true,
+			err:     "logfmt: column 10: unterminated quoted value",
+		},
+		{
+			name:    "junk after quote",
+			input:   `msg="hi"there`,
+			want:    nil,
+			wantErr: true,
+			err:     `logfmt: column 9: unexpected 't' after quoted value`,
+		},
+		{
+			name:    "quote in unquoted value",
+			input:   `msg=hi"there"`,
+			want:    nil,
+			wantErr: true,
+			err:     `logfmt: column 7: unexpected '"' in unquoted value`,
+		},
+		{
+			name:    "quote after key",
+			input:   `msg"hi"`,
+			want:    nil,
+			wantErr: true,
+			err:     `logfmt: column 4: unexpected '"' after key "msg"`,
+		},
+		{
+			name:    "bad escape",
+			input:   `msg="\x"`,
+			want:    nil,
+			wantErr: true,
+			err:     `logfmt: column 6: invalid escape character 'x' in quoted value`,
+		},
+		{
+			name:    "bad utf8",
+			input:   "\xf8\xa1\xa1\xa1\xa1",
+			want:    nil,
+			wantErr: true,
+			err:     "logfmt: input not valid utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got []logfmt.Pair
+				err error
+			)
+
+			for pair, pairErr := range logfmt.Pairs(tt.input) {
+				if pairErr != nil {
+					err = pairErr
+					break
+				}
+				got = append(got, pair)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nPairs:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPairsBreak(t *testing.T) {
+	count := 0
+	for range logfmt.Pairs("a=1 b=2 c=3") {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("iterator yielded %d pairs after break, wanted 2", count)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	var err error
+	for _, pairErr := range logfmt.Pairs(`a="oops`) {
+		err = pairErr
+	}
+
+	var syntaxErr *logfmt.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Error was not a *logfmt.SyntaxError, got %T", err)
+	}
+
+	if syntaxErr.Column != 3 {
+		t.Errorf("Column = %d, wanted 3", syntaxErr.Column)
+	}
+}
+
+type request struct {
+	Addr     netip.Addr    `logfmt:"addr"`
+	Level    string        `logfmt:"level"`
+	Message  string        `logfmt:"msg"`
+	Ignored  string        `logfmt:"-"`
+	Path     string        // No tag, matched on the field name
+	Duration time.Duration `logfmt:"dur"`
+	Bytes    uint64        `logfmt:"bytes"`
+	Ratio    float64       `logfmt:"ratio"`
+	Status   int16         `logfmt:"status"`
+	Cached   bool          `logfmt:"cached"`
+	Retried  bool          `logfmt:"retried"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	line := `level=info msg="GET done" path=/ dur=1.5s status=200 bytes=512 ratio=0.25 ` +
+		`cached retried=false addr=127.0.0.1 Ignored=yes unknown=x`
+
+	var got request
+	if err := logfmt.Unmarshal(line, &got); err != nil {
+		t.Fatalf("Unmarshal returned an unexpected error: %v", err)
+	}
+
+	want := request{
+		Level:    "info",
+		Message:  "GET done",
+		Path:     "/",
+		Duration: 1500 * time.Millisecond,
+		Status:   200,
+		Bytes:    512,
+		Ratio:    0.25,
+		Cached:   true,
+		Retried:  false,
+		Addr:     netip.MustParseAddr("127.0.0.1"),
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nGot:\t%+v\nWanted:\t%+v\n", got, want)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		target any    // What to unmarshal into
+		name   string // Identifying test case name
+		input  string // Line to parse
+		err    string // The expected error message
+	}{
+		{
+			name:   "not a pointer",
+			input:  "a=1",
+			target: request{},
+			err:    "logfmt: Unmarshal requires a non-nil pointer to a struct, got logfmt_test.request",
+		},
+		{
+			name:   "nil pointer",
+			input:  "a=1",
+			target: (*request)(nil),
+			err:    "logfmt: Unmarshal requires a non-nil pointer to a struct, got *logfmt_test.request",
+		},
+		{
+			name:   "empty int",
+			input:  "status=",
+			target: &request{},
+			err:    `logfmt: cannot decode status="" into int16: strconv.ParseInt: parsing "": invalid syntax`,
+		},
+		{
+			name:   "bad int",
+			input:  "status=ok",
+			target: &request{},
+			err:    `logfmt: cannot decode status="ok" into int16: strconv.ParseInt: parsing "ok": invalid syntax`,
+		},
+		{
+			name:   "out of range",
+			input:  "status=40000",
+			target: &request{},
+			err:    `logfmt: cannot decode status="40000" into int16: strconv.ParseInt: parsing "40000": value out of range`,
+		},
+		{
+			name:   "bad duration",
+			input:  "dur=soon",
+			target: &request{},
+			err:    `logfmt: cannot decode dur="soon" into time.Duration: time: invalid duration "soon"`,
+		},
+		{
+			name:   "unsupported",
+			input:  "a=1",
+			target: &struct{ A []string }{},
+			err:    `logfmt: cannot decode a="1" into []string: unsupported field type`,
+		},
+		{
+			name:   "bad syntax",
+			input:  `msg="oops`,
+			target: &request{},
+			err:    "logfmt: column 5: unterminated quoted value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := logfmt.Unmarshal(tt.input, tt.target)
+			if err == nil {
+				t.Fatal("Unmarshal returned nil error, wanted one")
+			}
+
+			if msg := err.Error(); msg != tt.err {
+				t.Errorf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrorUnwrap(t *testing.T) {
+	var got request
+
+	err := logfmt.Unmarshal("status=ok", &got)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Unmarshal error %v did not wrap strconv.ErrSyntax", err)
+	}
+}
+
+func ExamplePairs() {
+	line := `level=info msg="hello world" dur=3ms cached`
+
+	for pair, err := range logfmt.Pairs(line) {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		fmt.Printf("%s: %q (bare: %v)\n", pair.Key, pair.Value, pair.Bare)
+	}
+
+	// Output: level: "info" (bare: false)
+	// msg: "hello world" (bare: false)
+	// dur: "3ms" (bare: false)
+	// cached: "" (bare: true)
+}
+
+func ExampleUnmarshal() {
+	type Entry struct {
+		Level    string        `logfmt:"level"`
+		Message  string        `logfmt:"msg"`
+		Duration time.Duration `logfmt:"dur"`
+		Cached   bool          `logfmt:"cached"`
+	}
+
+	var entry Entry
+	if err := logfmt.Unmarshal(`level=info msg="hello world" dur=3ms cached`, &entry); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Printf("%+v\n", entry)
+
+	// Output: {Level:info Message:hello world Duration:3ms Cached:true}
+}
@@ -0,0 +1,195 @@
+package logfmt
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UnmarshalError is the error returned by [Unmarshal] when a value cannot be decoded into
+// the struct field for its key.
+type UnmarshalError struct {
+	Err   error        // The underlying error from decoding the value
+	Type  reflect.Type // The type of the field
+	Key   string       // The key of the pair
+	Value string       // The value that could not be decoded
+}
+
+// Error implements the error interface for [UnmarshalError].
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("logfmt: cannot decode %s=%q into %s: %v", e.Key, e.Value, e.Type, e.Err)
+}
+
+// Unwrap returns the underlying decoding error.
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal parses a single logfmt line and stores the values in the struct pointed to by v.
+//
+// Each pair is stored in the exported struct field whose `logfmt` tag matches the key, fields
+// without a tag match a key equal to the field name ignoring case, and fields tagged `logfmt:"-"`
+// are ignored. Pairs with no matching field are skipped, and if a key appears more than once, the
+// last value wins.
+//
+// Fields may be strings, bools, any sized int, uint or float, a [time.Duration] (parsed with
+// [time.ParseDuration]), or implement [encoding.TextUnmarshaler]. A bare key decodes as true
+// into a bool field.
+//
+// Syntax errors are a [*SyntaxError], and values that can't be decoded into their field
+// are an [*UnmarshalError].
+func Unmarshal(line string, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("logfmt: Unmarshal requires a non-nil pointer to a struct, got %T", v)
+	}
+
+	target = target.Elem()
+	fields := fieldsFor(target.Type())
+
+	for pair, err := range Pairs(line) {
+		if err != nil {
+			return err
+		}
+
+		index, ok := fields.lookup(pair.Key)
+		if !ok {
+			continue
+		}
+
+		field := target.FieldByIndex(index)
+		if err := decode(field, pair); err != nil {
+			return &UnmarshalError{
+				Key:   pair.Key,
+				Value: pair.Value,
+				Type:  field.Type(),
+				Err:   err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// decode stores the value from pair into field.
+func decode(field reflect.Value, pair Pair) error {
+	if field.CanAddr() {
+		if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(pair.Value))
+		}
+	}
+
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(pair.Value)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(duration))
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(pair.Value)
+	case reflect.Bool:
+		if pair.Bare {
+			field.SetBool(true)
+			return nil
+		}
+
+		b, err := strconv.ParseBool(pair.Value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(pair.Value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(pair.Value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(pair.Value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(f)
+	default:
+		return errors.New("unsupported field type")
+	}
+
+	return nil
+}
+
+// durationType is the [reflect.Type] of [time.Duration], which is decoded specially.
+var durationType = reflect.TypeFor[time.Duration]()
+
+// fieldSet maps keys to the index of the struct field they decode into.
+type fieldSet struct {
+	tagged map[string][]int // Fields with an explicit tag, matched exactly
+	named  map[string][]int // Untagged fields by lower case name, matched ignoring case
+}
+
+// lookup returns the index of the field for key.
+func (f *fieldSet) lookup(key string) ([]int, bool) {
+	if index, ok := f.tagged[key]; ok {
+		return index, true
+	}
+
+	index, ok := f.named[strings.ToLower(key)]
+
+	return index, ok
+}
+
+// fieldCache caches the fieldSet for each struct type passed to [Unmarshal].
+var fieldCache sync.Map // map[reflect.Type]*fieldSet
+
+// fieldsFor returns the fieldSet for a struct type, building it if this is the first time
+// we've seen it.
+func fieldsFor(typ reflect.Type) *fieldSet {
+	if cached, ok := fieldCache.Load(typ); ok {
+		return cached.(*fieldSet) //nolint:forcetypeassert // Only fieldSets are stored
+	}
+
+	fields := &fieldSet{
+		tagged: make(map[string][]int),
+		named:  make(map[string][]int),
+	}
+
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("logfmt")
+		switch {
+		case tag == "-":
+			continue
+		case ok && tag != "":
+			fields.tagged[tag] = field.Index
+		default:
+			fields.named[strings.ToLower(field.Name)] = field.Index
+		}
+	}
+
+	cached, _ := fieldCache.LoadOrStore(typ, fields)
+
+	return cached.(*fieldSet) //nolint:forcetypeassert // Only fieldSets are stored
+}
@@ -0,0 +1,58 @@
+package netaddr_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/netaddr"
+)
+
+// benchLog is a chunk of log text with addresses scattered through it.
+var benchLog = strings.Repeat(
+	"2024-01-01T12:00:00Z accepted 10.0.0.1:5432 from [2001:db8::7]:60000 via aa:bb:cc:dd:ee:ff\n", 200,
+)
+
+func BenchmarkIPv4(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := netaddr.IPv4("192.168.100.200"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIPv6(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := netaddr.IPv6("2001:db8:85a3::8a2e:370:7334"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHostPort(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := netaddr.HostPort("[fe80::1%eth0]:443"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMAC(b *testing.B) {
+	for b.Loop() {
+		if _, _, err := netaddr.MAC("00:00:5e:00:53:01"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindAll(b *testing.B) {
+	b.SetBytes(int64(len(benchLog)))
+
+	for b.Loop() {
+		for _, err := range parser.FindAll(netaddr.HostPort, benchLog) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package netaddr_test
+
+// The fuzz tests in here check that the parsers never panic and that they agree with
+// the standard library about what is and isn't a valid address.
+//
+// The parsers in this package only need to match a prefix of their input, so agreement
+// means: whenever the standard library accepts the whole input, so must we (consuming all
+// of it, with the same result), and whenever we consume the whole input, the standard
+// library must accept it.
+
+import (
+	"bytes"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/netaddr"
+)
+
+// seeds are inputs for all the fuzz tests.
+var seeds = []string{
+	"192.168.0.1",
+	"1.2.3.4:80",
+	"10.0.0.0/8",
+	"2001:db8::1",
+	"::ffff:1.2.3.4",
+	"fe80::1%eth0",
+	"[fe80::1%eth0]:443",
+	"2001:db8::/32",
+	"1:2:3:4:5:6:7:8",
+	"1.2.3.4.5",
+	"1:2:3:4:5:6:7:8:9",
+	"1:2:3:4:5:6:7:8::",
+	"::ffff:1.2.3.4.5",
+	"00:00:5e:00:53:01",
+	"0000.5e00.5301",
+	"02-00-5e-10-00-00-00-01",
+}
+
+// zoneOK reports whether the zone of addr (if any) only contains chars we accept in a zone,
+// netip accepts absolutely anything.
+func zoneOK(addr netip.Addr) bool {
+	return !strings.ContainsFunc(addr.Zone(), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && !strings.ContainsRune("-._~", r)
+	})
+}
+
+func FuzzIP(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := netaddr.IP(input)
+		want, stdErr := netip.ParseAddr(input)
+
+		if stdErr == nil && zoneOK(want) {
+			if err != nil || rest != "" {
+				t.Fatalf("netip accepted %q as %s, but IP returned (%s, %q, %v)", input, want, got, rest, err)
+			}
+
+			if got != want {
+				t.Fatalf("IP(%q) = %s, netip got %s", input, got, want)
+			}
+		}
+
+		if err == nil && rest == "" && stdErr != nil {
+			t.Fatalf("IP accepted %q as %s, but netip rejected it: %v", input, got, stdErr)
+		}
+
+		if err == nil && continuesAddress(got, rest) {
+			t.Fatalf("IP(%q) stopped partway through an address at %s, leaving %q", input, got, rest)
+		}
+	})
+}
+
+// continuesAddress reports whether rest starts with something that would make addr part of a
+// longer, invalid, address: a '.' and a digit after an IPv4 address, or a ':' followed by a hex
+// digit or another ':' after an IPv6 one without a zone.
+func continuesAddress(addr netip.Addr, rest string) bool {
+	if len(rest) < 2 {
+		return false
+	}
+
+	if addr.Is4() {
+		return rest[0] == '.' && rest[1] >= '0' && rest[1] <= '9'
+	}
+
+	return addr.Zone() == "" && rest[0] == ':' && strings.ContainsRune("0123456789abcdefABCDEF:", rune(rest[1]))
+}
+
+func FuzzCIDR(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := netaddr.CIDR(input)
+		want, stdErr := netip.ParsePrefix(input)
+
+		if stdErr == nil {
+			if err != nil || rest != "" {
+				t.Fatalf("netip accepted %q as %s, but CIDR returned (%s, %q, %v)", input, want, got, rest, err)
+			}
+
+			if got != want {
+				t.Fatalf("CIDR(%q) = %s, netip got %s", input, got, want)
+			}
+		}
+
+		if err == nil && rest == "" && stdErr != nil {
+			t.Fatalf("CIDR accepted %q as %s, but netip rejected it: %v", input, got, stdErr)
+		}
+	})
+}
+
+func FuzzHostPort(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := netaddr.HostPort(input)
+		want, stdErr := netip.ParseAddrPort(input)
+
+		if stdErr == nil && zoneOK(want.Addr()) {
+			if err != nil || rest != "" {
+				t.Fatalf("netip accepted %q as %s, but HostPort returned (%s, %q, %v)", input, want, got, rest, err)
+			}
+
+			if got != want {
+				t.Fatalf("HostPort(%q) = %s, netip got %s", input, got, want)
+			}
+		}
+
+		if err == nil && rest == "" && stdErr != nil {
+			t.Fatalf("HostPort accepted %q as %s, but netip rejected it: %v", input, got, stdErr)
+		}
+	})
+}
+
+func FuzzMAC(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, rest, err := netaddr.MAC(input)
+		want, stdErr := net.ParseMAC(input)
+
+		if stdErr == nil {
+			if err != nil || rest != "" {
+				t.Fatalf("net accepted %q as %s, but MAC returned (%s, %q, %v)", input, want, got, rest, err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Fatalf("MAC(%q) = %s, net got %s", input, got, want)
+			}
+		}
+
+		if err == nil && rest == "" && stdErr != nil {
+			t.Fatalf("MAC accepted %q as %s, but net rejected it: %v", input, got, stdErr)
+		}
+	})
+}
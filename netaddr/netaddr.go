@@ -0,0 +1,451 @@
+// Package netaddr implements prefix parsers for network addresses, built on the combinators in [parser].
+//
+// Unlike [netip.ParseAddr] and friends, which need to be handed exactly the text of an address,
+// the parsers in this package recognise an address at the start of their input and return the
+// remainder, so they can find addresses embedded in larger text:
+//
+//	for match, err := range parser.FindAll(netaddr.IPv4, "connect from 10.0.0.1 to 10.0.0.2") {
+//		...
+//	}
+//
+// Each parser is a plain function with the [parser.Parser] signature, so they can be passed
+// directly to any combinator. The accepted syntax matches the standard library exactly, and
+// this is checked by fuzzing against [net/netip] and [net.ParseMAC].
+package netaddr // import "go.followtheprocess.codes/parser/netaddr"
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// IPv4 parses a dotted decimal IPv4 address like 192.168.0.1 from the start of input.
+//
+// Each of the 4 octets must be between 0 and 255 with no leading zeros. Any digits following
+// the last octet are part of it, so 1.2.3.4567 is an error rather than 1.2.3.45 with "67" remaining.
+// Likewise a '.' and a digit after it would be a fifth octet, so 1.2.3.4.5 is an error too, but a
+// '.' that isn't followed by a digit ends the address.
+func IPv4(input string) (netip.Addr, string, error) {
+	octets, rest, err := ipv4(input)
+	if err != nil {
+		return netip.Addr{}, "", err
+	}
+
+	return netip.AddrFrom4(octets), rest, nil
+}
+
+// IPv6 parses an IPv6 address like 2001:db8::1 from the start of input.
+//
+// All the textual forms from RFC 4291 are supported, including "::" to elide groups of zeros and
+// a trailing embedded IPv4 address like ::ffff:192.168.0.1, as is an optional zone like fe80::1%eth0.
+// Zones may contain ASCII letters, digits and any of "-._~".
+//
+// A single ':' that isn't followed by another group ends the address, so "::1: connected" parses
+// as ::1 with ": connected" remaining. After the 8th group though, a ':' followed by a hex digit
+// or another ':' would make the address too long, so 1:2:3:4:5:6:7:8:9 is an error.
+func IPv6(input string) (netip.Addr, string, error) {
+	groups, rest, err := ipv6(input)
+	if err != nil {
+		return netip.Addr{}, "", err
+	}
+
+	addr := netip.AddrFrom16(groups)
+
+	if _, afterPercent, err := percent(rest); err == nil {
+		name, remainder, err := zone(afterPercent)
+		if err != nil {
+			return netip.Addr{}, "", errMissingZone
+		}
+
+		addr = addr.WithZone(name)
+		rest = remainder
+	}
+
+	return addr, rest, nil
+}
+
+// IP parses either an [IPv4] or an [IPv6] address from the start of input.
+func IP(input string) (netip.Addr, string, error) {
+	addr, rest, v4Err := IPv4(input)
+	if v4Err == nil {
+		return addr, rest, nil
+	}
+
+	addr, rest, v6Err := IPv6(input)
+	if v6Err == nil {
+		return addr, rest, nil
+	}
+
+	// Report the error from whichever one the input looked most like
+	if _, afterDigits, err := decimal(input); err == nil {
+		if _, _, err := dot(afterDigits); err == nil {
+			return netip.Addr{}, "", v4Err
+		}
+	}
+
+	if errors.Is(v6Err, errNoIPv6) {
+		return netip.Addr{}, "", errNoIP
+	}
+
+	return netip.Addr{}, "", v6Err
+}
+
+// CIDR parses an IP network prefix in CIDR notation like 10.0.0.0/8 or 2001:db8::/32 from
+// the start of input.
+//
+// The prefix length must be in range for the address family and may not have leading zeros,
+// and IPv6 zones are not allowed. Like [netip.ParsePrefix], the address is not masked so
+// 10.1.2.3/8 is returned as is.
+func CIDR(input string) (netip.Prefix, string, error) {
+	addr, rest, err := IP(input)
+	if err != nil {
+		if errors.Is(err, errNoIP) {
+			return netip.Prefix{}, "", errNoCIDR
+		}
+		return netip.Prefix{}, "", fmt.Errorf("CIDR: %w", err)
+	}
+
+	if addr.Zone() != "" {
+		return netip.Prefix{}, "", errors.New("CIDR: IPv6 zones are not allowed in a prefix")
+	}
+
+	_, rest, err = slash(rest)
+	if err != nil {
+		return netip.Prefix{}, "", errors.New("CIDR: expected '/' after address")
+	}
+
+	digits, rest, err := decimal(rest)
+	if err != nil {
+		return netip.Prefix{}, "", errors.New("CIDR: expected prefix length after '/'")
+	}
+
+	bits, err := strconv.Atoi(digits)
+	if err != nil || bits > addr.BitLen() || (len(digits) > 1 && digits[0] == '0') {
+		return netip.Prefix{}, "", fmt.Errorf("CIDR: invalid prefix length %q for %s", digits, addr)
+	}
+
+	return netip.PrefixFrom(addr, bits), rest, nil
+}
+
+// HostPort parses an IP address and port like 10.0.0.1:80 or [::1]:443 from the start of input.
+//
+// IPv6 addresses must be enclosed in square brackets, and may have a zone. The port must be
+// a decimal number no larger than 65535.
+func HostPort(input string) (netip.AddrPort, string, error) {
+	var (
+		addr netip.Addr
+		rest string
+		err  error
+	)
+
+	if _, afterBracket, bracketErr := openBracket(input); bracketErr == nil {
+		addr, rest, err = IPv6(afterBracket)
+		if err != nil {
+			return netip.AddrPort{}, "", fmt.Errorf("HostPort: %w", err)
+		}
+
+		if _, rest, err = closeBracket(rest); err != nil {
+			return netip.AddrPort{}, "", errors.New("HostPort: expected ']' after IPv6 address")
+		}
+	} else {
+		addr, rest, err = IPv4(input)
+		if err != nil {
+			if errors.Is(err, errNoIPv4) {
+				return netip.AddrPort{}, "", errNoHostPort
+			}
+			return netip.AddrPort{}, "", fmt.Errorf("HostPort: %w", err)
+		}
+	}
+
+	_, rest, err = colon(rest)
+	if err != nil {
+		return netip.AddrPort{}, "", errors.New("HostPort: expected ':' before port")
+	}
+
+	digits, rest, err := decimal(rest)
+	if err != nil {
+		return netip.AddrPort{}, "", errors.New("HostPort: expected port number after ':'")
+	}
+
+	port, err := strconv.ParseUint(digits, 10, 16)
+	if err != nil {
+		return netip.AddrPort{}, "", fmt.Errorf("HostPort: invalid port %q", digits)
+	}
+
+	return netip.AddrPortFrom(addr, uint16(port)), rest, nil
+}
+
+// MAC parses a hardware address from the start of input, in any of the formats accepted by
+// [net.ParseMAC]:
+//
+//	00:00:5e:00:53:01
+//	00-00-5e-00-53-01
+//	0000.5e00.5301
+//	00005e005301
+//
+// IEEE 802 MAC-48, EUI-48, EUI-64, or a 20-octet IP over InfiniBand link-layer address are allowed.
+func MAC(input string) (net.HardwareAddr, string, error) {
+	first, rest, err := hexDigits(input)
+	if err != nil {
+		return nil, "", errNoMAC
+	}
+
+	var sep string // The separator between groups
+	switch len(first) {
+	case 12, 16, 40:
+		// No separators at all
+		return decodeMAC(first), rest, nil
+	case 2:
+		sep = ":"
+		if strings.HasPrefix(rest, "-") {
+			sep = "-"
+		}
+	case 4:
+		sep = "."
+	default:
+		return nil, "", fmt.Errorf("MAC: invalid group %q, expected 2 or 4 hex digits", first)
+	}
+
+	groups := []string{first}
+	for {
+		afterSep, ok := strings.CutPrefix(rest, sep)
+		if !ok {
+			break
+		}
+
+		group, remainder, err := hexDigits(afterSep)
+		if err != nil {
+			// A trailing separator isn't part of the address
+			break
+		}
+
+		if len(group) != len(first) {
+			return nil, "", fmt.Errorf("MAC: invalid group %q, expected %d hex digits", group, len(first))
+		}
+
+		groups = append(groups, group)
+		rest = remainder
+	}
+
+	decoded := strings.Join(groups, "")
+	switch len(decoded) / 2 {
+	case 6, 8, 20:
+	default:
+		return nil, "", fmt.Errorf("MAC: invalid address length of %d bytes", len(decoded)/2)
+	}
+
+	return decodeMAC(decoded), rest, nil
+}
+
+// decodeMAC decodes a string of hex digit pairs into a hardware address.
+func decodeMAC(digits string) net.HardwareAddr {
+	addr := make(net.HardwareAddr, len(digits)/2)
+	for i := range addr {
+		b, _ := strconv.ParseUint(digits[2*i:2*i+2], 16, 8) // Can't fail, they're all hex digits
+		addr[i] = byte(b)
+	}
+
+	return addr
+}
+
+// ipv4 parses the 4 octets of a dotted decimal IPv4 address.
+func ipv4(input string) ([4]byte, string, error) {
+	var octets [4]byte
+
+	rest := input
+	for i := range octets {
+		if i > 0 {
+			_, afterDot, err := dot(rest)
+			if err != nil {
+				return [4]byte{}, "", fmt.Errorf("IPv4: expected 4 octets, got %d", i)
+			}
+			rest = afterDot
+		}
+
+		digits, remainder, err := decimal(rest)
+		if err != nil {
+			if i == 0 {
+				return [4]byte{}, "", errNoIPv4
+			}
+			return [4]byte{}, "", fmt.Errorf("IPv4: expected octet %d", i+1)
+		}
+
+		if len(digits) > 1 && digits[0] == '0' {
+			return [4]byte{}, "", fmt.Errorf("IPv4: octet %q has a leading zero", digits)
+		}
+
+		n, err := strconv.ParseUint(digits, 10, 8)
+		if err != nil {
+			return [4]byte{}, "", fmt.Errorf("IPv4: octet %q out of range", digits)
+		}
+
+		octets[i] = byte(n)
+		rest = remainder
+	}
+
+	if afterDot, ok := strings.CutPrefix(rest, "."); ok && afterDot != "" && isDigit(rune(afterDot[0])) {
+		return [4]byte{}, "", errors.New("IPv4: more than 4 octets")
+	}
+
+	return octets, rest, nil
+}
+
+// ipv6 parses the 16 bytes of an IPv6 address, without any zone.
+func ipv6(input string) ([16]byte, string, error) {
+	var addr [16]byte
+
+	ellipsis := -1 // Byte index of the "::", if there is one
+	filled := 0    // Number of bytes filled so far
+	rest := input
+
+	if afterEllipsis, ok := strings.CutPrefix(rest, "::"); ok {
+		ellipsis = 0
+		rest = afterEllipsis
+	}
+
+	for filled < len(addr) {
+		digits, remainder, err := hexDigits(rest)
+		if err != nil {
+			if ellipsis == filled {
+				// Nothing after the "::" is fine, e.g. "::" or "2001:db8::"
+				break
+			}
+			return [16]byte{}, "", errNoIPv6
+		}
+
+		if _, _, err := dot(remainder); err == nil {
+			// This must be an embedded IPv4 address, so the group was actually its first octet
+			if filled > len(addr)-4 {
+				return [16]byte{}, "", errors.New("IPv6: too many groups before embedded IPv4 address")
+			}
+
+			octets, remainder, err := ipv4(rest)
+			if err != nil {
+				return [16]byte{}, "", fmt.Errorf("IPv6: invalid embedded IPv4 address: %w", err)
+			}
+
+			copy(addr[filled:], octets[:])
+			filled += len(octets)
+			rest = remainder
+
+			break
+		}
+
+		if len(digits) > 4 {
+			return [16]byte{}, "", fmt.Errorf("IPv6: group %q has more than 4 hex digits", digits)
+		}
+
+		group, _ := strconv.ParseUint(digits, 16, 16) // Can't fail, it's at most 4 hex digits
+
+		addr[filled] = byte(group >> 8)
+		addr[filled+1] = byte(group)
+		filled += 2
+		rest = remainder
+
+		if filled == len(addr) {
+			break
+		}
+
+		if afterEllipsis, ok := strings.CutPrefix(rest, "::"); ok {
+			if ellipsis != -1 {
+				return [16]byte{}, "", errors.New("IPv6: multiple '::' in address")
+			}
+
+			ellipsis = filled
+			rest = afterEllipsis
+
+			continue
+		}
+
+		afterColon, ok := strings.CutPrefix(rest, ":")
+		if !ok {
+			break
+		}
+
+		if _, _, err := hexDigits(afterColon); err != nil {
+			// A trailing ':' isn't part of the address
+			break
+		}
+
+		rest = afterColon
+	}
+
+	// Whatever ended the address, a ':' followed by a hex digit or another ':' would carry it on
+	if afterColon, ok := strings.CutPrefix(rest, ":"); ok && afterColon != "" && (isHex(rune(afterColon[0])) || afterColon[0] == ':') {
+		if filled == len(addr) {
+			return [16]byte{}, "", errors.New("IPv6: more than 8 groups")
+		}
+
+		return [16]byte{}, "", errors.New("IPv6: unexpected ':' after the address")
+	}
+
+	if ellipsis == -1 {
+		if filled != len(addr) {
+			return [16]byte{}, "", fmt.Errorf("IPv6: expected 8 groups, got %d", filled/2)
+		}
+
+		return addr, rest, nil
+	}
+
+	if filled == len(addr) {
+		return [16]byte{}, "", errors.New("IPv6: '::' must replace at least one group")
+	}
+
+	// Shift everything after the "::" to the end, filling the gap with zeros
+	n := filled - ellipsis
+	copy(addr[len(addr)-n:], addr[ellipsis:filled])
+	clear(addr[ellipsis : len(addr)-n])
+
+	return addr, rest, nil
+}
+
+// isDigit reports whether r is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isHex reports whether r is an ASCII hex digit.
+func isHex(r rune) bool {
+	return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// isZoneChar reports whether r is allowed in an IPv6 zone, these are the unreserved
+// chars from RFC 6874.
+func isZoneChar(r rune) bool {
+	return isDigit(r) || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || strings.ContainsRune("-._~", r)
+}
+
+var (
+	// decimal parses a run of decimal digits.
+	decimal = parser.TakeWhile(isDigit)
+
+	// hexDigits parses a run of hex digits.
+	hexDigits = parser.TakeWhile(isHex)
+
+	// zone parses the name of an IPv6 zone.
+	zone = parser.TakeWhile(isZoneChar)
+
+	dot          = parser.Char('.')
+	colon        = parser.Char(':')
+	slash        = parser.Char('/')
+	percent      = parser.Char('%')
+	openBracket  = parser.Char('[')
+	closeBracket = parser.Char(']')
+)
+
+// Errors for when the input doesn't start with anything like the requested address, these
+// are the common case when searching through text, so they are preallocated.
+var (
+	errNoIPv4      = errors.New("IPv4: input does not start with an IPv4 address")
+	errNoIPv6      = errors.New("IPv6: input does not start with an IPv6 address")
+	errNoIP        = errors.New("IP: input does not start with an IP address")
+	errNoCIDR      = errors.New("CIDR: input does not start with an IP address")
+	errNoHostPort  = errors.New("HostPort: input does not start with an IP address")
+	errNoMAC       = errors.New("MAC: input does not start with a MAC address")
+	errMissingZone = errors.New("IPv6: missing zone after '%'")
+)
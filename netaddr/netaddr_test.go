@@ -0,0 +1,306 @@
+package netaddr_test
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/netaddr"
+)
+
+func TestIPv4(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected address, formatted with String
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "simple", input: "192.168.0.1", want: "192.168.0.1", rest: ""},
+		{name: "zeros", input: "0.0.0.0", want: "0.0.0.0", rest: ""},
+		{name: "max", input: "255.255.255.255", want: "255.255.255.255", rest: ""},
+		{name: "with remainder", input: "10.0.0.1: connected", want: "10.0.0.1", rest: ": connected"},
+		{name: "trailing dot", input: "10.0.0.1.", want: "10.0.0.1", rest: "."},
+		{name: "too many octets", input: "1.2.3.4.5", wantErr: true, err: "IPv4: more than 4 octets"},
+		{name: "dot then letters", input: "1.2.3.4.x", want: "1.2.3.4", rest: ".x"},
+		{name: "empty", input: "", wantErr: true, err: "IPv4: input does not start with an IPv4 address"},
+		{name: "letters", input: "abc", wantErr: true, err: "IPv4: input does not start with an IPv4 address"},
+		{name: "too few", input: "1.2.3", wantErr: true, err: "IPv4: expected 4 octets, got 3"},
+		{name: "missing octet", input: "1.2..4", wantErr: true, err: "IPv4: expected octet 3"},
+		{name: "out of range", input: "1.2.3.256", wantErr: true, err: `IPv4: octet "256" out of range`},
+		{name: "greedy digits", input: "1.2.3.4567", wantErr: true, err: `IPv4: octet "4567" out of range`},
+		{name: "leading zero", input: "01.2.3.4", wantErr: true, err: `IPv4: octet "01" has a leading zero`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := netaddr.IPv4(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestIPv6(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected address, formatted with String
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "full", input: "2001:0db8:0000:0000:0000:ff00:0042:8329", want: "2001:db8::ff00:42:8329", rest: ""},
+		{name: "compressed", input: "2001:db8::1", want: "2001:db8::1", rest: ""},
+		{name: "unspecified", input: "::", want: "::", rest: ""},
+		{name: "loopback", input: "::1", want: "::1", rest: ""},
+		{name: "trailing ellipsis", input: "2001:db8:: rest", want: "2001:db8::", rest: " rest"},
+		{name: "embedded ipv4", input: "::ffff:192.168.0.1", want: "::ffff:192.168.0.1", rest: ""},
+		{name: "embedded ipv4 full", input: "1:2:3:4:5:6:1.2.3.4", want: "1:2:3:4:5:6:102:304", rest: ""},
+		{name: "zone", input: "fe80::1%eth0 up", want: "fe80::1%eth0", rest: " up"},
+		{name: "trailing colon", input: "::1: connected", want: "::1", rest: ": connected"},
+		{name: "upper case", input: "FE80::ABCD", want: "fe80::abcd", rest: ""},
+		{name: "empty", input: "", wantErr: true, err: "IPv6: input does not start with an IPv6 address"},
+		{name: "too few groups", input: "1:2:3", wantErr: true, err: "IPv6: expected 8 groups, got 3"},
+		{name: "group too long", input: "12345::", wantErr: true, err: `IPv6: group "12345" has more than 4 hex digits`},
+		{name: "multiple ellipses", input: "1::2::3", wantErr: true, err: "IPv6: multiple '::' in address"},
+		{name: "too many groups", input: "1:2:3:4:5:6:7:8:9", wantErr: true, err: "IPv6: more than 8 groups"},
+		{name: "ellipsis after 8 groups", input: "1:2:3:4:5:6:7:8::", wantErr: true, err: "IPv6: more than 8 groups"},
+		{name: "triple colon", input: ":::1", wantErr: true, err: "IPv6: unexpected ':' after the address"},
+		{name: "colon after embedded ipv4", input: "::1.2.3.4:5", wantErr: true, err: "IPv6: unexpected ':' after the address"},
+		{name: "colon after 8 groups", input: "1:2:3:4:5:6:7:8: up", want: "1:2:3:4:5:6:7:8", rest: ": up"},
+		{name: "embedded ipv4 too many octets", input: "::ffff:1.2.3.4.5", wantErr: true, err: "IPv6: invalid embedded IPv4 address: IPv4: more than 4 octets"},
+		{name: "ellipsis replaces nothing", input: "1:2:3:4::5:6:7:8", wantErr: true, err: "IPv6: '::' must replace at least one group"},
+		{name: "missing zone", input: "fe80::1%", wantErr: true, err: "IPv6: missing zone after '%'"},
+		{
+			name:    "bad embedded ipv4",
+			input:   "::ffff:1.2.3.999",
+			wantErr: true,
+			err:     `IPv6: invalid embedded IPv4 address: IPv4: octet "999" out of range`,
+		},
+		{
+			name:    "embedded ipv4 too late",
+			input:   "1:2:3:4:5:6:7:1.2.3.4",
+			wantErr: true,
+			err:     "IPv6: too many groups before embedded IPv4 address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := netaddr.IPv6(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestIP(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected address, formatted with String
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "ipv4", input: "10.0.0.1 x", want: "10.0.0.1", rest: " x"},
+		{name: "ipv6", input: "1::2 x", want: "1::2", rest: " x"},
+		{name: "neither", input: "hello", wantErr: true, err: "IP: input does not start with an IP address"},
+		{name: "bad ipv4", input: "1.2.3.400", wantErr: true, err: `IPv4: octet "400" out of range`},
+		{name: "bad ipv6", input: "1:2:3", wantErr: true, err: "IPv6: expected 8 groups, got 3"},
+		{name: "version string", input: "1.2.3.4.5", wantErr: true, err: "IPv4: more than 4 octets"},
+		{name: "long ipv6", input: "1:2:3:4:5:6:7:8:9", wantErr: true, err: "IPv6: more than 8 groups"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := netaddr.IP(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestCIDR(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected prefix, formatted with String
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "ipv4", input: "10.0.0.0/8", want: "10.0.0.0/8", rest: ""},
+		{name: "unmasked", input: "10.1.2.3/8, next", want: "10.1.2.3/8", rest: ", next"},
+		{name: "ipv6", input: "2001:db8::/32", want: "2001:db8::/32", rest: ""},
+		{name: "zero bits", input: "0.0.0.0/0", want: "0.0.0.0/0", rest: ""},
+		{name: "missing slash", input: "10.0.0.0", wantErr: true, err: "CIDR: expected '/' after address"},
+		{name: "missing bits", input: "10.0.0.0/", wantErr: true, err: "CIDR: expected prefix length after '/'"},
+		{name: "too many bits", input: "10.0.0.0/33", wantErr: true, err: `CIDR: invalid prefix length "33" for 10.0.0.0`},
+		{name: "leading zero", input: "10.0.0.0/08", wantErr: true, err: `CIDR: invalid prefix length "08" for 10.0.0.0`},
+		{name: "zone", input: "fe80::%eth0/64", wantErr: true, err: "CIDR: IPv6 zones are not allowed in a prefix"},
+		{name: "no address", input: "/8", wantErr: true, err: "CIDR: input does not start with an IP address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := netaddr.CIDR(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected address and port, formatted with String
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "ipv4", input: "10.0.0.1:80", want: "10.0.0.1:80", rest: ""},
+		{name: "ipv6", input: "[::1]:443/path", want: "[::1]:443", rest: "/path"},
+		{name: "zone", input: "[fe80::1%eth0]:22", want: "[fe80::1%eth0]:22", rest: ""},
+		{name: "max port", input: "1.2.3.4:65535", want: "1.2.3.4:65535", rest: ""},
+		{name: "missing port", input: "1.2.3.4", wantErr: true, err: "HostPort: expected ':' before port"},
+		{name: "empty port", input: "1.2.3.4:", wantErr: true, err: "HostPort: expected port number after ':'"},
+		{name: "port too large", input: "1.2.3.4:65536", wantErr: true, err: `HostPort: invalid port "65536"`},
+		{name: "unclosed bracket", input: "[::1:80", wantErr: true, err: "HostPort: expected ']' after IPv6 address"},
+		{name: "unbracketed ipv6", input: "::1:80", wantErr: true, err: "HostPort: input does not start with an IP address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := netaddr.HostPort(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestMAC(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // Input to parse
+		want    string // The expected address, formatted with String
+		rest    string // The expected remainder
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "colons", input: "00:00:5e:00:53:01", want: "00:00:5e:00:53:01", rest: ""},
+		{name: "hyphens", input: "00-00-5E-00-53-01 up", want: "00:00:5e:00:53:01", rest: " up"},
+		{name: "dots", input: "0000.5e00.5301", want: "00:00:5e:00:53:01", rest: ""},
+		{name: "bare", input: "00005E005301", want: "00:00:5e:00:53:01", rest: ""},
+		{name: "eui64", input: "02:00:5e:10:00:00:00:01", want: "02:00:5e:10:00:00:00:01", rest: ""},
+		{name: "trailing separator", input: "00:00:5e:00:53:01:", want: "00:00:5e:00:53:01", rest: ":"},
+		{name: "mixed separators", input: "00:00-5e:00:53:01", wantErr: true, err: "MAC: invalid address length of 2 bytes"},
+		{name: "too short", input: "00:00:5e", wantErr: true, err: "MAC: invalid address length of 3 bytes"},
+		{name: "bad group", input: "00:0:5e:00:53:01", wantErr: true, err: `MAC: invalid group "0", expected 2 hex digits`},
+		{name: "bad first group", input: "000:00", wantErr: true, err: `MAC: invalid group "000", expected 2 or 4 hex digits`},
+		{name: "not hex", input: "zz:zz", wantErr: true, err: "MAC: input does not start with a MAC address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := netaddr.MAC(tt.input)
+			check(t, got, rest, err, tt.want, tt.rest, tt.err, tt.wantErr)
+		})
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	text := "accepted 10.0.0.1:5432 from [2001:db8::7]:60000, rejected 999.1.1.1 and 1.2.3.4:99999"
+
+	var got []string
+	for match, err := range parser.FindAll(netaddr.HostPort, text) {
+		if err != nil {
+			t.Fatalf("FindAll returned an unexpected error: %v", err)
+		}
+		got = append(got, match.Value.String())
+	}
+
+	want := []string{"10.0.0.1:5432", "[2001:db8::7]:60000"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("\nGot:\t%v\nWanted:\t%v\n", got, want)
+	}
+}
+
+func TestChain(t *testing.T) {
+	// The parsers compose with the core combinators, here a MAC address and an IP separated by a space
+	entry := parser.Map(
+		parser.Chain(
+			parser.Map(netaddr.MAC, func(mac net.HardwareAddr) (string, error) { return mac.String(), nil }),
+			parser.Char(' '),
+			parser.Map(netaddr.IP, func(addr netip.Addr) (string, error) { return addr.String(), nil }),
+		),
+		func(parts []string) (string, error) { return parts[0] + " -> " + parts[2], nil },
+	)
+
+	got, rest, err := entry("aa:bb:cc:dd:ee:ff fe80::1%en0 reachable")
+	if err != nil {
+		t.Fatalf("parser returned an unexpected error: %v", err)
+	}
+
+	if want := "aa:bb:cc:dd:ee:ff -> fe80::1%en0"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	if rest != " reachable" {
+		t.Errorf("rest = %q, wanted %q", rest, " reachable")
+	}
+}
+
+func ExampleIPv4() {
+	text := "connection from 10.0.0.1 to 192.168.1.20 refused"
+
+	for match, err := range parser.FindAll(netaddr.IPv4, text) {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Printf("%s at %d-%d\n", match.Value, match.Start, match.End)
+	}
+
+	// Output: 10.0.0.1 at 16-24
+	// 192.168.1.20 at 28-40
+}
+
+func ExampleCIDR() {
+	prefix, rest, err := netaddr.CIDR("10.0.0.0/8 via eth0")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(prefix, prefix.Contains(netip.MustParseAddr("10.1.2.3")))
+	fmt.Printf("%q\n", rest)
+
+	// Output: 10.0.0.0/8 true
+	// " via eth0"
+}
+
+// check compares the result of one of the address parsers with what we wanted.
+func check[T fmt.Stringer](t *testing.T, got T, rest string, err error, want, wantRest, wantErr string, errExpected bool) {
+	t.Helper()
+
+	if (err != nil) != errExpected {
+		t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, errExpected)
+	}
+
+	if err != nil {
+		if msg := err.Error(); msg != wantErr {
+			t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, wantErr)
+		}
+		return
+	}
+
+	if got.String() != want {
+		t.Errorf("\nValue:\t%s\nWanted:\t%s\n", got, want)
+	}
+
+	if rest != wantRest {
+		t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", rest, wantRest)
+	}
+}
@@ -1,5 +1,11 @@
 // Package parser implements simple, yet expressive mechanisms for [combinatorial parsing] in Go.
 //
+// Parsers don't limit the work they do: recursion through [Lazy] or a [Rule], backtracking in
+// [Try] and repetition in [FoldMany] carry on for as long as the input lets them, and none of them
+// take a context. For untrusted input, protection is opt-in: parse with [Run] and wrap the
+// recursive and backtracking parts of the grammar with [Guard], see [Budget]. Only the guarded
+// parsers are bounded by its limits or stopped by its context.
+//
 // [combinatorial parsing]: https://en.wikipedia.org/wiki/Parser_combinator
 package parser // import "go.followtheprocess.codes/parser"
 
@@ -14,6 +20,10 @@ import (
 // to parse complex grammars.
 //
 // Each Parser is generic over type T and returns the parsed value from the input, the remaining unparsed input and an error.
+//
+// Parsers only look at as much of the input as they need to, so the cost of applying one is in proportion
+// to what it consumes rather than to the length of the whole input. That includes checking for invalid
+// utf-8, which is only an error for a parser that reaches it.
 type Parser[T any] func(input string) (value T, remainder string, err error)
 
 // Take returns a [Parser] that consumes n utf-8 chars from the input.
@@ -29,21 +39,16 @@ func Take(n int) Parser[string] {
 			return "", "", errors.New("Take: cannot take from empty input")
 		}
 
-		if !utf8.ValidString(input) {
-			return "", "", errors.New("Take: input not valid utf-8")
-		}
-
 		runes := 0 // How many runes we've seen
-		end := 0   // The starting byte position of the nth rune
-		for pos, char := range input {
-			runes++
-			if runes == n {
-				// We've hit our limit, pos is the starting byte of the nth rune
-				// but we want to return the entire string *including* the nth rune
-				// so the actual end is pos + the byte length of the nth rune
-				end = pos + utf8.RuneLen(char)
-				break
+		end := 0   // The byte position just after the last rune taken
+		for end < len(input) && runes < n {
+			char, width := utf8.DecodeRuneInString(input[end:])
+			if char == utf8.RuneError && width == 1 {
+				return "", "", errors.New("Take: input not valid utf-8")
 			}
+
+			end += width
+			runes++
 		}
 
 		if runes < n {
@@ -69,7 +74,7 @@ func Exact(match string) Parser[string] {
 			return "", "", errors.New("Exact: cannot match on empty input")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("Exact: input not valid utf-8")
 		}
 
@@ -77,8 +82,11 @@ func Exact(match string) Parser[string] {
 			return "", "", errors.New("Exact: match must not be empty")
 		}
 
-		start := strings.Index(input, match)
-		if start != 0 {
+		if !validPrefix(input, len(match)) {
+			return "", "", errors.New("Exact: input not valid utf-8")
+		}
+
+		if !strings.HasPrefix(input, match) {
 			return "", "", fmt.Errorf("Exact: match (%s) not in input", match)
 		}
 
@@ -100,7 +108,7 @@ func ExactCaseInsensitive(match string) Parser[string] {
 			return "", "", errors.New("ExactCaseInsensitive: cannot match on empty input")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("ExactCaseInsensitive: input not valid utf-8")
 		}
 
@@ -115,6 +123,10 @@ func ExactCaseInsensitive(match string) Parser[string] {
 			return "", "", fmt.Errorf("ExactCaseInsensitive: match (%s) not in input", match)
 		}
 
+		if !validPrefix(input, matchLen) {
+			return "", "", errors.New("ExactCaseInsensitive: input not valid utf-8")
+		}
+
 		// The beginning of input where the match string could possibly be
 		potentialMatch := input[:matchLen]
 
@@ -141,7 +153,7 @@ func Char(char rune) Parser[string] {
 		}
 
 		if r != char {
-			return "", "", fmt.Errorf("Char: requested char (%s) not found in input", string(char))
+			return "", "", charError{char: char}
 		}
 
 		return input[:width], input[width:], nil
@@ -159,14 +171,16 @@ func Char(char rune) Parser[string] {
 // If the predicate doesn't return false for any char in the input, the entire input is returned as the value
 // with no remainder.
 //
-// A predicate that never returns true will return an error.
+// A predicate that returns false for the first char will return an error, even if it would return true
+// for a later one, as TakeWhile only looks at the chars it consumes. Earlier versions only returned an
+// error if the predicate returned true for no char in the input, and matched nothing otherwise.
 func TakeWhile(predicate func(r rune) bool) Parser[string] {
 	return func(input string) (string, string, error) {
 		if input == "" {
 			return "", "", errors.New("TakeWhile: input text is empty")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("TakeWhile: input not valid utf-8")
 		}
 
@@ -174,24 +188,23 @@ func TakeWhile(predicate func(r rune) bool) Parser[string] {
 			return "", "", errors.New("TakeWhile: predicate must be a non-nil function")
 		}
 
-		if strings.IndexFunc(input, predicate) == -1 {
-			return "", "", errors.New("TakeWhile: predicate never returned true")
-		}
+		end := 0 // Byte position just after the last rune that the predicate returns true for
+		for end < len(input) {
+			char, width := utf8.DecodeRuneInString(input[end:])
+			if char == utf8.RuneError && width == 1 {
+				return "", "", errors.New("TakeWhile: input not valid utf-8")
+			}
 
-		end := 0        // Byte position of last rune that the predicate returns true for
-		broken := false // Whether the predicate ever returned false so we broke the loop
-		for pos, char := range input {
-			end = pos
 			if !predicate(char) {
-				broken = true
 				break
 			}
+
+			end += width
 		}
 
-		if !broken {
-			// If we didn't break the loop, the predicate never returned false
-			// so just return the whole input
-			return input, "", nil
+		if end == 0 {
+			char, _ := utf8.DecodeRuneInString(input)
+			return "", "", takeWhileError{char: char}
 		}
 
 		return input[:end], input[end:], nil
@@ -209,14 +222,16 @@ func TakeWhile(predicate func(r rune) bool) Parser[string] {
 // If the predicate never returns true, the entire input will be returned as the value
 // with no remainder.
 //
-// A predicate that never returns false will return an error.
+// A predicate that returns true for the first char will return an error, even if it would return false
+// for a later one, as TakeUntil only looks at the chars it consumes. Earlier versions only returned an
+// error if the predicate returned false for no char in the input, and matched nothing otherwise.
 func TakeUntil(predicate func(r rune) bool) Parser[string] {
 	return func(input string) (string, string, error) {
 		if input == "" {
 			return "", "", errors.New("TakeUntil: input text is empty")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("TakeUntil: input not valid utf-8")
 		}
 
@@ -224,26 +239,23 @@ func TakeUntil(predicate func(r rune) bool) Parser[string] {
 			return "", "", errors.New("TakeUntil: predicate must be a non-nil function")
 		}
 
-		// strings.IndexFunc returns the index of the first char for which the predicate
-		// returns true, we want the opposite to test if it ever returns false
-		notPred := func(r rune) bool { return !predicate(r) }
-
-		if strings.IndexFunc(input, notPred) == -1 {
-			return "", "", errors.New("TakeUntil: predicate never returned false")
-		}
+		end := 0 // Byte position just after the last rune that the predicate returns false for
+		for end < len(input) {
+			char, width := utf8.DecodeRuneInString(input[end:])
+			if char == utf8.RuneError && width == 1 {
+				return "", "", errors.New("TakeUntil: input not valid utf-8")
+			}
 
-		end := 0        // Byte position of last rune that the predicate returns false for
-		broken := false // Whether the predicate ever returned true so we broke the loop
-		for pos, char := range input {
-			end = pos
 			if predicate(char) {
-				broken = true
 				break
 			}
+
+			end += width
 		}
 
-		if !broken {
-			return input, "", nil
+		if end == 0 {
+			char, _ := utf8.DecodeRuneInString(input)
+			return "", "", takeUntilError{char: char}
 		}
 
 		return input[:end], input[end:], nil
@@ -259,15 +271,19 @@ func TakeUntil(predicate func(r rune) bool) Parser[string] {
 //   - predicate is nil
 //   - lower < 0
 //   - lower > upper
-//   - predicate never returns true
+//   - predicate never returns true, unless lower is 0
 //   - predicate matched some chars but less than lower limit
+//
+// With a lower limit of 0, a predicate that returns false for the first char matches nothing,
+// without looking at the rest of the input. Earlier versions returned an error here if the
+// predicate returned true for no char in the input.
 func TakeWhileBetween(lower, upper int, predicate func(r rune) bool) Parser[string] {
 	return func(input string) (string, string, error) {
 		if input == "" {
 			return "", "", errors.New("TakeWhileBetween: input text is empty")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("TakeWhileBetween: input not valid utf-8")
 		}
 
@@ -283,63 +299,31 @@ func TakeWhileBetween(lower, upper int, predicate func(r rune) bool) Parser[stri
 			return "", "", fmt.Errorf("TakeWhileBetween: invalid range, lower (%d) must be < upper (%d)", lower, upper)
 		}
 
-		// Does the predicate ever return true? Quick failure case
-		if strings.IndexFunc(input, predicate) == -1 {
-			return "", "", errors.New("TakeWhileBetween: predicate never returned true")
-		}
+		// Take the longest sequence for which the predicate returns true, up to the upper
+		// limit of chars
+		n := 0   // How many chars the predicate has returned true for
+		end := 0 // Byte position just after the last of those chars
+		for end < len(input) && n < upper {
+			char, width := utf8.DecodeRuneInString(input[end:])
+			if char == utf8.RuneError && width == 1 {
+				return "", "", errors.New("TakeWhileBetween: input not valid utf-8")
+			}
 
-		index := 0 // Index of last char for which predicate returns true
-		for pos, char := range input {
 			if !predicate(char) {
 				break
 			}
-			// Add the byte width of the char in question because the next char is the
-			// first one where predicate(char) == false, that's where we want to cut
-			// the string
-			runeLen := utf8.RuneLen(char)
-			if runeLen != -1 {
-				index = pos + utf8.RuneLen(char)
-			}
-		}
 
-		// If we have an index, our job now is to return whichever is longest out of
-		// the sequence for which the predicate returned true, or the entire input
-		// up to the upper limit of chars
-		startToIndex := input[:index]
-		n := utf8.RuneCountInString(startToIndex)
+			end += width
+			n++
+		}
 
 		if n < lower {
 			// The number of chars for which the predicate returned true is less
 			// than our lower limit, which is an error
-			return "", "", fmt.Errorf(
-				"TakeWhileBetween: predicate matched only %d chars (%s), below lower limit (%d)",
-				n,
-				startToIndex,
-				lower,
-			)
-		}
-
-		if n > upper {
-			// The sequence of chars for which the predicate returned true is
-			// longer than our upper limit, so cut if off at upper utf-8 chars
-			runes := 0  // How many runes we've scanned through
-			cutOff := 0 // Index of where to cut the string off
-			for pos, char := range startToIndex {
-				runes++
-				if runes == upper {
-					// Add the byte width of the char in question because we want to
-					// include it in the slice and pos is just the starting byte position
-					cutOff = pos + utf8.RuneLen(char)
-				}
-			}
-
-			return input[:cutOff], input[cutOff:], nil
+			return "", "", belowLowerError{predicate: predicate, input: input, matched: input[:end], lower: lower}
 		}
 
-		// If we get here, we know that the number of utf-8 chars for which the predicate
-		// returned true is already less than our upper limit, so we can just use the
-		// index from earlier
-		return input[:index], input[index:], nil
+		return input[:end], input[end:], nil
 	}
 }
 
@@ -355,7 +339,7 @@ func TakeTo(match string) Parser[string] {
 			return "", "", errors.New("TakeTo: input text is empty")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("TakeTo: input not valid utf-8")
 		}
 
@@ -365,9 +349,17 @@ func TakeTo(match string) Parser[string] {
 
 		start := strings.Index(input, match)
 		if start == -1 {
+			if !utf8.ValidString(input) {
+				return "", "", errors.New("TakeTo: input not valid utf-8")
+			}
+
 			return "", "", fmt.Errorf("TakeTo: match (%s) not in input", match)
 		}
 
+		if !validPrefix(input, start) {
+			return "", "", errors.New("TakeTo: input not valid utf-8")
+		}
+
 		return input[:start], input[start:], nil
 	}
 }
@@ -473,16 +465,23 @@ func AnyOf(chars string) Parser[string] {
 			return "", "", errors.New("AnyOf: chars must not be empty")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("AnyOf: input not valid utf-8")
 		}
 
 		end := 0 // The end of the matching sequence
-		for pos, char := range input {
+		for pos := 0; pos < len(input); {
+			char, width := utf8.DecodeRuneInString(input[pos:])
+			if char == utf8.RuneError && width == 1 {
+				return "", "", errors.New("AnyOf: input not valid utf-8")
+			}
+
 			if !strings.ContainsRune(chars, char) {
 				end = pos
 				break
 			}
+
+			pos += width
 		}
 
 		// If we've broken the loop but end is still 0, there were no matches
@@ -515,16 +514,23 @@ func NotAnyOf(chars string) Parser[string] {
 			return "", "", errors.New("NotAnyOf: chars must not be empty")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("NotAnyOf: input not valid utf-8")
 		}
 
 		end := 0 // The end of the matching sequence
-		for pos, char := range input {
+		for pos := 0; pos < len(input); {
+			char, width := utf8.DecodeRuneInString(input[pos:])
+			if char == utf8.RuneError && width == 1 {
+				return "", "", errors.New("NotAnyOf: input not valid utf-8")
+			}
+
 			if strings.ContainsRune(chars, char) {
 				end = pos
 				break
 			}
+
+			pos += width
 		}
 
 		// If we've broken the loop but end is still 0, there were no matches
@@ -549,7 +555,7 @@ func Optional(match string) Parser[string] {
 			return "", "", errors.New("Optional: input text is empty")
 		}
 
-		if !utf8.ValidString(input) {
+		if !validPrefix(input, 1) {
 			return "", "", errors.New("Optional: input not valid utf-8")
 		}
 
@@ -557,8 +563,7 @@ func Optional(match string) Parser[string] {
 			return "", "", errors.New("Optional: match must not be empty")
 		}
 
-		start := strings.Index(input, match)
-		if start != 0 {
+		if !strings.HasPrefix(input, match) {
 			// The optional match isn't at the start of the string
 			return "", input, nil
 		}
@@ -602,6 +607,128 @@ func Map[T1, T2 any](parser Parser[T1], fn func(T1) (T2, error)) Parser[T2] {
 	}
 }
 
+// Verify returns a [Parser] that applies another parser and then checks the parsed value
+// with a predicate, failing if the predicate returns false.
+//
+// It is particularly useful for rejecting values that are syntactically fine but semantically
+// invalid, such as a port number greater than 65535 or a month of 13.
+//
+// If the predicate returns false, the error is a [*VerifyError] with msg as its message, along with
+// the text the parser matched and where it starts. Verify fails at the position the parser started
+// at and like every other parser, consumes nothing when it fails, so [Try] can backtrack and attempt
+// the next parser on the same input.
+//
+// If the provided parser returns an error, Verify will bubble up this error to the caller.
+//
+// If pred is nil, an error will be returned.
+func Verify[T any](parser Parser[T], pred func(T) bool, msg string) Parser[T] {
+	return func(input string) (T, string, error) {
+		var zero T
+
+		if pred == nil {
+			return zero, "", errors.New("Verify: pred must be a non-nil function")
+		}
+
+		value, remainder, err := parser(input)
+		if err != nil {
+			return zero, "", fmt.Errorf("Verify: parser returned error: %w", err)
+		}
+
+		if !pred(value) {
+			matched := input[:len(input)-len(remainder)]
+			return zero, "", &VerifyError{Msg: msg, Text: matched, Input: input}
+		}
+
+		return value, remainder, nil
+	}
+}
+
+// VerifyError is the error returned from [Verify] when the predicate rejects a parsed value.
+type VerifyError struct {
+	Msg   string // The message passed to Verify, saying what's wrong with the value
+	Text  string // The text the rejected value was parsed from
+	Input string // The input Verify was applied to, which starts with Text
+}
+
+// Error implements the error interface for VerifyError, the message is Msg followed by the
+// rejected text.
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Msg, e.Text)
+}
+
+// Offset returns the byte offset in src, the complete input the parse was started on, at which
+// the rejected value starts.
+func (e *VerifyError) Offset(src string) int {
+	return len(src) - len(e.Input)
+}
+
+// Bind returns a [Parser] that applies another parser, then passes the parsed value to fn
+// to choose the parser to apply to the remaining input, returning the value from that second parser.
+//
+// Unlike [Map], the next step in the parse can depend on a value that was previously parsed which makes
+// Bind the tool for context-sensitive formats, for example length-prefixed formats like netstrings
+// where the length must be parsed before the payload, or heredocs where the terminator is declared
+// up front.
+//
+// If either parser returns an error, Bind will bubble up this error to the caller.
+//
+// If fn is nil or returns a nil parser, an error will be returned.
+func Bind[A, B any](parser Parser[A], fn func(A) Parser[B]) Parser[B] {
+	return func(input string) (B, string, error) {
+		var zero B
+
+		if fn == nil {
+			return zero, "", errors.New("Bind: fn must be a non-nil function")
+		}
+
+		value, remainder, err := parser(input)
+		if err != nil {
+			return zero, "", fmt.Errorf("Bind: parser returned error: %w", err)
+		}
+
+		next := fn(value)
+		if next == nil {
+			return zero, "", errors.New("Bind: fn returned a nil parser")
+		}
+
+		newValue, remainder, err := next(remainder)
+		if err != nil {
+			return zero, "", fmt.Errorf("Bind: next parser returned error: %w", err)
+		}
+
+		return newValue, remainder, nil
+	}
+}
+
+// Lazy returns a [Parser] that defers calling fn to obtain the real parser until the returned
+// parser is actually applied to some input.
+//
+// This is what makes recursive grammars possible, a parser can refer to a variable that will
+// hold a parser that isn't constructed yet, for example a JSON value which may be an array which
+// itself contains JSON values.
+//
+// A parser built this way mustn't refer to itself before consuming any input (left recursion) or it
+// will never terminate, use a [Rule] for that. Nor does it bound how deep the recursion goes, wrap
+// it with [Guard] to do that.
+//
+// If fn is nil or returns a nil parser, an error will be returned.
+func Lazy[T any](fn func() Parser[T]) Parser[T] {
+	return func(input string) (T, string, error) {
+		var zero T
+
+		if fn == nil {
+			return zero, "", errors.New("Lazy: fn must be a non-nil function")
+		}
+
+		parser := fn()
+		if parser == nil {
+			return zero, "", errors.New("Lazy: fn returned a nil parser")
+		}
+
+		return parser(input)
+	}
+}
+
 // Try returns a [Parser] that attempts a series of sub-parsers, returning the output from the
 // first successful one.
 //
@@ -684,3 +811,249 @@ func Count[T any](parser Parser[T], count int) Parser[[]T] {
 		return values, finalRemainder, nil
 	}
 }
+
+// FoldMany returns a [Parser] that applies another parser repeatedly until it fails, folding each
+// parsed value into an accumulator rather than collecting them into a slice.
+//
+// init is called once each time the returned parser is invoked to produce the starting accumulator,
+// and step is called with the current accumulator and each parsed value to produce the next one. This
+// makes FoldMany useful for things like summing digits or counting matches without allocating.
+//
+// FoldMany matches zero or more times, if the parser fails on the very first attempt, the value
+// from init is returned along with the entire input as the remainder. Parsing also stops if the
+// parser succeeds without consuming any input, as it would otherwise loop forever.
+//
+// If init or step is nil, an error will be returned.
+func FoldMany[T, A any](parser Parser[T], init func() A, step func(A, T) A) Parser[A] {
+	return func(input string) (A, string, error) {
+		var zero A
+
+		if init == nil {
+			return zero, "", errors.New("FoldMany: init must be a non-nil function")
+		}
+
+		if step == nil {
+			return zero, "", errors.New("FoldMany: step must be a non-nil function")
+		}
+
+		acc := init()
+		remainder := input // The remaining input after each successful application of parser
+
+		for {
+			value, rest, err := parser(remainder)
+			if err != nil || len(rest) == len(remainder) {
+				// Either the parser has failed or it made no progress, either way we're done
+				break
+			}
+
+			acc = step(acc, value)
+			remainder = rest
+		}
+
+		return acc, remainder, nil
+	}
+}
+
+// SkipMany returns a [Parser] that applies another parser repeatedly until it fails, discarding
+// the parsed values.
+//
+// The value is the portion of the input consumed by all the successful applications of the parser,
+// as this is just a slice of the input, SkipMany never allocates.
+//
+// SkipMany matches zero or more times so never returns an error, if the parser fails on the
+// very first attempt, the value will be empty and the entire input is returned as the remainder.
+// Like [FoldMany], parsing also stops if the parser succeeds without consuming any input.
+func SkipMany[T any](parser Parser[T]) Parser[string] {
+	return func(input string) (string, string, error) {
+		remainder := input
+
+		for {
+			_, rest, err := parser(remainder)
+			if err != nil || len(rest) == len(remainder) {
+				break
+			}
+
+			remainder = rest
+		}
+
+		end := len(input) - len(remainder)
+
+		return input[:end], remainder, nil
+	}
+}
+
+// SkipCount returns a [Parser] that applies another parser a certain number of times, discarding
+// the parsed values.
+//
+// It is the non-allocating equivalent of [Count], and the value is the portion of the input consumed
+// by all the applications of the parser.
+//
+// If count is negative, the parser fails or the input is exhausted before the parser has been
+// applied the requested number of times, an error will be returned.
+func SkipCount[T any](parser Parser[T], count int) Parser[string] {
+	return func(input string) (string, string, error) {
+		if count < 0 {
+			return "", "", fmt.Errorf("SkipCount: count must not be negative, got %d", count)
+		}
+
+		remainder := input
+
+		for range count {
+			_, rest, err := parser(remainder)
+			if err != nil {
+				return "", "", fmt.Errorf("SkipCount: parser failed: %w", err)
+			}
+
+			remainder = rest
+		}
+
+		end := len(input) - len(remainder)
+
+		return input[:end], remainder, nil
+	}
+}
+
+// Recognize returns a [Parser] that applies another parser, returning the portion of the input it
+// consumed as the value in place of the value it parsed.
+//
+// It is useful when the structure of some text needs checking but only the text itself is wanted,
+// like a number made up of a sign, digits and an optional fraction.
+//
+// If the parser returns an error, Recognize will bubble up this error to the caller.
+func Recognize[T any](parser Parser[T]) Parser[string] {
+	return func(input string) (string, string, error) {
+		_, remainder, err := parser(input)
+		if err != nil {
+			return "", "", fmt.Errorf("Recognize: parser returned error: %w", err)
+		}
+
+		end := len(input) - len(remainder)
+
+		return input[:end], remainder, nil
+	}
+}
+
+// Peek returns a [Parser] that applies another parser without consuming any input, the value is
+// the one the parser returned but the remainder is the entire input.
+//
+// Peek is a positive lookahead, it lets a grammar check what comes next before deciding how to
+// parse it.
+//
+// If the parser returns an error, Peek will bubble up this error to the caller.
+func Peek[T any](parser Parser[T]) Parser[T] {
+	return func(input string) (T, string, error) {
+		var zero T
+
+		value, _, err := parser(input)
+		if err != nil {
+			return zero, "", fmt.Errorf("Peek: parser returned error: %w", err)
+		}
+
+		return value, input, nil
+	}
+}
+
+// Not returns a [Parser] that succeeds without consuming any input if another parser fails, the
+// value is always empty and the remainder is the entire input.
+//
+// Not is a negative lookahead, for things like a keyword that mustn't be followed by another letter
+// or an identifier that mustn't be a keyword.
+//
+// If the parser succeeds, an error will be returned.
+func Not[T any](parser Parser[T]) Parser[string] {
+	return func(input string) (string, string, error) {
+		_, remainder, err := parser(input)
+		if err == nil {
+			matched := input[:len(input)-len(remainder)]
+			return "", "", fmt.Errorf("Not: parser matched (%s)", matched)
+		}
+
+		return "", input, nil
+	}
+}
+
+// Succeed returns a [Parser] that always succeeds with value, consuming no input.
+//
+// It's most useful as the last alternative in a [Try], to provide a default when none of the
+// others match.
+func Succeed[T any](value T) Parser[T] {
+	return func(input string) (T, string, error) {
+		return value, input, nil
+	}
+}
+
+// The errors below are for the most common ways of failing to match, which are what almost every
+// attempt ends in when searching through text with [FindAll], so they're only formatted when the
+// message is asked for.
+
+// charError is the error from [Char] when the input starts with a different char.
+type charError struct {
+	char rune // The char that was requested
+}
+
+// Error implements the error interface for charError.
+func (e charError) Error() string {
+	return fmt.Sprintf("Char: requested char (%s) not found in input", string(e.char))
+}
+
+// takeWhileError is the error from [TakeWhile] when the predicate returns false for the first char.
+type takeWhileError struct {
+	char rune // The first char of the input
+}
+
+// Error implements the error interface for takeWhileError.
+func (e takeWhileError) Error() string {
+	return fmt.Sprintf("TakeWhile: predicate returned false for the first char %q", e.char)
+}
+
+// takeUntilError is the error from [TakeUntil] when the predicate returns true for the first char.
+type takeUntilError struct {
+	char rune // The first char of the input
+}
+
+// Error implements the error interface for takeUntilError.
+func (e takeUntilError) Error() string {
+	return fmt.Sprintf("TakeUntil: predicate returned true for the first char %q", e.char)
+}
+
+// belowLowerError is the error from [TakeWhileBetween] when the predicate matched fewer chars than
+// the lower limit.
+//
+// If it matched none, the message says whether the predicate returns true anywhere in the input,
+// which means searching the rest of it. That's only done if the message is asked for, so a failed
+// attempt costs no more than the chars it looked at.
+type belowLowerError struct {
+	predicate func(r rune) bool // The predicate the chars were matched with
+	input     string            // The input TakeWhileBetween was applied to
+	matched   string            // The chars the predicate returned true for
+	lower     int               // The lower limit
+}
+
+// Error implements the error interface for belowLowerError.
+func (e belowLowerError) Error() string {
+	if e.matched == "" && strings.IndexFunc(e.input, e.predicate) == -1 {
+		return "TakeWhileBetween: predicate never returned true"
+	}
+
+	return fmt.Sprintf(
+		"TakeWhileBetween: predicate matched only %d chars (%s), below lower limit (%d)",
+		utf8.RuneCountInString(e.matched),
+		e.matched,
+		e.lower,
+	)
+}
+
+// validPrefix reports whether the chars of s up to byte offset n, including any char that n cuts
+// through, are valid utf-8.
+func validPrefix(s string, n int) bool {
+	for i := 0; i < n && i < len(s); {
+		char, width := utf8.DecodeRuneInString(s[i:])
+		if char == utf8.RuneError && width == 1 {
+			return false
+		}
+
+		i += width
+	}
+
+	return true
+}
@@ -94,6 +94,24 @@ func TestTake(t *testing.T) {
 			wantErr:   true,
 			err:       "Take: input not valid utf-8",
 		},
+		{
+			name:      "bad utf8 after the chars taken",
+			input:     "abc\xf8\xa1",
+			value:     "ab",
+			remainder: "c\xf8\xa1",
+			n:         2,
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "bad utf8 within the chars taken",
+			input:     "ab\xf8\xa1",
+			value:     "",
+			remainder: "",
+			n:         3,
+			wantErr:   true,
+			err:       "Take: input not valid utf-8",
+		},
 		{
 			name:      "simple",
 			input:     "Hello I am some input",
@@ -216,6 +234,15 @@ func TestExact(t *testing.T) {
 			wantErr:   true,
 			err:       "Exact: input not valid utf-8",
 		},
+		{
+			name:      "bad utf8 after the match",
+			input:     "something\xf8\xa1",
+			value:     "something",
+			remainder: "\xf8\xa1",
+			match:     "something",
+			wantErr:   false,
+			err:       "",
+		},
 		{
 			name:      "empty input and match",
 			input:     "",
@@ -600,7 +627,34 @@ func TestTakeWhile(t *testing.T) {
 			remainder: "",
 			predicate: unicode.IsDigit, // False for every char in input
 			wantErr:   true,
-			err:       "TakeWhile: predicate never returned true",
+			err:       "TakeWhile: predicate returned false for the first char 'a'",
+		},
+		{
+			name:      "predicate false for first char",
+			input:     "abc123", // Digits, but not at the start
+			value:     "",
+			remainder: "",
+			predicate: unicode.IsDigit,
+			wantErr:   true,
+			err:       "TakeWhile: predicate returned false for the first char 'a'",
+		},
+		{
+			name:      "bad utf8 after the match",
+			input:     "123 \xf8\xa1",
+			value:     "123",
+			remainder: " \xf8\xa1",
+			predicate: unicode.IsDigit,
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "bad utf8 within the match",
+			input:     "123\xf8\xa1",
+			value:     "",
+			remainder: "",
+			predicate: unicode.IsDigit,
+			wantErr:   true,
+			err:       "TakeWhile: input not valid utf-8",
 		},
 		{
 			name:      "consume whitespace",
@@ -877,7 +931,16 @@ func TestTakeUntil(t *testing.T) {
 			remainder: "",
 			predicate: func(r rune) bool { return true },
 			wantErr:   true,
-			err:       "TakeUntil: predicate never returned false",
+			err:       "TakeUntil: predicate returned true for the first char 'f'",
+		},
+		{
+			name:      "predicate true for first char",
+			input:     " leading space",
+			value:     "",
+			remainder: "",
+			predicate: unicode.IsSpace, // False for later chars, but true for the first
+			wantErr:   true,
+			err:       "TakeUntil: predicate returned true for the first char ' '",
 		},
 		{
 			name:      "consume until whitespace",
@@ -1692,76 +1755,74 @@ func TestMap(t *testing.T) {
 	}
 }
 
-func TestTry(t *testing.T) {
+func TestVerify(t *testing.T) {
 	type test[T any] struct {
-		value     T
-		name      string
-		input     string
-		remainder string
-		err       string
-		parsers   []parser.Parser[T]
-		wantErr   bool
+		p         parser.Parser[T] // The parser to verify the output of
+		pred      func(T) bool     // The predicate to check the value with
+		name      string           // Identifying test case name
+		input     string           // Entire input to be parsed
+		msg       string           // The message passed to Verify
+		remainder string           // The remaining unparsed input
+		err       string           // The expected error message (if there is one)
+		value     T                // The parsed value
+		wantErr   bool             // Whether it should have returned an error
 	}
 
-	tests := []test[string]{
+	port := parser.Map(parser.TakeWhile(unicode.IsDigit), strconv.Atoi)
+	validPort := func(n int) bool { return n > 0 && n <= 65535 }
+
+	tests := []test[int]{
 		{
-			name:  "empty input",
-			input: "",
-			parsers: []parser.Parser[string]{
-				// Some random parsers
-				parser.Take(3),
-				parser.Char('X'),
-				parser.TakeWhile(unicode.IsLetter),
-			},
-			value:     "",
+			name:      "nil pred",
+			input:     "8080",
+			p:         port,
+			pred:      nil,
+			msg:       "invalid port",
+			value:     0,
 			remainder: "",
 			wantErr:   true,
-			err:       "Try: all parsers failed",
+			err:       "Verify: pred must be a non-nil function",
 		},
 		{
-			name:  "digits then symbols",
-			input: "123456*&^$£@",
-			parsers: []parser.Parser[string]{
-				parser.TakeWhile(unicode.IsLetter), // Will fail
-				parser.TakeWhile(unicode.IsDigit),  // Should return the output from this one
-			},
-			value:     "123456",
-			remainder: "*&^$£@",
-			wantErr:   false,
-			err:       "",
+			name:      "empty input",
+			input:     "",
+			p:         port,
+			pred:      validPort,
+			msg:       "invalid port",
+			value:     0,
+			remainder: "",
+			wantErr:   true,
+			err:       "Verify: parser returned error: Map: parser returned error: TakeWhile: input text is empty",
 		},
 		{
-			name:  "digits then symbols",
-			input: "xyzabc日ð本Ê語",
-			parsers: []parser.Parser[string]{
-				parser.OneOf("abc"),                // Will fail
-				parser.Char('本'),                   // Same
-				parser.ExactCaseInsensitive("XyZ"), // Should succeed
-			},
-			value:     "xyz",
-			remainder: "abc日ð本Ê語",
+			name:      "valid",
+			input:     "8080/tcp",
+			p:         port,
+			pred:      validPort,
+			msg:       "invalid port",
+			value:     8080,
+			remainder: "/tcp",
 			wantErr:   false,
 			err:       "",
 		},
 		{
-			name:  "first is successful",
-			input: "hello there",
-			parsers: []parser.Parser[string]{
-				parser.Take(2),        // Will succeed and return
-				parser.Exact("hello"), // Should never get invoked
-			},
-			value:     "he",
-			remainder: "llo there",
-			wantErr:   false,
-			err:       "",
+			name:      "invalid",
+			input:     "70000/tcp",
+			p:         port,
+			pred:      validPort,
+			msg:       "invalid port",
+			value:     0,
+			remainder: "",
+			wantErr:   true,
+			err:       "invalid port (70000)",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			value, remainder, err := parser.Try(tt.parsers...)(tt.input)
+			value, remainder, err := parser.Verify(tt.p, tt.pred, tt.msg)(tt.input)
 
-			result := parserTest[string]{
+			result := parserTest[int]{
 				gotValue:      value,
 				gotRemainder:  remainder,
 				gotErr:        err,
@@ -1776,156 +1837,866 @@ func TestTry(t *testing.T) {
 	}
 }
 
-func TestChain(t *testing.T) {
-	type test[T any] struct {
-		value     []T
-		name      string
-		input     string
-		remainder string
-		err       string
-		parsers   []parser.Parser[T]
-		wantErr   bool
+func TestVerifyError(t *testing.T) {
+	src := "host:8080\nother:70000/tcp"
+
+	port := parser.Map(parser.TakeWhile(unicode.IsDigit), strconv.Atoi)
+	validPort := func(n int) bool { return n > 0 && n <= 65535 }
+
+	entry := parser.Chain(
+		parser.Map(parser.TakeWhile(unicode.IsLetter), func(string) (int, error) { return 0, nil }),
+		parser.Map(parser.Char(':'), func(string) (int, error) { return 0, nil }),
+		parser.Verify(port, validPort, "invalid port"),
+	)
+
+	_, rest, err := entry(src)
+	if err != nil {
+		t.Fatalf("first entry returned an unexpected error: %v", err)
 	}
 
-	tests := []test[string]{
+	_, _, err = entry(rest[1:])
+
+	var verifyErr *parser.VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("error %v is not a *VerifyError", err)
+	}
+
+	if verifyErr.Msg != "invalid port" || verifyErr.Text != "70000" {
+		t.Errorf("got Msg %q and Text %q, wanted %q and %q", verifyErr.Msg, verifyErr.Text, "invalid port", "70000")
+	}
+
+	if offset := verifyErr.Offset(src); offset != 16 {
+		t.Errorf("got Offset %d, wanted 16", offset)
+	}
+
+	if want := "Chain: sub parser failed: invalid port (70000)"; err.Error() != want {
+		t.Errorf("\nGot:\t%v\nWanted:\t%s\n", err, want)
+	}
+}
+
+func TestVerifyBacktracks(t *testing.T) {
+	// A month is 1 or 2 digits from 1-12, Try should fall back to taking a single
+	// digit if the two digit version isn't a valid month
+	month := func(n int) bool { return n >= 1 && n <= 12 }
+	twoDigits := parser.Map(parser.Take(2), strconv.Atoi)
+	oneDigit := parser.Map(parser.Take(1), strconv.Atoi)
+
+	value, remainder, err := parser.Try(
+		parser.Verify(twoDigits, month, "invalid month"),
+		parser.Verify(oneDigit, month, "invalid month"),
+	)("13")
+
+	result := parserTest[int]{
+		gotValue:      value,
+		gotRemainder:  remainder,
+		gotErr:        err,
+		wantValue:     1,
+		wantRemainder: "3",
+		wantErr:       false,
+		wantErrMsg:    "",
+	}
+
+	testParser(t, result)
+}
+
+func TestBind(t *testing.T) {
+	// A netstring is a length prefixed string e.g. "5:hello,", where the length is in bytes
+	length := parser.Map(
+		parser.Chain(parser.TakeWhile(unicode.IsDigit), parser.Char(':')),
+		func(parts []string) (int, error) { return strconv.Atoi(parts[0]) },
+	)
+	takeBytes := func(n int) parser.Parser[string] {
+		return func(input string) (string, string, error) {
+			if n > len(input) {
+				return "", "", fmt.Errorf("requested %d bytes but input had only %d", n, len(input))
+			}
+
+			return input[:n], input[n:], nil
+		}
+	}
+	payload := func(n int) parser.Parser[string] {
+		return parser.Map(
+			parser.Chain(takeBytes(n), parser.Char(',')),
+			func(parts []string) (string, error) { return parts[0], nil },
+		)
+	}
+
+	tests := []struct {
+		fn        func(int) parser.Parser[string] // The function choosing the next parser
+		name      string                          // Identifying test case name
+		input     string                          // Entire input to be parsed
+		value     string                          // The parsed value
+		remainder string                          // The remaining unparsed input
+		err       string                          // The expected error message (if there is one)
+		wantErr   bool                            // Whether it should have returned an error
+	}{
 		{
-			name:  "empty input",
-			input: "",
-			parsers: []parser.Parser[string]{
-				// Some random parsers
-				parser.Take(3),
-				parser.Char('X'),
-				parser.TakeWhile(unicode.IsLetter),
-			},
-			value:     nil,
+			name:      "nil fn",
+			input:     "5:hello,",
+			fn:        nil,
+			value:     "",
 			remainder: "",
 			wantErr:   true,
-			err:       "Chain: sub parser failed: Take: cannot take from empty input",
+			err:       "Bind: fn must be a non-nil function",
 		},
 		{
-			name:  "pairs of chars",
-			input: "abcd1234",
-			parsers: []parser.Parser[string]{
-				// Chain pairs
-				parser.Take(2),
-				parser.Take(2),
-				parser.Take(2),
-				parser.Take(2),
-			},
-			value:     []string{"ab", "cd", "12", "34"},
+			name:      "fn returns nil parser",
+			input:     "5:hello,",
+			fn:        func(int) parser.Parser[string] { return nil },
+			value:     "",
 			remainder: "",
-			wantErr:   false,
-			err:       "",
+			wantErr:   true,
+			err:       "Bind: fn returned a nil parser",
 		},
 		{
-			name:  "too many pairs of chars",
-			input: "abcd1234",
-			parsers: []parser.Parser[string]{
-				// Chain pairs
-				parser.Take(2),
-				parser.Take(2),
-				parser.Take(2),
-				parser.Take(2),
-				parser.Take(2), // One more than there is in the input
-			},
-			value:     nil,
+			name:      "empty input",
+			input:     "",
+			fn:        payload,
+			value:     "",
 			remainder: "",
 			wantErr:   true,
-			err:       "Chain: sub parser failed: Take: cannot take from empty input",
+			err:       "Bind: parser returned error: Map: parser returned error: Chain: sub parser failed: TakeWhile: input text is empty",
 		},
 		{
-			name:  "combo",
-			input: "abcd1234exact \t\n 日ð本Ê語 eof",
-			parsers: []parser.Parser[string]{
-				parser.TakeWhile(unicode.IsLetter), // Get abcd
-				parser.TakeWhile(unicode.IsDigit),  // 1234
-				parser.Exact("exact"),              // exact
-				parser.TakeWhile(unicode.IsSpace),  // Consume all whitespace
-				parser.TakeTo("語"),                 // Take up to this char
-				parser.Char('語'),                   // Take that char
-				parser.TakeWhile(unicode.IsSpace),  // More space
-				parser.Exact("eof"),                // Boom
-			},
-			value:     []string{"abcd", "1234", "exact", " \t\n ", "日ð本Ê", "語", " ", "eof"},
-			remainder: "",
+			name:      "netstring",
+			input:     "5:hello,rest",
+			fn:        payload,
+			value:     "hello",
+			remainder: "rest",
 			wantErr:   false,
 			err:       "",
 		},
 		{
-			name:  "remainder left",
-			input: "abcd1234",
-			parsers: []parser.Parser[string]{
-				parser.TakeWhile(unicode.IsLetter), // Get abcd
-				parser.Take(2),                     // 12
-			},
-			value:     []string{"abcd", "12"},
-			remainder: "34",
+			name:      "netstring utf8",
+			input:     "8:日ð本,", // 3 chars, but 8 bytes
+			fn:        payload,
+			value:     "日ð本",
+			remainder: "",
 			wantErr:   false,
 			err:       "",
 		},
+		{
+			name:      "netstring wrong length",
+			input:     "4:hello,",
+			fn:        payload,
+			value:     "",
+			remainder: "",
+			wantErr:   true,
+			err:       "Bind: next parser returned error: Map: parser returned error: Chain: sub parser failed: Char: requested char (,) not found in input",
+		},
+		{
+			name:      "netstring too short",
+			input:     "10:hello,",
+			fn:        payload,
+			value:     "",
+			remainder: "",
+			wantErr:   true,
+			err:       "Bind: next parser returned error: Map: parser returned error: Chain: sub parser failed: requested 10 bytes but input had only 6",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			value, remainder, err := parser.Chain(tt.parsers...)(tt.input)
-
-			// Can't use the helper as []string is not comparable
-
-			// Should only error if we wanted one
-			if (err != nil) != tt.wantErr {
-				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
-			}
-
-			// If we did get an error, the message should match what we expect
-			if err != nil {
-				if msg := err.Error(); msg != tt.err {
-					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
-				}
-			}
+			value, remainder, err := parser.Bind(length, tt.fn)(tt.input)
 
-			// The value should be as expected
-			if !reflect.DeepEqual(value, tt.value) {
-				t.Errorf("\nValue:\t%#v\nWanted:\t%#v\n", value, tt.value)
+			result := parserTest[string]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
 			}
 
-			// Likewise the remainder
-			if remainder != tt.remainder {
-				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, tt.remainder)
-			}
+			testParser(t, result)
 		})
 	}
 }
 
-func TestCount(t *testing.T) {
-	type test[T any] struct {
-		p         parser.Parser[T] // The parser to apply
-		name      string           // Identifying test case name
-		input     string           // Input to the parser
-		remainder string           // Expected remainder after parsing
-		err       string           // The expected error message, if there was one
-		value     []T              // The expected value after parsing
-		count     int              // Number of times to apply p to input
-		wantErr   bool             // Whether or not we wanted an error
+func TestBindHeredoc(t *testing.T) {
+	// A heredoc declares its own terminator e.g. "<<EOF\n...\nEOF"
+	delimiter := parser.Map(
+		parser.Chain(
+			parser.Exact("<<"),
+			parser.TakeUntil(func(r rune) bool { return r == '\n' }),
+			parser.Char('\n'),
+		),
+		func(parts []string) (string, error) { return parts[1], nil },
+	)
+	body := func(delim string) parser.Parser[string] {
+		return parser.Map(
+			parser.Chain(parser.TakeTo("\n"+delim), parser.Exact("\n"+delim)),
+			func(parts []string) (string, error) { return parts[0], nil },
+		)
 	}
 
-	tests := []test[string]{
+	heredoc := parser.Bind(delimiter, body)
+
+	tests := []struct {
+		name      string // Identifying test case name
+		input     string // Entire input to be parsed
+		value     string // The parsed value
+		remainder string // The remaining unparsed input
+		err       string // The expected error message (if there is one)
+		wantErr   bool   // Whether it should have returned an error
+	}{
+		{
+			name:      "simple",
+			input:     "<<EOF\nhello\nworld\nEOF\nrest",
+			value:     "hello\nworld",
+			remainder: "\nrest",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "custom delimiter",
+			input:     "<<END\nEOF is not the end\nEND",
+			value:     "EOF is not the end",
+			remainder: "",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "missing terminator",
+			input:     "<<EOF\nhello\nEND",
+			value:     "",
+			remainder: "",
+			wantErr:   true,
+			err:       "Bind: next parser returned error: Map: parser returned error: Chain: sub parser failed: TakeTo: match (\nEOF) not in input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := heredoc(tt.input)
+
+			result := parserTest[string]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
+			}
+
+			testParser(t, result)
+		})
+	}
+}
+
+func TestLazy(t *testing.T) {
+	// nested is a recursive parser for balanced parentheses like "(())" returning
+	// the nesting depth, it refers to itself so needs to be constructed lazily
+	var nested parser.Parser[int]
+	nested = parser.Try(
+		parser.Map(
+			parser.Chain(
+				parser.Map(parser.Char('('), func(string) (int, error) { return 0, nil }),
+				parser.Lazy(func() parser.Parser[int] { return nested }),
+				parser.Map(parser.Char(')'), func(string) (int, error) { return 0, nil }),
+			),
+			func(parts []int) (int, error) { return parts[1] + 1, nil },
+		),
+		parser.Map(parser.Exact("()"), func(string) (int, error) { return 1, nil }),
+	)
+
+	tests := []struct {
+		fn        func() parser.Parser[int] // The function returning the deferred parser
+		name      string                    // Identifying test case name
+		input     string                    // Entire input to be parsed
+		remainder string                    // The remaining unparsed input
+		err       string                    // The expected error message (if there is one)
+		value     int                       // The parsed value
+		wantErr   bool                      // Whether it should have returned an error
+	}{
+		{
+			name:      "nil fn",
+			input:     "()",
+			fn:        nil,
+			value:     0,
+			remainder: "",
+			wantErr:   true,
+			err:       "Lazy: fn must be a non-nil function",
+		},
+		{
+			name:      "fn returns nil parser",
+			input:     "()",
+			fn:        func() parser.Parser[int] { return nil },
+			value:     0,
+			remainder: "",
+			wantErr:   true,
+			err:       "Lazy: fn returned a nil parser",
+		},
+		{
+			name:      "empty input",
+			input:     "",
+			fn:        func() parser.Parser[int] { return nested },
+			value:     0,
+			remainder: "",
+			wantErr:   true,
+			err:       "Try: all parsers failed",
+		},
+		{
+			name:      "one level",
+			input:     "()rest",
+			fn:        func() parser.Parser[int] { return nested },
+			value:     1,
+			remainder: "rest",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "deeply nested",
+			input:     "((((()))))",
+			fn:        func() parser.Parser[int] { return nested },
+			value:     5,
+			remainder: "",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "unbalanced",
+			input:     "((()",
+			fn:        func() parser.Parser[int] { return nested },
+			value:     0,
+			remainder: "",
+			wantErr:   true,
+			err:       "Try: all parsers failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Lazy(tt.fn)(tt.input)
+
+			result := parserTest[int]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
+			}
+
+			testParser(t, result)
+		})
+	}
+}
+
+func TestTry(t *testing.T) {
+	type test[T any] struct {
+		value     T
+		name      string
+		input     string
+		remainder string
+		err       string
+		parsers   []parser.Parser[T]
+		wantErr   bool
+	}
+
+	tests := []test[string]{
+		{
+			name:  "empty input",
+			input: "",
+			parsers: []parser.Parser[string]{
+				// Some random parsers
+				parser.Take(3),
+				parser.Char('X'),
+				parser.TakeWhile(unicode.IsLetter),
+			},
+			value:     "",
+			remainder: "",
+			wantErr:   true,
+			err:       "Try: all parsers failed",
+		},
+		{
+			name:  "digits then symbols",
+			input: "123456*&^$£@",
+			parsers: []parser.Parser[string]{
+				parser.TakeWhile(unicode.IsLetter), // Will fail
+				parser.TakeWhile(unicode.IsDigit),  // Should return the output from this one
+			},
+			value:     "123456",
+			remainder: "*&^$£@",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:  "digits then symbols",
+			input: "xyzabc日ð本Ê語",
+			parsers: []parser.Parser[string]{
+				parser.OneOf("abc"),                // Will fail
+				parser.Char('本'),                   // Same
+				parser.ExactCaseInsensitive("XyZ"), // Should succeed
+			},
+			value:     "xyz",
+			remainder: "abc日ð本Ê語",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:  "first is successful",
+			input: "hello there",
+			parsers: []parser.Parser[string]{
+				parser.Take(2),        // Will succeed and return
+				parser.Exact("hello"), // Should never get invoked
+			},
+			value:     "he",
+			remainder: "llo there",
+			wantErr:   false,
+			err:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Try(tt.parsers...)(tt.input)
+
+			result := parserTest[string]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
+			}
+
+			testParser(t, result)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	type test[T any] struct {
+		value     []T
+		name      string
+		input     string
+		remainder string
+		err       string
+		parsers   []parser.Parser[T]
+		wantErr   bool
+	}
+
+	tests := []test[string]{
+		{
+			name:  "empty input",
+			input: "",
+			parsers: []parser.Parser[string]{
+				// Some random parsers
+				parser.Take(3),
+				parser.Char('X'),
+				parser.TakeWhile(unicode.IsLetter),
+			},
+			value:     nil,
+			remainder: "",
+			wantErr:   true,
+			err:       "Chain: sub parser failed: Take: cannot take from empty input",
+		},
+		{
+			name:  "pairs of chars",
+			input: "abcd1234",
+			parsers: []parser.Parser[string]{
+				// Chain pairs
+				parser.Take(2),
+				parser.Take(2),
+				parser.Take(2),
+				parser.Take(2),
+			},
+			value:     []string{"ab", "cd", "12", "34"},
+			remainder: "",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:  "too many pairs of chars",
+			input: "abcd1234",
+			parsers: []parser.Parser[string]{
+				// Chain pairs
+				parser.Take(2),
+				parser.Take(2),
+				parser.Take(2),
+				parser.Take(2),
+				parser.Take(2), // One more than there is in the input
+			},
+			value:     nil,
+			remainder: "",
+			wantErr:   true,
+			err:       "Chain: sub parser failed: Take: cannot take from empty input",
+		},
+		{
+			name:  "combo",
+			input: "abcd1234exact \t\n 日ð本Ê語 eof",
+			parsers: []parser.Parser[string]{
+				parser.TakeWhile(unicode.IsLetter), // Get abcd
+				parser.TakeWhile(unicode.IsDigit),  // 1234
+				parser.Exact("exact"),              // exact
+				parser.TakeWhile(unicode.IsSpace),  // Consume all whitespace
+				parser.TakeTo("語"),                 // Take up to this char
+				parser.Char('語'),                   // Take that char
+				parser.TakeWhile(unicode.IsSpace),  // More space
+				parser.Exact("eof"),                // Boom
+			},
+			value:     []string{"abcd", "1234", "exact", " \t\n ", "日ð本Ê", "語", " ", "eof"},
+			remainder: "",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:  "remainder left",
+			input: "abcd1234",
+			parsers: []parser.Parser[string]{
+				parser.TakeWhile(unicode.IsLetter), // Get abcd
+				parser.Take(2),                     // 12
+			},
+			value:     []string{"abcd", "12"},
+			remainder: "34",
+			wantErr:   false,
+			err:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Chain(tt.parsers...)(tt.input)
+
+			// Can't use the helper as []string is not comparable
+
+			// Should only error if we wanted one
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			// If we did get an error, the message should match what we expect
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			// The value should be as expected
+			if !reflect.DeepEqual(value, tt.value) {
+				t.Errorf("\nValue:\t%#v\nWanted:\t%#v\n", value, tt.value)
+			}
+
+			// Likewise the remainder
+			if remainder != tt.remainder {
+				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, tt.remainder)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	type test[T any] struct {
+		p         parser.Parser[T] // The parser to apply
+		name      string           // Identifying test case name
+		input     string           // Input to the parser
+		remainder string           // Expected remainder after parsing
+		err       string           // The expected error message, if there was one
+		value     []T              // The expected value after parsing
+		count     int              // Number of times to apply p to input
+		wantErr   bool             // Whether or not we wanted an error
+	}
+
+	tests := []test[string]{
+		{
+			name:      "empty input",
+			input:     "",
+			p:         parser.Take(2),
+			count:     2,
+			value:     nil,
+			remainder: "",
+			wantErr:   true,
+			err:       "Count: parser failed: Take: cannot take from empty input",
+		},
+		{
+			name:      "take pairs",
+			input:     "123456",
+			p:         parser.Take(2),
+			count:     3,
+			value:     []string{"12", "34", "56"},
+			remainder: "",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "input too short",
+			input:     "abcabcabc",
+			p:         parser.Exact("abc"),
+			count:     4,
+			value:     nil,
+			remainder: "",
+			wantErr:   true,
+			err:       "Count: parser failed: Exact: cannot match on empty input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Count(tt.p, tt.count)(tt.input)
+
+			// Can't use the helper as []string is not comparable
+
+			// Should only error if we wanted one
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			// If we did get an error, the message should match what we expect
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+			}
+
+			// The value should be as expected
+			if !reflect.DeepEqual(value, tt.value) {
+				t.Errorf("\nValue:\t%#v\nWanted:\t%#v\n", value, tt.value)
+			}
+
+			// Likewise the remainder
+			if remainder != tt.remainder {
+				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, tt.remainder)
+			}
+		})
+	}
+}
+
+func TestFoldMany(t *testing.T) {
+	type test[T, A any] struct {
+		p         parser.Parser[T] // The parser to apply repeatedly
+		init      func() A         // The function producing the initial accumulator
+		step      func(A, T) A     // The fold function
+		name      string           // Identifying test case name
+		input     string           // Input to the parser
+		remainder string           // Expected remainder after parsing
+		err       string           // The expected error message, if there was one
+		value     A                // The expected value after parsing
+		wantErr   bool             // Whether or not we wanted an error
+	}
+
+	zero := func() int { return 0 }
+	sum := func(acc, n int) int { return acc + n }
+	digit := parser.Map(parser.TakeWhileBetween(1, 1, unicode.IsDigit), strconv.Atoi)
+
+	tests := []test[int, int]{
+		{
+			name:      "nil init",
+			input:     "123",
+			p:         digit,
+			init:      nil,
+			step:      sum,
+			value:     0,
+			remainder: "",
+			wantErr:   true,
+			err:       "FoldMany: init must be a non-nil function",
+		},
+		{
+			name:      "nil step",
+			input:     "123",
+			p:         digit,
+			init:      zero,
+			step:      nil,
+			value:     0,
+			remainder: "",
+			wantErr:   true,
+			err:       "FoldMany: step must be a non-nil function",
+		},
+		{
+			name:      "empty input",
+			input:     "",
+			p:         digit,
+			init:      zero,
+			step:      sum,
+			value:     0,
+			remainder: "",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "no matches",
+			input:     "abc",
+			p:         digit,
+			init:      zero,
+			step:      sum,
+			value:     0,
+			remainder: "abc",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "sum digits",
+			input:     "12345rest",
+			p:         digit,
+			init:      zero,
+			step:      sum,
+			value:     15,
+			remainder: "rest",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "sum digits whole input",
+			input:     "999",
+			p:         digit,
+			init:      zero,
+			step:      sum,
+			value:     27,
+			remainder: "",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:  "no progress",
+			input: "abc",
+			p: func(input string) (int, string, error) {
+				// Always succeeds but never consumes anything
+				return 1, input, nil
+			},
+			init:      zero,
+			step:      sum,
+			value:     0,
+			remainder: "abc",
+			wantErr:   false,
+			err:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.FoldMany(tt.p, tt.init, tt.step)(tt.input)
+
+			result := parserTest[int]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
+			}
+
+			testParser(t, result)
+		})
+	}
+}
+
+func TestSkipMany(t *testing.T) {
+	tests := []struct {
+		p         parser.Parser[string] // The parser to apply repeatedly
+		name      string                // Identifying test case name
+		input     string                // Input to the parser
+		value     string                // Expected value after parsing
+		remainder string                // Expected remainder after parsing
+	}{
+		{
+			name:      "empty input",
+			input:     "",
+			p:         parser.Exact("abc"),
+			value:     "",
+			remainder: "",
+		},
+		{
+			name:      "no matches",
+			input:     "xyz",
+			p:         parser.Exact("abc"),
+			value:     "",
+			remainder: "xyz",
+		},
+		{
+			name:      "some matches",
+			input:     "abcabcabcxyz",
+			p:         parser.Exact("abc"),
+			value:     "abcabcabc",
+			remainder: "xyz",
+		},
+		{
+			name:      "whole input",
+			input:     "日ð本日ð本",
+			p:         parser.Exact("日ð本"),
+			value:     "日ð本日ð本",
+			remainder: "",
+		},
+		{
+			name:      "whitespace",
+			input:     " \t\n  value",
+			p:         parser.OneOf(" \t\n"),
+			value:     " \t\n  ",
+			remainder: "value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.SkipMany(tt.p)(tt.input)
+
+			result := parserTest[string]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       false,
+				wantErrMsg:    "",
+			}
+
+			testParser(t, result)
+		})
+	}
+}
+
+func TestSkipCount(t *testing.T) {
+	tests := []struct {
+		p         parser.Parser[string] // The parser to apply
+		name      string                // Identifying test case name
+		input     string                // Input to the parser
+		value     string                // Expected value after parsing
+		remainder string                // Expected remainder after parsing
+		err       string                // The expected error message, if there was one
+		count     int                   // Number of times to apply p to input
+		wantErr   bool                  // Whether or not we wanted an error
+	}{
+		{
+			name:      "empty input",
+			input:     "",
+			p:         parser.Take(2),
+			count:     2,
+			value:     "",
+			remainder: "",
+			wantErr:   true,
+			err:       "SkipCount: parser failed: Take: cannot take from empty input",
+		},
 		{
-			name:      "empty input",
-			input:     "",
+			name:      "negative count",
+			input:     "123456",
 			p:         parser.Take(2),
-			count:     2,
-			value:     nil,
+			count:     -1,
+			value:     "",
 			remainder: "",
 			wantErr:   true,
-			err:       "Count: parser failed: Take: cannot take from empty input",
+			err:       "SkipCount: count must not be negative, got -1",
 		},
 		{
-			name:      "take pairs",
+			name:      "zero count",
 			input:     "123456",
 			p:         parser.Take(2),
+			count:     0,
+			value:     "",
+			remainder: "123456",
+			wantErr:   false,
+			err:       "",
+		},
+		{
+			name:      "skip pairs",
+			input:     "123456rest",
+			p:         parser.Take(2),
 			count:     3,
-			value:     []string{"12", "34", "56"},
-			remainder: "",
+			value:     "123456",
+			remainder: "rest",
 			wantErr:   false,
 			err:       "",
 		},
@@ -1934,44 +2705,227 @@ func TestCount(t *testing.T) {
 			input:     "abcabcabc",
 			p:         parser.Exact("abc"),
 			count:     4,
-			value:     nil,
+			value:     "",
 			remainder: "",
 			wantErr:   true,
-			err:       "Count: parser failed: Exact: cannot match on empty input",
+			err:       "SkipCount: parser failed: Exact: cannot match on empty input",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			value, remainder, err := parser.Count(tt.p, tt.count)(tt.input)
-
-			// Can't use the helper as []string is not comparable
+			value, remainder, err := parser.SkipCount(tt.p, tt.count)(tt.input)
 
-			// Should only error if we wanted one
-			if (err != nil) != tt.wantErr {
-				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			result := parserTest[string]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
 			}
 
-			// If we did get an error, the message should match what we expect
-			if err != nil {
-				if msg := err.Error(); msg != tt.err {
-					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
-				}
+			testParser(t, result)
+		})
+	}
+}
+
+func TestRecognize(t *testing.T) {
+	tests := []struct {
+		p         parser.Parser[[]string] // The parser to recognize the input of
+		name      string                  // Identifying test case name
+		input     string                  // Input to the parser
+		value     string                  // Expected value after parsing
+		remainder string                  // Expected remainder after parsing
+		err       string                  // The expected error message (if there is one)
+		wantErr   bool                    // Whether it should have returned an error
+	}{
+		{
+			name:      "match",
+			input:     "-12.5 rest",
+			p:         parser.Chain(parser.Char('-'), parser.AnyOf("0123456789"), parser.Char('.'), parser.AnyOf("0123456789")),
+			value:     "-12.5",
+			remainder: " rest",
+			wantErr:   false,
+		},
+		{
+			name:      "unicode",
+			input:     "日ð本日",
+			p:         parser.Chain(parser.Take(1), parser.Take(2)),
+			value:     "日ð本",
+			remainder: "日",
+			wantErr:   false,
+		},
+		{
+			name:      "no match",
+			input:     "12.5",
+			p:         parser.Chain(parser.Char('-'), parser.AnyOf("0123456789")),
+			value:     "",
+			remainder: "",
+			wantErr:   true,
+			err:       "Recognize: parser returned error: Chain: sub parser failed: Char: requested char (-) not found in input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Recognize(tt.p)(tt.input)
+
+			result := parserTest[string]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
 			}
 
-			// The value should be as expected
-			if !reflect.DeepEqual(value, tt.value) {
-				t.Errorf("\nValue:\t%#v\nWanted:\t%#v\n", value, tt.value)
+			testParser(t, result)
+		})
+	}
+}
+
+func TestPeek(t *testing.T) {
+	tests := []struct {
+		p         parser.Parser[string] // The parser to look ahead with
+		name      string                // Identifying test case name
+		input     string                // Input to the parser
+		value     string                // Expected value after parsing
+		remainder string                // Expected remainder after parsing
+		err       string                // The expected error message (if there is one)
+		wantErr   bool                  // Whether it should have returned an error
+	}{
+		{
+			name:      "match",
+			input:     "hello world",
+			p:         parser.Exact("hello"),
+			value:     "hello",
+			remainder: "hello world",
+			wantErr:   false,
+		},
+		{
+			name:      "no match",
+			input:     "goodbye",
+			p:         parser.Exact("hello"),
+			value:     "",
+			remainder: "",
+			wantErr:   true,
+			err:       "Peek: parser returned error: Exact: match (hello) not in input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Peek(tt.p)(tt.input)
+
+			result := parserTest[string]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
 			}
 
-			// Likewise the remainder
-			if remainder != tt.remainder {
-				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, tt.remainder)
+			testParser(t, result)
+		})
+	}
+}
+
+func TestNot(t *testing.T) {
+	tests := []struct {
+		p         parser.Parser[string] // The parser that mustn't match
+		name      string                // Identifying test case name
+		input     string                // Input to the parser
+		value     string                // Expected value after parsing
+		remainder string                // Expected remainder after parsing
+		err       string                // The expected error message (if there is one)
+		wantErr   bool                  // Whether it should have returned an error
+	}{
+		{
+			name:      "no match",
+			input:     "goodbye",
+			p:         parser.Exact("hello"),
+			value:     "",
+			remainder: "goodbye",
+			wantErr:   false,
+		},
+		{
+			name:      "empty input",
+			input:     "",
+			p:         parser.Exact("hello"),
+			value:     "",
+			remainder: "",
+			wantErr:   false,
+		},
+		{
+			name:      "match",
+			input:     "hello world",
+			p:         parser.Exact("hello"),
+			value:     "",
+			remainder: "",
+			wantErr:   true,
+			err:       "Not: parser matched (hello)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := parser.Not(tt.p)(tt.input)
+
+			result := parserTest[string]{
+				gotValue:      value,
+				gotRemainder:  remainder,
+				gotErr:        err,
+				wantValue:     tt.value,
+				wantRemainder: tt.remainder,
+				wantErr:       tt.wantErr,
+				wantErrMsg:    tt.err,
 			}
+
+			testParser(t, result)
 		})
 	}
 }
 
+func TestSucceed(t *testing.T) {
+	// As the last alternative in a Try, Succeed provides a default
+	sign := parser.Try(parser.OneOf("+-"), parser.Succeed("+"))
+
+	for input, want := range map[string]string{"-1": "-", "+1": "+", "1": "+", "": "+"} {
+		value, _, err := sign(input)
+
+		result := parserTest[string]{
+			gotValue:      value,
+			gotRemainder:  "",
+			gotErr:        err,
+			wantValue:     want,
+			wantRemainder: "",
+			wantErr:       false,
+			wantErrMsg:    "",
+		}
+
+		testParser(t, result)
+	}
+
+	value, remainder, err := parser.Succeed(42)("input")
+
+	result := parserTest[int]{
+		gotValue:      value,
+		gotRemainder:  remainder,
+		gotErr:        err,
+		wantValue:     42,
+		wantRemainder: "input",
+		wantErr:       false,
+		wantErrMsg:    "",
+	}
+
+	testParser(t, result)
+}
+
 func ExampleTake() {
 	input := "Hello I am some input for you to parser"
 
@@ -2198,6 +3152,71 @@ func ExampleMap() {
 	// Remainder: " <- this is a number"
 }
 
+func ExampleVerify() {
+	input := "99999/tcp" // Syntactically a number, but not a valid port
+
+	port := parser.Map(parser.TakeWhile(unicode.IsDigit), strconv.Atoi)
+	validPort := func(n int) bool { return n > 0 && n <= 65535 }
+
+	_, _, err := parser.Verify(port, validPort, "port out of range")(input)
+
+	fmt.Println(err)
+
+	// Output: port out of range (99999)
+}
+
+func ExampleBind() {
+	input := "5:hello,rest..." // A length prefixed string, the length tells us how many chars to take
+
+	length := parser.Map(parser.TakeWhile(unicode.IsDigit), strconv.Atoi)
+
+	value, remainder, err := parser.Bind(length, func(n int) parser.Parser[string] {
+		return parser.Map(
+			parser.Chain(parser.Char(':'), parser.Take(n), parser.Char(',')),
+			func(parts []string) (string, error) { return parts[1], nil },
+		)
+	})(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: "hello"
+	// Remainder: "rest..."
+}
+
+func ExampleLazy() {
+	input := "[[[x]]]rest..." // Arbitrarily nested brackets around an x
+
+	// item refers to itself, so it must be wrapped in Lazy as it hasn't
+	// been assigned yet when the Chain is constructed
+	var item parser.Parser[string]
+	item = parser.Try(
+		parser.Char('x'),
+		parser.Map(
+			parser.Chain(
+				parser.Char('['),
+				parser.Lazy(func() parser.Parser[string] { return item }),
+				parser.Char(']'),
+			),
+			func(parts []string) (string, error) { return parts[1], nil },
+		),
+	)
+
+	value, remainder, err := item(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: "x"
+	// Remainder: "rest..."
+}
+
 func ExampleTry() {
 	input := "xyzabc日ð本Ê語"
 
@@ -2255,6 +3274,120 @@ func ExampleCount() {
 	// Remainder: "rest..."
 }
 
+func ExampleFoldMany() {
+	input := "12345rest..." // Let's sum up the digits
+
+	digit := parser.Map(parser.TakeWhileBetween(1, 1, unicode.IsDigit), strconv.Atoi)
+
+	value, remainder, err := parser.FoldMany(
+		digit,
+		func() int { return 0 },
+		func(sum, n int) int { return sum + n },
+	)(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %d\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: 15
+	// Remainder: "rest..."
+}
+
+func ExampleSkipMany() {
+	input := " \t\n  some text" // Skip over the leading whitespace
+
+	value, remainder, err := parser.SkipMany(parser.OneOf(" \t\n"))(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: " \t\n  "
+	// Remainder: "some text"
+}
+
+func ExampleSkipCount() {
+	input := "12345678rest..." // Pairs of digits with a bit on the end
+
+	value, remainder, err := parser.SkipCount(parser.Take(2), 4)(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: "12345678"
+	// Remainder: "rest..."
+}
+
+func ExampleRecognize() {
+	input := "-12.5e3 metres" // A number, where we want the text of it rather than its parts
+
+	digits := parser.AnyOf("0123456789")
+	number := parser.Chain(parser.Optional("-"), digits, parser.Optional("."), digits, parser.Optional("e"), digits)
+
+	value, remainder, err := parser.Recognize(number)(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: "-12.5e3"
+	// Remainder: " metres"
+}
+
+func ExamplePeek() {
+	input := "0x1F" // Look at the prefix to decide how to parse the number
+
+	value, remainder, err := parser.Peek(parser.Exact("0x"))(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: "0x"
+	// Remainder: "0x1F"
+}
+
+func ExampleNot() {
+	// A keyword is only a keyword if it's not the start of a longer identifier
+	keyword := parser.Chain(parser.Exact("if"), parser.Not(parser.TakeWhileBetween(1, 1, unicode.IsLetter)))
+
+	_, remainder, err := keyword("if x")
+	fmt.Printf("if x: %q, %v\n", remainder, err)
+
+	_, _, err = keyword("iffy")
+	fmt.Printf("iffy: %v\n", err)
+
+	// Output: if x: " x", <nil>
+	// iffy: Chain: sub parser failed: Not: parser matched (f)
+}
+
+func ExampleSucceed() {
+	// A sign is optional and defaults to +
+	sign := parser.Try(parser.OneOf("+-"), parser.Succeed("+"))
+
+	value, remainder, err := sign("42")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: "+"
+	// Remainder: "42"
+}
+
 // parserTest is a simple structure to encapsulate everything we need to test about
 // the result of applying a parser to some input.
 type parserTest[T comparable] struct {
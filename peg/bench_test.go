@@ -0,0 +1,64 @@
+package peg_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/peg"
+)
+
+// benchGrammar is a grammar for a small JSON-like language.
+const benchGrammar = `
+document <- _ value !.
+value    <- (object / array / string / number / literal) _
+object   <- '{' _ (member (',' _ member)*)? '}'
+member   <- string _ ':' _ value
+array    <- '[' _ (value (',' _ value)*)? ']'
+string   <- '"' ([^"\\] / '\\' .)* '"'
+number   <- '-'? [0-9]+ ('.' [0-9]+)?
+literal  <- 'true' / 'false' / 'null'
+_        <- [ \t\r\n]*
+`
+
+// benchDocument is a document in benchGrammar with many similar objects.
+var benchDocument = "[" + strings.Repeat(`{"name": "service", "port": 8080, "tags": ["a", "b\"c"], "enabled": true},`, 200) + "null]"
+
+func BenchmarkCompile(b *testing.B) {
+	for b.Loop() {
+		if _, err := peg.Compile(benchGrammar); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	p, err := peg.Compile(benchGrammar)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(benchDocument)))
+
+	for b.Loop() {
+		if _, _, err := p(benchDocument); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParserError(b *testing.B) {
+	p, err := peg.Compile(benchGrammar)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	input := benchDocument[:len(benchDocument)-1] // Missing the closing ']'
+
+	b.SetBytes(int64(len(input)))
+
+	for b.Loop() {
+		if _, _, err := p(input); err == nil {
+			b.Fatal("expected an error")
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package peg
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// errNoMatch is the error the compiled parsers fail with internally, the [ParseError] describing
+// the failure is built from what the [machine] recorded along the way.
+//
+// Rules fail with it rather than wrapping the error from their expression, so that failing deep in
+// a nested input doesn't build up an error message as long as the nesting.
+var errNoMatch = errors.New("peg: no match")
+
+// Compile checks the grammar and compiles it into a [parser.Parser] that starts from the first rule,
+// see [Grammar.CompileRule].
+func (g *Grammar) Compile() (parser.Parser[Node], error) {
+	if len(g.Rules) == 0 {
+		return nil, g.fail(0, "grammar has no rules")
+	}
+
+	return g.CompileRule(g.Rules[0].Name)
+}
+
+// CompileRule checks the grammar and compiles it into a [parser.Parser] that starts from the named
+// rule, producing the [Node] for that rule.
+//
+// Like any other parser, it matches a prefix of the input and returns the rest as the remainder, a
+// grammar that must match all of its input can end the start rule with !. to say so. The parser is
+// safe to use concurrently.
+//
+// If the grammar refers to an undefined rule, defines a rule more than once, or has a left recursive
+// rule (one that could refer to itself again without consuming any input), the error will be a
+// [*SyntaxError] pointing at the problem. The parser fails with a [*ParseError] describing what was
+// expected at the furthest point in the input it got to.
+func (g *Grammar) CompileRule(name string) (parser.Parser[Node], error) {
+	return g.TraceRule(name, nil)
+}
+
+// TraceRule is like [Grammar.CompileRule], but the parser calls trace with an [Event] every time it
+// tries a rule and again when the rule matches or fails, which is a lot of calls but very useful for
+// working out why a grammar doesn't match what it's meant to.
+//
+// If the parser is used concurrently, trace is called concurrently too. A nil trace is the same as
+// [Grammar.CompileRule].
+func (g *Grammar) TraceRule(name string, trace func(Event)) (parser.Parser[Node], error) {
+	if err := g.check(); err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(g.Rules, func(rule Rule) bool { return rule.Name == name }) {
+		return nil, g.fail(0, "undefined rule %q", name)
+	}
+
+	// Each parse needs its own machine to record its failures, so we keep a pool of them rather
+	// than compiling the grammar again every time
+	machines := &sync.Pool{
+		New: func() any { return g.machine(trace) },
+	}
+
+	return func(input string) (Node, string, error) {
+		if !utf8.ValidString(input) {
+			return Node{}, "", newParseError(input, invalidUTF8Offset(input), "input not valid utf-8", nil)
+		}
+
+		m := machines.Get().(*machine) //nolint:forcetypeassert // Only machines are stored
+		defer machines.Put(m)
+
+		m.reset(len(input))
+
+		node, remainder, err := (*m.rules[name])(input)
+		if err != nil {
+			return Node{}, "", m.failure(input)
+		}
+
+		return node, remainder, nil
+	}, nil
+}
+
+// fail returns a new [SyntaxError] at offset into the grammar source.
+func (g *Grammar) fail(offset int, format string, args ...any) error {
+	err := &SyntaxError{Msg: fmt.Sprintf(format, args...), Offset: offset}
+	if offset <= len(g.src) && g.src != "" {
+		err.Line, err.Column = position(g.src, offset)
+	}
+
+	return err
+}
+
+// check returns an error if any rule is defined twice, refers to an undefined rule or is left
+// recursive.
+func (g *Grammar) check() error {
+	rules := make(map[string]Rule, len(g.Rules))
+	for _, rule := range g.Rules {
+		if _, ok := rules[rule.Name]; ok {
+			return g.fail(rule.Span.Start, "rule %q already defined", rule.Name)
+		}
+
+		rules[rule.Name] = rule
+	}
+
+	for _, rule := range g.Rules {
+		if err := g.checkReferences(rule.Expr, rules); err != nil {
+			return err
+		}
+	}
+
+	nullable := g.nullable()
+
+	// The rules each rule could call before consuming any input
+	leftmost := make(map[string][]string, len(g.Rules))
+	for _, rule := range g.Rules {
+		leftmost[rule.Name] = leftReferences(rule.Expr, nullable, nil)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(g.Rules))
+
+	var path []string
+
+	// visit walks the leftmost calls from name depth first, returning the first cycle it finds
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, next := range leftmost[name] {
+			switch state[next] {
+			case visiting:
+				start := slices.Index(path, next)
+				return append(slices.Clone(path[start:]), next)
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+
+		return nil
+	}
+
+	for _, rule := range g.Rules {
+		if state[rule.Name] != unvisited {
+			continue
+		}
+
+		if cycle := visit(rule.Name); cycle != nil {
+			recursive := rules[cycle[0]]
+			return g.fail(recursive.Span.Start, "rule %q is left recursive: %s", recursive.Name, strings.Join(cycle, " -> "))
+		}
+	}
+
+	return nil
+}
+
+// checkReferences returns an error if expr refers to a rule that isn't defined.
+func (g *Grammar) checkReferences(expr Expr, rules map[string]Rule) error {
+	if expr.Kind == KindReference {
+		if _, ok := rules[expr.Text]; !ok {
+			return g.fail(expr.Span.Start, "undefined rule %q", expr.Text)
+		}
+	}
+
+	for _, child := range expr.Children {
+		if err := g.checkReferences(child, rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nullable returns the set of rules that can succeed without consuming any input.
+func (g *Grammar) nullable() map[string]bool {
+	nullable := make(map[string]bool, len(g.Rules))
+
+	// A rule is nullable if its expression is, given what we know so far, so keep going
+	// until we stop learning anything new
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.Rules {
+			if !nullable[rule.Name] && canBeEmpty(rule.Expr, nullable) {
+				nullable[rule.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	return nullable
+}
+
+// canBeEmpty reports whether expr can succeed without consuming any input, given the set of
+// nullable rules.
+func canBeEmpty(expr Expr, nullable map[string]bool) bool {
+	switch expr.Kind {
+	case KindChoice:
+		return slices.ContainsFunc(expr.Children, func(child Expr) bool { return canBeEmpty(child, nullable) })
+	case KindSequence:
+		for _, child := range expr.Children {
+			if !canBeEmpty(child, nullable) {
+				return false
+			}
+		}
+		return true
+	case KindZeroOrMore, KindOptional, KindAnd, KindNot:
+		return true
+	case KindOneOrMore, KindLabel, KindAction:
+		return canBeEmpty(expr.Children[0], nullable)
+	case KindLiteral:
+		return expr.Text == ""
+	case KindReference:
+		return nullable[expr.Text]
+	default:
+		return false
+	}
+}
+
+// leftReferences appends the names of the rules expr could call before consuming any input to names.
+func leftReferences(expr Expr, nullable map[string]bool, names []string) []string {
+	switch expr.Kind {
+	case KindReference:
+		return append(names, expr.Text)
+	case KindSequence:
+		for _, child := range expr.Children {
+			names = leftReferences(child, nullable, names)
+			if !canBeEmpty(child, nullable) {
+				break
+			}
+		}
+		return names
+	default:
+		for _, child := range expr.Children {
+			names = leftReferences(child, nullable, names)
+		}
+		return names
+	}
+}
+
+// machine is a compiled grammar along with the state of a single parse, the compiled parsers
+// record the furthest failure as they go so that it can be reported once the parse fails.
+type machine struct {
+	err      *ParseError                     // Set once the parse has gone too deep, failing every rule after
+	rules    map[string]*parser.Parser[Node] // The compiled rules by name
+	trace    func(Event)                     // Called as rules are tried, if not nil
+	expected []string                        // The leaf expressions that failed at the furthest failure
+	size     int                             // Length of the input
+	furthest int                             // Length of the remainder at the furthest failure, -1 if none
+	depth    int                             // Current nesting of rules
+	quiet    int                             // Current nesting of lookaheads, whose failures aren't recorded
+}
+
+// machine compiles the grammar into a new machine, the grammar must have been checked.
+func (g *Grammar) machine(trace func(Event)) *machine {
+	// References are compiled before the rules they refer to, so they hold on to the place the
+	// rule will be rather than looking it up by name on every call
+	m := &machine{rules: make(map[string]*parser.Parser[Node], len(g.Rules)), trace: trace}
+	for _, rule := range g.Rules {
+		m.rules[rule.Name] = new(parser.Parser[Node])
+	}
+
+	for _, rule := range g.Rules {
+		*m.rules[rule.Name] = m.rule(rule.Name, m.compile(rule.Expr))
+	}
+
+	return m
+}
+
+// reset prepares the machine to parse an input of the given size.
+func (m *machine) reset(size int) {
+	m.err = nil
+	m.expected = m.expected[:0]
+	m.size = size
+	m.furthest = -1
+	m.depth = 0
+	m.quiet = 0
+}
+
+// compile compiles a single expression into a parser returning the nodes of the rules it matched.
+func (m *machine) compile(expr Expr) parser.Parser[[]Node] {
+	switch expr.Kind {
+	case KindChoice:
+		alternatives := make([]parser.Parser[[]Node], 0, len(expr.Children))
+		for _, child := range expr.Children {
+			alternatives = append(alternatives, m.compile(child))
+		}
+		return parser.Try(alternatives...)
+	case KindSequence:
+		if len(expr.Children) == 0 {
+			return empty
+		}
+		items := make([]parser.Parser[[]Node], 0, len(expr.Children))
+		for _, child := range expr.Children {
+			items = append(items, m.compile(child))
+		}
+		return sequence(items)
+	case KindZeroOrMore:
+		return many(m.compile(expr.Children[0]))
+	case KindOneOrMore:
+		operand := m.compile(expr.Children[0])
+		return sequence([]parser.Parser[[]Node]{operand, many(operand)})
+	case KindOptional:
+		return parser.Try(m.compile(expr.Children[0]), empty)
+	case KindAnd, KindNot:
+		return m.lookahead(expr, m.compile(expr.Children[0]))
+	case KindLabel, KindAction:
+		// Annotations for code generators, which don't change what's matched
+		return m.compile(expr.Children[0])
+	case KindReference:
+		compiled := m.rules[expr.Text]
+		rule := parser.Lazy(func() parser.Parser[Node] { return *compiled })
+		return func(input string) ([]Node, string, error) {
+			node, rest, err := rule(input)
+			if err != nil {
+				return nil, "", err
+			}
+			return []Node{node}, rest, nil
+		}
+	default:
+		return m.leaf(expr)
+	}
+}
+
+// rule returns the parser for a single rule, which builds its [Node].
+func (m *machine) rule(name string, body parser.Parser[[]Node]) parser.Parser[Node] {
+	return func(input string) (Node, string, error) {
+		if m.err != nil {
+			return Node{}, "", m.err
+		}
+
+		m.depth++
+		defer func() { m.depth-- }()
+
+		if m.depth > MaxDepth {
+			offset := m.size - len(input)
+			m.err = &ParseError{Msg: fmt.Sprintf("exceeded max nesting depth of %d", MaxDepth), Offset: offset}
+			return Node{}, "", m.err
+		}
+
+		start := m.size - len(input)
+		if m.trace != nil {
+			m.trace(Event{Rule: name, Span: Span{Start: start, End: start}, Depth: m.depth, Step: StepEnter})
+		}
+
+		children, rest, err := body(input)
+		if err != nil {
+			if m.trace != nil {
+				m.trace(Event{Rule: name, Span: Span{Start: start, End: start}, Depth: m.depth, Step: StepFail})
+			}
+
+			return Node{}, "", errNoMatch
+		}
+
+		node := Node{
+			Rule:     name,
+			Text:     input[:len(input)-len(rest)],
+			Children: children,
+			Span:     Span{Start: start, End: m.size - len(rest)},
+		}
+
+		if m.trace != nil {
+			m.trace(Event{Rule: name, Span: node.Span, Depth: m.depth, Step: StepMatch})
+		}
+
+		return node, rest, nil
+	}
+}
+
+// leaf returns the parser for a literal, class or any char, which match directly against the input.
+func (m *machine) leaf(expr Expr) parser.Parser[[]Node] {
+	var match func(input string) (int, bool) // How many bytes of input expr matches, if any
+
+	switch expr.Kind {
+	case KindLiteral:
+		match = func(input string) (int, bool) {
+			return len(expr.Text), strings.HasPrefix(input, expr.Text)
+		}
+	case KindClass:
+		match = func(input string) (int, bool) {
+			if input == "" {
+				return 0, false
+			}
+			char, width := utf8.DecodeRuneInString(input)
+			in := slices.ContainsFunc(expr.Ranges, func(r Range) bool { return r.Low <= char && char <= r.High })
+			return width, in != expr.Negated
+		}
+	default:
+		match = func(input string) (int, bool) {
+			_, width := utf8.DecodeRuneInString(input)
+			return width, input != ""
+		}
+	}
+
+	description := describe(expr)
+
+	return func(input string) ([]Node, string, error) {
+		width, ok := match(input)
+		if !ok {
+			m.record(input, description)
+			return nil, "", errNoMatch
+		}
+
+		return nil, input[width:], nil
+	}
+}
+
+// lookahead returns the parser for a lookahead, which succeeds without consuming any input if
+// operand matches (for &) or doesn't (for !).
+func (m *machine) lookahead(expr Expr, operand parser.Parser[[]Node]) parser.Parser[[]Node] {
+	description := describe(expr)
+
+	return func(input string) ([]Node, string, error) {
+		// Whatever fails inside a lookahead is part of how it works, not what the input was missing
+		m.quiet++
+		_, _, err := operand(input)
+		m.quiet--
+
+		if m.err != nil {
+			return nil, "", m.err
+		}
+
+		if (err == nil) != (expr.Kind == KindAnd) {
+			m.record(input, description)
+			return nil, "", errNoMatch
+		}
+
+		return nil, input, nil
+	}
+}
+
+// record records that the expression with the given description failed to match at the start of
+// input, if that's at least as far into the input as any other failure.
+func (m *machine) record(input, description string) {
+	if m.quiet > 0 || (m.furthest != -1 && len(input) > m.furthest) {
+		return
+	}
+
+	if len(input) != m.furthest {
+		m.furthest = len(input)
+		m.expected = m.expected[:0]
+	}
+
+	m.expected = append(m.expected, description)
+}
+
+// failure returns the [ParseError] for a failed parse of input.
+func (m *machine) failure(input string) error {
+	if m.err != nil {
+		return newParseError(input, m.err.Offset, m.err.Msg, nil)
+	}
+
+	if m.furthest == -1 {
+		// Only possible if every failure was in a lookahead
+		return newParseError(input, 0, "input did not match", nil)
+	}
+
+	offset := len(input) - m.furthest
+	rest := input[offset:]
+
+	expected := slices.Clone(m.expected)
+	slices.Sort(expected)
+	expected = slices.Compact(expected)
+
+	var msg string
+	if rest == "" {
+		msg = "unexpected end of input"
+	} else {
+		char, _ := utf8.DecodeRuneInString(rest)
+		msg = fmt.Sprintf("unexpected %q", char)
+	}
+
+	if len(expected) == 1 {
+		msg += ", expected " + expected[0]
+	} else {
+		msg += ", expected one of " + strings.Join(expected, ", ")
+	}
+
+	return newParseError(input, offset, msg, expected)
+}
+
+// newParseError builds a [ParseError] at offset into input.
+func newParseError(input string, offset int, msg string, expected []string) *ParseError {
+	err := &ParseError{Msg: msg, Expected: expected, Offset: offset}
+	err.Line, err.Column = position(input, offset)
+
+	return err
+}
+
+// describe returns how expr is described when it's what the input was missing.
+func describe(expr Expr) string {
+	switch {
+	case expr.Kind == KindAny:
+		return "any char"
+	case expr.Kind == KindNot && expr.Children[0].Kind == KindAny:
+		return "end of input"
+	default:
+		return expr.String()
+	}
+}
+
+// many returns a parser that applies operand zero or more times, collecting all of its nodes.
+func many(operand parser.Parser[[]Node]) parser.Parser[[]Node] {
+	return parser.FoldMany(operand, func() []Node { return nil }, func(nodes, more []Node) []Node {
+		return append(nodes, more...)
+	})
+}
+
+// empty is a parser that always succeeds, consuming nothing.
+func empty(input string) ([]Node, string, error) {
+	return nil, input, nil
+}
+
+// sequence returns a parser that applies each of items in turn, collecting all of their nodes.
+//
+// It's [parser.Chain] without wrapping the error from a failed item, which is only ever errNoMatch
+// or the depth error, so a failed sequence doesn't cost an allocation.
+func sequence(items []parser.Parser[[]Node]) parser.Parser[[]Node] {
+	return func(input string) ([]Node, string, error) {
+		var nodes []Node
+
+		rest := input
+		for _, item := range items {
+			matched, after, err := item(rest)
+			if err != nil {
+				return nil, "", err
+			}
+
+			nodes = append(nodes, matched...)
+			rest = after
+		}
+
+		return nodes, rest, nil
+	}
+}
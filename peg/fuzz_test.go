@@ -0,0 +1,108 @@
+package peg_test
+
+// The fuzz tests in here check that neither the grammar parser nor a compiled grammar ever panic,
+// that printing a grammar and parsing it again gives the same grammar, and that the parse trees
+// from a compiled grammar are consistent with the input they came from.
+
+import (
+	"testing"
+
+	"go.followtheprocess.codes/parser/peg"
+)
+
+// checkNode checks that the text of node and every node inside it is the part of the input its
+// span covers, and that children are in order within their parent.
+func checkNode(t *testing.T, input string, node peg.Node) {
+	t.Helper()
+
+	if node.Span.Start < 0 || node.Span.End > len(input) || node.Span.Start > node.Span.End {
+		t.Fatalf("span %+v of %s is outside the input %q", node.Span, node.Rule, input)
+	}
+
+	if got := input[node.Span.Start:node.Span.End]; got != node.Text {
+		t.Fatalf("span %+v of %s covers %q, but its text is %q", node.Span, node.Rule, got, node.Text)
+	}
+
+	start := node.Span.Start
+	for _, child := range node.Children {
+		if child.Span.Start < start || child.Span.End > node.Span.End {
+			t.Fatalf("child %s %+v is out of order or outside its parent %s %+v", child.Rule, child.Span, node.Rule, node.Span)
+		}
+
+		start = child.Span.End
+
+		checkNode(t, input, child)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		arithmetic,
+		benchGrammar,
+		"a <- b c / !d e* / &(f / g)+ h?",
+		`a = [^\n\]-] 'it\'s' "\u0007\U0001F600" . ()`,
+		"# comment only",
+		"a <- (b",
+		"{ import \"strconv\" }\nn <int> <- d:[0-9]+ { return strconv.Atoi(d) } / x:(y z)* {x}",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, src string) {
+		grammar, err := peg.Parse(src)
+		if err != nil {
+			return
+		}
+
+		printed := grammar.String()
+
+		again, err := peg.Parse(printed)
+		if err != nil {
+			t.Fatalf("Parse(%q) printed as %q, which doesn't parse: %v", src, printed, err)
+		}
+
+		if reprinted := again.String(); reprinted != printed {
+			t.Fatalf("Parse(%q) printed as %q, which parses as %q", src, printed, reprinted)
+		}
+
+		// Compiling must either fail cleanly or give a parser that works
+		if p, err := grammar.Compile(); err == nil {
+			_, _, _ = p(src)
+		}
+	})
+}
+
+func FuzzParser(f *testing.F) {
+	p, err := peg.Compile(benchGrammar)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	seeds := []string{
+		`{"a": [1, -2.5, "x\"y"], "b": {"c": null}}`,
+		"[true, false,]",
+		`"unterminated`,
+		"",
+		"  [ ] ",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		node, remainder, err := p(input)
+		if err != nil {
+			if remainder != "" {
+				t.Fatalf("failed parse of %q returned a remainder %q", input, remainder)
+			}
+			return
+		}
+
+		if remainder != "" {
+			t.Fatalf("document ends with !. but left %q of %q", remainder, input)
+		}
+
+		checkNode(t, input, node)
+	})
+}
@@ -0,0 +1,615 @@
+package peg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+const (
+	identStart = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_" // Chars a rule name can start with
+	identChars = identStart + "0123456789"                               // Chars a rule name can contain
+	hexDigits  = "0123456789abcdefABCDEF"                                // Digits of a \u or \U escape
+)
+
+var (
+	// comment is a '#' and the rest of the line.
+	comment = parser.Map(parser.Chain(parser.Char('#'), parser.SkipMany(parser.NoneOf("\n"))), concat)
+
+	// spacing is any whitespace and comments between tokens.
+	spacing = parser.SkipMany(parser.Try(parser.OneOf(" \t\r\n"), comment))
+
+	// identifier is a rule name.
+	identifier = parser.Map(parser.Chain(parser.OneOf(identStart), parser.SkipMany(parser.OneOf(identChars))), concat)
+
+	// arrow separates a rule name from its expression.
+	arrow = parser.Try(parser.Map(parser.Chain(parser.Char('<'), parser.Char('-')), concat), parser.Char('='))
+)
+
+// escapes maps the char after a '\' in a literal or class to the char it stands for.
+var escapes = map[byte]rune{
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'\'': '\'',
+	'"':  '"',
+	'\\': '\\',
+	'[':  '[',
+	']':  ']',
+	'-':  '-',
+	'^':  '^',
+}
+
+// Parse parses the text of a grammar, see the package documentation for the syntax.
+//
+// Any error returned will be a [*SyntaxError], including if a rule is defined more than once.
+func Parse(src string) (*Grammar, error) {
+	if !utf8.ValidString(src) {
+		err := &SyntaxError{Msg: "input not valid utf-8", Offset: invalidUTF8Offset(src)}
+		err.Line, err.Column = position(src, err.Offset)
+
+		return nil, err
+	}
+
+	g := &grammar{src: src}
+
+	preamble, rules, err := g.rules()
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			// Shouldn't happen, everything in the grammar returns a SyntaxError
+			syntaxErr = &SyntaxError{Msg: err.Error()}
+		}
+
+		syntaxErr.Line, syntaxErr.Column = position(src, syntaxErr.Offset)
+
+		return nil, syntaxErr
+	}
+
+	return &Grammar{src: src, Preamble: preamble, Rules: rules}, nil
+}
+
+// grammar holds the state needed to parse the text of a particular grammar.
+type grammar struct {
+	src   string // The entire grammar source
+	depth int    // Current nesting depth of parenthesised expressions
+}
+
+// offset returns the byte offset in the source of a suffix of it.
+func (g *grammar) offset(rest string) int {
+	return len(g.src) - len(rest)
+}
+
+// span returns the span of the source from input up to rest.
+func (g *grammar) span(input, rest string) Span {
+	return Span{Start: g.offset(input), End: g.offset(rest)}
+}
+
+// fail returns a new [SyntaxError] at the start of rest.
+func (g *grammar) fail(rest, format string, args ...any) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Offset: g.offset(rest)}
+}
+
+// unexpected returns a [SyntaxError] describing the unexpected char at the start
+// of rest (or the unexpected end of input).
+func (g *grammar) unexpected(rest, context string) error {
+	if rest == "" {
+		return g.fail(rest, "unexpected end of input, %s", context)
+	}
+
+	char, _ := utf8.DecodeRuneInString(rest)
+
+	return g.fail(rest, "unexpected %q, %s", char, context)
+}
+
+// rules parses the whole grammar, an optional preamble followed by a list of rules.
+func (g *grammar) rules() (string, []Rule, error) {
+	rest := skip(g.src)
+
+	var preamble string
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		if preamble, rest, err = g.code(rest); err != nil {
+			return "", nil, err
+		}
+	}
+
+	rest = skip(rest)
+
+	if rest == "" {
+		return "", nil, g.fail(rest, "grammar has no rules")
+	}
+
+	var rules []Rule
+	defined := make(map[string]Rule)
+
+	for rest != "" {
+		var (
+			rule Rule
+			err  error
+		)
+
+		start := rest
+
+		rule, rest, err = g.rule(rest)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if first, ok := defined[rule.Name]; ok {
+			line, _ := position(g.src, first.Span.Start)
+			return "", nil, g.fail(start, "rule %q already defined on line %d", rule.Name, line)
+		}
+
+		defined[rule.Name] = rule
+		rules = append(rules, rule)
+	}
+
+	return preamble, rules, nil
+}
+
+// rule parses a single rule definition, a name, an optional type, an arrow and an expression.
+func (g *grammar) rule(input string) (Rule, string, error) {
+	name, rest, err := identifier(input)
+	if err != nil {
+		return Rule{}, "", g.unexpected(input, "expected a rule name")
+	}
+
+	rest = skip(rest)
+
+	var goType string
+	if strings.HasPrefix(rest, "<") && !strings.HasPrefix(rest, "<-") {
+		if goType, rest, err = g.ruleType(rest); err != nil {
+			return Rule{}, "", err
+		}
+	}
+
+	_, after, err := arrow(rest)
+	if err != nil {
+		return Rule{}, "", g.unexpected(rest, "expected '<-' after the rule name")
+	}
+
+	expr, rest, err := g.expression(skip(after))
+	if err != nil {
+		return Rule{}, "", err
+	}
+
+	return Rule{Name: name, Type: goType, Expr: expr, Span: Span{Start: g.offset(input), End: expr.Span.End}}, rest, nil
+}
+
+// ruleType parses the Go type of a rule, like <int> or <[]*Node>, input starts at the '<'.
+func (g *grammar) ruleType(input string) (string, string, error) {
+	// Outside of the string literals of struct tags, a Go type can't contain a '>' so the first
+	// one that isn't in a literal is the end
+	end := -1
+
+	for i := 1; i < len(input) && end == -1; i++ {
+		switch input[i] {
+		case '>':
+			end = i
+		case '\n':
+			return "", "", g.fail(input, "unterminated rule type, expected '>'")
+		case '"', '`':
+			length := goLiteralEnd(input[i:])
+			if length == -1 {
+				return "", "", g.fail(input[i:], "unterminated literal in rule type")
+			}
+			i += length - 1
+		}
+	}
+
+	if end == -1 {
+		return "", "", g.fail(input, "unterminated rule type, expected '>'")
+	}
+
+	goType := strings.TrimSpace(input[1:end])
+	if goType == "" {
+		return "", "", g.fail(input, "empty rule type")
+	}
+
+	return goType, skip(input[end+1:]), nil
+}
+
+// expression parses an ordered choice of sequences, a single sequence is returned as it is.
+func (g *grammar) expression(input string) (Expr, string, error) {
+	first, rest, err := g.alternative(input)
+	if err != nil {
+		return Expr{}, "", err
+	}
+
+	if !strings.HasPrefix(rest, "/") {
+		return first, rest, nil
+	}
+
+	alternatives := []Expr{first}
+	for strings.HasPrefix(rest, "/") {
+		var alternative Expr
+		if alternative, rest, err = g.alternative(skip(rest[1:])); err != nil {
+			return Expr{}, "", err
+		}
+
+		alternatives = append(alternatives, alternative)
+	}
+
+	end := alternatives[len(alternatives)-1].Span.End
+
+	return Expr{Kind: KindChoice, Children: alternatives, Span: Span{Start: g.offset(input), End: end}}, rest, nil
+}
+
+// alternative parses a sequence along with the action after it, if there is one.
+func (g *grammar) alternative(input string) (Expr, string, error) {
+	sequence, rest, err := g.sequence(input)
+	if err != nil {
+		return Expr{}, "", err
+	}
+
+	if !strings.HasPrefix(rest, "{") {
+		return sequence, rest, nil
+	}
+
+	code, after, err := g.code(rest)
+	if err != nil {
+		return Expr{}, "", err
+	}
+
+	action := Expr{
+		Kind:     KindAction,
+		Text:     code,
+		Children: []Expr{sequence},
+		Span:     Span{Start: sequence.Span.Start, End: g.offset(after)},
+	}
+
+	return action, skip(after), nil
+}
+
+// code parses a block of Go code in braces, returning the code without them. Braces inside strings,
+// runes and comments don't count towards finding the end of the block.
+func (g *grammar) code(input string) (string, string, error) {
+	depth := 0
+
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return input[1:i], input[i+1:], nil
+			}
+		case '"', '\'', '`':
+			end := goLiteralEnd(input[i:])
+			if end == -1 {
+				return "", "", g.fail(input[i:], "unterminated literal in code block")
+			}
+			i += end - 1
+		case '/':
+			switch {
+			case strings.HasPrefix(input[i:], "//"):
+				end := strings.IndexByte(input[i:], '\n')
+				if end == -1 {
+					end = len(input) - i
+				}
+				i += end - 1
+			case strings.HasPrefix(input[i:], "/*"):
+				end := strings.Index(input[i+2:], "*/")
+				if end == -1 {
+					return "", "", g.fail(input[i:], "unterminated comment in code block")
+				}
+				i += end + 3
+			}
+		}
+	}
+
+	return "", "", g.fail(input, "unterminated code block, expected '}'")
+}
+
+// goLiteralEnd returns the length of the Go string, raw string or rune literal at the start of s,
+// or -1 if it isn't terminated.
+func goLiteralEnd(s string) int {
+	quote := s[0]
+
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == quote:
+			return i + 1
+		case s[i] == '\\' && quote != '`':
+			i++ // Skip whatever is escaped
+		case s[i] == '\n' && quote != '`':
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// sequence parses a sequence of prefixed expressions, which ends at a '/', a ')', an action, the
+// start of the next rule or the end of the grammar. A sequence of one expression is returned as it is.
+func (g *grammar) sequence(input string) (Expr, string, error) {
+	var items []Expr
+
+	rest := input
+	for !g.sequenceEnd(rest) {
+		var (
+			item Expr
+			err  error
+		)
+
+		if item, rest, err = g.prefix(rest); err != nil {
+			return Expr{}, "", err
+		}
+
+		items = append(items, item)
+	}
+
+	switch len(items) {
+	case 0:
+		start := g.offset(input)
+		return Expr{Kind: KindSequence, Span: Span{Start: start, End: start}}, rest, nil
+	case 1:
+		return items[0], rest, nil
+	default:
+		span := Span{Start: items[0].Span.Start, End: items[len(items)-1].Span.End}
+		return Expr{Kind: KindSequence, Children: items, Span: span}, rest, nil
+	}
+}
+
+// sequenceEnd reports whether rest is at the end of a sequence.
+func (g *grammar) sequenceEnd(rest string) bool {
+	if rest == "" || rest[0] == '/' || rest[0] == ')' || rest[0] == '{' {
+		return true
+	}
+
+	// A name followed by an arrow (or a type) is the start of the next rule, not a reference
+	_, after, err := identifier(rest)
+	if err != nil {
+		return false
+	}
+
+	after = skip(after)
+
+	return strings.HasPrefix(after, "<") || strings.HasPrefix(after, "=")
+}
+
+// prefix parses an expression optionally preceded by a label or a lookahead, '&' or '!'.
+func (g *grammar) prefix(input string) (Expr, string, error) {
+	if label, after, err := identifier(input); err == nil && strings.HasPrefix(after, ":") {
+		operand, rest, err := g.prefix(skip(after[1:]))
+		if err != nil {
+			return Expr{}, "", err
+		}
+
+		expr := Expr{Kind: KindLabel, Text: label, Children: []Expr{operand}, Span: Span{Start: g.offset(input), End: operand.Span.End}}
+
+		return expr, rest, nil
+	}
+
+	var kind Kind
+
+	switch {
+	case strings.HasPrefix(input, "&"):
+		kind = KindAnd
+	case strings.HasPrefix(input, "!"):
+		kind = KindNot
+	default:
+		return g.suffix(input)
+	}
+
+	operand, rest, err := g.suffix(skip(input[1:]))
+	if err != nil {
+		return Expr{}, "", err
+	}
+
+	return Expr{Kind: kind, Children: []Expr{operand}, Span: Span{Start: g.offset(input), End: operand.Span.End}}, rest, nil
+}
+
+// suffix parses a primary expression optionally followed by a repetition, '*', '+' or '?'.
+func (g *grammar) suffix(input string) (Expr, string, error) {
+	operand, rest, err := g.primary(input)
+	if err != nil {
+		return Expr{}, "", err
+	}
+
+	var kind Kind
+
+	switch {
+	case strings.HasPrefix(rest, "*"):
+		kind = KindZeroOrMore
+	case strings.HasPrefix(rest, "+"):
+		kind = KindOneOrMore
+	case strings.HasPrefix(rest, "?"):
+		kind = KindOptional
+	default:
+		return operand, rest, nil
+	}
+
+	end := g.offset(rest) + 1
+
+	return Expr{Kind: kind, Children: []Expr{operand}, Span: Span{Start: operand.Span.Start, End: end}}, skip(rest[1:]), nil
+}
+
+// primary parses a literal, class, '.', rule reference or parenthesised expression, along with any
+// spacing after it.
+func (g *grammar) primary(input string) (Expr, string, error) {
+	if input == "" {
+		return Expr{}, "", g.unexpected(input, "expected an expression")
+	}
+
+	switch input[0] {
+	case '(':
+		return g.group(input)
+	case '\'', '"':
+		return g.literal(input)
+	case '[':
+		return g.class(input)
+	case '.':
+		return Expr{Kind: KindAny, Span: g.span(input, input[1:])}, skip(input[1:]), nil
+	}
+
+	name, rest, err := identifier(input)
+	if err != nil {
+		return Expr{}, "", g.unexpected(input, "expected an expression")
+	}
+
+	return Expr{Kind: KindReference, Text: name, Span: g.span(input, rest)}, skip(rest), nil
+}
+
+// group parses a parenthesised expression.
+func (g *grammar) group(input string) (Expr, string, error) {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if g.depth > MaxDepth {
+		return Expr{}, "", g.fail(input, "exceeded max nesting depth of %d", MaxDepth)
+	}
+
+	expr, rest, err := g.expression(skip(input[1:]))
+	if err != nil {
+		return Expr{}, "", err
+	}
+
+	if !strings.HasPrefix(rest, ")") {
+		return Expr{}, "", g.unexpected(rest, "expected ')' to close the group")
+	}
+
+	if expr.Kind == KindSequence && len(expr.Children) == 0 {
+		// An empty group is the only way to write an empty sequence, so it gets the span of the group
+		expr.Span = g.span(input, rest[1:])
+	}
+
+	return expr, skip(rest[1:]), nil
+}
+
+// literal parses a literal quoted with either ' or ".
+func (g *grammar) literal(input string) (Expr, string, error) {
+	quote := input[:1]
+	chars := parser.SkipMany(parser.NoneOf(quote + "\\\n"))
+
+	value := &strings.Builder{}
+	rest := input[1:]
+
+	for {
+		run, after, _ := chars(rest) // Can't fail, SkipMany matches zero or more
+		value.WriteString(run)
+		rest = after
+
+		switch {
+		case strings.HasPrefix(rest, quote):
+			expr := Expr{Kind: KindLiteral, Text: value.String(), Span: g.span(input, rest[1:])}
+			return expr, skip(rest[1:]), nil
+		case strings.HasPrefix(rest, `\`):
+			char, after, err := g.escape(rest)
+			if err != nil {
+				return Expr{}, "", err
+			}
+			value.WriteRune(char)
+			rest = after
+		default:
+			return Expr{}, "", g.fail(input, "unterminated literal")
+		}
+	}
+}
+
+// class parses a char class like [a-z_] or [^\n].
+func (g *grammar) class(input string) (Expr, string, error) {
+	expr := Expr{Kind: KindClass}
+
+	rest := input[1:]
+	if strings.HasPrefix(rest, "^") {
+		expr.Negated = true
+		rest = rest[1:]
+	}
+
+	for !strings.HasPrefix(rest, "]") {
+		low, after, err := g.classChar(input, rest)
+		if err != nil {
+			return Expr{}, "", err
+		}
+
+		high := low
+		if strings.HasPrefix(after, "-") && !strings.HasPrefix(after, "-]") {
+			if high, after, err = g.classChar(input, after[1:]); err != nil {
+				return Expr{}, "", err
+			}
+
+			if high < low {
+				return Expr{}, "", g.fail(rest, "invalid range %q-%q in char class", low, high)
+			}
+		}
+
+		expr.Ranges = append(expr.Ranges, Range{Low: low, High: high})
+		rest = after
+	}
+
+	if len(expr.Ranges) == 0 {
+		return Expr{}, "", g.fail(input, "empty char class")
+	}
+
+	expr.Span = g.span(input, rest[1:])
+
+	return expr, skip(rest[1:]), nil
+}
+
+// classChar parses a single, possibly escaped, char in the class starting at class.
+func (g *grammar) classChar(class, rest string) (rune, string, error) {
+	if rest == "" || rest[0] == '\n' {
+		return 0, "", g.fail(class, "unterminated char class")
+	}
+
+	if rest[0] == '\\' {
+		return g.escape(rest)
+	}
+
+	char, width := utf8.DecodeRuneInString(rest)
+
+	return char, rest[width:], nil
+}
+
+// escape parses an escape sequence in a literal or class, input starts at the '\'.
+func (g *grammar) escape(input string) (rune, string, error) {
+	if len(input) < 2 {
+		return 0, "", g.fail(input, "unterminated escape sequence")
+	}
+
+	if char, ok := escapes[input[1]]; ok {
+		return char, input[2:], nil
+	}
+
+	var digits int
+
+	switch input[1] {
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	default:
+		char, _ := utf8.DecodeRuneInString(input[1:])
+		return 0, "", g.fail(input, "invalid escape sequence '\\%c'", char)
+	}
+
+	hex, rest, err := parser.Count(parser.OneOf(hexDigits), digits)(input[2:])
+	if err != nil {
+		return 0, "", g.fail(input, "invalid escape sequence, '\\%c' must be followed by %d hex digits", input[1], digits)
+	}
+
+	code, err := strconv.ParseUint(strings.Join(hex, ""), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, "", g.fail(input, "invalid escape sequence, %s is not a valid char", input[:len(input)-len(rest)])
+	}
+
+	return rune(code), rest, nil
+}
+
+// skip skips any spacing at the start of input.
+func skip(input string) string {
+	_, rest, _ := spacing(input) // Can't fail, SkipMany matches zero or more
+	return rest
+}
+
+// concat joins the values from a [parser.Chain] back into the text they were parsed from.
+func concat(parts []string) (string, error) {
+	return strings.Join(parts, ""), nil
+}
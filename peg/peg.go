@@ -0,0 +1,430 @@
+// Package peg compiles Parsing Expression Grammars, given as text at runtime, into a [parser.Parser]
+// that produces a generic parse tree, for formats that are defined by users rather than in Go.
+//
+// A grammar is a list of rules, the first of which is where parsing starts:
+//
+//	# Comments start with a '#' and run to the end of the line
+//	sum    <- number (('+' / '-') number)*
+//	number <- [0-9]+ / '(' sum ')'
+//
+// Each rule is a name, an arrow ('<-' or '=') and an expression built from:
+//
+//	'abc' "abc"   a literal, with the escapes \n \r \t \' \" \\ \[ \] \- \uXXXX and \UXXXXXXXX
+//	[a-z_] [^\n]  a char class, optionally negated with a leading ^
+//	.             any single char
+//	name          a reference to another rule
+//	(e)           grouping
+//	e* e+ e?      zero or more, one or more, optional
+//	&e !e         positive and negative lookahead, which never consume input
+//	e1 e2         a sequence
+//	e1 / e2       ordered choice, the first alternative that matches wins
+//
+// Rules can also be annotated for code generators like parsergen, with a Go type after the rule
+// name, labels on the items of a sequence and a block of Go code (an action) after a sequence, along
+// with a block of code before the first rule (the preamble):
+//
+//	{ import "strconv" }
+//	number <int> <- digits:[0-9]+ { return strconv.Atoi(digits) }
+//
+// The annotations are kept in the [Grammar] but don't change what it matches, so compiling an
+// annotated grammar ignores them.
+//
+// Matching follows the usual PEG semantics: choice is ordered and never reconsidered once an
+// alternative has matched, and repetition is greedy and never backtracks. The grammar itself is
+// parsed with the combinators in [parser], and a compiled grammar is built around [parser.Try],
+// [parser.FoldMany] and [parser.Lazy], so it's an ordinary [parser.Parser] that can be combined
+// with any other.
+//
+// A rule that refers to a rule that isn't defined, or that could call itself again without consuming
+// any input (left recursion) would never terminate, so both are reported when the grammar is compiled.
+package peg // import "go.followtheprocess.codes/parser/peg"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// MaxDepth is the maximum nesting of rules in a parse, deeper input is rejected with a [ParseError]
+// rather than risk exhausting the stack.
+const MaxDepth = 10000
+
+// Kind is the kind of an [Expr].
+type Kind int
+
+const (
+	KindChoice     Kind = iota // Ordered choice, e1 / e2
+	KindSequence               // A sequence, e1 e2
+	KindZeroOrMore             // Zero or more repetitions, e*
+	KindOneOrMore              // One or more repetitions, e+
+	KindOptional               // An optional expression, e?
+	KindAnd                    // Positive lookahead, &e
+	KindNot                    // Negative lookahead, !e
+	KindLiteral                // A literal string, 'abc'
+	KindClass                  // A char class, [a-z]
+	KindAny                    // Any single char, .
+	KindReference              // A reference to a rule by name
+	KindLabel                  // A labelled expression, name:e
+	KindAction                 // A sequence followed by a block of code, e { code }
+)
+
+// String implements [fmt.Stringer] for [Kind].
+func (k Kind) String() string {
+	switch k {
+	case KindChoice:
+		return "choice"
+	case KindSequence:
+		return "sequence"
+	case KindZeroOrMore:
+		return "zero or more"
+	case KindOneOrMore:
+		return "one or more"
+	case KindOptional:
+		return "optional"
+	case KindAnd:
+		return "and"
+	case KindNot:
+		return "not"
+	case KindLiteral:
+		return "literal"
+	case KindClass:
+		return "class"
+	case KindAny:
+		return "any"
+	case KindReference:
+		return "reference"
+	case KindLabel:
+		return "label"
+	case KindAction:
+		return "action"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Span is the half open range of byte offsets [Start, End) of a piece of the input.
+type Span struct {
+	Start int // Offset of the first byte
+	End   int // Offset of the byte after the last one
+}
+
+// Range is an inclusive range of chars in a char class, a single char has Low == High.
+type Range struct {
+	Low  rune // The first char in the range
+	High rune // The last char in the range
+}
+
+// Expr is a parsing expression, one node of the syntax tree of a [Grammar].
+type Expr struct {
+	Text     string  // The value of a literal, name of a referenced rule, label, or code of an action
+	Children []Expr  // The alternatives of a choice, the items of a sequence, or the single operand of the others
+	Ranges   []Range // The chars matched by a class
+	Span     Span    // Where the expression is in the grammar source
+	Kind     Kind    // The kind of expression
+	Negated  bool    // Whether a class matches the chars not in Ranges instead
+}
+
+// String returns the expression in grammar syntax, with the parentheses needed to parse it back to
+// the same tree.
+func (e Expr) String() string {
+	s := &strings.Builder{}
+	e.format(s)
+
+	return s.String()
+}
+
+// precedence returns how tightly the expression binds, so we know when it needs parentheses.
+func (e Expr) precedence() int {
+	switch e.Kind {
+	case KindChoice:
+		return 0
+	case KindSequence, KindAction:
+		return 1
+	case KindAnd, KindNot, KindLabel:
+		return 2
+	case KindZeroOrMore, KindOneOrMore, KindOptional:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// format writes the expression to s in grammar syntax.
+func (e Expr) format(s *strings.Builder) {
+	// Operands are wrapped in parentheses if they bind less tightly than the operator needs
+	operand := func(child Expr, precedence int) {
+		if child.precedence() < precedence {
+			s.WriteByte('(')
+			child.format(s)
+			s.WriteByte(')')
+			return
+		}
+		child.format(s)
+	}
+
+	switch e.Kind {
+	case KindChoice:
+		for i, child := range e.Children {
+			if i > 0 {
+				s.WriteString(" / ")
+			}
+			operand(child, 1)
+		}
+	case KindSequence:
+		if len(e.Children) == 0 {
+			s.WriteString("()")
+			return
+		}
+		for i, child := range e.Children {
+			if i > 0 {
+				s.WriteByte(' ')
+			}
+			operand(child, 2)
+		}
+	case KindAnd, KindNot:
+		if e.Kind == KindAnd {
+			s.WriteByte('&')
+		} else {
+			s.WriteByte('!')
+		}
+		operand(e.Children[0], 3)
+	case KindZeroOrMore, KindOneOrMore, KindOptional:
+		operand(e.Children[0], 4)
+		switch e.Kind {
+		case KindZeroOrMore:
+			s.WriteByte('*')
+		case KindOneOrMore:
+			s.WriteByte('+')
+		default:
+			s.WriteByte('?')
+		}
+	case KindLiteral:
+		s.WriteByte('"')
+		for _, char := range e.Text {
+			writeChar(s, char, `"\`)
+		}
+		s.WriteByte('"')
+	case KindClass:
+		s.WriteByte('[')
+		if e.Negated {
+			s.WriteByte('^')
+		}
+		for i, r := range e.Ranges {
+			special := `]\-`
+			if i == 0 && !e.Negated {
+				special += "^" // A leading ^ would negate the class
+			}
+			writeChar(s, r.Low, special)
+			if r.High != r.Low {
+				s.WriteByte('-')
+				writeChar(s, r.High, special)
+			}
+		}
+		s.WriteByte(']')
+	case KindAny:
+		s.WriteByte('.')
+	case KindReference:
+		s.WriteString(e.Text)
+	case KindLabel:
+		s.WriteString(e.Text)
+		s.WriteByte(':')
+		operand(e.Children[0], 2)
+	case KindAction:
+		operand(e.Children[0], 1)
+		s.WriteString(" {")
+		s.WriteString(e.Text)
+		s.WriteByte('}')
+	}
+}
+
+// writeChar writes a single char of a literal or class to s, escaping it if it's one of special
+// or isn't printable.
+func writeChar(s *strings.Builder, char rune, special string) {
+	switch {
+	case char == '\n':
+		s.WriteString(`\n`)
+	case char == '\r':
+		s.WriteString(`\r`)
+	case char == '\t':
+		s.WriteString(`\t`)
+	case strings.ContainsRune(special, char):
+		s.WriteByte('\\')
+		s.WriteRune(char)
+	case !strconv.IsPrint(char) && char <= 0xFFFF:
+		fmt.Fprintf(s, `\u%04X`, char)
+	case !strconv.IsPrint(char):
+		fmt.Fprintf(s, `\U%08X`, char)
+	default:
+		s.WriteRune(char)
+	}
+}
+
+// Rule is a single named rule in a [Grammar].
+type Rule struct {
+	Name string // The rule name
+	Type string // The Go type of the rule's value, if it's annotated with one
+	Expr Expr   // The expression the rule matches
+	Span Span   // Where the rule definition is in the grammar source
+}
+
+// Grammar is a parsed grammar, ready to be compiled with [Grammar.Compile].
+type Grammar struct {
+	src      string // The source the grammar was parsed from, if any
+	Preamble string // The code in the block before the first rule, if there is one
+	Rules    []Rule // The rules in the order they were defined, the first is the start rule
+}
+
+// String returns the grammar in grammar syntax, one rule per line.
+func (g *Grammar) String() string {
+	s := &strings.Builder{}
+	if g.Preamble != "" {
+		s.WriteByte('{')
+		s.WriteString(g.Preamble)
+		s.WriteString("}\n")
+	}
+
+	for _, rule := range g.Rules {
+		s.WriteString(rule.Name)
+		if rule.Type != "" {
+			s.WriteString(" <")
+			s.WriteString(rule.Type)
+			s.WriteByte('>')
+		}
+		s.WriteString(" <- ")
+		rule.Expr.format(s)
+		s.WriteByte('\n')
+	}
+
+	return s.String()
+}
+
+// Node is a node in the parse tree produced by a compiled grammar, one for each rule that matched.
+//
+// Literals, classes and the other expressions within a rule don't get nodes of their own, a rule's
+// Text covers everything it matched and its Children are the nodes for the rules it referred to.
+type Node struct {
+	Rule     string // The name of the rule that matched
+	Text     string // The text the rule matched
+	Children []Node // The nodes of the rules referred to within this one, in order
+	Span     Span   // Where the text is in the input
+}
+
+// String returns the parse tree as an indented outline, one node per line.
+func (n Node) String() string {
+	s := &strings.Builder{}
+	n.outline(s, 0)
+
+	return s.String()
+}
+
+// outline writes the node and its children to s, indented by depth.
+func (n Node) outline(s *strings.Builder, depth int) {
+	s.WriteString(strings.Repeat("  ", depth))
+	s.WriteString(n.Rule)
+	s.WriteByte(' ')
+	s.WriteString(strconv.Quote(n.Text))
+	s.WriteByte('\n')
+
+	for _, child := range n.Children {
+		child.outline(s, depth+1)
+	}
+}
+
+// SyntaxError is the error returned when a grammar is invalid, either because it can't be parsed
+// or because it can't be compiled.
+type SyntaxError struct {
+	Msg    string // Description of the problem
+	Offset int    // Byte offset in the grammar source at which the error occurred
+	Line   int    // 1 indexed line number of Offset, 0 if the grammar has no source
+	Column int    // 1 indexed column (in utf-8 chars) of Offset, 0 if the grammar has no source
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return "peg: " + e.Msg
+	}
+
+	return fmt.Sprintf("peg: %s at line %d, column %d", e.Msg, e.Line, e.Column)
+}
+
+// Step is what happened to a rule in an [Event].
+type Step int
+
+const (
+	StepEnter Step = iota // The rule is about to be tried
+	StepMatch             // The rule matched
+	StepFail              // The rule didn't match
+)
+
+// String implements [fmt.Stringer] for [Step].
+func (s Step) String() string {
+	switch s {
+	case StepEnter:
+		return "enter"
+	case StepMatch:
+		return "match"
+	case StepFail:
+		return "fail"
+	default:
+		return fmt.Sprintf("Step(%d)", int(s))
+	}
+}
+
+// Event is an attempt at matching a rule, reported to the trace function of a parser compiled with
+// [Grammar.TraceRule]. Each rule that's tried has a [StepEnter] event, followed by the events of the
+// rules it tries in turn and then a [StepMatch] or [StepFail] event.
+type Event struct {
+	Rule  string // The name of the rule
+	Span  Span   // The text the rule matched for StepMatch, otherwise empty at where it was tried
+	Depth int    // The nesting of the rule, 1 for the start rule
+	Step  Step   // What happened to the rule
+}
+
+// ParseError is the error returned when the input doesn't match a compiled grammar.
+type ParseError struct {
+	Msg      string   // Description of the problem
+	Expected []string // What would have let the parse continue at Offset, in grammar syntax
+	Offset   int      // Byte offset in the input at which the error occurred
+	Line     int      // 1 indexed line number of Offset
+	Column   int      // 1 indexed column (in utf-8 chars) of Offset
+}
+
+// Error implements the error interface for [ParseError].
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("peg: %s at line %d, column %d", e.Msg, e.Line, e.Column)
+}
+
+// Compile parses a grammar and compiles it, it's shorthand for [Parse] followed by [Grammar.Compile].
+func Compile(src string) (parser.Parser[Node], error) {
+	grammar, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return grammar.Compile()
+}
+
+// position returns the 1 indexed line and column of offset into src.
+func position(src string, offset int) (line, column int) {
+	before := src[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	return 1 + strings.Count(before, "\n"), 1 + utf8.RuneCountInString(before[lineStart:])
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid utf-8 sequence in s.
+func invalidUTF8Offset(s string) int {
+	for pos, char := range s {
+		if char == utf8.RuneError {
+			if _, width := utf8.DecodeRuneInString(s[pos:]); width == 1 {
+				return pos
+			}
+		}
+	}
+
+	return len(s)
+}
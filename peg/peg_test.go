@@ -0,0 +1,841 @@
+package peg_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.followtheprocess.codes/parser/peg"
+)
+
+// arithmetic is a grammar for sums and products of integers, with parentheses.
+const arithmetic = `# Arithmetic, with the usual precedence
+expr    <- _ sum !.
+sum     <- product (('+' / '-') _ product)*
+product <- value (('*' / '/') _ value)*
+value   <- number / '(' _ sum ')' _
+number  <- [0-9]+ _
+_       <- [ \t\n]*
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		src     string // The grammar source to parse
+		want    string // The grammar printed back with Grammar.String
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{
+			name:    "single rule",
+			src:     "greeting <- 'hello'",
+			want:    "greeting <- \"hello\"\n",
+			wantErr: false,
+		},
+		{
+			name:    "equals arrow and comments",
+			src:     "# A comment\na = 'x' # trailing\n# Another\nb = \"y\"\n",
+			want:    "a <- \"x\"\nb <- \"y\"\n",
+			wantErr: false,
+		},
+		{
+			name:    "precedence",
+			src:     "a <- b c / !d e* / &(f / g)+ h?",
+			want:    "a <- b c / !d e* / &(f / g)+ h?\n",
+			wantErr: false,
+		},
+		{
+			name:    "redundant parentheses",
+			src:     "a <- ((b)) (c d) ((e f) / g)",
+			want:    "a <- b (c d) (e f / g)\n",
+			wantErr: false,
+		},
+		{
+			name:    "classes",
+			src:     `a <- [a-z_] [^\n\]] [\-^] [-a] [a-] .`,
+			want:    `a <- [a-z_] [^\n\]] [\-^] [\-a] [a\-] .` + "\n",
+			wantErr: false,
+		},
+		{
+			name:    "leading caret in a class",
+			src:     `a <- [\^a]`,
+			want:    `a <- [\^a]` + "\n",
+			wantErr: false,
+		},
+		{
+			name:    "literal escapes",
+			src:     `a <- 'it\'s' "\"q\"\t\\" 'é\U0001F600' '\u0007'`,
+			want:    `a <- "it's" "\"q\"\t\\" "é😀" "\u0007"` + "\n",
+			wantErr: false,
+		},
+		{
+			name:    "empty literal and group",
+			src:     "a <- '' / ()",
+			want:    "a <- \"\" / ()\n",
+			wantErr: false,
+		},
+		{
+			name:    "empty rule",
+			src:     "a <-\nb <- a",
+			want:    "a <- ()\nb <- a\n",
+			wantErr: false,
+		},
+		{
+			name:    "annotations",
+			src:     "{ import \"strconv\" }\nsum <int> <- l:num '+' r:num { return l + r }\n  / num\nnum <int> = d:[0-9]+ { return strconv.Atoi(d) }",
+			want:    "{ import \"strconv\" }\nsum <int> <- l:num \"+\" r:num { return l + r } / num\nnum <int> <- d:[0-9]+ { return strconv.Atoi(d) }\n",
+			wantErr: false,
+		},
+		{
+			name:    "struct tags in type",
+			src:     "a <struct{ X int `json:\">\"` }> <- 'x' { return }\nb <struct{ Y int \"tag:\\\">\\\"\" }> <- a",
+			want:    "a <struct{ X int `json:\">\"` }> <- \"x\" { return }\nb <struct{ Y int \"tag:\\\">\\\"\" }> <- a\n",
+			wantErr: false,
+		},
+		{
+			name:    "braces in action code",
+			src:     "a <- 'x' { s := \"}\" + `{` + string('}') // }\n /* } */ return map[string]int{}, nil }",
+			want:    "a <- \"x\" { s := \"}\" + `{` + string('}') // }\n /* } */ return map[string]int{}, nil }\n",
+			wantErr: false,
+		},
+		{
+			name:    "labelled groups and lookaheads",
+			src:     "a <- x:(b c)* y:!d (e {e} / f) {x}",
+			want:    "a <- x:(b c)* y:!d (e {e} / f) {x}\n",
+			wantErr: false,
+		},
+		{
+			name:    "empty",
+			src:     "  # nothing\n",
+			err:     "peg: grammar has no rules at line 2, column 1",
+			wantErr: true,
+		},
+		{
+			name:    "missing arrow",
+			src:     "a 'x'",
+			err:     `peg: unexpected '\'', expected '<-' after the rule name at line 1, column 3`,
+			wantErr: true,
+		},
+		{
+			name:    "bad rule name",
+			src:     "1a <- 'x'",
+			err:     "peg: unexpected '1', expected a rule name at line 1, column 1",
+			wantErr: true,
+		},
+		{
+			name:    "unclosed group",
+			src:     "a <- ('x' / 'y'",
+			err:     "peg: unexpected end of input, expected ')' to close the group at line 1, column 16",
+			wantErr: true,
+		},
+		{
+			name:    "stray close",
+			src:     "a <- 'x')",
+			err:     "peg: unexpected ')', expected a rule name at line 1, column 9",
+			wantErr: true,
+		},
+		{
+			name:    "double suffix",
+			src:     "a <- 'x'*+",
+			err:     "peg: unexpected '+', expected an expression at line 1, column 10",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated literal",
+			src:     "a <- 'x\nb <- 'y'",
+			err:     "peg: unterminated literal at line 1, column 6",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated class",
+			src:     "a <- [abc",
+			err:     "peg: unterminated char class at line 1, column 6",
+			wantErr: true,
+		},
+		{
+			name:    "empty class",
+			src:     "a <- []",
+			err:     "peg: empty char class at line 1, column 6",
+			wantErr: true,
+		},
+		{
+			name:    "backwards range",
+			src:     "a <- [z-a]",
+			err:     "peg: invalid range 'z'-'a' in char class at line 1, column 7",
+			wantErr: true,
+		},
+		{
+			name:    "invalid escape",
+			src:     `a <- 'a\qb'`,
+			err:     `peg: invalid escape sequence '\q' at line 1, column 8`,
+			wantErr: true,
+		},
+		{
+			name:    "short unicode escape",
+			src:     `a <- '\u00'`,
+			err:     `peg: invalid escape sequence, '\u' must be followed by 4 hex digits at line 1, column 7`,
+			wantErr: true,
+		},
+		{
+			name:    "surrogate escape",
+			src:     `a <- '\uD800'`,
+			err:     `peg: invalid escape sequence, \uD800 is not a valid char at line 1, column 7`,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate rule",
+			src:     "a <- 'x'\nb <- a\na <- 'y'",
+			err:     `peg: rule "a" already defined on line 1 at line 3, column 1`,
+			wantErr: true,
+		},
+		{
+			name:    "unterminated action",
+			src:     "a <- 'x' { return \"}",
+			err:     "peg: unterminated literal in code block at line 1, column 19",
+			wantErr: true,
+		},
+		{
+			name:    "unclosed action",
+			src:     "a <- 'x' { return 1",
+			err:     "peg: unterminated code block, expected '}' at line 1, column 10",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated type",
+			src:     "a <int <- 'x'",
+			err:     "peg: unterminated rule type, expected '>' at line 1, column 3",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated literal in type",
+			src:     "a <struct{ X int `json:\"x\"> <- 'x'",
+			err:     "peg: unterminated literal in rule type at line 1, column 18",
+			wantErr: true,
+		},
+		{
+			name:    "label at end of input",
+			src:     "a <- x:",
+			err:     "peg: unexpected end of input, expected an expression at line 1, column 8",
+			wantErr: true,
+		},
+		{
+			name:    "empty type",
+			src:     "a < > <- 'x'",
+			err:     "peg: empty rule type at line 1, column 3",
+			wantErr: true,
+		},
+		{
+			name:    "invalid utf-8",
+			src:     "a <- '\xff'",
+			err:     "peg: input not valid utf-8 at line 1, column 7",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grammar, err := peg.Parse(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nParse(%q)\nerr:\t%v\nwantErr:\t%v\n", tt.src, err, tt.wantErr)
+			}
+
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("\nGot:\t%s\nWanted:\t%s\n", err, tt.err)
+				}
+
+				var syntaxErr *peg.SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("error %v is not a *peg.SyntaxError", err)
+				}
+				return
+			}
+
+			if got := grammar.String(); got != tt.want {
+				t.Errorf("\nGot:\t%q\nWanted:\t%q\n", got, tt.want)
+			}
+
+			// Printing the grammar must give the same tree back
+			again, err := peg.Parse(grammar.String())
+			if err != nil {
+				t.Fatalf("printed grammar %q doesn't parse: %v", grammar, err)
+			}
+
+			if again.String() != grammar.String() {
+				t.Errorf("printed grammar %q parses as %q", grammar, again)
+			}
+		})
+	}
+}
+
+func TestParseSpans(t *testing.T) {
+	src := "start <- 'a' b*\nb <- [x-z] / ."
+
+	grammar, err := peg.Parse(src)
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	// The text of each span, rule by rule, walking the expressions depth first
+	var got []string
+
+	var walk func(expr peg.Expr)
+	walk = func(expr peg.Expr) {
+		got = append(got, src[expr.Span.Start:expr.Span.End])
+		for _, child := range expr.Children {
+			walk(child)
+		}
+	}
+
+	for _, rule := range grammar.Rules {
+		got = append(got, src[rule.Span.Start:rule.Span.End])
+		walk(rule.Expr)
+	}
+
+	want := []string{
+		"start <- 'a' b*", "'a' b*", "'a'", "b*", "b",
+		"b <- [x-z] / .", "[x-z] / .", "[x-z]", ".",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("\nGot:\t%q\nWanted:\t%q\n", got, want)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	tests := []struct {
+		name string // Identifying test case name
+		src  string // The grammar source to compile
+		err  string // The expected error message
+	}{
+		{
+			name: "undefined rule",
+			src:  "a <- 'x' b\nb <- c",
+			err:  `peg: undefined rule "c" at line 2, column 6`,
+		},
+		{
+			name: "direct left recursion",
+			src:  "expr <- expr '-' term / term\nterm <- [0-9]",
+			err:  `peg: rule "expr" is left recursive: expr -> expr at line 1, column 1`,
+		},
+		{
+			name: "indirect left recursion",
+			src:  "a <- 'x' / b\nb <- c 'y'\nc <- a",
+			err:  `peg: rule "a" is left recursive: a -> b -> c -> a at line 1, column 1`,
+		},
+		{
+			name: "left recursion after a nullable prefix",
+			src:  "a <- b? ' '* a 'x'\nb <- 'y'",
+			err:  `peg: rule "a" is left recursive: a -> a at line 1, column 1`,
+		},
+		{
+			name: "left recursion through a nullable rule",
+			src:  "a <- b a / 'x'\nb <- 'y'*",
+			err:  `peg: rule "a" is left recursive: a -> a at line 1, column 1`,
+		},
+		{
+			name: "left recursion in a lookahead",
+			src:  "a <- !a 'x'",
+			err:  `peg: rule "a" is left recursive: a -> a at line 1, column 1`,
+		},
+		{
+			name: "left recursion in an unused rule",
+			src:  "a <- 'x'\nb <- 'y' / c\nc <- b",
+			err:  `peg: rule "b" is left recursive: b -> c -> b at line 2, column 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := peg.Compile(tt.src)
+			if err == nil {
+				t.Fatalf("Compile(%q) returned no error", tt.src)
+			}
+
+			if err.Error() != tt.err {
+				t.Errorf("\nGot:\t%s\nWanted:\t%s\n", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCompileRule(t *testing.T) {
+	grammar, err := peg.Parse(arithmetic)
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	number, err := grammar.CompileRule("number")
+	if err != nil {
+		t.Fatalf("CompileRule returned an unexpected error: %v", err)
+	}
+
+	node, remainder, err := number("42 + 1")
+	if err != nil {
+		t.Fatalf("number returned an unexpected error: %v", err)
+	}
+
+	if node.Rule != "number" || node.Text != "42 " || remainder != "+ 1" {
+		t.Errorf("number returned (%s %q, %q), wanted (number \"42 \", \"+ 1\")", node.Rule, node.Text, remainder)
+	}
+
+	_, err = grammar.CompileRule("missing")
+	if err == nil || err.Error() != `peg: undefined rule "missing" at line 1, column 1` {
+		t.Errorf("CompileRule with an undefined rule returned %v", err)
+	}
+
+	// A grammar built by hand has no source to point into
+	built := &peg.Grammar{Rules: []peg.Rule{{Name: "a", Expr: peg.Expr{Kind: peg.KindReference, Text: "b"}}}}
+
+	_, err = built.Compile()
+	if err == nil || err.Error() != `peg: undefined rule "b"` {
+		t.Errorf("Compile with a built grammar returned %v", err)
+	}
+
+	_, err = (&peg.Grammar{}).Compile()
+	if err == nil || err.Error() != "peg: grammar has no rules" {
+		t.Errorf("Compile with no rules returned %v", err)
+	}
+}
+
+func TestTraceRule(t *testing.T) {
+	grammar, err := peg.Parse("pair <- key '=' value\nkey <- [a-z]+\nvalue <- [0-9]+ / key")
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	var events []string
+
+	p, err := grammar.TraceRule("pair", func(event peg.Event) {
+		events = append(events, fmt.Sprintf("%d %s %s %d-%d", event.Depth, event.Step, event.Rule, event.Span.Start, event.Span.End))
+	})
+	if err != nil {
+		t.Fatalf("TraceRule returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = p("a=b"); err != nil {
+		t.Fatalf("parser returned an unexpected error: %v", err)
+	}
+
+	want := []string{
+		"1 enter pair 0-0",
+		"2 enter key 0-0",
+		"2 match key 0-1",
+		"2 enter value 2-2",
+		"3 enter key 2-2",
+		"3 match key 2-3",
+		"2 match value 2-3",
+		"1 match pair 0-3",
+	}
+
+	if !slices.Equal(events, want) {
+		t.Errorf("\nGot:\t%q\nWanted:\t%q\n", events, want)
+	}
+
+	events = nil
+
+	if _, _, err = p("="); err == nil {
+		t.Fatal("parser returned no error for invalid input")
+	}
+
+	want = []string{"1 enter pair 0-0", "2 enter key 0-0", "2 fail key 0-0", "1 fail pair 0-0"}
+	if !slices.Equal(events, want) {
+		t.Errorf("\nGot:\t%q\nWanted:\t%q\n", events, want)
+	}
+}
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		name          string // Identifying test case name
+		grammar       string // The grammar to compile
+		input         string // The input to parse
+		want          string // The expected parse tree, as printed by Node.String
+		wantRemainder string // The expected remainder
+		err           string // The expected error message, if there was one
+		wantErr       bool   // Whether or not we wanted an error
+	}{
+		{
+			name:          "literal",
+			grammar:       "a <- 'hello'",
+			input:         "hello world",
+			want:          "a \"hello\"\n",
+			wantRemainder: " world",
+			wantErr:       false,
+		},
+		{
+			name:          "nested rules",
+			grammar:       "pair <- word '=' word\nword <- [a-z]+",
+			input:         "key=value;",
+			want:          "pair \"key=value\"\n  word \"key\"\n  word \"value\"\n",
+			wantRemainder: ";",
+			wantErr:       false,
+		},
+		{
+			name:          "ordered choice",
+			grammar:       "a <- b / c\nb <- 'ab'\nc <- 'a'",
+			input:         "ac",
+			want:          "a \"a\"\n  c \"a\"\n",
+			wantRemainder: "c",
+			wantErr:       false,
+		},
+		{
+			name:          "repetition",
+			grammar:       "list <- item (',' item)*\nitem <- [0-9]",
+			input:         "1,2,3",
+			want:          "list \"1,2,3\"\n  item \"1\"\n  item \"2\"\n  item \"3\"\n",
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "repetition doesn't backtrack",
+			grammar:       "a <- 'x'* 'x'",
+			input:         "xxx",
+			err:           `peg: unexpected end of input, expected "x" at line 1, column 4`,
+			wantErr:       true,
+			wantRemainder: "",
+		},
+		{
+			name:          "lookahead",
+			grammar:       "word <- !keyword [a-z]+\nkeyword <- 'if' ![a-z]",
+			input:         "iffy",
+			want:          "word \"iffy\"\n",
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "negative lookahead fails",
+			grammar:       "word <- !keyword [a-z]+\nkeyword <- 'if' ![a-z]",
+			input:         "if",
+			err:           `peg: unexpected 'i', expected !keyword at line 1, column 1`,
+			wantRemainder: "",
+			wantErr:       true,
+		},
+		{
+			name:          "positive lookahead",
+			grammar:       "a <- &'ab' 'a'",
+			input:         "abc",
+			want:          "a \"a\"\n",
+			wantRemainder: "bc",
+			wantErr:       false,
+		},
+		{
+			name:          "unicode",
+			grammar:       "a <- [α-ω]+ . 'é'",
+			input:         "αβγ😀é!",
+			want:          "a \"αβγ😀é\"\n",
+			wantRemainder: "!",
+			wantErr:       false,
+		},
+		{
+			name:          "negated class",
+			grammar:       `line <- [^\n]* '\n'`,
+			input:         "hello\nworld",
+			want:          "line \"hello\\n\"\n",
+			wantRemainder: "world",
+			wantErr:       false,
+		},
+		{
+			name:          "empty match",
+			grammar:       "a <- b?\nb <- 'x'",
+			input:         "y",
+			want:          "a \"\"\n",
+			wantRemainder: "y",
+			wantErr:       false,
+		},
+		{
+			name:    "arithmetic",
+			grammar: arithmetic,
+			input:   "1 + 2*(3 - 4)",
+			want: `expr "1 + 2*(3 - 4)"
+  _ ""
+  sum "1 + 2*(3 - 4)"
+    product "1 "
+      value "1 "
+        number "1 "
+          _ " "
+    _ " "
+    product "2*(3 - 4)"
+      value "2"
+        number "2"
+          _ ""
+      _ ""
+      value "(3 - 4)"
+        _ ""
+        sum "3 - 4"
+          product "3 "
+            value "3 "
+              number "3 "
+                _ " "
+          _ " "
+          product "4"
+            value "4"
+              number "4"
+                _ ""
+        _ ""
+`,
+			wantRemainder: "",
+			wantErr:       false,
+		},
+		{
+			name:          "furthest failure",
+			grammar:       arithmetic,
+			input:         "1 + (2 * 3",
+			err:           `peg: unexpected end of input, expected one of ")", "*", "+", "-", "/", [ \t\n], [0-9] at line 1, column 11`,
+			wantRemainder: "",
+			wantErr:       true,
+		},
+		{
+			name:          "trailing input",
+			grammar:       arithmetic,
+			input:         "1 2",
+			err:           `peg: unexpected '2', expected one of "*", "+", "-", "/", [ \t\n], end of input at line 1, column 3`,
+			wantRemainder: "",
+			wantErr:       true,
+		},
+		{
+			name:          "any char at the end",
+			grammar:       "a <- 'x' .",
+			input:         "x",
+			err:           "peg: unexpected end of input, expected any char at line 1, column 2",
+			wantRemainder: "",
+			wantErr:       true,
+		},
+		{
+			name:          "error on a later line",
+			grammar:       "lines <- ([a-z]* '\\n')*  !.",
+			input:         "abc\ndef\ngh1\n",
+			err:           `peg: unexpected '1', expected one of "\n", [a-z] at line 3, column 3`,
+			wantRemainder: "",
+			wantErr:       true,
+		},
+		{
+			name:          "invalid utf-8",
+			grammar:       "a <- .*",
+			input:         "ab\xffc",
+			err:           "peg: input not valid utf-8 at line 1, column 3",
+			wantRemainder: "",
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := peg.Compile(tt.grammar)
+			if err != nil {
+				t.Fatalf("Compile(%q) returned an unexpected error: %v", tt.grammar, err)
+			}
+
+			node, remainder, err := p(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nParse(%q)\nerr:\t%v\nwantErr:\t%v\n", tt.input, err, tt.wantErr)
+			}
+
+			if remainder != tt.wantRemainder {
+				t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, tt.wantRemainder)
+			}
+
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("\nGot:\t%s\nWanted:\t%s\n", err, tt.err)
+				}
+
+				var parseErr *peg.ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("error %v is not a *peg.ParseError", err)
+				}
+				return
+			}
+
+			if got := node.String(); got != tt.want {
+				t.Errorf("\nGot:\n%s\nWanted:\n%s\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParserSpans(t *testing.T) {
+	p, err := peg.Compile("pair <- word '=' word\nword <- [a-zé]+")
+	if err != nil {
+		t.Fatalf("Compile returned an unexpected error: %v", err)
+	}
+
+	input := "café=au"
+
+	node, _, err := p(input)
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	want := []peg.Span{{Start: 0, End: 8}, {Start: 0, End: 5}, {Start: 6, End: 8}}
+	got := []peg.Span{node.Span, node.Children[0].Span, node.Children[1].Span}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("\nGot:\t%v\nWanted:\t%v\n", got, want)
+	}
+
+	for _, n := range []peg.Node{node, node.Children[0], node.Children[1]} {
+		if input[n.Span.Start:n.Span.End] != n.Text {
+			t.Errorf("span %v of %s doesn't cover its text %q", n.Span, n.Rule, n.Text)
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	p, err := peg.Compile("list <- '[' list? ']'")
+	if err != nil {
+		t.Fatalf("Compile returned an unexpected error: %v", err)
+	}
+
+	ok := strings.Repeat("[", peg.MaxDepth) + strings.Repeat("]", peg.MaxDepth)
+	if _, _, err := p(ok); err != nil {
+		t.Fatalf("nesting to MaxDepth returned an unexpected error: %v", err)
+	}
+
+	deep := "[" + ok + "]"
+
+	_, _, err = p(deep)
+	if err == nil {
+		t.Fatal("nesting beyond MaxDepth returned no error")
+	}
+
+	want := fmt.Sprintf("peg: exceeded max nesting depth of %d at line 1, column %d", peg.MaxDepth, peg.MaxDepth+1)
+	if err.Error() != want {
+		t.Errorf("\nGot:\t%s\nWanted:\t%s\n", err, want)
+	}
+
+	// The depth is per parse, so the same parser carries on working
+	if _, _, err := p("[[]]"); err != nil {
+		t.Errorf("parse after a failure returned an unexpected error: %v", err)
+	}
+}
+
+func TestParserConcurrent(t *testing.T) {
+	p, err := peg.Compile(arithmetic)
+	if err != nil {
+		t.Fatalf("Compile returned an unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 100 {
+				input := fmt.Sprintf("%d + %d", i, j)
+				if j%2 == 0 {
+					input += " +"
+				}
+
+				node, _, err := p(input)
+				if (err != nil) != (j%2 == 0) {
+					t.Errorf("Parse(%q) returned %v", input, err)
+					return
+				}
+
+				if err == nil && node.Text != input {
+					t.Errorf("Parse(%q) matched %q", input, node.Text)
+					return
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+func TestKindString(t *testing.T) {
+	tests := []struct {
+		want string   // Expected string
+		kind peg.Kind // The kind under test
+	}{
+		{kind: peg.KindChoice, want: "choice"},
+		{kind: peg.KindSequence, want: "sequence"},
+		{kind: peg.KindZeroOrMore, want: "zero or more"},
+		{kind: peg.KindOneOrMore, want: "one or more"},
+		{kind: peg.KindOptional, want: "optional"},
+		{kind: peg.KindAnd, want: "and"},
+		{kind: peg.KindNot, want: "not"},
+		{kind: peg.KindLiteral, want: "literal"},
+		{kind: peg.KindClass, want: "class"},
+		{kind: peg.KindAny, want: "any"},
+		{kind: peg.KindReference, want: "reference"},
+		{kind: peg.KindLabel, want: "label"},
+		{kind: peg.KindAction, want: "action"},
+		{kind: peg.Kind(99), want: "Kind(99)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("Kind(%d).String() = %q, wanted %q", int(tt.kind), got, tt.want)
+		}
+	}
+}
+
+func TestStepString(t *testing.T) {
+	tests := []struct {
+		want string   // Expected string
+		step peg.Step // The step under test
+	}{
+		{step: peg.StepEnter, want: "enter"},
+		{step: peg.StepMatch, want: "match"},
+		{step: peg.StepFail, want: "fail"},
+		{step: peg.Step(99), want: "Step(99)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.step.String(); got != tt.want {
+			t.Errorf("Step(%d).String() = %q, wanted %q", int(tt.step), got, tt.want)
+		}
+	}
+}
+
+func ExampleCompile() {
+	p, err := peg.Compile(`
+		assignment <- name _ '=' _ value
+		name       <- [a-z]+
+		value      <- [0-9]+ / name
+		_          <- ' '*
+	`)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	node, remainder, err := p("answer = 42; rest")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Print(node)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: assignment "answer = 42"
+	//   name "answer"
+	//   _ " "
+	//   _ " "
+	//   value "42"
+	// Remainder: "; rest"
+}
+
+func ExampleParseError() {
+	p, err := peg.Compile(`list <- '[' (item (',' item)*)? ']'
+		item <- [0-9]+`)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	_, _, err = p("[1,2;3]")
+
+	var parseErr *peg.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println(parseErr)
+		fmt.Printf("Offset: %d\n", parseErr.Offset)
+	}
+
+	// Output: peg: unexpected ';', expected one of ",", "]", [0-9] at line 1, column 5
+	// Offset: 4
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errNoSeed is what a left recursive call to a [Rule] fails with before the rule has matched anything
+// at that point in the input, which forces it to match one of its other alternatives first.
+var errNoSeed = errors.New("Rule: left recursive call before the rule has matched")
+
+// Rule is a named, recursive part of a grammar that, unlike a recursive parser built with [Lazy], can
+// refer to itself as the very first thing it parses (left recursion), directly or through other rules.
+//
+// Left recursion is the natural way to write left associative operators:
+//
+//	expr = expr '-' term | term
+//
+// but a plain parser for it never terminates, as the first thing expr does is call expr again on the
+// same input. A Rule grows its result instead, following Warth et al.'s packrat parsers: the first
+// time it's called at a point in the input, the recursive call fails, so the rule has to match one of
+// its other alternatives (term above). That result is the seed, and the rule is tried again with the
+// recursive call returning the seed, which matches a longer stretch of the input (term '-' term),
+// and so on until it stops getting any longer. This also means the results nest to the left, so
+// "1-2-3" is parsed as (1-2)-3.
+//
+// A Rule is defined once it's been created, so that the parser it's defined as can refer to it, and
+// [Rule.Parse] is its [Parser]. It holds the state of the parses it's in the middle of, so the
+// parsers built from it must not be used concurrently, although they can be used for any number
+// of inputs one after the other.
+//
+// A rule that can recurse without consuming any input other than on the left, like a = b a | ""
+// with a b that can be empty, is not made to terminate.
+type Rule[T any] struct {
+	parser  Parser[T] // The parser the rule was defined as
+	growing []seed[T] // The parses the rule is in the middle of, innermost last
+}
+
+// seed is the result of a [Rule] grown so far at a point in the input.
+type seed[T any] struct {
+	value     T      // The value the rule has parsed
+	err       error  // Not nil if the rule hasn't matched yet
+	input     string // The input the rule is being applied to
+	remainder string // The input left after the value
+}
+
+// Define sets the parser a [Rule] applies, which will typically refer back to the rule itself.
+//
+// A rule must be defined before it's used to parse anything, and defining it again replaces the
+// previous definition.
+func (r *Rule[T]) Define(parser Parser[T]) {
+	r.parser = parser
+}
+
+// Parse applies the parser the rule was defined as to input, growing the result for as long as
+// the rule's left recursive calls let it consume more of the input.
+//
+// If the rule hasn't been defined, or the parser fails before it's matched anything, an error
+// will be returned.
+func (r *Rule[T]) Parse(input string) (T, string, error) {
+	var zero T
+
+	if r.parser == nil {
+		return zero, "", errors.New("Rule: must be defined before it's used")
+	}
+
+	// Called again before consuming anything, this is the left recursion so use what we've got
+	for i := len(r.growing) - 1; i >= 0; i-- {
+		if current := r.growing[i]; len(current.input) == len(input) && current.input == input {
+			return current.value, current.remainder, current.err
+		}
+	}
+
+	// Nested calls at other points in the input push and pop their own seeds, which can move the
+	// slice, so this one is only ever accessed by index
+	index := len(r.growing)
+	r.growing = append(r.growing, seed[T]{input: input, err: errNoSeed})
+
+	defer func() { r.growing = r.growing[:index] }()
+
+	for {
+		value, remainder, err := r.parser(input)
+		current := &r.growing[index]
+
+		if err != nil {
+			if current.err == errNoSeed { //nolint:errorlint // Only ever the sentinel itself
+				current.err = err
+			}
+
+			break
+		}
+
+		if current.err == nil && len(remainder) >= len(current.remainder) {
+			// Stopped getting longer, the last result is as far as this rule goes
+			break
+		}
+
+		current.value, current.remainder, current.err = value, remainder, nil
+	}
+
+	if err := r.growing[index].err; err != nil {
+		return zero, "", fmt.Errorf("Rule: parser returned error: %w", err)
+	}
+
+	return r.growing[index].value, r.growing[index].remainder, nil
+}
@@ -0,0 +1,248 @@
+package parser_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// subtraction builds the direct left recursive grammar expr = expr '-' number | number, with the
+// value of each expr its parenthesised form so the associativity shows.
+func subtraction() parser.Parser[string] {
+	expr := &parser.Rule[string]{}
+
+	number := parser.TakeWhile(func(r rune) bool { return r >= '0' && r <= '9' })
+
+	expr.Define(parser.Try(
+		parser.Map(parser.Chain(expr.Parse, parser.Char('-'), number), func(parts []string) (string, error) {
+			return "(" + parts[0] + "-" + parts[2] + ")", nil
+		}),
+		number,
+	))
+
+	return expr.Parse
+}
+
+// arithmetic builds an indirectly left recursive grammar evaluating sums and products, with
+// parentheses:
+//
+//	sum     = sum ('+' | '-') product | product
+//	product = product ('*' | '/') value | value
+//	value   = '(' sum ')' | number
+//
+// where sum recurses through value, after the '('.
+func arithmetic() parser.Parser[int] {
+	sum := &parser.Rule[int]{}
+	product := &parser.Rule[int]{}
+
+	number := parser.Map(parser.TakeWhile(func(r rune) bool { return r >= '0' && r <= '9' }), strconv.Atoi)
+	operator := func(op string) parser.Parser[int] {
+		return parser.Map(parser.Exact(op), func(string) (int, error) { return 0, nil })
+	}
+
+	value := parser.Try(
+		parser.Map(parser.Chain(operator("("), sum.Parse, operator(")")), func(values []int) (int, error) {
+			return values[1], nil
+		}),
+		number,
+	)
+
+	binary := func(left parser.Parser[int], op string, right parser.Parser[int], fn func(a, b int) int) parser.Parser[int] {
+		return parser.Map(parser.Chain(left, operator(op), right), func(values []int) (int, error) {
+			return fn(values[0], values[2]), nil
+		})
+	}
+
+	sum.Define(parser.Try(
+		binary(sum.Parse, "+", product.Parse, func(a, b int) int { return a + b }),
+		binary(sum.Parse, "-", product.Parse, func(a, b int) int { return a - b }),
+		product.Parse,
+	))
+
+	product.Define(parser.Try(
+		binary(product.Parse, "*", value, func(a, b int) int { return a * b }),
+		binary(product.Parse, "/", value, func(a, b int) int { return a / b }),
+		value,
+	))
+
+	return sum.Parse
+}
+
+// mutual builds a grammar where two rules are left recursive through each other:
+//
+//	a = b 'a' | 'x'
+//	b = a 'b' | 'y'
+func mutual() parser.Parser[string] {
+	a := &parser.Rule[string]{}
+	b := &parser.Rule[string]{}
+
+	concat := func(parts []string) (string, error) { return parts[0] + parts[1], nil }
+
+	a.Define(parser.Try(parser.Map(parser.Chain(b.Parse, parser.Char('a')), concat), parser.Char('x')))
+	b.Define(parser.Try(parser.Map(parser.Chain(a.Parse, parser.Char('b')), concat), parser.Char('y')))
+
+	return a.Parse
+}
+
+func TestRule(t *testing.T) {
+	tests := []struct {
+		parser    parser.Parser[string] // The parser under test
+		name      string                // Identifying test case name
+		input     string                // Entire input to be parsed
+		want      string                // The expected value
+		remainder string                // The expected remainder
+		errMsg    string                // The expected error message, if there was one
+		wantErr   bool                  // Whether or not we want an error
+	}{
+		{
+			name:      "direct seed only",
+			parser:    subtraction(),
+			input:     "12",
+			want:      "12",
+			remainder: "",
+			wantErr:   false,
+		},
+		{
+			name:      "direct left associative",
+			parser:    subtraction(),
+			input:     "10-4-3",
+			want:      "((10-4)-3)",
+			remainder: "",
+			wantErr:   false,
+		},
+		{
+			name:      "direct stops growing",
+			parser:    subtraction(),
+			input:     "1-2-x",
+			want:      "(1-2)",
+			remainder: "-x",
+			wantErr:   false,
+		},
+		{
+			name:      "direct no match",
+			parser:    subtraction(),
+			input:     "-x",
+			want:      "",
+			remainder: "",
+			errMsg:    "Rule: parser returned error: Try: all parsers failed",
+			wantErr:   true,
+		},
+		{
+			name:      "mutual seed",
+			parser:    mutual(),
+			input:     "x",
+			want:      "x",
+			remainder: "",
+			wantErr:   false,
+		},
+		{
+			name:      "mutual",
+			parser:    mutual(),
+			input:     "xbaba!",
+			want:      "xbaba",
+			remainder: "!",
+			wantErr:   false,
+		},
+		{
+			name:      "mutual through the other seed",
+			parser:    mutual(),
+			input:     "yaba",
+			want:      "yaba",
+			remainder: "",
+			wantErr:   false,
+		},
+		{
+			name:      "mutual no match",
+			parser:    mutual(),
+			input:     "ab",
+			want:      "",
+			remainder: "",
+			errMsg:    "Rule: parser returned error: Try: all parsers failed",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, err := tt.parser(tt.input)
+
+			testParser(t, parserTest[string]{
+				gotErr:        err,
+				gotValue:      value,
+				wantValue:     tt.want,
+				gotRemainder:  remainder,
+				wantRemainder: tt.remainder,
+				wantErrMsg:    tt.errMsg,
+				wantErr:       tt.wantErr,
+			})
+		})
+	}
+}
+
+func TestRuleIndirect(t *testing.T) {
+	tests := []struct {
+		input string // The expression to evaluate
+		want  int    // The expected value
+	}{
+		{input: "7", want: 7},
+		{input: "10-4-3", want: 3},
+		{input: "100/10/5", want: 2},
+		{input: "1+2*3", want: 7},
+		{input: "2*3+1", want: 7},
+		{input: "(1+2)*3", want: 9},
+		{input: "8-(4-3)", want: 7},
+		{input: "2*(3+(4-1)*2)-10/5", want: 16},
+	}
+
+	// The same parsers are used for every input, one after another
+	p := arithmetic()
+
+	for _, tt := range tests {
+		got, remainder, err := p(tt.input)
+		if err != nil {
+			t.Fatalf("parsing %q returned an unexpected error: %v", tt.input, err)
+		}
+
+		if got != tt.want || remainder != "" {
+			t.Errorf("parsing %q gave (%d, %q), wanted (%d, %q)", tt.input, got, remainder, tt.want, "")
+		}
+	}
+}
+
+func TestRuleUndefined(t *testing.T) {
+	rule := &parser.Rule[string]{}
+
+	_, _, err := rule.Parse("input")
+	if err == nil || err.Error() != "Rule: must be defined before it's used" {
+		t.Errorf("undefined rule returned %v", err)
+	}
+}
+
+func ExampleRule() {
+	// expr = expr '-' number | number
+	expr := &parser.Rule[int]{}
+
+	number := parser.Map(parser.TakeWhile(func(r rune) bool { return r >= '0' && r <= '9' }), strconv.Atoi)
+	minus := parser.Map(parser.Char('-'), func(string) (int, error) { return 0, nil })
+
+	expr.Define(parser.Try(
+		parser.Map(parser.Chain(expr.Parse, minus, number), func(values []int) (int, error) {
+			return values[0] - values[2], nil
+		}),
+		number,
+	))
+
+	value, remainder, err := expr.Parse("10-4-3")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Left associative, (10-4)-3 rather than 10-(4-3)
+	fmt.Printf("Value: %d, Remainder: %q\n", value, remainder)
+
+	// Output: Value: 3, Remainder: ""
+}
@@ -0,0 +1,35 @@
+package semver_test
+
+import (
+	"testing"
+
+	"go.followtheprocess.codes/parser/semver"
+)
+
+func BenchmarkParse(b *testing.B) {
+	input := "v1.2.3-rc.1+build.123"
+
+	for b.Loop() {
+		if _, err := semver.Parse(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompare(b *testing.B) {
+	x := semver.MustParse("1.2.3-rc.1.alpha")
+	y := semver.MustParse("1.2.3-rc.1.beta")
+
+	for b.Loop() {
+		semver.Compare(x, y)
+	}
+}
+
+func BenchmarkCheck(b *testing.B) {
+	constraint := semver.MustParseConstraint("^1.2 || >=2.5.0 <3.0.0")
+	version := semver.MustParse("2.6.1")
+
+	for b.Loop() {
+		constraint.Check(version)
+	}
+}
@@ -0,0 +1,368 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// Constraint is a parsed version range that versions can be checked against.
+//
+// A constraint is one or more comparator sets separated by "||", a version satisfies the
+// constraint if it satisfies every comparator in any one of the sets. Comparators within
+// a set are separated by whitespace:
+//
+//	>=1.2.7 <1.3.0 || >=2.0.0
+//
+// Each comparator is an optional operator followed by a version, which may be partial
+// (missing minor or patch numbers) or use 'x', 'X' or '*' as a wildcard for a component.
+// The operators are:
+//
+//	=1.2.3   exactly 1.2.3, the same as a full version with no operator
+//	>1.2.3   greater than (>1.2 means >=1.3.0)
+//	>=1.2.3  greater than or equal to
+//	<1.2.3   less than (<1.2 means <1.2.0)
+//	<=1.2.3  less than or equal to (<=1.2 means <1.3.0)
+//	~1.2.3   patch updates only, >=1.2.3 <1.3.0 (~1 means >=1.0.0 <2.0.0)
+//	^1.2.3   no breaking changes, >=1.2.3 <2.0.0, where for 0.x versions the first non-zero
+//	         component is treated as breaking, so ^0.2.3 means >=0.2.3 <0.3.0
+//
+// A partial version without an operator matches anything with the given components, so
+// 1.2 (or 1.2.x) means >=1.2.0 <1.3.0, and * matches any version.
+//
+// A version with a prerelease only satisfies a comparator set if one of its comparators
+// has a prerelease on the same major, minor and patch version, so >=1.2.3-beta.1 matches
+// 1.2.3-beta.2 but not 1.3.0-beta.1. This stops unstable releases being selected unless
+// explicitly asked for.
+type Constraint struct {
+	text string         // The original constraint text
+	sets [][]comparator // The comparator sets, any one of which must be satisfied
+}
+
+// String implements [fmt.Stringer] for [Constraint], returning the text it was parsed from.
+func (c Constraint) String() string {
+	return c.text
+}
+
+// Check reports whether the version satisfies the constraint.
+func (c Constraint) Check(version Version) bool {
+	for _, set := range c.sets {
+		if satisfies(version, set) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseConstraint parses a version [Constraint].
+//
+// Any syntax error will be a [*SyntaxError].
+func ParseConstraint(text string) (Constraint, error) {
+	g := grammar{src: text}
+	constraint := Constraint{text: text}
+
+	rest := text
+	for {
+		set, remainder, err := g.comparatorSet(rest)
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		constraint.sets = append(constraint.sets, set)
+
+		if remainder == "" {
+			return constraint, nil
+		}
+
+		// comparatorSet only stops early at a "||"
+		_, rest, _ = or(remainder)
+	}
+}
+
+// MustParseConstraint is like [ParseConstraint] but panics if the text is not a valid constraint.
+//
+// It is intended for constraints known to be valid at compile time, such as in tests.
+func MustParseConstraint(text string) Constraint {
+	constraint, err := ParseConstraint(text)
+	if err != nil {
+		panic(err)
+	}
+
+	return constraint
+}
+
+// op is a primitive comparison operator.
+type op int
+
+const (
+	opEqual        op = iota // =
+	opGreater                // >
+	opGreaterEqual           // >=
+	opLess                   // <
+	opLessEqual              // <=
+)
+
+// comparator is a single primitive comparison against a version, all the operators
+// in a constraint are reduced to these.
+type comparator struct {
+	version Version // The version to compare against
+	op      op      // The comparison
+}
+
+// matches reports whether version satisfies the comparator.
+func (c comparator) matches(version Version) bool {
+	result := Compare(version, c.version)
+
+	switch c.op {
+	case opGreater:
+		return result > 0
+	case opGreaterEqual:
+		return result >= 0
+	case opLess:
+		return result < 0
+	case opLessEqual:
+		return result <= 0
+	default:
+		return result == 0
+	}
+}
+
+// satisfies reports whether version satisfies every comparator in the set, applying
+// the prerelease rule.
+func satisfies(version Version, set []comparator) bool {
+	for _, c := range set {
+		if !c.matches(version) {
+			return false
+		}
+	}
+
+	if len(version.Prerelease) == 0 {
+		return true
+	}
+
+	for _, c := range set {
+		if len(c.version.Prerelease) != 0 &&
+			c.version.Major == version.Major &&
+			c.version.Minor == version.Minor &&
+			c.version.Patch == version.Patch {
+			return true
+		}
+	}
+
+	return false
+}
+
+// partial is a possibly incomplete version in a constraint.
+type partial struct {
+	prerelease []string  // Prerelease identifiers, only allowed if all 3 components are given
+	components [3]uint64 // Major, minor and patch
+	given      int       // How many of the components were given as numbers, the rest are wildcards
+}
+
+// version returns the partial version with any missing components set to 0.
+func (p partial) version() Version {
+	return Version{
+		Major:      p.components[0],
+		Minor:      p.components[1],
+		Patch:      p.components[2],
+		Prerelease: p.prerelease,
+	}
+}
+
+// bump returns the smallest version greater than every version matching the partial version
+// in component i, i.e. with component i incremented and all lower components set to 0.
+func (p partial) bump(i int) Version {
+	components := p.components
+	components[i]++
+
+	for j := i + 1; j < len(components); j++ {
+		components[j] = 0
+	}
+
+	return Version{Major: components[0], Minor: components[1], Patch: components[2]}
+}
+
+// comparatorSet parses whitespace separated comparators up to a "||" or the end of input.
+func (g grammar) comparatorSet(input string) ([]comparator, string, error) {
+	var set []comparator
+
+	count := 0 // Number of comparators parsed, wildcards produce no primitive comparators
+	rest := input
+
+	for {
+		_, rest, _ = space(rest)
+
+		if rest == "" || strings.HasPrefix(rest, "||") {
+			if count == 0 {
+				return nil, "", g.fail(rest, "expected a version")
+			}
+			return set, rest, nil
+		}
+
+		symbol, remainder, err := operator(rest)
+		if err != nil {
+			// No operator
+			symbol, remainder = "", rest
+		}
+
+		_, remainder, _ = space(remainder)
+
+		version, remainder, err := g.partial(remainder)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if remainder != "" && !strings.HasPrefix(remainder, " ") && !strings.HasPrefix(remainder, "\t") &&
+			!strings.HasPrefix(remainder, "||") {
+			return nil, "", g.fail(remainder, fmt.Sprintf("unexpected %q after version", firstChar(remainder)))
+		}
+
+		set = append(set, desugar(symbol, version)...)
+		count++
+		rest = remainder
+	}
+}
+
+// partial parses a possibly partial version, with optional wildcards.
+func (g grammar) partial(input string) (partial, string, error) {
+	var version partial
+
+	rest := input
+	if rest != "" {
+		_, rest, _ = vPrefix(rest)
+	}
+
+	wild := false
+	for i, component := range [...]string{"major", "minor", "patch"} {
+		if i > 0 {
+			_, remainder, err := parser.Char('.')(rest)
+			if err != nil {
+				break
+			}
+			rest = remainder
+		}
+
+		if _, remainder, err := wildcard(rest); err == nil {
+			wild = true
+			rest = remainder
+			continue
+		}
+
+		if wild {
+			return partial{}, "", g.fail(rest, "expected a wildcard after a wildcard")
+		}
+
+		n, remainder, err := g.number(rest, component)
+		if err != nil {
+			return partial{}, "", err
+		}
+
+		version.components[i] = n
+		version.given++
+		rest = remainder
+	}
+
+	if version.given == len(version.components) {
+		prerelease, _, remainder, err := g.suffix(rest)
+		if err != nil {
+			return partial{}, "", err
+		}
+
+		version.prerelease = prerelease
+		rest = remainder
+	}
+
+	return version, rest, nil
+}
+
+// desugar reduces an operator and a partial version to primitive comparators.
+func desugar(symbol string, version partial) []comparator {
+	// A full wildcard
+	if version.given == 0 {
+		switch symbol {
+		case ">", "<":
+			// Nothing can be greater or less than every version
+			return []comparator{{op: opLess, version: Version{Prerelease: []string{"0"}}}}
+		default:
+			return nil
+		}
+	}
+
+	lower := version.version()
+	last := version.given - 1 // Index of the last given component
+
+	switch symbol {
+	case "^":
+		// The first non-zero component is the breaking one, or the last given one if they're all zero
+		breaking := last
+		for i := range version.given {
+			if version.components[i] != 0 {
+				breaking = i
+				break
+			}
+		}
+
+		return between(lower, version.bump(breaking))
+
+	case "~":
+		// Allow patch changes if a minor version was given, else minor changes
+		return between(lower, version.bump(min(last, 1)))
+
+	case ">":
+		if version.given == len(version.components) {
+			return []comparator{{op: opGreater, version: lower}}
+		}
+		return []comparator{{op: opGreaterEqual, version: version.bump(last)}}
+
+	case ">=":
+		return []comparator{{op: opGreaterEqual, version: lower}}
+
+	case "<":
+		return []comparator{{op: opLess, version: lower}}
+
+	case "<=":
+		if version.given == len(version.components) {
+			return []comparator{{op: opLessEqual, version: lower}}
+		}
+		return []comparator{{op: opLess, version: version.bump(last)}}
+
+	default:
+		// "=" or no operator
+		if version.given == len(version.components) {
+			return []comparator{{op: opEqual, version: lower}}
+		}
+		return between(lower, version.bump(last))
+	}
+}
+
+// between returns the comparators for >=lower <upper.
+func between(lower, upper Version) []comparator {
+	return []comparator{
+		{op: opGreaterEqual, version: lower},
+		{op: opLess, version: upper},
+	}
+}
+
+var (
+	// space parses optional whitespace between comparators.
+	space = parser.SkipMany(parser.OneOf(" \t"))
+
+	// or parses the separator between comparator sets.
+	or = parser.Exact("||")
+
+	// operator parses a comparison operator, longest first so ">=" isn't read as ">".
+	operator = parser.Try(
+		parser.Exact(">="),
+		parser.Exact("<="),
+		parser.Exact(">"),
+		parser.Exact("<"),
+		parser.Exact("="),
+		parser.Exact("^"),
+		parser.Exact("~"),
+	)
+
+	// wildcard parses a wildcard version component.
+	wildcard = parser.OneOf("xX*")
+)
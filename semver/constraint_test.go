@@ -0,0 +1,191 @@
+package semver_test
+
+import (
+	"fmt"
+	"testing"
+
+	"go.followtheprocess.codes/parser/semver"
+)
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string   // Constraint to parse
+		match      []string // Versions that should satisfy it
+		noMatch    []string // Versions that should not
+	}{
+		{
+			constraint: "1.2.3",
+			match:      []string{"1.2.3", "v1.2.3+build"},
+			noMatch:    []string{"1.2.4", "1.2.3-rc.1"},
+		},
+		{
+			constraint: "=1.2.3",
+			match:      []string{"1.2.3"},
+			noMatch:    []string{"1.2.2"},
+		},
+		{
+			constraint: "^1.2.3",
+			match:      []string{"1.2.3", "1.2.10", "1.9.0"},
+			noMatch:    []string{"1.2.2", "2.0.0", "2.0.0-rc.1", "1.3.0-beta"},
+		},
+		{
+			constraint: "^1.2",
+			match:      []string{"1.2.0", "1.99.99"},
+			noMatch:    []string{"1.1.9", "2.0.0"},
+		},
+		{
+			constraint: "^0.2.3",
+			match:      []string{"0.2.3", "0.2.9"},
+			noMatch:    []string{"0.3.0", "0.2.2", "1.0.0"},
+		},
+		{
+			constraint: "^0.0.3",
+			match:      []string{"0.0.3"},
+			noMatch:    []string{"0.0.4", "0.0.2"},
+		},
+		{
+			constraint: "^0.0",
+			match:      []string{"0.0.0", "0.0.9"},
+			noMatch:    []string{"0.1.0"},
+		},
+		{
+			constraint: "^1.2.3-beta.2",
+			match:      []string{"1.2.3-beta.2", "1.2.3-beta.10", "1.2.3", "1.5.0"},
+			noMatch:    []string{"1.2.3-beta.1", "1.2.4-beta.3", "2.0.0"},
+		},
+		{
+			constraint: "~1.2.3",
+			match:      []string{"1.2.3", "1.2.99"},
+			noMatch:    []string{"1.3.0", "1.2.2"},
+		},
+		{
+			constraint: "~1.2",
+			match:      []string{"1.2.0", "1.2.5"},
+			noMatch:    []string{"1.3.0", "1.1.0"},
+		},
+		{
+			constraint: "~1",
+			match:      []string{"1.0.0", "1.9.9"},
+			noMatch:    []string{"2.0.0", "0.9.9"},
+		},
+		{
+			constraint: ">=1.0 <2.0",
+			match:      []string{"1.0.0", "1.5.3"},
+			noMatch:    []string{"0.9.9", "2.0.0", "2.0.0-rc.1"},
+		},
+		{
+			constraint: "> 1.2 <= 1.4",
+			match:      []string{"1.3.0", "1.4.0", "1.4.9"},
+			noMatch:    []string{"1.2.9", "1.5.0"},
+		},
+		{
+			constraint: ">1.2.3 <1.2",
+			match:      nil,
+			noMatch:    []string{"1.2.4", "1.1.0"},
+		},
+		{
+			constraint: "1.2.x",
+			match:      []string{"1.2.0", "1.2.7"},
+			noMatch:    []string{"1.3.0"},
+		},
+		{
+			constraint: "1.*",
+			match:      []string{"1.0.0", "1.99.0"},
+			noMatch:    []string{"2.0.0"},
+		},
+		{
+			constraint: "*",
+			match:      []string{"0.0.0", "99.99.99"},
+			noMatch:    []string{"1.0.0-rc.1"},
+		},
+		{
+			constraint: ">*",
+			match:      nil,
+			noMatch:    []string{"0.0.0", "1.0.0"},
+		},
+		{
+			constraint: "^1.2 || ^3.0 || =5.0.0-rc.1",
+			match:      []string{"1.2.0", "3.1.0", "5.0.0-rc.1"},
+			noMatch:    []string{"2.0.0", "4.0.0", "5.0.0-rc.2"},
+		},
+		{
+			constraint: "v1.2.3||v2",
+			match:      []string{"1.2.3", "2.5.0"},
+			noMatch:    []string{"1.2.4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			constraint, err := semver.ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint returned an unexpected error: %v", err)
+			}
+
+			if got := constraint.String(); got != tt.constraint {
+				t.Errorf("String() = %q, wanted %q", got, tt.constraint)
+			}
+
+			for _, version := range tt.match {
+				if !constraint.Check(semver.MustParse(version)) {
+					t.Errorf("%s should satisfy %s", version, tt.constraint)
+				}
+			}
+
+			for _, version := range tt.noMatch {
+				if constraint.Check(semver.MustParse(version)) {
+					t.Errorf("%s should not satisfy %s", version, tt.constraint)
+				}
+			}
+		})
+	}
+}
+
+func TestConstraintErrors(t *testing.T) {
+	tests := []struct {
+		constraint string // Constraint to parse
+		err        string // The expected error message
+	}{
+		{constraint: "", err: `semver: invalid "": column 1: expected a version`},
+		{constraint: "  ", err: `semver: invalid "  ": column 3: expected a version`},
+		{constraint: "^1.2 ||", err: `semver: invalid "^1.2 ||": column 8: expected a version`},
+		{constraint: "|| 1.2", err: `semver: invalid "|| 1.2": column 1: expected a version`},
+		{constraint: ">=", err: `semver: invalid ">=": column 3: expected major version number`},
+		{constraint: ">=1.0<2.0", err: `semver: invalid ">=1.0<2.0": column 6: unexpected '<' after version`},
+		{constraint: "1.x.3", err: `semver: invalid "1.x.3": column 5: expected a wildcard after a wildcard`},
+		{constraint: "1.2-rc", err: `semver: invalid "1.2-rc": column 4: unexpected '-' after version`},
+		{constraint: "^01.2", err: `semver: invalid "^01.2": column 2: major version has a leading zero`},
+		{constraint: "1.2.3 - 2.0.0", err: `semver: invalid "1.2.3 - 2.0.0": column 7: expected major version number`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			_, err := semver.ParseConstraint(tt.constraint)
+			if err == nil {
+				t.Fatal("ParseConstraint returned nil error, wanted one")
+			}
+
+			if msg := err.Error(); msg != tt.err {
+				t.Errorf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+			}
+		})
+	}
+}
+
+func ExampleConstraint() {
+	constraint, err := semver.ParseConstraint("^1.2 || >=2.5.0 <3.0.0")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, version := range []string{"1.4.0", "2.0.0", "2.6.1", "2.6.1-rc.1", "3.0.0"} {
+		fmt.Printf("%s: %v\n", version, constraint.Check(semver.MustParse(version)))
+	}
+
+	// Output: 1.4.0: true
+	// 2.0.0: false
+	// 2.6.1: true
+	// 2.6.1-rc.1: false
+	// 3.0.0: false
+}
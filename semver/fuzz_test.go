@@ -0,0 +1,73 @@
+package semver_test
+
+// The fuzz tests in here check that the parsers never panic, and that every version
+// that parses survives a round trip through its canonical string form.
+
+import (
+	"reflect"
+	"testing"
+
+	"go.followtheprocess.codes/parser/semver"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"1.2.3",
+		"v1.2.3-rc.1+build.123",
+		"1.0.0-x-y-z.--+001.0-a",
+		"0.0.0-0",
+		"1.02.3",
+		"1.2.3-rc..1",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		version, err := semver.Parse(input)
+		if err != nil {
+			return
+		}
+
+		canonical := version.String()
+
+		again, err := semver.Parse(canonical)
+		if err != nil {
+			t.Fatalf("canonical form %q of %q did not parse: %v", canonical, input, err)
+		}
+
+		if !reflect.DeepEqual(version, again) {
+			t.Fatalf("round trip of %q changed the version: %#v != %#v", input, version, again)
+		}
+
+		if got := semver.Compare(version, again); got != 0 {
+			t.Fatalf("Compare(%s, %s) = %d, wanted 0", version, again, got)
+		}
+	})
+}
+
+func FuzzParseConstraint(f *testing.F) {
+	for _, seed := range []string{
+		"^1.2.3",
+		"~1.2 || >=2.0.0-rc.1 <3",
+		"1.x",
+		">*",
+		"v1.2.3||v2",
+	} {
+		f.Add(seed, "1.2.3")
+	}
+
+	f.Fuzz(func(t *testing.T, text, version string) {
+		constraint, err := semver.ParseConstraint(text)
+		if err != nil {
+			return
+		}
+
+		if constraint.String() != text {
+			t.Fatalf("String() = %q, wanted %q", constraint.String(), text)
+		}
+
+		if v, err := semver.Parse(version); err == nil {
+			constraint.Check(v)
+		}
+	})
+}
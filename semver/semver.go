@@ -0,0 +1,331 @@
+// Package semver implements parsing and comparison of [Semantic Versions], built on the combinators in [parser].
+//
+// Versions follow the SemVer 2.0.0 grammar exactly, with the exception that a leading 'v' (as in
+// v1.2.3) is accepted and ignored as it's so common in practice. Numeric components and numeric
+// prerelease identifiers may not have leading zeros.
+//
+// Version ranges, like those used by package managers to express dependency requirements, are
+// supported with [Constraint].
+//
+// [Semantic Versions]: https://semver.org/spec/v2.0.0.html
+package semver // import "go.followtheprocess.codes/parser/semver"
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// Version is a parsed semantic version.
+type Version struct {
+	Prerelease []string // Dot separated prerelease identifiers e.g. ["rc", "1"] for 1.2.3-rc.1
+	Build      []string // Dot separated build metadata identifiers, ignored when comparing versions
+	Major      uint64   // The major version
+	Minor      uint64   // The minor version
+	Patch      uint64   // The patch version
+}
+
+// String implements [fmt.Stringer] for [Version], returning the canonical form with no leading 'v'.
+func (v Version) String() string {
+	var builder strings.Builder
+	builder.WriteString(strconv.FormatUint(v.Major, 10))
+	builder.WriteByte('.')
+	builder.WriteString(strconv.FormatUint(v.Minor, 10))
+	builder.WriteByte('.')
+	builder.WriteString(strconv.FormatUint(v.Patch, 10))
+
+	if len(v.Prerelease) != 0 {
+		builder.WriteByte('-')
+		builder.WriteString(strings.Join(v.Prerelease, "."))
+	}
+
+	if len(v.Build) != 0 {
+		builder.WriteByte('+')
+		builder.WriteString(strings.Join(v.Build, "."))
+	}
+
+	return builder.String()
+}
+
+// SyntaxError is the error returned when a version or constraint cannot be parsed.
+type SyntaxError struct {
+	Input  string // The text that was being parsed
+	Msg    string // Description of the problem
+	Column int    // 1 indexed column (in utf-8 chars) where the problem occurred
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("semver: invalid %q: column %d: %s", e.Input, e.Column, e.Msg)
+}
+
+// Parse parses a semantic version, the entire text must be a valid version.
+//
+// Any syntax error will be a [*SyntaxError].
+func Parse(text string) (Version, error) {
+	g := grammar{src: text}
+
+	version, rest, err := g.version(text)
+	if err != nil {
+		return Version{}, err
+	}
+
+	if rest != "" {
+		return Version{}, g.fail(rest, fmt.Sprintf("unexpected %q after version", firstChar(rest)))
+	}
+
+	return version, nil
+}
+
+// MustParse is like [Parse] but panics if the text is not a valid version.
+//
+// It is intended for versions known to be valid at compile time, such as in tests.
+func MustParse(text string) Version {
+	version, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+
+	return version
+}
+
+// Parser returns a [parser.Parser] that parses a semantic version from the start of its input, so
+// versions can be embedded in larger grammars. Unlike [Parse], any input after the version is
+// returned as the remainder.
+//
+// Errors are a [*SyntaxError] with columns relative to the start of the input passed to the parser.
+func Parser() parser.Parser[Version] {
+	return func(input string) (Version, string, error) {
+		return grammar{src: input}.version(input)
+	}
+}
+
+// Compare returns -1 if a has lower precedence than b, 0 if they have the same precedence
+// and +1 if a has higher precedence than b, according to the SemVer 2.0.0 precedence rules.
+//
+// Build metadata is ignored, so versions that differ only in build metadata compare equal.
+// Compare is suitable for use with [slices.SortFunc].
+func Compare(a, b Version) int {
+	if c := cmp.Compare(a.Major, b.Major); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+
+	// A version with a prerelease has lower precedence than the same version without
+	switch {
+	case len(a.Prerelease) == 0 && len(b.Prerelease) == 0:
+		return 0
+	case len(a.Prerelease) == 0:
+		return 1
+	case len(b.Prerelease) == 0:
+		return -1
+	}
+
+	for i := range min(len(a.Prerelease), len(b.Prerelease)) {
+		if c := compareIdentifier(a.Prerelease[i], b.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(a.Prerelease), len(b.Prerelease))
+}
+
+// compareIdentifier compares two prerelease identifiers, numeric identifiers are compared
+// numerically and have lower precedence than alphanumeric ones, which are compared in ASCII order.
+func compareIdentifier(a, b string) int {
+	aNumeric, bNumeric := isNumeric(a), isNumeric(b)
+
+	switch {
+	case aNumeric && bNumeric:
+		// No leading zeros so the longer number is bigger, and this can't overflow
+		if c := cmp.Compare(len(a), len(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case aNumeric:
+		return -1
+	case bNumeric:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// grammar holds the state needed to report positioned errors while parsing.
+type grammar struct {
+	src string // The entire input, for error positions
+}
+
+// version parses a version from the start of input.
+func (g grammar) version(input string) (Version, string, error) {
+	rest := input
+	if rest != "" {
+		_, rest, _ = vPrefix(rest)
+	}
+
+	major, rest, err := g.number(rest, "major")
+	if err != nil {
+		return Version{}, "", err
+	}
+
+	if rest, err = g.dot(rest, "major"); err != nil {
+		return Version{}, "", err
+	}
+
+	minor, rest, err := g.number(rest, "minor")
+	if err != nil {
+		return Version{}, "", err
+	}
+
+	if rest, err = g.dot(rest, "minor"); err != nil {
+		return Version{}, "", err
+	}
+
+	patch, rest, err := g.number(rest, "patch")
+	if err != nil {
+		return Version{}, "", err
+	}
+
+	version := Version{Major: major, Minor: minor, Patch: patch}
+
+	version.Prerelease, version.Build, rest, err = g.suffix(rest)
+	if err != nil {
+		return Version{}, "", err
+	}
+
+	return version, rest, nil
+}
+
+// suffix parses the optional prerelease and build metadata after the patch version.
+func (g grammar) suffix(input string) (prerelease, build []string, rest string, err error) {
+	rest = input
+
+	if _, remainder, err := parser.Char('-')(rest); err == nil {
+		prerelease, rest, err = g.identifiers(remainder, "prerelease")
+		if err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	if _, remainder, err := parser.Char('+')(rest); err == nil {
+		build, rest, err = g.identifiers(remainder, "build metadata")
+		if err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	return prerelease, build, rest, nil
+}
+
+// number parses a numeric version component, which may not have leading zeros.
+func (g grammar) number(input, component string) (uint64, string, error) {
+	digits, rest, err := numeric(input)
+	if err != nil || digits == "" {
+		return 0, "", g.fail(input, "expected "+component+" version number")
+	}
+
+	if len(digits) > 1 && digits[0] == '0' {
+		return 0, "", g.fail(input, component+" version has a leading zero")
+	}
+
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, "", g.fail(input, component+" version is too large")
+	}
+
+	return n, rest, nil
+}
+
+// dot parses the '.' after a version component.
+func (g grammar) dot(input, after string) (string, error) {
+	_, rest, err := parser.Char('.')(input)
+	if err != nil {
+		return "", g.fail(input, "expected '.' after "+after+" version")
+	}
+
+	return rest, nil
+}
+
+// identifiers parses a dot separated list of prerelease or build metadata identifiers.
+func (g grammar) identifiers(input, kind string) ([]string, string, error) {
+	var identifiers []string
+
+	rest := input
+	for {
+		ident, remainder, err := identifier(rest)
+		if err != nil || ident == "" {
+			return nil, "", g.fail(rest, "empty "+kind+" identifier")
+		}
+
+		if kind == "prerelease" && len(ident) > 1 && ident[0] == '0' && isNumeric(ident) {
+			return nil, "", g.fail(rest, "numeric prerelease identifier has a leading zero")
+		}
+
+		identifiers = append(identifiers, ident)
+
+		_, afterDot, err := parser.Char('.')(remainder)
+		if err != nil {
+			return identifiers, remainder, nil
+		}
+
+		rest = afterDot
+	}
+}
+
+// fail returns a [SyntaxError] at the start of rest.
+func (g grammar) fail(rest, msg string) error {
+	return &SyntaxError{
+		Input:  g.src,
+		Msg:    msg,
+		Column: 1 + utf8.RuneCountInString(g.src[:len(g.src)-len(rest)]),
+	}
+}
+
+// isDigit reports whether r is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isIdentifierChar reports whether r is allowed in a prerelease or build identifier.
+func isIdentifierChar(r rune) bool {
+	return isDigit(r) || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '-'
+}
+
+// isNumeric reports whether s is entirely ASCII digits.
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if !isDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// firstChar returns the first utf-8 char in s.
+func firstChar(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+var (
+	// vPrefix parses the optional 'v' prefix.
+	vPrefix = parser.Optional("v")
+
+	// numeric parses a run of digits.
+	numeric = parser.TakeWhile(isDigit)
+
+	// identifier parses a single prerelease or build identifier.
+	identifier = parser.TakeWhile(isIdentifierChar)
+)
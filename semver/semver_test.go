@@ -0,0 +1,274 @@
+package semver_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+
+	"go.followtheprocess.codes/parser/semver"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string         // Identifying test case name
+		input   string         // Version to parse
+		err     string         // The expected error message, if there was one
+		want    semver.Version // The expected version
+		wantErr bool           // Whether or not we wanted an error
+	}{
+		{
+			name:    "simple",
+			input:   "1.2.3",
+			want:    semver.Version{Major: 1, Minor: 2, Patch: 3},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "v prefix",
+			input:   "v10.20.30",
+			want:    semver.Version{Major: 10, Minor: 20, Patch: 30},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "zeros",
+			input:   "0.0.0",
+			want:    semver.Version{},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "prerelease and build",
+			input: "v1.2.3-rc.1+build.123",
+			want: semver.Version{
+				Major:      1,
+				Minor:      2,
+				Patch:      3,
+				Prerelease: []string{"rc", "1"},
+				Build:      []string{"build", "123"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "hyphens and leading zero build",
+			input: "1.0.0-x-y-z.--+001.0-a",
+			want: semver.Version{
+				Major:      1,
+				Prerelease: []string{"x-y-z", "--"},
+				Build:      []string{"001", "0-a"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:  "alphanumeric with leading zero",
+			input: "1.0.0-0alpha",
+			want: semver.Version{
+				Major:      1,
+				Prerelease: []string{"0alpha"},
+			},
+			wantErr: false,
+			err:     "",
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: true,
+			err:     `semver: invalid "": column 1: expected major version number`,
+		},
+		{
+			name:    "missing patch",
+			input:   "1.2",
+			wantErr: true,
+			err:     `semver: invalid "1.2": column 4: expected '.' after minor version`,
+		},
+		{
+			name:    "leading zero",
+			input:   "1.02.3",
+			wantErr: true,
+			err:     `semver: invalid "1.02.3": column 3: minor version has a leading zero`,
+		},
+		{
+			name:    "leading zero prerelease",
+			input:   "1.2.3-rc.01",
+			wantErr: true,
+			err:     `semver: invalid "1.2.3-rc.01": column 10: numeric prerelease identifier has a leading zero`,
+		},
+		{
+			name:    "empty prerelease identifier",
+			input:   "1.2.3-rc..1",
+			wantErr: true,
+			err:     `semver: invalid "1.2.3-rc..1": column 10: empty prerelease identifier`,
+		},
+		{
+			name:    "empty build",
+			input:   "1.2.3+",
+			wantErr: true,
+			err:     `semver: invalid "1.2.3+": column 7: empty build metadata identifier`,
+		},
+		{
+			name:    "trailing junk",
+			input:   "1.2.3_beta",
+			wantErr: true,
+			err:     `semver: invalid "1.2.3_beta": column 6: unexpected '_' after version`,
+		},
+		{
+			name:    "too large",
+			input:   "99999999999999999999.0.0",
+			wantErr: true,
+			err:     `semver: invalid "99999999999999999999.0.0": column 1: major version is too large`,
+		},
+		{
+			name:    "negative",
+			input:   "-1.0.0",
+			wantErr: true,
+			err:     `semver: invalid "-1.0.0": column 1: expected major version number`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := semver.Parse(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+
+				var syntaxErr *semver.SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("Error was not a *semver.SyntaxError, got %T", err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVersion:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		input string // Version to parse
+		want  string // Canonical string form
+	}{
+		{input: "1.2.3", want: "1.2.3"},
+		{input: "v1.2.3", want: "1.2.3"},
+		{input: "1.2.3-rc.1", want: "1.2.3-rc.1"},
+		{input: "1.2.3+build.5", want: "1.2.3+build.5"},
+		{input: "v1.2.3-rc.1+build.123", want: "1.2.3-rc.1+build.123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := semver.MustParse(tt.input).String(); got != tt.want {
+				t.Errorf("String() = %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// In strictly increasing precedence, straight from the spec plus a few more
+	ordered := []string{
+		"0.9.9",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"1.10.0",
+		"2.0.0",
+	}
+
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+
+			if got := semver.Compare(semver.MustParse(a), semver.MustParse(b)); got != want {
+				t.Errorf("Compare(%s, %s) = %d, wanted %d", a, b, got, want)
+			}
+		}
+	}
+
+	// Build metadata is ignored
+	if got := semver.Compare(semver.MustParse("1.0.0+a"), semver.MustParse("1.0.0+b")); got != 0 {
+		t.Errorf("Compare(1.0.0+a, 1.0.0+b) = %d, wanted 0", got)
+	}
+}
+
+func TestParser(t *testing.T) {
+	version, rest, err := semver.Parser()("v1.2.3-beta rest of input")
+	if err != nil {
+		t.Fatalf("Parser returned an unexpected error: %v", err)
+	}
+
+	if got := version.String(); got != "1.2.3-beta" {
+		t.Errorf("version = %q, wanted %q", got, "1.2.3-beta")
+	}
+
+	if rest != " rest of input" {
+		t.Errorf("rest = %q, wanted %q", rest, " rest of input")
+	}
+}
+
+func TestMustParsePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParse did not panic on an invalid version")
+		}
+	}()
+
+	semver.MustParse("not a version")
+}
+
+func ExampleParse() {
+	version, err := semver.Parse("v1.2.3-rc.1+build.123")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Println(version.Major, version.Minor, version.Patch)
+	fmt.Println(version.Prerelease, version.Build)
+	fmt.Println(version)
+
+	// Output: 1 2 3
+	// [rc 1] [build 123]
+	// 1.2.3-rc.1+build.123
+}
+
+func ExampleCompare() {
+	versions := []semver.Version{
+		semver.MustParse("1.10.0"),
+		semver.MustParse("1.2.0"),
+		semver.MustParse("1.2.0-rc.1"),
+		semver.MustParse("0.9.0"),
+	}
+
+	slices.SortFunc(versions, semver.Compare)
+
+	fmt.Println(versions)
+
+	// Output: [0.9.0 1.2.0-rc.1 1.2.0 1.10.0]
+}
@@ -0,0 +1,55 @@
+package sexpr_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/sexpr"
+)
+
+// benchConfig is a config file of many similar forms.
+var benchConfig = strings.Repeat(`
+; A backend
+(backend :name "api-%d"
+         :hosts ["10.0.0.1:8080" "10.0.0.2:8080"]
+         :health {:path "/healthz" :interval 2.5 :enabled true}
+         :tags #{:prod :eu-west}
+         :started #inst "2024-03-15T09:30:00Z"
+         :filter '(fn [req] (not= (:method req) \D)))
+`, 200)
+
+// benchDeep is a single form nested close to the default max depth.
+var benchDeep = strings.Repeat("[", sexpr.DefaultMaxDepth) + strings.Repeat("]", sexpr.DefaultMaxDepth)
+
+func BenchmarkParseAll(b *testing.B) {
+	b.SetBytes(int64(len(benchConfig)))
+
+	for b.Loop() {
+		if _, err := sexpr.ParseAll(benchConfig); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseDeep(b *testing.B) {
+	b.SetBytes(int64(len(benchDeep)))
+
+	for b.Loop() {
+		if _, err := sexpr.Parse(benchDeep); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	forms, err := sexpr.ParseAll(benchConfig)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		for _, form := range forms {
+			_ = form.String()
+		}
+	}
+}
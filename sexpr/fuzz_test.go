@@ -0,0 +1,86 @@
+package sexpr_test
+
+// There's no reader in the standard library to compare against, so the fuzz tests in here
+// check that the reader never panics, that the spans it records are consistent, and that
+// printing what it read and reading that back gives the same tree.
+
+import (
+	"testing"
+
+	"go.followtheprocess.codes/parser/sexpr"
+)
+
+// checkSpans checks that the span of node and every node inside it is inside its parent's.
+func checkSpans(t *testing.T, src string, node sexpr.Node, parent sexpr.Span) {
+	t.Helper()
+
+	if node.Span.Start < parent.Start || node.Span.End > parent.End || node.Span.Start >= node.Span.End {
+		t.Fatalf("span %+v of %s is empty or outside its parent %+v in %q", node.Span, node, parent, src)
+	}
+
+	for _, item := range node.Items {
+		checkSpans(t, src, item, node.Span)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		"(defn f [x] {:x (inc x)})",
+		`#inst "1985-04-12T23:20:50.52Z"`,
+		"`(a ~b ~@c @d 'e)",
+		`[\a \newline é "s\té" 1.5e3 -7 nil true]`,
+		"#{:a :b} ; comment\n #_(ignored)",
+		"(a,b,,c)",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		node, err := sexpr.Parse(input)
+		if err != nil {
+			return
+		}
+
+		checkSpans(t, input, node, sexpr.Span{Start: 0, End: len(input)})
+
+		printed := node.String()
+
+		again, err := sexpr.Parse(printed)
+		if err != nil {
+			t.Fatalf("Parse(%q) printed as %q, which doesn't read back: %v", input, printed, err)
+		}
+
+		if reprinted := again.String(); reprinted != printed {
+			t.Fatalf("Parse(%q) printed as %q, which reads back as %q", input, printed, reprinted)
+		}
+	})
+}
+
+func FuzzParseAll(f *testing.F) {
+	seeds := []string{
+		"(server :port 8080)\n(routes [\"/\" index])",
+		"#_#_a b c",
+		"; only a comment",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		forms, err := sexpr.ParseAll(input)
+		if err != nil {
+			return
+		}
+
+		last := 0
+		for _, form := range forms {
+			if form.Span.Start < last {
+				t.Fatalf("form %s at %+v overlaps the one before it in %q", form, form.Span, input)
+			}
+
+			checkSpans(t, input, form, sexpr.Span{Start: 0, End: len(input)})
+			last = form.Span.End
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package sexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// String implements [fmt.Stringer] for [Node], printing it in a canonical form that reads
+// back as the same tree (apart from the spans).
+//
+// Reader macros are printed expanded, so 'x prints as (quote x), comments and discarded forms
+// aren't in the tree so aren't printed, and all whitespace between forms becomes a single space.
+func (n Node) String() string {
+	builder := &strings.Builder{}
+	n.write(builder)
+
+	return builder.String()
+}
+
+// write writes the canonical form of the node to builder.
+func (n Node) write(builder *strings.Builder) {
+	switch n.Kind {
+	case KindNil:
+		builder.WriteString("nil")
+	case KindBool:
+		builder.WriteString(strconv.FormatBool(n.Bool))
+	case KindInt:
+		builder.WriteString(strconv.FormatInt(n.Int, 10))
+	case KindFloat:
+		text := strconv.FormatFloat(n.Float, 'g', -1, 64)
+		builder.WriteString(text)
+
+		// Make sure it reads back as a float, not an int
+		if !strings.ContainsAny(text, ".e") {
+			builder.WriteString(".0")
+		}
+	case KindString:
+		writeString(builder, n.Text)
+	case KindChar:
+		writeChar(builder, n.Char)
+	case KindSymbol:
+		builder.WriteString(n.Text)
+	case KindKeyword:
+		builder.WriteByte(':')
+		builder.WriteString(n.Text)
+	case KindList:
+		writeItems(builder, "(", n.Items, ")")
+	case KindVector:
+		writeItems(builder, "[", n.Items, "]")
+	case KindMap:
+		writeItems(builder, "{", n.Items, "}")
+	case KindSet:
+		writeItems(builder, "#{", n.Items, "}")
+	case KindTagged:
+		builder.WriteByte('#')
+		builder.WriteString(n.Text)
+
+		for _, item := range n.Items {
+			builder.WriteByte(' ')
+			item.write(builder)
+		}
+	default:
+		fmt.Fprintf(builder, "#<%s>", n.Kind)
+	}
+}
+
+// writeItems writes the elements of a collection between opening and closing.
+func writeItems(builder *strings.Builder, opening string, items []Node, closing string) {
+	builder.WriteString(opening)
+
+	for i, item := range items {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+
+		item.write(builder)
+	}
+
+	builder.WriteString(closing)
+}
+
+// writeString writes text as a quoted string, escaping it as needed.
+func writeString(builder *strings.Builder, text string) {
+	builder.WriteByte('"')
+
+	for _, r := range text {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(builder, `\u%04x`, r)
+				continue
+			}
+
+			builder.WriteRune(r)
+		}
+	}
+
+	builder.WriteByte('"')
+}
+
+// writeChar writes a character, by name if it has one.
+func writeChar(builder *strings.Builder, r rune) {
+	for name, named := range namedChars {
+		if r == named {
+			builder.WriteByte('\\')
+			builder.WriteString(name)
+
+			return
+		}
+	}
+
+	// Anything else invisible is written as an escape where it fits in one, the reader
+	// takes the first char after the '\' whatever it is, so the rest can be raw
+	if r <= 0xffff && (!unicode.IsGraphic(r) || unicode.IsSpace(r)) {
+		fmt.Fprintf(builder, `\u%04x`, r)
+		return
+	}
+
+	builder.WriteByte('\\')
+	builder.WriteRune(r)
+}
@@ -0,0 +1,890 @@
+// Package sexpr implements a reader for S-expressions with the extended syntax of [EDN]: vectors,
+// maps, sets, keywords, characters and tagged elements, built on the combinators in [parser].
+//
+// Reading produces a [Node] tree that records the exact [Span] of the input each form came from,
+// so tools built on it (like config loaders) can point back at the source of a problem.
+//
+// Reader macros, like 'x for (quote x), are configurable with [Options]: each maps a prefix to
+// the symbol of the two element list it expands to. Nesting is limited to [Options.MaxDepth]
+// so that deeply nested (and likely malicious) input can't exhaust the stack.
+//
+// As with the json package, the tree is exactly what was written: maps and sets keep their
+// elements in order, including any duplicates, and tagged elements like #inst "2024-01-01"
+// are returned as they are rather than interpreted.
+//
+// [EDN]: https://github.com/edn-format/edn
+package sexpr // import "go.followtheprocess.codes/parser/sexpr"
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// DefaultMaxDepth is the maximum nesting depth used when [Options] doesn't set one.
+const DefaultMaxDepth = 10000
+
+// Kind is the kind of a [Node].
+type Kind int
+
+const (
+	KindNil     Kind = iota // The nil literal
+	KindBool                // true or false
+	KindInt                 // An integer like 42 or -7
+	KindFloat               // A floating point number like 1.5 or 2e10
+	KindString              // A string like "hello"
+	KindChar                // A character like \a or \newline
+	KindSymbol              // A symbol like foo or my.ns/bar
+	KindKeyword             // A keyword like :foo
+	KindList                // A list like (a b c), including expanded reader macros
+	KindVector              // A vector like [a b c]
+	KindMap                 // A map like {:a 1 :b 2}
+	KindSet                 // A set like #{a b c}
+	KindTagged              // A tagged element like #inst "2024-01-01"
+)
+
+// String implements [fmt.Stringer] for [Kind].
+func (k Kind) String() string {
+	switch k {
+	case KindNil:
+		return "nil"
+	case KindBool:
+		return "bool"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindString:
+		return "string"
+	case KindChar:
+		return "char"
+	case KindSymbol:
+		return "symbol"
+	case KindKeyword:
+		return "keyword"
+	case KindList:
+		return "list"
+	case KindVector:
+		return "vector"
+	case KindMap:
+		return "map"
+	case KindSet:
+		return "set"
+	case KindTagged:
+		return "tagged"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Span is a half open range of byte offsets [Start, End) into the parsed input.
+type Span struct {
+	Start int // Byte offset of the first byte
+	End   int // Byte offset one past the last byte
+}
+
+// Node is a single form read from the input.
+//
+// Only the fields relevant to the node's [Kind] are populated, e.g. a [KindInt] will only
+// have Int set.
+type Node struct {
+	// Text is the name of a symbol, the name of a keyword without the leading ':', the
+	// unescaped contents of a string, or the tag of a tagged element without the leading '#'.
+	Text string
+
+	// Items are the elements of a list, vector or set, the keys and values of a map one after
+	// the other, or the single element of a tagged element.
+	Items []Node
+
+	Span  Span    // Where in the input the node came from
+	Float float64 // The value of a float
+	Int   int64   // The value of an int
+	Char  rune    // The value of a char
+	Kind  Kind    // The kind of node
+	Bool  bool    // The value of a bool
+}
+
+// Options configures the reader, the zero value is ready to use and reads with the
+// [DefaultMacros] and [DefaultMaxDepth].
+type Options struct {
+	// Macros maps reader macro prefixes to the symbol they expand to, e.g. with "'" mapped to
+	// "quote", 'x reads as (quote x). A nil map means [DefaultMacros], use an empty map to
+	// turn reader macros off.
+	//
+	// Prefixes are matched longest first, after any whitespace and comments but before
+	// anything else, so a macro can take over syntax that would otherwise be an error,
+	// like "#'". Commas are whitespace, as in EDN, unless a prefix starts with one.
+	Macros map[string]string
+
+	// MaxDepth is the maximum nesting depth of collections, reader macros, tagged elements
+	// and discards. Zero (or less) means [DefaultMaxDepth].
+	MaxDepth int
+}
+
+// DefaultMacros returns the reader macros used when [Options] doesn't set any, which are
+// those of Clojure that expand to a single form.
+func DefaultMacros() map[string]string {
+	return map[string]string{
+		"'":  "quote",
+		"`":  "quasiquote",
+		"~":  "unquote",
+		"~@": "unquote-splicing",
+		"@":  "deref",
+	}
+}
+
+// SyntaxError is the error returned when the input can't be read.
+//
+// Line and Column are calculated from the Offset by [Parse] and [ParseAll], errors from
+// the parser returned by [Parser] only have the Offset set.
+type SyntaxError struct {
+	Msg    string // Description of the problem
+	Offset int    // Byte offset in the input at which the error occurred
+	Line   int    // 1 indexed line number of Offset
+	Column int    // 1 indexed column (in utf-8 chars) of Offset
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("sexpr: %s at offset %d", e.Msg, e.Offset)
+	}
+
+	return fmt.Sprintf("sexpr: %s at line %d, column %d", e.Msg, e.Line, e.Column)
+}
+
+// Parse reads a single form with the default [Options].
+func Parse(src string) (Node, error) {
+	return Options{}.Parse(src)
+}
+
+// Parse reads a complete document containing a single form.
+//
+// Whitespace, comments and discarded forms are allowed either side of it, but anything else
+// is an error. Any error returned will be a [*SyntaxError].
+func (o Options) Parse(src string) (Node, error) {
+	if !utf8.ValidString(src) {
+		return Node{}, newSyntaxError(src, invalidUTF8Offset(src), "input not valid utf-8")
+	}
+
+	g := newGrammar(src, o)
+
+	node, rest, err := g.form(src)
+	if err != nil {
+		return Node{}, locate(src, err)
+	}
+
+	if rest, err = g.skip(rest); err != nil {
+		return Node{}, locate(src, err)
+	}
+
+	if rest != "" {
+		return Node{}, locate(src, g.fail(rest, "unexpected %q after top-level form", firstChar(rest)))
+	}
+
+	return node, nil
+}
+
+// ParseAll reads every form with the default [Options].
+func ParseAll(src string) ([]Node, error) {
+	return Options{}.ParseAll(src)
+}
+
+// ParseAll reads a complete document containing any number of forms, like a config file or
+// a source file. Any error returned will be a [*SyntaxError].
+func (o Options) ParseAll(src string) ([]Node, error) {
+	if !utf8.ValidString(src) {
+		return nil, newSyntaxError(src, invalidUTF8Offset(src), "input not valid utf-8")
+	}
+
+	g := newGrammar(src, o)
+
+	var nodes []Node
+
+	rest := src
+	for {
+		var err error
+		if rest, err = g.skip(rest); err != nil {
+			return nil, locate(src, err)
+		}
+
+		if rest == "" {
+			return nodes, nil
+		}
+
+		var node Node
+		if node, rest, err = g.form(rest); err != nil {
+			return nil, locate(src, err)
+		}
+
+		nodes = append(nodes, node)
+	}
+}
+
+// Parser returns a [parser.Parser] that reads a single form with the default [Options].
+func Parser(src string) parser.Parser[Node] {
+	return Options{}.Parser(src)
+}
+
+// Parser returns a [parser.Parser] that reads a single form (with optional leading
+// whitespace and comments) from the start of input, returning any remaining input after it.
+//
+// src is the complete document the input is a suffix of, it's used to calculate the [Span]
+// of each node and the position of any errors.
+func (o Options) Parser(src string) parser.Parser[Node] {
+	return newGrammar(src, o).form
+}
+
+// macro is a single reader macro.
+type macro struct {
+	prefix string // What the macro starts with, like '
+	symbol string // The symbol it expands to, like quote
+}
+
+// grammar holds the state needed to build the parsers for a particular document.
+type grammar struct {
+	form       parser.Parser[Node] // Parses any form, lazily as it refers back to itself
+	src        string              // The entire document
+	whitespace string              // The chars that count as whitespace
+	macros     []macro             // Reader macros, longest prefix first
+	maxDepth   int                 // Maximum nesting depth
+	depth      int                 // Current nesting depth
+}
+
+// newGrammar returns the grammar for src with the given options.
+func newGrammar(src string, options Options) *grammar {
+	g := &grammar{src: src, maxDepth: options.MaxDepth, whitespace: " \t\n\r,"}
+	if g.maxDepth <= 0 {
+		g.maxDepth = DefaultMaxDepth
+	}
+
+	macros := options.Macros
+	if macros == nil {
+		macros = DefaultMacros()
+	}
+
+	for prefix, symbol := range macros {
+		if prefix == "" {
+			continue
+		}
+
+		if prefix[0] == ',' {
+			g.whitespace = " \t\n\r"
+		}
+
+		g.macros = append(g.macros, macro{prefix: prefix, symbol: symbol})
+	}
+
+	slices.SortFunc(g.macros, func(a, b macro) int {
+		return cmp.Or(cmp.Compare(len(b.prefix), len(a.prefix)), cmp.Compare(a.prefix, b.prefix))
+	})
+
+	// Collections, macros etc. contain forms, which may themselves be collections and macros
+	// so we need to refer to the form parser before it's constructed
+	var form parser.Parser[Node]
+	g.form = parser.Lazy(func() parser.Parser[Node] { return form })
+	form = g.any
+
+	return g
+}
+
+// offset returns the byte offset in the source of a suffix of it.
+func (g *grammar) offset(rest string) int {
+	return len(g.src) - len(rest)
+}
+
+// span returns the span of the source between two suffixes of it.
+func (g *grammar) span(from, to string) Span {
+	return Span{Start: g.offset(from), End: g.offset(to)}
+}
+
+// fail returns a new [SyntaxError] at the start of rest.
+func (g *grammar) fail(rest, format string, args ...any) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Offset: g.offset(rest)}
+}
+
+// enter records going one level deeper into the input at the start of rest, returning an
+// error if that's too deep. Every call must be matched by a call to leave.
+func (g *grammar) enter(rest string) error {
+	g.depth++
+	if g.depth > g.maxDepth {
+		return g.fail(rest, "exceeded max nesting depth of %d", g.maxDepth)
+	}
+
+	return nil
+}
+
+// leave records coming back out of a level of nesting.
+func (g *grammar) leave() {
+	g.depth--
+}
+
+// skip skips any whitespace, comments and discarded forms at the start of input.
+func (g *grammar) skip(input string) (string, error) {
+	rest := input
+	for {
+		rest = strings.TrimLeft(rest, g.whitespace)
+
+		switch {
+		case strings.HasPrefix(rest, ";"):
+			end := strings.IndexByte(rest, '\n')
+			if end == -1 {
+				return "", nil
+			}
+
+			rest = rest[end+1:]
+
+		case strings.HasPrefix(rest, "#_"):
+			var err error
+			if rest, err = g.discard(rest); err != nil {
+				return "", err
+			}
+
+		default:
+			return rest, nil
+		}
+	}
+}
+
+// discard parses a "#_" and the form after it, which is thrown away.
+func (g *grammar) discard(input string) (string, error) {
+	defer g.leave()
+
+	if err := g.enter(input); err != nil {
+		return "", err
+	}
+
+	_, rest, err := g.form(input[len("#_"):])
+	if err != nil {
+		return "", err
+	}
+
+	return rest, nil
+}
+
+// any parses any form, after skipping whitespace and comments.
+func (g *grammar) any(input string) (Node, string, error) {
+	rest, err := g.skip(input)
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	if rest == "" {
+		return Node{}, "", g.fail(rest, "unexpected end of input, expected a form")
+	}
+
+	for _, macro := range g.macros {
+		if strings.HasPrefix(rest, macro.prefix) {
+			return g.macro(rest, macro)
+		}
+	}
+
+	// Dispatching on the first char means we get an error from the parser that was
+	// actually relevant
+	switch c := rest[0]; c {
+	case '(':
+		return g.collection(rest, KindList, "(", ')')
+	case '[':
+		return g.collection(rest, KindVector, "[", ']')
+	case '{':
+		return g.collection(rest, KindMap, "{", '}')
+	case '"':
+		return g.string(rest)
+	case '\\':
+		return g.char(rest)
+	case '#':
+		return g.dispatch(rest)
+	case ')', ']', '}':
+		return Node{}, "", g.fail(rest, "unexpected %q", c)
+	default:
+		return g.atom(rest)
+	}
+}
+
+// macro parses a reader macro and the form after it.
+func (g *grammar) macro(input string, macro macro) (Node, string, error) {
+	defer g.leave()
+
+	if err := g.enter(input); err != nil {
+		return Node{}, "", err
+	}
+
+	afterPrefix := input[len(macro.prefix):]
+
+	form, rest, err := g.form(afterPrefix)
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	symbol := Node{Kind: KindSymbol, Text: macro.symbol, Span: g.span(input, afterPrefix)}
+	node := Node{Kind: KindList, Items: []Node{symbol, form}, Span: g.span(input, rest)}
+
+	return node, rest, nil
+}
+
+// collection parses a list, vector, map or set, input starts with the already matched
+// opening and the collection ends with closing.
+func (g *grammar) collection(input string, kind Kind, opening string, closing byte) (Node, string, error) {
+	defer g.leave()
+
+	if err := g.enter(input); err != nil {
+		return Node{}, "", err
+	}
+
+	rest := input[len(opening):]
+
+	var items []Node
+	for {
+		var err error
+		if rest, err = g.skip(rest); err != nil {
+			return Node{}, "", err
+		}
+
+		if rest == "" {
+			return Node{}, "", g.fail(rest, "unexpected end of input, expected %q", closing)
+		}
+
+		if c := rest[0]; c == closing {
+			rest = rest[1:]
+			break
+		} else if c == ')' || c == ']' || c == '}' {
+			return Node{}, "", g.fail(rest, "unexpected %q, expected %q", c, closing)
+		}
+
+		var item Node
+		if item, rest, err = g.form(rest); err != nil {
+			return Node{}, "", err
+		}
+
+		items = append(items, item)
+	}
+
+	if kind == KindMap && len(items)%2 != 0 {
+		return Node{}, "", g.fail(input, "map has a key with no value")
+	}
+
+	return Node{Kind: kind, Items: items, Span: g.span(input, rest)}, rest, nil
+}
+
+// dispatch parses the forms starting with '#': sets and tagged elements. Discards are
+// handled along with whitespace.
+func (g *grammar) dispatch(input string) (Node, string, error) {
+	if strings.HasPrefix(input, "#{") {
+		return g.collection(input, KindSet, "#{", '}')
+	}
+
+	afterHash := input[len("#"):]
+	if afterHash == "" {
+		return Node{}, "", g.fail(afterHash, "unexpected end of input after '#'")
+	}
+
+	if !unicode.IsLetter(firstChar(afterHash)) {
+		return Node{}, "", g.fail(afterHash, "unexpected %q after '#'", firstChar(afterHash))
+	}
+
+	tag, rest := token(afterHash)
+	if err := g.name(tag, afterHash, "tag"); err != nil {
+		return Node{}, "", err
+	}
+
+	defer g.leave()
+
+	if err := g.enter(input); err != nil {
+		return Node{}, "", err
+	}
+
+	element, rest, err := g.form(rest)
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	return Node{Kind: KindTagged, Text: tag, Items: []Node{element}, Span: g.span(input, rest)}, rest, nil
+}
+
+// atom parses a number, keyword or symbol (including nil, true and false).
+func (g *grammar) atom(input string) (Node, string, error) {
+	text, rest := token(input)
+
+	switch {
+	case text == "":
+		// Only a comma that starts a macro prefix, but isn't one
+		return Node{}, "", g.fail(input, "unexpected %q", firstChar(input))
+
+	case isNumber(text):
+		return g.number(text, input, rest)
+
+	case text[0] == ':':
+		name := text[len(":"):]
+		if name == "" {
+			return Node{}, "", g.fail(input[len(text):], "expected keyword name after ':'")
+		}
+
+		if err := g.name(name, input[len(":"):], "keyword"); err != nil {
+			return Node{}, "", err
+		}
+
+		return Node{Kind: KindKeyword, Text: name, Span: g.span(input, rest)}, rest, nil
+
+	default:
+		if err := g.name(text, input, "symbol"); err != nil {
+			return Node{}, "", err
+		}
+
+		node := Node{Kind: KindSymbol, Text: text, Span: g.span(input, rest)}
+		switch text {
+		case "nil":
+			node = Node{Kind: KindNil, Span: node.Span}
+		case "true", "false":
+			node = Node{Kind: KindBool, Bool: text == "true", Span: node.Span}
+		}
+
+		return node, rest, nil
+	}
+}
+
+// name checks that text, which starts at the start of input, is a valid name for a symbol,
+// keyword or tag, what describes which in any error.
+func (g *grammar) name(text, input, what string) error {
+	if c := text[0]; c == ':' || c == '#' || c == '\'' {
+		return g.fail(input, "invalid character %q at start of %s", c, what)
+	}
+
+	valid, rest, err := symbolChars(text)
+	if err != nil && !utf8.ValidString(text) {
+		return g.fail(input, "%s not valid utf-8", what)
+	}
+
+	if rest != "" || valid == "" {
+		bad := input[len(valid):]
+		return g.fail(bad, "invalid character %q in %s", firstChar(bad), what)
+	}
+
+	return nil
+}
+
+// number parses the number text, which is all of input up to rest.
+//
+//	number = [ "+" / "-" ] int [ frac ] [ exp ]
+func (g *grammar) number(text, input, rest string) (Node, string, error) {
+	remainder := text
+	if _, afterSign, err := sign(remainder); err == nil {
+		remainder = afterSign
+	}
+
+	// int = "0" / ( digit1-9 *DIGIT )
+	if _, afterFirst, err := nonZero(remainder); err == nil {
+		remainder = afterFirst
+		if _, afterDigits, err := digits(remainder); err == nil {
+			remainder = afterDigits
+		}
+	} else if _, afterZero, err := zero(remainder); err == nil {
+		remainder = afterZero
+	} else {
+		return Node{}, "", g.fail(input, "invalid number %q", text)
+	}
+
+	isFloat := false
+
+	// frac = "." 1*DIGIT
+	if _, afterPoint, err := point(remainder); err == nil {
+		fraction, afterFraction, _ := digits(afterPoint)
+		if fraction == "" {
+			return Node{}, "", g.fail(input, "invalid number %q", text)
+		}
+
+		remainder = afterFraction
+		isFloat = true
+	}
+
+	// exp = ( "e" / "E" ) [ "+" / "-" ] 1*DIGIT
+	if _, afterE, err := exponent(remainder); err == nil {
+		if _, afterSign, err := sign(afterE); err == nil {
+			afterE = afterSign
+		}
+
+		power, afterPower, _ := digits(afterE)
+		if power == "" {
+			return Node{}, "", g.fail(input, "invalid number %q", text)
+		}
+
+		remainder = afterPower
+		isFloat = true
+	}
+
+	if remainder != "" {
+		return Node{}, "", g.fail(input, "invalid number %q", text)
+	}
+
+	span := g.span(input, rest)
+
+	if isFloat {
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return Node{}, "", g.fail(input, "number %s out of range", text)
+		}
+
+		return Node{Kind: KindFloat, Float: n, Span: span}, rest, nil
+	}
+
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return Node{}, "", g.fail(input, "number %s out of range", text)
+	}
+
+	return Node{Kind: KindInt, Int: n, Span: span}, rest, nil
+}
+
+// string parses a quoted string.
+//
+// If the string contains no escapes, the returned text is a slice of the input so no
+// allocation takes place.
+func (g *grammar) string(input string) (Node, string, error) {
+	_, rest, err := quote(input)
+	if err != nil {
+		return Node{}, "", g.fail(input, "expected '\"' at start of string")
+	}
+
+	var builder *strings.Builder // Only allocated if we actually need to unescape
+
+	for {
+		end := strings.IndexAny(rest, "\"\\")
+		if end == -1 {
+			return Node{}, "", g.fail(rest[len(rest):], "unexpected end of input in string")
+		}
+
+		// Strings can contain any char, including newlines, but Parser doesn't check the
+		// whole input up front like Parse does
+		chunk := rest[:end]
+		if !utf8.ValidString(chunk) {
+			return Node{}, "", g.fail(rest, "string not valid utf-8")
+		}
+
+		if builder != nil {
+			builder.WriteString(chunk)
+		}
+
+		rest = rest[end:]
+
+		if rest[0] == '"' {
+			span := g.span(input, rest[1:])
+			if builder == nil {
+				return Node{Kind: KindString, Text: input[1 : len(input)-len(rest)], Span: span}, rest[1:], nil
+			}
+
+			return Node{Kind: KindString, Text: builder.String(), Span: span}, rest[1:], nil
+		}
+
+		if builder == nil {
+			builder = &strings.Builder{}
+			builder.WriteString(input[1 : len(input)-len(rest)])
+		}
+
+		if len(rest) < len(`\"`) {
+			return Node{}, "", g.fail(rest[len(rest):], "unexpected end of input in string escape")
+		}
+
+		switch escape := rest[1]; escape {
+		case '"', '\\':
+			builder.WriteByte(escape)
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 't':
+			builder.WriteByte('\t')
+		case 'b':
+			builder.WriteByte('\b')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'u':
+			r, afterHex, err := g.unicode(rest)
+			if err != nil {
+				return Node{}, "", err
+			}
+
+			builder.WriteRune(r)
+			rest = afterHex
+
+			continue
+		default:
+			return Node{}, "", g.fail(rest, "invalid escape character %q in string", firstChar(rest[1:]))
+		}
+
+		rest = rest[len(`\n`):]
+	}
+}
+
+// unicode parses a unicode escape like \u00e9 at the start of input.
+func (g *grammar) unicode(input string) (rune, string, error) {
+	// Only ever needs to look at the 4 hex digits
+	start := len(`\u`)
+	digits, _, err := hex(input[start:min(len(input), start+4)])
+	if err != nil {
+		return 0, "", g.fail(input, "invalid unicode escape")
+	}
+
+	rest := input[start+len(digits):]
+
+	// Can't fail, they're 4 hex digits
+	n, _ := strconv.ParseUint(digits, 16, 32)
+
+	r := rune(n)
+	if !utf8.ValidRune(r) {
+		return 0, "", g.fail(input, "unicode escape %s is a surrogate", input[:len(`\u`)+len(digits)])
+	}
+
+	return r, rest, nil
+}
+
+// char parses a character like \a, \newline or \u00e9.
+func (g *grammar) char(input string) (Node, string, error) {
+	_, afterBackslash, err := backslash(input)
+	if err != nil {
+		return Node{}, "", g.fail(input, "expected '\\' at start of character")
+	}
+
+	if afterBackslash == "" {
+		return Node{}, "", g.fail(afterBackslash, "unexpected end of input after '\\'")
+	}
+
+	// The first char is taken whatever it is, so \( and \; work, anything
+	// after it up to a delimiter makes it a named char
+	r, width := utf8.DecodeRuneInString(afterBackslash)
+	if r == utf8.RuneError && width <= 1 {
+		return Node{}, "", g.fail(afterBackslash, "character not valid utf-8")
+	}
+
+	more, rest := token(afterBackslash[width:])
+	name := afterBackslash[:width+len(more)]
+
+	if more != "" {
+		named, ok := namedChars[name]
+		switch {
+		case ok:
+			r = named
+		case name[0] == 'u' && len(name) == len("u0000"):
+			if r, _, err = g.unicode(input); err != nil {
+				return Node{}, "", err
+			}
+		default:
+			return Node{}, "", g.fail(input, "unknown character name %q", name)
+		}
+	}
+
+	return Node{Kind: KindChar, Char: r, Span: g.span(input, rest)}, rest, nil
+}
+
+// namedChars are the chars that can be written by name, like \newline.
+var namedChars = map[string]rune{
+	"newline":   '\n',
+	"return":    '\r',
+	"space":     ' ',
+	"tab":       '\t',
+	"backspace": '\b',
+	"formfeed":  '\f',
+}
+
+// delimiters are the chars that end an atom, along with the end of the input.
+const delimiters = " \t\n\r,()[]{}\";"
+
+// token splits input at the first delimiter, returning the text before it and the rest.
+//
+// Atoms are only ever as long as this, so the combinators that check them only need to look
+// this far, rather than at the whole rest of the input every time.
+func token(input string) (string, string) {
+	end := strings.IndexAny(input, delimiters)
+	if end == -1 {
+		return input, ""
+	}
+
+	return input[:end], input[end:]
+}
+
+// isNumber reports whether an atom is a number (even if it's not a valid one), which is when
+// it starts with a digit, optionally after a sign or a decimal point.
+func isNumber(text string) bool {
+	if text != "" && (text[0] == '+' || text[0] == '-' || text[0] == '.') {
+		text = text[1:]
+	}
+
+	return text != "" && text[0] >= '0' && text[0] <= '9'
+}
+
+// isSymbolChar reports whether r can be part of a symbol, keyword or tag.
+func isSymbolChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".*+!-_?$%&=<>/:#'", r)
+}
+
+var (
+	symbolChars = parser.TakeWhile(isSymbolChar)
+
+	sign     = parser.OneOf("+-")
+	zero     = parser.Char('0')
+	nonZero  = parser.OneOf("123456789")
+	digits   = parser.TakeWhile(func(r rune) bool { return r >= '0' && r <= '9' })
+	point    = parser.Char('.')
+	exponent = parser.OneOf("eE")
+
+	quote     = parser.Char('"')
+	backslash = parser.Char('\\')
+
+	// hex parses the 4 hex digits of a unicode escape.
+	hex = parser.TakeWhileBetween(4, 4, func(r rune) bool {
+		return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+	})
+)
+
+// locate fills in the line and column of a [SyntaxError] from the grammar.
+func locate(src string, err error) error {
+	syntaxErr, ok := err.(*SyntaxError)
+	if !ok {
+		// Shouldn't happen, everything in the grammar returns a SyntaxError
+		return &SyntaxError{Msg: err.Error()}
+	}
+
+	syntaxErr.locate(src)
+
+	return syntaxErr
+}
+
+// newSyntaxError builds a [SyntaxError] at offset into src.
+func newSyntaxError(src string, offset int, msg string) *SyntaxError {
+	err := &SyntaxError{Msg: msg, Offset: offset}
+	err.locate(src)
+
+	return err
+}
+
+// locate fills in the Line and Column of the error from its Offset into src.
+func (e *SyntaxError) locate(src string) {
+	before := src[:e.Offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	e.Line = 1 + strings.Count(before, "\n")
+	e.Column = 1 + utf8.RuneCountInString(before[lineStart:])
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid utf-8 sequence in s.
+func invalidUTF8Offset(s string) int {
+	for pos, char := range s {
+		if char == utf8.RuneError {
+			if _, width := utf8.DecodeRuneInString(s[pos:]); width == 1 {
+				return pos
+			}
+		}
+	}
+
+	return len(s)
+}
+
+// firstChar returns the first utf-8 char in s.
+func firstChar(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
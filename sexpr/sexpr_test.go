@@ -0,0 +1,553 @@
+package sexpr_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/sexpr"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // The document to read
+		want    string // The expected node, printed with its String method
+		kind    string // The expected kind of node
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{name: "nil", input: "nil", want: "nil", kind: "nil"},
+		{name: "true", input: "true", want: "true", kind: "bool"},
+		{name: "false", input: " \n false ", want: "false", kind: "bool"},
+		{name: "int", input: "42", want: "42", kind: "int"},
+		{name: "negative int", input: "-7", want: "-7", kind: "int"},
+		{name: "plus int", input: "+7", want: "7", kind: "int"},
+		{name: "zero", input: "0", want: "0", kind: "int"},
+		{name: "float", input: "1.5", want: "1.5", kind: "float"},
+		{name: "float exponent", input: "-2E+3", want: "-2000.0", kind: "float"},
+		{name: "float fraction and exponent", input: "6.02e23", want: "6.02e+23", kind: "float"},
+		{name: "string", input: `"hello, world"`, want: `"hello, world"`, kind: "string"},
+		{name: "string escapes", input: `"a\"b\\c\nd\teé\b"`, want: `"a\"b\\c\nd\teé\u0008"`, kind: "string"},
+		{name: "string with newline", input: "\"two\nlines\"", want: `"two\nlines"`, kind: "string"},
+		{name: "char", input: `\a`, want: `\a`, kind: "char"},
+		{name: "char paren", input: `\(`, want: `\(`, kind: "char"},
+		{name: "char named", input: `\newline`, want: `\newline`, kind: "char"},
+		{name: "char unicode", input: `\λ`, want: `\λ`, kind: "char"},
+		{name: "char u", input: `\u`, want: `\u`, kind: "char"},
+		{name: "symbol", input: "foo", want: "foo", kind: "symbol"},
+		{name: "symbol namespaced", input: "clojure.core/map", want: "clojure.core/map", kind: "symbol"},
+		{name: "symbol operators", input: "<=", want: "<=", kind: "symbol"},
+		{name: "symbol minus", input: "-", want: "-", kind: "symbol"},
+		{name: "symbol unicode", input: "λ", want: "λ", kind: "symbol"},
+		{name: "symbol predicate", input: "empty?", want: "empty?", kind: "symbol"},
+		{name: "keyword", input: ":foo", want: ":foo", kind: "keyword"},
+		{name: "keyword namespaced", input: ":my.ns/foo", want: ":my.ns/foo", kind: "keyword"},
+		{name: "list", input: "(a b c)", want: "(a b c)", kind: "list"},
+		{name: "empty list", input: "()", want: "()", kind: "list"},
+		{name: "vector", input: "[1, 2, 3]", want: "[1 2 3]", kind: "vector"},
+		{name: "map", input: "{:a 1, :b [2 3]}", want: "{:a 1 :b [2 3]}", kind: "map"},
+		{name: "set", input: "#{1 2 3}", want: "#{1 2 3}", kind: "set"},
+		{name: "set duplicates", input: "#{1 1}", want: "#{1 1}", kind: "set"},
+		{name: "tagged", input: `#inst "1985-04-12T23:20:50.52Z"`, want: `#inst "1985-04-12T23:20:50.52Z"`, kind: "tagged"},
+		{name: "tagged namespaced", input: "#myapp/Person {:name \"Fred\"}", want: `#myapp/Person {:name "Fred"}`, kind: "tagged"},
+		{name: "nested", input: "(defn f [x] {:x (inc x)})", want: "(defn f [x] {:x (inc x)})", kind: "list"},
+		{name: "quote", input: "'x", want: "(quote x)", kind: "list"},
+		{name: "quote list", input: "'(1 2)", want: "(quote (1 2))", kind: "list"},
+		{name: "nested macros", input: "`(a ~b ~@c @d)", want: "(quasiquote (a (unquote b) (unquote-splicing c) (deref d)))", kind: "list"},
+		{name: "quote quote", input: "''x", want: "(quote (quote x))", kind: "list"},
+		{name: "comments", input: "; leading\n(a ; inside\n b) ; trailing", want: "(a b)", kind: "list"},
+		{name: "discard", input: "#_ignored [1 #_2 3 #_(4 5)] #_after", want: "[1 3]", kind: "vector"},
+		{name: "discard discard", input: "[#_#_1 2 3]", want: "[3]", kind: "vector"},
+		{name: "no space between forms", input: `(a"b"[c]:d)`, want: `(a "b" [c] :d)`, kind: "list"},
+		{name: "symbol with quote", input: "a'b", want: "a'b", kind: "symbol"},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: true,
+			err:     "sexpr: unexpected end of input, expected a form at line 1, column 1",
+		},
+		{
+			name:    "only a comment",
+			input:   "; nothing here",
+			wantErr: true,
+			err:     "sexpr: unexpected end of input, expected a form at line 1, column 15",
+		},
+		{
+			name:    "two forms",
+			input:   "a b",
+			wantErr: true,
+			err:     "sexpr: unexpected 'b' after top-level form at line 1, column 3",
+		},
+		{
+			name:    "unclosed list",
+			input:   "(a (b c)\n",
+			wantErr: true,
+			err:     "sexpr: unexpected end of input, expected ')' at line 2, column 1",
+		},
+		{
+			name:    "mismatched close",
+			input:   "(a [b)]",
+			wantErr: true,
+			err:     "sexpr: unexpected ')', expected ']' at line 1, column 6",
+		},
+		{
+			name:    "stray close",
+			input:   ")",
+			wantErr: true,
+			err:     "sexpr: unexpected ')' at line 1, column 1",
+		},
+		{
+			name:    "odd map",
+			input:   "{:a 1 :b}",
+			wantErr: true,
+			err:     "sexpr: map has a key with no value at line 1, column 1",
+		},
+		{
+			name:    "leading zero",
+			input:   "007",
+			wantErr: true,
+			err:     `sexpr: invalid number "007" at line 1, column 1`,
+		},
+		{
+			name:    "number with junk",
+			input:   "(12abc)",
+			wantErr: true,
+			err:     `sexpr: invalid number "12abc" at line 1, column 2`,
+		},
+		{
+			name:    "leading point",
+			input:   ".5",
+			wantErr: true,
+			err:     `sexpr: invalid number ".5" at line 1, column 1`,
+		},
+		{
+			name:    "no fraction",
+			input:   "1.",
+			wantErr: true,
+			err:     `sexpr: invalid number "1." at line 1, column 1`,
+		},
+		{
+			name:    "int out of range",
+			input:   "9223372036854775808",
+			wantErr: true,
+			err:     "sexpr: number 9223372036854775808 out of range at line 1, column 1",
+		},
+		{
+			name:    "float out of range",
+			input:   "1e999",
+			wantErr: true,
+			err:     "sexpr: number 1e999 out of range at line 1, column 1",
+		},
+		{
+			name:    "unterminated string",
+			input:   `("abc)`,
+			wantErr: true,
+			err:     "sexpr: unexpected end of input in string at line 1, column 7",
+		},
+		{
+			name:    "bad escape",
+			input:   `"a\qb"`,
+			wantErr: true,
+			err:     `sexpr: invalid escape character 'q' in string at line 1, column 3`,
+		},
+		{
+			name:    "bad unicode escape",
+			input:   `"\u12"`,
+			wantErr: true,
+			err:     "sexpr: invalid unicode escape at line 1, column 2",
+		},
+		{
+			name:    "surrogate escape",
+			input:   `"\ud800"`,
+			wantErr: true,
+			err:     `sexpr: unicode escape \ud800 is a surrogate at line 1, column 2`,
+		},
+		{
+			name:    "unknown char name",
+			input:   `\bell`,
+			wantErr: true,
+			err:     `sexpr: unknown character name "bell" at line 1, column 1`,
+		},
+		{
+			name:    "backslash at end",
+			input:   `(a \`,
+			wantErr: true,
+			err:     `sexpr: unexpected end of input after '\' at line 1, column 5`,
+		},
+		{
+			name:    "bad symbol char",
+			input:   "(foo@bar)",
+			wantErr: true,
+			err:     `sexpr: invalid character '@' in symbol at line 1, column 5`,
+		},
+		{
+			name:    "empty keyword",
+			input:   ": a",
+			wantErr: true,
+			err:     "sexpr: expected keyword name after ':' at line 1, column 2",
+		},
+		{
+			name:    "double colon keyword",
+			input:   "::a",
+			wantErr: true,
+			err:     "sexpr: invalid character ':' at start of keyword at line 1, column 2",
+		},
+		{
+			name:    "bad dispatch",
+			input:   "#!shebang",
+			wantErr: true,
+			err:     `sexpr: unexpected '!' after '#' at line 1, column 2`,
+		},
+		{
+			name:    "hash at end",
+			input:   "#",
+			wantErr: true,
+			err:     "sexpr: unexpected end of input after '#' at line 1, column 2",
+		},
+		{
+			name:    "tag without element",
+			input:   "[#inst]",
+			wantErr: true,
+			err:     "sexpr: unexpected ']' at line 1, column 7",
+		},
+		{
+			name:    "quote without form",
+			input:   "(a ')",
+			wantErr: true,
+			err:     "sexpr: unexpected ')' at line 1, column 5",
+		},
+		{
+			name:    "discard without form",
+			input:   "a #_",
+			wantErr: true,
+			err:     "sexpr: unexpected end of input, expected a form at line 1, column 5",
+		},
+		{
+			name:    "invalid utf-8",
+			input:   "(a \"\xff\")",
+			wantErr: true,
+			err:     "sexpr: input not valid utf-8 at line 1, column 5",
+		},
+		{
+			name:    "error on a later line",
+			input:   "(config\n  :name \"café\"\n  :port 80a)",
+			wantErr: true,
+			err:     `sexpr: invalid number "80a" at line 3, column 9`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sexpr.Parse(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+
+				var syntaxErr *sexpr.SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("Error was not a *sexpr.SyntaxError, got %T", err)
+				}
+
+				return
+			}
+
+			if printed := got.String(); printed != tt.want {
+				t.Errorf("\nValue:\t%s\nWanted:\t%s\n", printed, tt.want)
+			}
+
+			if kind := got.Kind.String(); kind != tt.kind {
+				t.Errorf("\nKind:\t%s\nWanted:\t%s\n", kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	got, err := sexpr.Parse(`[nil true -3 2.5 "s" \c sym :kw]`)
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	want := []sexpr.Node{
+		{Kind: sexpr.KindNil, Span: sexpr.Span{Start: 1, End: 4}},
+		{Kind: sexpr.KindBool, Bool: true, Span: sexpr.Span{Start: 5, End: 9}},
+		{Kind: sexpr.KindInt, Int: -3, Span: sexpr.Span{Start: 10, End: 12}},
+		{Kind: sexpr.KindFloat, Float: 2.5, Span: sexpr.Span{Start: 13, End: 16}},
+		{Kind: sexpr.KindString, Text: "s", Span: sexpr.Span{Start: 17, End: 20}},
+		{Kind: sexpr.KindChar, Char: 'c', Span: sexpr.Span{Start: 21, End: 23}},
+		{Kind: sexpr.KindSymbol, Text: "sym", Span: sexpr.Span{Start: 24, End: 27}},
+		{Kind: sexpr.KindKeyword, Text: "kw", Span: sexpr.Span{Start: 28, End: 31}},
+	}
+
+	if !reflect.DeepEqual(got.Items, want) {
+		t.Errorf("\nItems:\t%#v\nWanted:\t%#v\n", got.Items, want)
+	}
+
+	if got.Span != (sexpr.Span{Start: 0, End: 32}) {
+		t.Errorf("Span = %+v, wanted {Start:0 End:32}", got.Span)
+	}
+}
+
+func TestMacroSpans(t *testing.T) {
+	src := "( 'foo ~@ bar)"
+
+	got, err := sexpr.Parse(src)
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	var spans []string
+	for _, item := range got.Items {
+		spans = append(spans, src[item.Span.Start:item.Span.End])
+		for _, inner := range item.Items {
+			spans = append(spans, src[inner.Span.Start:inner.Span.End])
+		}
+	}
+
+	want := []string{"'foo", "'", "foo", "~@ bar", "~@", "bar"}
+	if !reflect.DeepEqual(spans, want) {
+		t.Errorf("\nSpans:\t%q\nWanted:\t%q\n", spans, want)
+	}
+}
+
+func TestMacros(t *testing.T) {
+	tests := []struct {
+		macros map[string]string // The reader macros to use
+		name   string            // Identifying test case name
+		input  string            // The document to read
+		want   string            // The expected forms, printed with their String method
+		err    string            // The expected error message, if there was one
+	}{
+		{
+			name:   "none",
+			macros: map[string]string{},
+			input:  "'x",
+			err:    `sexpr: invalid character '\'' at start of symbol at line 1, column 1`,
+		},
+		{
+			name:   "var",
+			macros: map[string]string{"#'": "var"},
+			input:  "#'foo #{1}",
+			want:   "(var foo) #{1}",
+		},
+		{
+			name:   "scheme",
+			macros: map[string]string{"'": "quote", "`": "quasiquote", ",": "unquote", ",@": "unquote-splicing"},
+			input:  "`(a ,b ,@c)",
+			want:   "(quasiquote (a (unquote b) (unquote-splicing c)))",
+		},
+		{
+			name:   "comma macro stops comma being whitespace",
+			macros: map[string]string{",@": "unquote-splicing"},
+			input:  "[a, b]",
+			err:    "sexpr: unexpected ',' at line 1, column 3",
+		},
+		{
+			name:   "longest prefix wins",
+			macros: map[string]string{"^": "meta", "^^": "double-meta"},
+			input:  "^a ^^b",
+			want:   "(meta a) (double-meta b)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms, err := sexpr.Options{Macros: tt.macros}.ParseAll(tt.input)
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t%q\n", msg, tt.err)
+				}
+
+				return
+			}
+
+			var printed []string
+			for _, form := range forms {
+				printed = append(printed, form.String())
+			}
+
+			if tt.err != "" {
+				t.Fatalf("ParseAll(%q) = %s, wanted error %q", tt.input, printed, tt.err)
+			}
+
+			if got := strings.Join(printed, " "); got != tt.want {
+				t.Errorf("\nForms:\t%s\nWanted:\t%s\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	tests := []struct {
+		name  string // Identifying test case name
+		open  string // What opens a level of nesting
+		close string // What closes a level of nesting
+	}{
+		{name: "list", open: "(", close: ")"},
+		{name: "vector", open: "[", close: "]"},
+		{name: "map", open: "{:k ", close: "}"},
+		{name: "set", open: "#{", close: "}"},
+		{name: "quote", open: "'", close: ""},
+		{name: "tagged", open: "#tag ", close: ""},
+		{name: "discard", open: "#_", close: " x"}, // Each discard throws away one of the x's, leaving one to read
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nest := func(depth int) string {
+				return strings.Repeat(tt.open, depth) + "x" + strings.Repeat(tt.close, depth)
+			}
+
+			options := sexpr.Options{MaxDepth: 50}
+			if _, err := options.Parse(nest(50)); err != nil {
+				t.Fatalf("Parse returned an unexpected error at max depth: %v", err)
+			}
+
+			_, err := options.Parse(nest(51))
+			if err == nil || !strings.Contains(err.Error(), "exceeded max nesting depth of 50") {
+				t.Fatalf("Parse past max depth returned %v, wanted max depth error", err)
+			}
+
+			// Far past the default is still a clean error, never a stack overflow
+			_, err = sexpr.Parse(nest(10 * sexpr.DefaultMaxDepth))
+			if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("exceeded max nesting depth of %d", sexpr.DefaultMaxDepth)) {
+				t.Fatalf("Parse far past default max depth returned %v, wanted max depth error", err)
+			}
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	src := `
+; Server config
+(server :host "localhost" :port 8080)
+
+#_(server :host "old")
+
+(routes
+  ["/" index]
+  ["/about" about])
+`
+
+	forms, err := sexpr.ParseAll(src)
+	if err != nil {
+		t.Fatalf("ParseAll returned an unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, form := range forms {
+		got = append(got, form.String())
+	}
+
+	want := []string{`(server :host "localhost" :port 8080)`, `(routes ["/" index] ["/about" about])`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nForms:\t%q\nWanted:\t%q\n", got, want)
+	}
+
+	if forms, err := sexpr.ParseAll("  ; just a comment\n"); err != nil || forms != nil {
+		t.Errorf("ParseAll of a comment = (%v, %v), wanted (nil, nil)", forms, err)
+	}
+
+	_, err = sexpr.ParseAll("(a)\n(b\n")
+	if msg := fmt.Sprint(err); msg != "sexpr: unexpected end of input, expected ')' at line 3, column 1" {
+		t.Errorf("ParseAll of an unclosed form returned %q", msg)
+	}
+}
+
+func TestParser(t *testing.T) {
+	// The parser should leave anything after the form alone so it can be
+	// composed with other parsers
+	src := `  (a "b") trailing`
+
+	node, remainder, err := sexpr.Parser(src)(src)
+	if err != nil {
+		t.Fatalf("Parser returned an unexpected error: %v", err)
+	}
+
+	if remainder != " trailing" {
+		t.Errorf("\nRemainder:\t%q\nWanted:\t%q\n", remainder, " trailing")
+	}
+
+	if got := src[node.Span.Start:node.Span.End]; got != `(a "b")` {
+		t.Errorf("\nSpan text:\t%q\nWanted:\t%q\n", got, `(a "b")`)
+	}
+
+	// Errors from the parser only have an offset
+	_, _, err = sexpr.Parser("(a")("(a")
+	if msg := fmt.Sprint(err); msg != "sexpr: unexpected end of input, expected ')' at offset 2" {
+		t.Errorf("Parser error = %q", msg)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if got := sexpr.KindTagged.String(); got != "tagged" {
+		t.Errorf("KindTagged.String() = %q, wanted tagged", got)
+	}
+
+	if got := sexpr.Kind(99).String(); got != "Kind(99)" {
+		t.Errorf("Kind(99).String() = %q, wanted Kind(99)", got)
+	}
+}
+
+func ExampleParse() {
+	input := `(server :host "localhost" :ports [80 443])`
+
+	form, err := sexpr.Parse(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	for _, item := range form.Items {
+		fmt.Printf("%-7s at %2d-%2d: %s\n", item.Kind, item.Span.Start, item.Span.End, input[item.Span.Start:item.Span.End])
+	}
+
+	// Output:
+	// symbol  at  1- 7: server
+	// keyword at  8-13: :host
+	// string  at 14-25: "localhost"
+	// keyword at 26-32: :ports
+	// vector  at 33-41: [80 443]
+}
+
+func ExampleOptions() {
+	// Scheme style unquoting, where commas aren't whitespace
+	options := sexpr.Options{
+		Macros: map[string]string{
+			"'":  "quote",
+			"`":  "quasiquote",
+			",":  "unquote",
+			",@": "unquote-splicing",
+		},
+	}
+
+	form, err := options.Parse("`(list ,x ,@rest)")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Println(form)
+
+	// Output: (quasiquote (list (unquote x) (unquote-splicing rest)))
+}
+
+func ExampleSyntaxError() {
+	input := "(config\n  :port 80a)"
+
+	_, err := sexpr.Parse(input)
+
+	fmt.Println(err)
+
+	// Output: sexpr: invalid number "80a" at line 2, column 9
+}
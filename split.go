@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"iter"
+)
+
+// Split returns an iterator over the substrings of the input between each match of a separator
+// [Parser], like [strings.Split] but where the separator can be anything a parser can recognise.
+//
+// Separators are found using the same scanning rules as [FindAll], so they never overlap and a
+// separator parser that succeeds without consuming any input never splits. The separators themselves
+// are not included in the yielded substrings.
+//
+// Like [strings.Split], if the input does not contain a separator, the entire input is yielded once,
+// and a separator at the very start or end of the input yields an empty substring either side.
+//
+// The substrings are slices of the input so Split never allocates them, and invalid utf-8 in the
+// input is yielded unsplit as a single substring.
+func Split[S any](input string, sep Parser[S]) iter.Seq[string] {
+	return SplitN(input, sep, -1)
+}
+
+// SplitN is like [Split] but stops after yielding at most n substrings, the last of which is the
+// unsplit remainder of the input.
+//
+// The count determines the number of substrings to yield, exactly like [strings.SplitN]:
+//   - n > 0: at most n substrings; the last substring will be the unsplit remainder
+//   - n == 0: nothing is yielded
+//   - n < 0: all substrings
+func SplitN[S any](input string, sep Parser[S], n int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if n == 0 {
+			return
+		}
+
+		start := 0 // Start of the current substring
+		pieces := 0
+
+		for match, err := range FindAll(sep, input) {
+			if err != nil {
+				// Invalid input, nothing to split on
+				break
+			}
+
+			if n > 0 && pieces == n-1 {
+				// The last piece is everything that's left
+				break
+			}
+
+			if !yield(input[start:match.Start]) {
+				return
+			}
+
+			pieces++
+			start = match.End
+		}
+
+		yield(input[start:])
+	}
+}
@@ -0,0 +1,183 @@
+package parser_test
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// listSep is a separator parser equivalent to the regex `\s*[,;]\s*`.
+var listSep = parser.Chain(
+	parser.SkipMany(parser.OneOf(" \t")),
+	parser.OneOf(",;"),
+	parser.SkipMany(parser.OneOf(" \t")),
+)
+
+// newline is a separator parser that matches either CRLF or LF line endings.
+var newline = parser.Try(parser.Exact("\r\n"), parser.Char('\n'))
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string   // Identifying test case name
+		input string   // Input to split
+		want  []string // Expected substrings
+	}{
+		{
+			name:  "empty input",
+			input: "",
+			want:  []string{""},
+		},
+		{
+			name:  "no separator",
+			input: "just one",
+			want:  []string{"just one"},
+		},
+		{
+			name:  "structured separator",
+			input: "a, b;c  ,\td ;e",
+			want:  []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:  "leading and trailing separators",
+			input: ", a, b ,",
+			want:  []string{"", "a", "b", ""},
+		},
+		{
+			name:  "adjacent separators",
+			input: "a,,b",
+			want:  []string{"a", "", "b"},
+		},
+		{
+			name:  "utf8",
+			input: "日ð本 ; 語þ日",
+			want:  []string{"日ð本", "語þ日"},
+		},
+		{
+			name:  "bad utf8",
+			input: "\xf8\xa1,\xa1\xa1\xa1",
+			want:  []string{"\xf8\xa1,\xa1\xa1\xa1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Collect(parser.Split(tt.input, listSep))
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	input := "one\r\ntwo\nthree\r\n"
+
+	got := slices.Collect(parser.Split(input, newline))
+	want := strings.Split(strings.ReplaceAll(input, "\r\n", "\n"), "\n")
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got, want)
+	}
+}
+
+func TestSplitN(t *testing.T) {
+	tests := []struct {
+		name  string   // Identifying test case name
+		input string   // Input to split
+		want  []string // Expected substrings
+		n     int      // Max number of substrings
+	}{
+		{
+			name:  "zero",
+			input: "a,b,c",
+			n:     0,
+			want:  nil,
+		},
+		{
+			name:  "negative",
+			input: "a,b,c",
+			n:     -1,
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "one",
+			input: "a, b, c",
+			n:     1,
+			want:  []string{"a, b, c"},
+		},
+		{
+			name:  "two",
+			input: "a, b, c",
+			n:     2,
+			want:  []string{"a", "b, c"},
+		},
+		{
+			name:  "more than there are",
+			input: "a, b, c",
+			n:     10,
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "empty input",
+			input: "",
+			n:     2,
+			want:  []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Collect(parser.SplitN(tt.input, listSep, tt.n))
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitBreak(t *testing.T) {
+	var got []string
+	for piece := range parser.Split("a,b,c,d", listSep) {
+		got = append(got, piece)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	want := []string{"a", "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got, want)
+	}
+}
+
+func ExampleSplit() {
+	input := "apples , pears;bananas ;  kiwis"
+
+	for piece := range parser.Split(input, listSep) {
+		fmt.Printf("%q\n", piece)
+	}
+
+	// Output: "apples"
+	// "pears"
+	// "bananas"
+	// "kiwis"
+}
+
+func ExampleSplitN() {
+	input := "key = value = with = equals"
+
+	sep := parser.Chain(parser.SkipMany(parser.Char(' ')), parser.Char('='), parser.SkipMany(parser.Char(' ')))
+
+	for piece := range parser.SplitN(input, sep, 2) {
+		fmt.Printf("%q\n", piece)
+	}
+
+	// Output: "key"
+	// "value = with = equals"
+}
@@ -0,0 +1,258 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StatefulParser is a [Parser] that also threads a user defined state of type S through the parse,
+// for grammars that need some context as they go, like a symbol table, a nesting depth or a flag
+// that's switched on part way through the input.
+//
+// Each StatefulParser is given the state as it was before it ran, and returns the state as it is
+// after, so the state moves through a parse in step with the remaining input. A failed parser returns
+// the zero value, an empty remainder and the state it was given, unchanged.
+//
+// Because the state is passed by value, backtracking rolls it back for free: [TryState] gives each
+// alternative the state from before the first one, whatever the failed ones did to it. That only
+// holds if the state is treated as an immutable value though, so a state containing maps or slices
+// should be copied when it's changed rather than modified in place, [ModifyState] is the natural
+// place to do that.
+//
+// Existing parsers can be used in a stateful grammar with [Lift], and a stateful grammar can be
+// used anywhere a [Parser] is expected with [WithState].
+type StatefulParser[S, T any] func(input string, state S) (value T, remainder string, newState S, err error)
+
+// Lift returns a [StatefulParser] that applies a stateless [Parser], passing the state through
+// unchanged.
+//
+// If the parser returns an error, Lift will bubble up this error to the caller.
+func Lift[S, T any](parser Parser[T]) StatefulParser[S, T] {
+	return func(input string, state S) (T, string, S, error) {
+		var zero T
+
+		if parser == nil {
+			return zero, "", state, errors.New("Lift: parser must not be nil")
+		}
+
+		value, remainder, err := parser(input)
+		if err != nil {
+			return zero, "", state, fmt.Errorf("Lift: parser returned error: %w", err)
+		}
+
+		return value, remainder, state, nil
+	}
+}
+
+// WithState returns a [Parser] that applies a [StatefulParser] starting from an initial state,
+// discarding the final state.
+//
+// It's how a stateful grammar is run, or plugged into anything that expects a [Parser], like
+// [FindAll] or [Split]. The initial state is the same for every input so, as with [StatefulParser]
+// in general, it mustn't be modified in place.
+//
+// If the stateful parser returns an error, WithState will bubble up this error to the caller.
+func WithState[S, T any](parser StatefulParser[S, T], initial S) Parser[T] {
+	return func(input string) (T, string, error) {
+		var zero T
+
+		if parser == nil {
+			return zero, "", errors.New("WithState: parser must not be nil")
+		}
+
+		value, remainder, _, err := parser(input, initial)
+		if err != nil {
+			return zero, "", fmt.Errorf("WithState: parser returned error: %w", err)
+		}
+
+		return value, remainder, nil
+	}
+}
+
+// GetState returns a [StatefulParser] that consumes no input and returns the current state as its
+// value, so that later parsers (see [BindState]) can depend on it.
+func GetState[S any]() StatefulParser[S, S] {
+	return func(input string, state S) (S, string, S, error) {
+		return state, input, state, nil
+	}
+}
+
+// PutState returns a [StatefulParser] that consumes no input and replaces the current state, the
+// value is the new state.
+func PutState[S any](newState S) StatefulParser[S, S] {
+	return func(input string, _ S) (S, string, S, error) {
+		return newState, input, newState, nil
+	}
+}
+
+// ModifyState returns a [StatefulParser] that consumes no input and replaces the current state
+// with the result of calling fn with it, the value is the new state.
+//
+// fn should return a modified copy of the state rather than changing it in place, so that
+// [TryState] can roll back to the original.
+//
+// If fn is nil or returns an error, an error will be returned and the state is left as it was.
+func ModifyState[S any](fn func(S) (S, error)) StatefulParser[S, S] {
+	return func(input string, state S) (S, string, S, error) {
+		var zero S
+
+		if fn == nil {
+			return zero, "", state, errors.New("ModifyState: fn must be a non-nil function")
+		}
+
+		newState, err := fn(state)
+		if err != nil {
+			return zero, "", state, fmt.Errorf("ModifyState: fn returned error: %w", err)
+		}
+
+		return newState, input, newState, nil
+	}
+}
+
+// TryState returns a [StatefulParser] that attempts a series of stateful sub-parsers, returning the
+// output from the first successful one.
+//
+// It's the stateful flavour of [Try], each parser is given the same input and the same state, so any
+// changes a failed parser made to the state are rolled back before the next one is attempted.
+//
+// If all parsers fail, an error will be returned.
+func TryState[S, T any](parsers ...StatefulParser[S, T]) StatefulParser[S, T] {
+	return func(input string, state S) (T, string, S, error) {
+		var zero T
+
+		for _, parser := range parsers {
+			value, remainder, newState, err := parser(input, state)
+			if err != nil {
+				// Try the next parser, from the original state
+				continue
+			}
+
+			return value, remainder, newState, nil
+		}
+
+		return zero, "", state, errors.New("TryState: all parsers failed")
+	}
+}
+
+// ChainState returns a [StatefulParser] that calls a series of stateful sub-parsers, passing the
+// remainder and the state from one as input to the next and returning a slice of values; one from
+// each parser, along with the remaining input and the state after applying all the parsers.
+//
+// It's the stateful flavour of [Chain], if any of the parsers fail, an error will be returned.
+func ChainState[S, T any](parsers ...StatefulParser[S, T]) StatefulParser[S, []T] {
+	return func(input string, state S) ([]T, string, S, error) {
+		values := make([]T, 0, len(parsers))
+
+		remainder, current := input, state
+		for _, parser := range parsers {
+			value, rest, newState, err := parser(remainder, current)
+			if err != nil {
+				return nil, "", state, fmt.Errorf("ChainState: sub parser failed: %w", err)
+			}
+			values = append(values, value)
+			remainder, current = rest, newState
+		}
+
+		return values, remainder, current, nil
+	}
+}
+
+// MapState returns a [StatefulParser] that applies a function to the result of another stateful
+// parser, the stateful flavour of [Map].
+//
+// If the provided parser or the mapping function fn return an error, MapState will bubble up
+// this error to the caller.
+func MapState[S, T1, T2 any](parser StatefulParser[S, T1], fn func(T1) (T2, error)) StatefulParser[S, T2] {
+	return func(input string, state S) (T2, string, S, error) {
+		var zero T2
+
+		if fn == nil {
+			return zero, "", state, errors.New("MapState: fn must be a non-nil function")
+		}
+
+		value, remainder, newState, err := parser(input, state)
+		if err != nil {
+			return zero, "", state, fmt.Errorf("MapState: parser returned error: %w", err)
+		}
+
+		newValue, err := fn(value)
+		if err != nil {
+			return zero, "", state, fmt.Errorf("MapState: fn returned error: %w", err)
+		}
+
+		return newValue, remainder, newState, nil
+	}
+}
+
+// BindState returns a [StatefulParser] that applies another stateful parser, then passes the parsed
+// value to fn to choose the parser to apply to the remaining input, the stateful flavour of [Bind].
+//
+// Combined with [GetState], it lets the rest of the parse depend on the state, for example
+// rejecting a reference to a name that isn't in the symbol table.
+//
+// If either parser returns an error, BindState will bubble up this error to the caller.
+//
+// If fn is nil or returns a nil parser, an error will be returned.
+func BindState[S, A, B any](parser StatefulParser[S, A], fn func(A) StatefulParser[S, B]) StatefulParser[S, B] {
+	return func(input string, state S) (B, string, S, error) {
+		var zero B
+
+		if fn == nil {
+			return zero, "", state, errors.New("BindState: fn must be a non-nil function")
+		}
+
+		value, remainder, newState, err := parser(input, state)
+		if err != nil {
+			return zero, "", state, fmt.Errorf("BindState: parser returned error: %w", err)
+		}
+
+		next := fn(value)
+		if next == nil {
+			return zero, "", state, errors.New("BindState: fn returned a nil parser")
+		}
+
+		newValue, remainder, newState, err := next(remainder, newState)
+		if err != nil {
+			return zero, "", state, fmt.Errorf("BindState: next parser returned error: %w", err)
+		}
+
+		return newValue, remainder, newState, nil
+	}
+}
+
+// FoldManyState returns a [StatefulParser] that applies another stateful parser repeatedly until
+// it fails, folding each parsed value into an accumulator, the stateful flavour of [FoldMany].
+//
+// The state is threaded through each application of the parser, and the state from the final,
+// failed application is discarded along with its value.
+//
+// If init or step is nil, an error will be returned.
+func FoldManyState[S, T, A any](parser StatefulParser[S, T], init func() A, step func(A, T) A) StatefulParser[S, A] {
+	return func(input string, state S) (A, string, S, error) {
+		var zero A
+
+		if init == nil {
+			return zero, "", state, errors.New("FoldManyState: init must be a non-nil function")
+		}
+
+		if step == nil {
+			return zero, "", state, errors.New("FoldManyState: step must be a non-nil function")
+		}
+
+		acc := init()
+		remainder, current := input, state
+
+		for {
+			value, rest, newState, err := parser(remainder, current)
+			if err != nil || len(rest) == len(remainder) {
+				// Either the parser has failed or it made no progress, either way we're done
+				break
+			}
+
+			acc = step(acc, value)
+			remainder, current = rest, newState
+		}
+
+		return acc, remainder, current, nil
+	}
+}
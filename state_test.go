@@ -0,0 +1,406 @@
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// count is a stateful parser that takes a single char, counting how many it's taken in the state.
+var count = parser.BindState(
+	parser.Lift[int](parser.Take(1)),
+	func(char string) parser.StatefulParser[int, string] {
+		return parser.MapState(
+			parser.ModifyState(func(n int) (int, error) { return n + 1, nil }),
+			func(int) (string, error) { return char, nil },
+		)
+	},
+)
+
+// failing is a stateful parser that changes the state then fails.
+var failing = parser.BindState(
+	parser.PutState(100),
+	func(int) parser.StatefulParser[int, string] { return parser.Lift[int](parser.Exact("nope")) },
+)
+
+func TestStatefulParsers(t *testing.T) {
+	tests := []struct {
+		parser        parser.StatefulParser[int, string] // The parser under test
+		name          string                             // Identifying test case name
+		input         string                             // Input to the parser
+		wantValue     string                             // Expected value
+		wantRemainder string                             // Expected remainder
+		wantErrMsg    string                             // Expected error message, if any
+		state         int                                // State to start from
+		wantState     int                                // Expected state after the parser
+		wantErr       bool                               // Whether it should have returned an error
+	}{
+		{
+			name:          "lift",
+			parser:        parser.Lift[int](parser.Exact("hello")),
+			input:         "hello world",
+			state:         3,
+			wantValue:     "hello",
+			wantRemainder: " world",
+			wantState:     3,
+			wantErr:       false,
+		},
+		{
+			name:          "lift error",
+			parser:        parser.Lift[int](parser.Exact("hello")),
+			input:         "goodbye",
+			state:         3,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     3,
+			wantErr:       true,
+			wantErrMsg:    "Lift: parser returned error: Exact: match (hello) not in input",
+		},
+		{
+			name:          "lift nil",
+			parser:        parser.Lift[int, string](nil),
+			input:         "hello",
+			state:         3,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     3,
+			wantErr:       true,
+			wantErrMsg:    "Lift: parser must not be nil",
+		},
+		{
+			name:          "modify state",
+			parser:        count,
+			input:         "日ð本",
+			state:         1,
+			wantValue:     "日",
+			wantRemainder: "ð本",
+			wantState:     2,
+			wantErr:       false,
+		},
+		{
+			name: "modify state error",
+			parser: parser.MapState(
+				parser.ModifyState(func(int) (int, error) { return 0, errors.New("too many") }),
+				func(int) (string, error) { return "", nil },
+			),
+			input:         "abc",
+			state:         1,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     1,
+			wantErr:       true,
+			wantErrMsg:    "MapState: parser returned error: ModifyState: fn returned error: too many",
+		},
+		{
+			name: "modify state nil",
+			parser: parser.MapState(
+				parser.ModifyState[int](nil),
+				func(int) (string, error) { return "", nil },
+			),
+			input:         "abc",
+			state:         1,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     1,
+			wantErr:       true,
+			wantErrMsg:    "MapState: parser returned error: ModifyState: fn must be a non-nil function",
+		},
+		{
+			name: "get state",
+			parser: parser.MapState(parser.GetState[int](), func(n int) (string, error) {
+				return fmt.Sprint(n), nil
+			}),
+			input:         "abc",
+			state:         42,
+			wantValue:     "42",
+			wantRemainder: "abc",
+			wantState:     42,
+			wantErr:       false,
+		},
+		{
+			name: "put state",
+			parser: parser.MapState(parser.PutState(7), func(n int) (string, error) {
+				return fmt.Sprint(n), nil
+			}),
+			input:         "abc",
+			state:         42,
+			wantValue:     "7",
+			wantRemainder: "abc",
+			wantState:     7,
+			wantErr:       false,
+		},
+		{
+			name:          "try rolls back state",
+			parser:        parser.TryState(failing, count),
+			input:         "abc",
+			state:         0,
+			wantValue:     "a",
+			wantRemainder: "bc",
+			wantState:     1,
+			wantErr:       false,
+		},
+		{
+			name:          "try all fail",
+			parser:        parser.TryState(failing, failing),
+			input:         "abc",
+			state:         5,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     5,
+			wantErr:       true,
+			wantErrMsg:    "TryState: all parsers failed",
+		},
+		{
+			name: "bind on state",
+			parser: parser.BindState(parser.GetState[int](), func(n int) parser.StatefulParser[int, string] {
+				return parser.Lift[int](parser.Take(n))
+			}),
+			input:         "abcdef",
+			state:         4,
+			wantValue:     "abcd",
+			wantRemainder: "ef",
+			wantState:     4,
+			wantErr:       false,
+		},
+		{
+			name:          "bind error keeps original state",
+			parser:        failing,
+			input:         "abc",
+			state:         5,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     5,
+			wantErr:       true,
+			wantErrMsg:    "BindState: next parser returned error: Lift: parser returned error: Exact: match (nope) not in input",
+		},
+		{
+			name: "bind nil fn",
+			parser: parser.BindState[int, string, string](
+				parser.Lift[int](parser.Take(1)),
+				nil,
+			),
+			input:         "abc",
+			state:         5,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     5,
+			wantErr:       true,
+			wantErrMsg:    "BindState: fn must be a non-nil function",
+		},
+		{
+			name: "bind nil parser",
+			parser: parser.BindState(
+				parser.Lift[int](parser.Take(1)),
+				func(string) parser.StatefulParser[int, string] { return nil },
+			),
+			input:         "abc",
+			state:         5,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     5,
+			wantErr:       true,
+			wantErrMsg:    "BindState: fn returned a nil parser",
+		},
+		{
+			name: "chain threads state",
+			parser: parser.MapState(parser.ChainState(count, count, count), func(chars []string) (string, error) {
+				return strings.Join(chars, ""), nil
+			}),
+			input:         "abcd",
+			state:         10,
+			wantValue:     "abc",
+			wantRemainder: "d",
+			wantState:     13,
+			wantErr:       false,
+		},
+		{
+			name: "chain error keeps original state",
+			parser: parser.MapState(parser.ChainState(count, count, count), func(chars []string) (string, error) {
+				return strings.Join(chars, ""), nil
+			}),
+			input:         "ab",
+			state:         10,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     10,
+			wantErr:       true,
+			wantErrMsg:    "MapState: parser returned error: ChainState: sub parser failed: BindState: parser returned error: Lift: parser returned error: Take: cannot take from empty input",
+		},
+		{
+			name: "fold many",
+			parser: parser.FoldManyState(count, func() string { return "" }, func(acc, char string) string {
+				return char + acc
+			}),
+			input:         "abc",
+			state:         0,
+			wantValue:     "cba",
+			wantRemainder: "",
+			wantState:     3,
+			wantErr:       false,
+		},
+		{
+			name: "fold many stops at failure",
+			parser: parser.FoldManyState(
+				parser.Lift[int](parser.Exact("ab")),
+				func() string { return "" },
+				func(acc, match string) string { return acc + match },
+			),
+			input:         "ababc",
+			state:         0,
+			wantValue:     "abab",
+			wantRemainder: "c",
+			wantState:     0,
+			wantErr:       false,
+		},
+		{
+			name:          "fold many nil init",
+			parser:        parser.FoldManyState(count, nil, func(acc, char string) string { return acc }),
+			input:         "abc",
+			state:         0,
+			wantValue:     "",
+			wantRemainder: "",
+			wantState:     0,
+			wantErr:       true,
+			wantErrMsg:    "FoldManyState: init must be a non-nil function",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, remainder, state, err := tt.parser(tt.input, tt.state)
+
+			testParser(t, parserTest[string]{
+				gotErr:        err,
+				gotValue:      value,
+				wantValue:     tt.wantValue,
+				gotRemainder:  remainder,
+				wantRemainder: tt.wantRemainder,
+				wantErrMsg:    tt.wantErrMsg,
+				wantErr:       tt.wantErr,
+			})
+
+			if state != tt.wantState {
+				t.Errorf("\nState:\t%d\nWanted:\t%d\n", state, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestTryStateMapRollback(t *testing.T) {
+	// A state holding a map is only rolled back if it's copied rather than modified in place
+	define := func(name string) parser.StatefulParser[map[string]bool, string] {
+		return parser.MapState(
+			parser.ModifyState(func(names map[string]bool) (map[string]bool, error) {
+				names = maps.Clone(names)
+				names[name] = true
+				return names, nil
+			}),
+			func(map[string]bool) (string, error) { return name, nil },
+		)
+	}
+
+	failAfterDefining := parser.BindState(define("a"), func(string) parser.StatefulParser[map[string]bool, string] {
+		return parser.Lift[map[string]bool](parser.Exact("nope"))
+	})
+
+	initial := map[string]bool{}
+
+	_, _, got, err := parser.TryState(failAfterDefining, define("b"))("input", initial)
+	if err != nil {
+		t.Fatalf("TryState returned an unexpected error: %v", err)
+	}
+
+	if want := map[string]bool{"b": true}; !maps.Equal(got, want) {
+		t.Errorf("\nState:\t%v\nWanted:\t%v\n", got, want)
+	}
+
+	if len(initial) != 0 {
+		t.Errorf("initial state was modified: %v", initial)
+	}
+}
+
+func TestWithState(t *testing.T) {
+	p := parser.WithState(parser.FoldManyState(count, func() int { return 0 }, func(acc int, _ string) int {
+		return acc + 1
+	}), 0)
+
+	value, remainder, err := p("abc")
+	if err != nil {
+		t.Fatalf("WithState returned an unexpected error: %v", err)
+	}
+
+	if value != 3 || remainder != "" {
+		t.Errorf("WithState returned (%d, %q), wanted (3, \"\")", value, remainder)
+	}
+
+	_, _, err = parser.WithState(failing, 0)("abc")
+	if err == nil || !strings.HasPrefix(err.Error(), "WithState: parser returned error: ") {
+		t.Errorf("WithState with a failing parser returned %v", err)
+	}
+
+	_, _, err = parser.WithState[int, string](nil, 0)("abc")
+	if err == nil || err.Error() != "WithState: parser must not be nil" {
+		t.Errorf("WithState with a nil parser returned %v", err)
+	}
+}
+
+func ExampleTryState() {
+	// Each statement either declares a name (let x) or uses one (use x), and using a name
+	// that hasn't been declared is an error. The state is the set of declared names.
+	type names []string
+
+	name := parser.Lift[names](parser.TakeWhile(unicode.IsLetter))
+	space := parser.Lift[names](parser.Char(' '))
+	end := parser.Lift[names](parser.Optional(";"))
+
+	declare := parser.BindState(
+		parser.ChainState(parser.Lift[names](parser.Exact("let")), space, name, end),
+		func(parts []string) parser.StatefulParser[names, string] {
+			return parser.MapState(
+				parser.ModifyState(func(declared names) (names, error) {
+					// A copy, not an append to the original, so TryState can roll it back
+					return append(slices.Clone(declared), parts[2]), nil
+				}),
+				func(names) (string, error) { return "declare " + parts[2], nil },
+			)
+		},
+	)
+
+	use := parser.BindState(
+		parser.ChainState(parser.Lift[names](parser.Exact("use")), space, name, end),
+		func(parts []string) parser.StatefulParser[names, string] {
+			return parser.MapState(parser.GetState[names](), func(declared names) (string, error) {
+				if !slices.Contains(declared, parts[2]) {
+					return "", fmt.Errorf("%s is not declared", parts[2])
+				}
+				return "use " + parts[2], nil
+			})
+		},
+	)
+
+	statements := parser.FoldManyState(
+		parser.TryState(declare, use),
+		func() []string { return nil },
+		func(done []string, statement string) []string { return append(done, statement) },
+	)
+
+	value, remainder, declared, err := statements("let x;use x;let y;use z;rest...", nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("Value: %q\n", value)
+	fmt.Printf("Declared: %q\n", declared)
+	fmt.Printf("Remainder: %q\n", remainder)
+
+	// Output: Value: ["declare x" "use x" "declare y"]
+	// Declared: ["x" "y"]
+	// Remainder: "use z;rest..."
+}
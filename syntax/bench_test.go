@@ -0,0 +1,44 @@
+package syntax_test
+
+import (
+	"strconv"
+	"testing"
+)
+
+// benchSettings is a config with many settings.
+var benchSettings = func() []setting {
+	settings := make([]setting, 0, 500)
+	for i := range 500 {
+		settings = append(settings, setting{Key: "setting", Value: i * 37})
+	}
+
+	return settings
+}()
+
+func BenchmarkPrint(b *testing.B) {
+	for b.Loop() {
+		if _, err := config.Print(benchSettings); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	text, err := config.Print(benchSettings)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(text)))
+
+	for b.Loop() {
+		settings, _, err := config.Parse(text)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if len(settings) != len(benchSettings) {
+			b.Fatal("parsed " + strconv.Itoa(len(settings)) + " settings")
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package syntax_test
+
+// The fuzz tests in here check the property every syntax is built to have: that any value which
+// can be printed is parsed back from the printed text as the same value, with nothing left over,
+// and that parsing arbitrary text never panics.
+
+import (
+	"slices"
+	"testing"
+)
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add("port", 8080, "retries", -3)
+	f.Add("a", 0, "", 1)
+	f.Add("Bad", 1, "key", 2)
+	f.Add("日本", 7, "x\n", 9)
+
+	f.Fuzz(func(t *testing.T, key1 string, value1 int, key2 string, value2 int) {
+		value := []setting{{key1, value1}, {key2, value2}}
+
+		text, err := config.Print(value)
+		if err != nil {
+			// Not every value has a representation, keys must be lower case letters
+			return
+		}
+
+		got, remainder, err := config.Parse(text)
+		if err != nil {
+			t.Fatalf("Print(%v) gave %q, which doesn't parse: %v", value, text, err)
+		}
+
+		if !slices.Equal(got, value) || remainder != "" {
+			t.Fatalf("Print(%v) gave %q, which parses as (%v, %q)", value, text, got, remainder)
+		}
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		"port = 8080\nretries = -3\n",
+		"port = 0080\n",
+		"port = 80",
+		"port = -\n",
+		"= 1\n",
+		"",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		value, remainder, err := config.Parse(input)
+		if err != nil {
+			t.Fatalf("Many never fails, but returned %v", err)
+		}
+
+		// Whatever was parsed prints as the canonical form of the same settings
+		text, err := config.Print(value)
+		if err != nil {
+			t.Fatalf("parsed %v from %q, which doesn't print: %v", value, input, err)
+		}
+
+		again, _, err := config.Parse(text)
+		if err != nil || !slices.Equal(again, value) {
+			t.Fatalf("parsed %v from %q, printed as %q, which parses as %v (%v)", value, input, text, again, err)
+		}
+
+		if len(remainder) > len(input) {
+			t.Fatalf("remainder %q is longer than the input %q", remainder, input)
+		}
+	})
+}
@@ -0,0 +1,328 @@
+// Package syntax implements invertible syntax descriptions, combinators like those in [parser]
+// that know how to print a value back to text as well as how to parse it, so a format is
+// described once and gives both a parser and a printer that can't drift apart.
+//
+// Each [Syntax] pairs a [parser.Parser] with a printer, and the combinators build both halves at
+// once. Where [parser.Map] only needs a function from the parsed value to a new one, [Map] also
+// needs its inverse, from the new value back to the parsed one:
+//
+//	key := syntax.TakeWhile(unicode.IsLower)
+//	number := syntax.TakeWhile(unicode.IsDigit)
+//
+//	setting := syntax.Map(
+//		syntax.Chain(key, syntax.Char('='), number),
+//		func(parts []string) (Setting, error) {
+//			n, err := strconv.Atoi(parts[2])
+//			return Setting{Key: parts[0], Value: n}, err
+//		},
+//		func(s Setting) ([]string, error) {
+//			return []string{s.Key, "=", strconv.Itoa(s.Value)}, nil
+//		},
+//	)
+//
+//	text, err := setting.Print(Setting{Key: "port", Value: 8080}) // "port=8080"
+//	value, _, err := setting.Parse(text)                          // Setting{Key: "port", Value: 8080}
+//
+// The printer produces one canonical text for each value, which the parser reads back as that
+// same value, so Parse(Print(v)) == v. The reverse isn't true in general: anything the parser
+// skips over or accepts in more than one form, like leading zeros above, is printed the one way.
+//
+// Values the parser could never produce, like a string that isn't the one [Exact] matches or a
+// slice of the wrong length for [Chain], can't be printed and return an error.
+package syntax // import "go.followtheprocess.codes/parser/syntax"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// Syntax is both a [parser.Parser] for values of type T and a printer that turns them back into
+// text the parser accepts.
+//
+// The zero Syntax can't parse or print anything, they're made with the functions in this package
+// or [New].
+type Syntax[T any] struct {
+	parse parser.Parser[T]              // Parses a value from the start of the input
+	print func(value T) (string, error) // Prints a value as text parse accepts
+}
+
+// New returns a [Syntax] from a parser and the printer that is its inverse, for when the syntax of
+// something can't be built from the other functions in this package.
+//
+// Printing a value and parsing the text must give back the same value, with nothing left over.
+func New[T any](parse parser.Parser[T], print func(value T) (string, error)) Syntax[T] {
+	return Syntax[T]{parse: parse, print: print}
+}
+
+// Parse parses a value from the start of input, returning it along with the remaining input, and
+// so is a [parser.Parser] for use with any of the other combinators.
+//
+// If the input doesn't match the syntax, an error will be returned.
+func (s Syntax[T]) Parse(input string) (T, string, error) {
+	if s.parse == nil {
+		var zero T
+		return zero, "", errors.New("Syntax: must be created before it's used")
+	}
+
+	return s.parse(input)
+}
+
+// Print returns the text for value, which [Syntax.Parse] reads back as the same value.
+//
+// If the value isn't one the syntax could have parsed, an error will be returned.
+func (s Syntax[T]) Print(value T) (string, error) {
+	if s.print == nil {
+		return "", errors.New("Syntax: must be created before it's used")
+	}
+
+	return s.print(value)
+}
+
+// Exact returns a [Syntax] for an exact, case-sensitive string, which is the only value it prints.
+//
+// It parses with [parser.Exact].
+func Exact(match string) Syntax[string] {
+	return Syntax[string]{
+		parse: parser.Exact(match),
+		print: func(value string) (string, error) {
+			if match == "" {
+				return "", errors.New("Exact: match must not be empty")
+			}
+
+			if value != match {
+				return "", fmt.Errorf("Exact: cannot print %q, only the match (%s)", value, match)
+			}
+
+			return value, nil
+		},
+	}
+}
+
+// Char returns a [Syntax] for a single exact, case-sensitive utf-8 character, which is the only
+// value it prints.
+//
+// It parses with [parser.Char].
+func Char(char rune) Syntax[string] {
+	return Syntax[string]{
+		parse: parser.Char(char),
+		print: func(value string) (string, error) {
+			if value != string(char) {
+				return "", fmt.Errorf("Char: cannot print %q, only the char (%s)", value, string(char))
+			}
+
+			return value, nil
+		},
+	}
+}
+
+// Take returns a [Syntax] for n utf-8 chars, printing any string of exactly that many.
+//
+// It parses with [parser.Take].
+func Take(n int) Syntax[string] {
+	return Syntax[string]{
+		parse: parser.Take(n),
+		print: func(value string) (string, error) {
+			if n <= 0 {
+				return "", fmt.Errorf("Take: n must be a non-zero positive integer, got %d", n)
+			}
+
+			if !utf8.ValidString(value) {
+				return "", errors.New("Take: value not valid utf-8")
+			}
+
+			if count := utf8.RuneCountInString(value); count != n {
+				return "", fmt.Errorf("Take: cannot print %q, it has %d utf-8 chars rather than %d", value, count, n)
+			}
+
+			return value, nil
+		},
+	}
+}
+
+// TakeWhile returns a [Syntax] for one or more chars the predicate returns true for, printing any
+// non-empty string made only of those chars.
+//
+// It parses with [parser.TakeWhile], so as with any syntax that consumes a variable amount of
+// input, whatever follows it mustn't start with a char the predicate accepts or it will be parsed
+// as part of the value.
+func TakeWhile(predicate func(r rune) bool) Syntax[string] {
+	return Syntax[string]{
+		parse: parser.TakeWhile(predicate),
+		print: func(value string) (string, error) {
+			if predicate == nil {
+				return "", errors.New("TakeWhile: predicate must be a non-nil function")
+			}
+
+			if value == "" {
+				return "", errors.New("TakeWhile: cannot print an empty value")
+			}
+
+			if !utf8.ValidString(value) {
+				return "", errors.New("TakeWhile: value not valid utf-8")
+			}
+
+			if index := strings.IndexFunc(value, func(r rune) bool { return !predicate(r) }); index != -1 {
+				char, _ := utf8.DecodeRuneInString(value[index:])
+				return "", fmt.Errorf("TakeWhile: cannot print %q, predicate returned false for %q", value, char)
+			}
+
+			return value, nil
+		},
+	}
+}
+
+// Map returns a [Syntax] that converts the values of another with fn when parsing, and back
+// again with inverse when printing.
+//
+// The functions must be inverses of each other for the values the syntax can parse: fn(v) gives
+// w if and only if inverse(w) gives v. Either can reject a value by returning an error, which
+// is how a value with no representation in the text is refused when printing.
+//
+// If the syntax or either function return an error, Map will bubble up this error to the caller.
+func Map[T1, T2 any](syntax Syntax[T1], fn func(T1) (T2, error), inverse func(T2) (T1, error)) Syntax[T2] {
+	return Syntax[T2]{
+		parse: parser.Map(syntax.Parse, fn),
+		print: func(value T2) (string, error) {
+			if inverse == nil {
+				return "", errors.New("Map: inverse must be a non-nil function")
+			}
+
+			original, err := inverse(value)
+			if err != nil {
+				return "", fmt.Errorf("Map: inverse returned error: %w", err)
+			}
+
+			text, err := syntax.Print(original)
+			if err != nil {
+				return "", fmt.Errorf("Map: syntax returned error: %w", err)
+			}
+
+			return text, nil
+		},
+	}
+}
+
+// Chain returns a [Syntax] for a series of others one after the other, with a slice of values;
+// one from each syntax.
+//
+// It parses with [parser.Chain], and prints a slice of exactly one value for each syntax by
+// printing each value with its syntax in turn.
+//
+// If any of the syntaxes fail, an error will be returned.
+func Chain[T any](syntaxes ...Syntax[T]) Syntax[[]T] {
+	parsers := make([]parser.Parser[T], 0, len(syntaxes))
+	for _, syntax := range syntaxes {
+		parsers = append(parsers, syntax.Parse)
+	}
+
+	return Syntax[[]T]{
+		parse: parser.Chain(parsers...),
+		print: func(values []T) (string, error) {
+			if len(values) != len(syntaxes) {
+				return "", fmt.Errorf("Chain: cannot print %d values with %d syntaxes", len(values), len(syntaxes))
+			}
+
+			s := &strings.Builder{}
+
+			for i, syntax := range syntaxes {
+				text, err := syntax.Print(values[i])
+				if err != nil {
+					return "", fmt.Errorf("Chain: sub syntax failed: %w", err)
+				}
+
+				s.WriteString(text)
+			}
+
+			return s.String(), nil
+		},
+	}
+}
+
+// Try returns a [Syntax] for any one of a series of alternatives, parsing with the first that
+// matches the input and printing with the first that can print the value.
+//
+// It parses with [parser.Try], so for Parse(Print(v)) == v to hold, the text an alternative prints
+// mustn't be matched by any alternative before it.
+//
+// If none of the alternatives can parse the input or print the value, an error will be returned.
+func Try[T any](syntaxes ...Syntax[T]) Syntax[T] {
+	parsers := make([]parser.Parser[T], 0, len(syntaxes))
+	for _, syntax := range syntaxes {
+		parsers = append(parsers, syntax.Parse)
+	}
+
+	return Syntax[T]{
+		parse: parser.Try(parsers...),
+		print: func(value T) (string, error) {
+			for _, syntax := range syntaxes {
+				text, err := syntax.Print(value)
+				if err != nil {
+					// Try the next syntax
+					continue
+				}
+
+				return text, nil
+			}
+
+			return "", errors.New("Try: all syntaxes failed")
+		},
+	}
+}
+
+// Many returns a [Syntax] for zero or more repetitions of another, with a slice of the values.
+//
+// It parses with [parser.FoldMany], applying the syntax until it fails, and prints each value in
+// the slice in turn. For Parse(Print(v)) == v to hold, the syntax must never print an empty text,
+// and the text of one value mustn't run on into the next, as the text of two [TakeWhile] values
+// would.
+//
+// If the syntax can't print one of the values, an error will be returned.
+func Many[T any](syntax Syntax[T]) Syntax[[]T] {
+	return Syntax[[]T]{
+		parse: parser.FoldMany(syntax.Parse, func() []T { return nil }, func(values []T, value T) []T {
+			return append(values, value)
+		}),
+		print: func(values []T) (string, error) {
+			s := &strings.Builder{}
+
+			for i, value := range values {
+				text, err := syntax.Print(value)
+				if err != nil {
+					return "", fmt.Errorf("Many: value %d: sub syntax failed: %w", i, err)
+				}
+
+				s.WriteString(text)
+			}
+
+			return s.String(), nil
+		},
+	}
+}
+
+// Lazy returns a [Syntax] that defers calling fn to obtain the real syntax until it's used to parse
+// or print something, which is what makes recursive syntaxes possible.
+//
+// If fn is nil, an error will be returned.
+func Lazy[T any](fn func() Syntax[T]) Syntax[T] {
+	return Syntax[T]{
+		parse: func(input string) (T, string, error) {
+			if fn == nil {
+				var zero T
+				return zero, "", errors.New("Lazy: fn must be a non-nil function")
+			}
+
+			return fn().Parse(input)
+		},
+		print: func(value T) (string, error) {
+			if fn == nil {
+				return "", errors.New("Lazy: fn must be a non-nil function")
+			}
+
+			return fn().Print(value)
+		},
+	}
+}
@@ -0,0 +1,421 @@
+package syntax_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+	"unicode"
+
+	"go.followtheprocess.codes/parser/syntax"
+)
+
+// setting is a single key = value line of a config file.
+type setting struct {
+	Key   string
+	Value int
+}
+
+// number is the syntax of a decimal integer, printed without leading zeros.
+var number = syntax.Map(
+	syntax.TakeWhile(func(r rune) bool { return unicode.IsDigit(r) || r == '-' }),
+	strconv.Atoi,
+	func(n int) (string, error) { return strconv.Itoa(n), nil },
+)
+
+// boolean is the syntax of true or false.
+var boolean = syntax.Try(
+	syntax.Map(syntax.Exact("true"), func(string) (bool, error) { return true, nil }, func(b bool) (string, error) {
+		if !b {
+			return "", errors.New("not true")
+		}
+
+		return "true", nil
+	}),
+	syntax.Map(syntax.Exact("false"), func(string) (bool, error) { return false, nil }, func(b bool) (string, error) {
+		if b {
+			return "", errors.New("not false")
+		}
+
+		return "false", nil
+	}),
+)
+
+// config is the syntax of a config file, a line for each setting.
+var config = syntax.Many(syntax.Map(
+	syntax.Chain(syntax.TakeWhile(unicode.IsLower), syntax.Exact(" = "), syntax.TakeWhile(func(r rune) bool {
+		return unicode.IsDigit(r) || r == '-'
+	}), syntax.Char('\n')),
+	func(parts []string) (setting, error) {
+		value, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return setting{}, err
+		}
+
+		return setting{Key: parts[0], Value: value}, nil
+	},
+	func(s setting) ([]string, error) {
+		return []string{s.Key, " = ", strconv.Itoa(s.Value), "\n"}, nil
+	},
+))
+
+// nested is a recursive syntax for a count of nesting depth, 0 is "x" and each level above it is
+// wrapped in brackets, so 2 is "[[x]]".
+var nested syntax.Syntax[int]
+
+func init() {
+	bracketed := syntax.Map(
+		syntax.Chain(
+			syntax.Map(syntax.Char('['), func(string) (int, error) { return 0, nil }, func(int) (string, error) { return "[", nil }),
+			syntax.Lazy(func() syntax.Syntax[int] { return nested }),
+			syntax.Map(syntax.Char(']'), func(string) (int, error) { return 0, nil }, func(int) (string, error) { return "]", nil }),
+		),
+		func(values []int) (int, error) { return values[1] + 1, nil },
+		func(depth int) ([]int, error) {
+			if depth < 1 {
+				return nil, errors.New("depth of a bracketed value must be at least 1")
+			}
+
+			return []int{0, depth - 1, 0}, nil
+		},
+	)
+
+	x := syntax.Map(syntax.Char('x'), func(string) (int, error) { return 0, nil }, func(depth int) (string, error) {
+		if depth != 0 {
+			return "", errors.New("only depth 0 is x")
+		}
+
+		return "x", nil
+	})
+
+	nested = syntax.Try(x, bracketed)
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name    string                 // Identifying test case name
+		print   func() (string, error) // Prints the value under test
+		want    string                 // The expected text
+		errMsg  string                 // The expected error message, if there was one
+		wantErr bool                   // Whether or not we want an error
+	}{
+		{
+			name:  "exact",
+			print: func() (string, error) { return syntax.Exact("hello").Print("hello") },
+			want:  "hello",
+		},
+		{
+			name:    "exact other value",
+			print:   func() (string, error) { return syntax.Exact("hello").Print("goodbye") },
+			errMsg:  `Exact: cannot print "goodbye", only the match (hello)`,
+			wantErr: true,
+		},
+		{
+			name:    "exact empty match",
+			print:   func() (string, error) { return syntax.Exact("").Print("") },
+			errMsg:  "Exact: match must not be empty",
+			wantErr: true,
+		},
+		{
+			name:  "char",
+			print: func() (string, error) { return syntax.Char('語').Print("語") },
+			want:  "語",
+		},
+		{
+			name:    "char other value",
+			print:   func() (string, error) { return syntax.Char('a').Print("ab") },
+			errMsg:  `Char: cannot print "ab", only the char (a)`,
+			wantErr: true,
+		},
+		{
+			name:  "take",
+			print: func() (string, error) { return syntax.Take(3).Print("日a本") },
+			want:  "日a本",
+		},
+		{
+			name:    "take wrong length",
+			print:   func() (string, error) { return syntax.Take(3).Print("ab") },
+			errMsg:  `Take: cannot print "ab", it has 2 utf-8 chars rather than 3`,
+			wantErr: true,
+		},
+		{
+			name:    "take invalid utf-8",
+			print:   func() (string, error) { return syntax.Take(1).Print("\xf8") },
+			errMsg:  "Take: value not valid utf-8",
+			wantErr: true,
+		},
+		{
+			name:    "take negative",
+			print:   func() (string, error) { return syntax.Take(-1).Print("") },
+			errMsg:  "Take: n must be a non-zero positive integer, got -1",
+			wantErr: true,
+		},
+		{
+			name:  "take while",
+			print: func() (string, error) { return syntax.TakeWhile(unicode.IsLower).Print("abc") },
+			want:  "abc",
+		},
+		{
+			name:    "take while rejected char",
+			print:   func() (string, error) { return syntax.TakeWhile(unicode.IsLower).Print("aBc") },
+			errMsg:  `TakeWhile: cannot print "aBc", predicate returned false for 'B'`,
+			wantErr: true,
+		},
+		{
+			name:    "take while empty",
+			print:   func() (string, error) { return syntax.TakeWhile(unicode.IsLower).Print("") },
+			errMsg:  "TakeWhile: cannot print an empty value",
+			wantErr: true,
+		},
+		{
+			name:    "take while nil predicate",
+			print:   func() (string, error) { return syntax.TakeWhile(nil).Print("a") },
+			errMsg:  "TakeWhile: predicate must be a non-nil function",
+			wantErr: true,
+		},
+		{
+			name:  "map",
+			print: func() (string, error) { return number.Print(-42) },
+			want:  "-42",
+		},
+		{
+			name: "map inverse error",
+			print: func() (string, error) {
+				positive := syntax.Map(number, func(n int) (int, error) { return n, nil }, func(n int) (int, error) {
+					if n < 0 {
+						return 0, errors.New("negative")
+					}
+
+					return n, nil
+				})
+
+				return positive.Print(-1)
+			},
+			errMsg:  "Map: inverse returned error: negative",
+			wantErr: true,
+		},
+		{
+			name: "map nil inverse",
+			print: func() (string, error) {
+				return syntax.Map[string, string](syntax.Exact("a"), nil, nil).Print("a")
+			},
+			errMsg:  "Map: inverse must be a non-nil function",
+			wantErr: true,
+		},
+		{
+			name: "chain",
+			print: func() (string, error) {
+				return syntax.Chain(syntax.Char('a'), syntax.Take(2)).Print([]string{"a", "bc"})
+			},
+			want: "abc",
+		},
+		{
+			name: "chain wrong count",
+			print: func() (string, error) {
+				return syntax.Chain(syntax.Char('a'), syntax.Take(2)).Print([]string{"a"})
+			},
+			errMsg:  "Chain: cannot print 1 values with 2 syntaxes",
+			wantErr: true,
+		},
+		{
+			name: "chain sub syntax error",
+			print: func() (string, error) {
+				return syntax.Chain(syntax.Char('a'), syntax.Take(2)).Print([]string{"b", "cd"})
+			},
+			errMsg:  `Chain: sub syntax failed: Char: cannot print "b", only the char (a)`,
+			wantErr: true,
+		},
+		{
+			name:  "try first",
+			print: func() (string, error) { return boolean.Print(true) },
+			want:  "true",
+		},
+		{
+			name:  "try second",
+			print: func() (string, error) { return boolean.Print(false) },
+			want:  "false",
+		},
+		{
+			name:    "try none",
+			print:   func() (string, error) { return syntax.Try(syntax.Char('a'), syntax.Char('b')).Print("c") },
+			errMsg:  "Try: all syntaxes failed",
+			wantErr: true,
+		},
+		{
+			name:  "many",
+			print: func() (string, error) { return config.Print([]setting{{"port", 8080}, {"retries", -1}}) },
+			want:  "port = 8080\nretries = -1\n",
+		},
+		{
+			name:  "many none",
+			print: func() (string, error) { return config.Print(nil) },
+			want:  "",
+		},
+		{
+			name:    "many bad value",
+			print:   func() (string, error) { return config.Print([]setting{{"port", 8080}, {"Bad", 1}}) },
+			errMsg:  `Many: value 1: sub syntax failed: Map: syntax returned error: Chain: sub syntax failed: TakeWhile: cannot print "Bad", predicate returned false for 'B'`,
+			wantErr: true,
+		},
+		{
+			name:  "lazy",
+			print: func() (string, error) { return nested.Print(3) },
+			want:  "[[[x]]]",
+		},
+		{
+			name:    "lazy nil",
+			print:   func() (string, error) { return syntax.Lazy[int](nil).Print(1) },
+			errMsg:  "Lazy: fn must be a non-nil function",
+			wantErr: true,
+		},
+		{
+			name:    "zero syntax",
+			print:   func() (string, error) { return syntax.Syntax[string]{}.Print("a") },
+			errMsg:  "Syntax: must be created before it's used",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.print()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil && err.Error() != tt.errMsg {
+				t.Errorf("\nGot:\t%s\nWanted:\t%s\n", err, tt.errMsg)
+			}
+
+			if got != tt.want {
+				t.Errorf("\nGot:\t%q\nWanted:\t%q\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	got, remainder, err := config.Parse("port = 0080\nname = x\n")
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	// The second line isn't a setting, so parsing stops before it
+	if !slices.Equal(got, []setting{{"port", 80}}) || remainder != "name = x\n" {
+		t.Errorf("Parse returned (%v, %q), wanted the port setting and the name line left over", got, remainder)
+	}
+
+	depth, remainder, err := nested.Parse("[[x]]]")
+	if err != nil || depth != 2 || remainder != "]" {
+		t.Errorf(`nested.Parse("[[x]]]") returned (%d, %q, %v), wanted (2, "]", nil)`, depth, remainder, err)
+	}
+
+	// A later char is a letter, but TakeWhile never matches nothing
+	_, _, err = syntax.TakeWhile(unicode.IsLower).Parse(" = x")
+	if err == nil || err.Error() != "TakeWhile: predicate returned false for the first char ' '" {
+		t.Errorf("TakeWhile of input starting with a rejected char returned %v", err)
+	}
+
+	_, _, err = syntax.Syntax[string]{}.Parse("a")
+	if err == nil || err.Error() != "Syntax: must be created before it's used" {
+		t.Errorf("zero Syntax returned %v", err)
+	}
+}
+
+// TestRoundTrip checks the property every syntax is built to have, that parsing the text printed
+// for a value gives back the same value, with nothing left over.
+func TestRoundTrip(t *testing.T) {
+	t.Run("config", func(t *testing.T) {
+		values := [][]setting{
+			nil,
+			{{"a", 0}},
+			{{"port", 8080}, {"retries", -3}, {"port", 1}},
+		}
+
+		for _, value := range values {
+			roundTrip(t, config, value, slices.Equal)
+		}
+	})
+
+	t.Run("boolean", func(t *testing.T) {
+		for _, value := range []bool{true, false} {
+			roundTrip(t, boolean, value, func(a, b bool) bool { return a == b })
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		for depth := range 50 {
+			roundTrip(t, nested, depth, func(a, b int) bool { return a == b })
+		}
+	})
+
+	t.Run("take", func(t *testing.T) {
+		for _, value := range []string{"abc", "日本語", "✅ ✅"} {
+			roundTrip(t, syntax.Take(3), value, func(a, b string) bool { return a == b })
+		}
+	})
+}
+
+// roundTrip checks that s parses the text it prints for value as the same value, according to equal.
+func roundTrip[T any](t *testing.T, s syntax.Syntax[T], value T, equal func(a, b T) bool) {
+	t.Helper()
+
+	text, err := s.Print(value)
+	if err != nil {
+		t.Fatalf("Print(%v) returned an unexpected error: %v", value, err)
+	}
+
+	got, remainder, err := s.Parse(text)
+	if err != nil {
+		t.Fatalf("Print(%v) gave %q, which doesn't parse: %v", value, text, err)
+	}
+
+	if !equal(got, value) || remainder != "" {
+		t.Errorf("Print(%v) gave %q, which parses as (%v, %q)", value, text, got, remainder)
+	}
+}
+
+func ExampleMap() {
+	// A point like (1,2), printed from and parsed to the same syntax
+	type point struct{ X, Y int }
+
+	coord := syntax.TakeWhile(unicode.IsDigit)
+
+	p := syntax.Map(
+		syntax.Chain(syntax.Char('('), coord, syntax.Char(','), coord, syntax.Char(')')),
+		func(parts []string) (point, error) {
+			x, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return point{}, err
+			}
+
+			y, err := strconv.Atoi(parts[3])
+			if err != nil {
+				return point{}, err
+			}
+
+			return point{X: x, Y: y}, nil
+		},
+		func(p point) ([]string, error) {
+			return []string{"(", strconv.Itoa(p.X), ",", strconv.Itoa(p.Y), ")"}, nil
+		},
+	)
+
+	text, err := p.Print(point{X: 3, Y: 14})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	value, remainder, err := p.Parse(text + " and more")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Text: %s, Value: %+v, Remainder: %q\n", text, value, remainder)
+
+	// Output: Text: (3,14), Value: {X:3 Y:14}, Remainder: " and more"
+}
@@ -0,0 +1,109 @@
+package toml_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser/toml"
+)
+
+// benchManifest is a build manifest with many similar tables.
+var benchManifest = func() string {
+	builder := &strings.Builder{}
+	builder.WriteString(`# A build manifest
+[workspace]
+name = "parser"
+version = "1.4.0"
+edition = 2024
+authors = ["Tom <tom@example.com>", 'Ann <ann@example.com>']
+published = 2024-03-15T09:30:00Z
+
+[workspace.settings]
+parallel = true
+jobs = 8
+timeout = "90s"
+`)
+
+	for i := range 200 {
+		fmt.Fprintf(builder, `
+[[target]]
+name = "target-%d"
+path = 'src\bin\target_%d.go'
+deps = [
+  "fmt",   # formatting
+  "os",
+  "strings",
+]
+flags = { race = true, tags = ["integration", "slow"], ldflags.strip = true }
+size_limit = 1_048_576
+ratio = 0.75
+description = """
+Builds target %d \
+with the default options."""
+`, i, i, i)
+	}
+
+	return builder.String()
+}()
+
+// benchDeep is a single array nested to the max depth.
+var benchDeep = "a = " + strings.Repeat("[", toml.MaxDepth) + strings.Repeat("]", toml.MaxDepth)
+
+func BenchmarkParse(b *testing.B) {
+	b.SetBytes(int64(len(benchManifest)))
+
+	for b.Loop() {
+		if _, err := toml.Parse(benchManifest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseDeep(b *testing.B) {
+	b.SetBytes(int64(len(benchDeep)))
+
+	for b.Loop() {
+		if _, err := toml.Parse(benchDeep); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	type Target struct {
+		Flags       map[string]any `toml:"flags"`
+		Name        string         `toml:"name"`
+		Path        string         `toml:"path"`
+		Description string         `toml:"description"`
+		Deps        []string       `toml:"deps"`
+		Ratio       float64        `toml:"ratio"`
+		SizeLimit   int            `toml:"size_limit"`
+	}
+
+	type Manifest struct {
+		Workspace struct {
+			Published time.Time `toml:"published"`
+			Name      string    `toml:"name"`
+			Version   string    `toml:"version"`
+			Authors   []string  `toml:"authors"`
+			Settings  struct {
+				Timeout  time.Duration `toml:"timeout"`
+				Jobs     int           `toml:"jobs"`
+				Parallel bool          `toml:"parallel"`
+			} `toml:"settings"`
+			Edition int `toml:"edition"`
+		} `toml:"workspace"`
+		Targets []Target `toml:"target"`
+	}
+
+	b.SetBytes(int64(len(benchManifest)))
+
+	for b.Loop() {
+		var manifest Manifest
+		if err := toml.Unmarshal(benchManifest, &manifest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
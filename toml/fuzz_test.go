@@ -0,0 +1,179 @@
+package toml_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser/toml"
+)
+
+// encode writes a parsed document back out as TOML, with every table inline, so the
+// fuzz tests can check it reads back as the same thing.
+func encode(doc map[string]any) string {
+	builder := &strings.Builder{}
+
+	for _, key := range sortedKeys(doc) {
+		writeKey(builder, key)
+		builder.WriteString(" = ")
+		writeValue(builder, doc[key])
+		builder.WriteByte('\n')
+	}
+
+	return builder.String()
+}
+
+// writeValue writes any parsed value as TOML.
+func writeValue(builder *strings.Builder, value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		builder.WriteByte('{')
+
+		for i, key := range sortedKeys(value) {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+
+			writeKey(builder, key)
+			builder.WriteString(" = ")
+			writeValue(builder, value[key])
+		}
+
+		builder.WriteByte('}')
+	case []any:
+		builder.WriteByte('[')
+
+		for i, element := range value {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+
+			writeValue(builder, element)
+		}
+
+		builder.WriteByte(']')
+	case string:
+		writeKey(builder, value)
+	case int64:
+		builder.WriteString(strconv.FormatInt(value, 10))
+	case float64:
+		writeFloat(builder, value)
+	case bool:
+		builder.WriteString(strconv.FormatBool(value))
+	case time.Time:
+		builder.WriteString(value.Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		// The local date and time types
+		builder.WriteString(value.String())
+	default:
+		panic(fmt.Sprintf("unexpected %T in parsed document", value))
+	}
+}
+
+// writeFloat writes a float so that it reads back as a float, not an integer.
+func writeFloat(builder *strings.Builder, f float64) {
+	switch {
+	case math.IsNaN(f):
+		builder.WriteString("nan")
+	case math.IsInf(f, 1):
+		builder.WriteString("inf")
+	case math.IsInf(f, -1):
+		builder.WriteString("-inf")
+	default:
+		text := strconv.FormatFloat(f, 'g', -1, 64)
+		builder.WriteString(text)
+
+		if !strings.ContainsAny(text, ".e") {
+			builder.WriteString(".0")
+		}
+	}
+}
+
+// writeKey writes text as a basic string, which works for both keys and values.
+func writeKey(builder *strings.Builder, text string) {
+	builder.WriteByte('"')
+
+	for _, r := range text {
+		switch {
+		case r == '"' || r == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(builder, `\u%04X`, r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	builder.WriteByte('"')
+}
+
+// sortedKeys returns the keys of m in order, so encoding is deterministic.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		"",
+		"a = 1",
+		"[a.b]\nc = 'd'\n[[e]]\nf = [1, 2.5, true]",
+		"a = { b.c = 1979-05-27T07:32:00Z, d = 07:32:00 }",
+		"a = \"\"\"\nline \\\n  continued \\u00e9\"\"\"\nb = '''raw\\n'''",
+		"x = 0xdead_beef\ny = -inf\nz = 1e-3\nw = 1979-05-27 00:00:00.123",
+		"# comment\r\n[ \"quoted key\" . bare ] # another\r\nv = +12_345",
+		"a.b = 1\n[a.c]\n[[d.e]]\n[d.e.f]",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		doc, err := toml.Parse(input)
+		if err != nil {
+			var syntaxErr *toml.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) returned a %T, wanted a *toml.SyntaxError", input, err)
+			}
+
+			if syntaxErr.Offset < 0 || syntaxErr.Offset > len(input) || syntaxErr.Line < 1 || syntaxErr.Column < 1 {
+				t.Fatalf("Parse(%q) returned an error outside the input: %+v", input, syntaxErr)
+			}
+
+			return
+		}
+
+		var decoded any
+		if err := toml.Unmarshal(input, &decoded); err != nil {
+			t.Fatalf("Unmarshal(%q) into any failed after Parse succeeded: %v", input, err)
+		}
+
+		// fmt sorts map keys and prints NaN the same each time, unlike reflect.DeepEqual
+		if fmt.Sprint(decoded) != fmt.Sprint(doc) {
+			t.Fatalf("Unmarshal(%q) into any = %v, Parse got %v", input, decoded, doc)
+		}
+
+		encoded := encode(doc)
+
+		again, err := toml.Parse(encoded)
+		if err != nil {
+			t.Fatalf("re-encoded %q as %q which didn't parse: %v", input, encoded, err)
+		}
+
+		if fmt.Sprint(again) != fmt.Sprint(doc) {
+			t.Fatalf("%q round tripped through %q to %v, wanted %v", input, encoded, again, doc)
+		}
+	})
+}
@@ -0,0 +1,1278 @@
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/datetime"
+)
+
+// grammar holds the state needed to parse a particular document.
+type grammar struct {
+	root    *table // The root table of the document
+	current *table // The table key/value pairs are added to, set by the last table header
+	src     string // The entire document
+	depth   int    // Current nesting depth of arrays and inline tables
+}
+
+// newGrammar returns a grammar for parsing src.
+func newGrammar(src string) *grammar {
+	root := newTable(kindHeader, 0)
+	return &grammar{root: root, current: root, src: src}
+}
+
+// keyPart is one part of a (possibly dotted) key.
+type keyPart struct {
+	name   string // The unquoted name
+	offset int    // Where it starts in the source
+}
+
+// offset returns the byte offset in the source of a suffix of it.
+func (g *grammar) offset(rest string) int {
+	return len(g.src) - len(rest)
+}
+
+// fail returns a new [SyntaxError] at the start of rest.
+func (g *grammar) fail(rest, format string, args ...any) error {
+	return g.failAt(g.offset(rest), format, args...)
+}
+
+// failAt returns a new [SyntaxError] at offset.
+func (g *grammar) failAt(offset int, format string, args ...any) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Offset: offset}
+}
+
+// unexpected returns a [SyntaxError] describing the unexpected char at the start
+// of rest (or the unexpected end of input).
+func (g *grammar) unexpected(rest, context string) error {
+	if rest == "" {
+		return g.fail(rest, "unexpected end of input, %s", context)
+	}
+
+	return g.fail(rest, "unexpected %q, %s", firstChar(rest), context)
+}
+
+// document parses the whole document, building the tree of tables under root.
+//
+//	toml = expression *( newline expression )
+//	expression = ws [ comment ] / ws keyval ws [ comment ] / ws table ws [ comment ]
+func (g *grammar) document() error {
+	rest := g.src
+	for rest != "" {
+		_, rest, _ = whitespace(rest)
+
+		var err error
+		if rest != "" {
+			switch rest[0] {
+			case '[':
+				rest, err = g.header(rest)
+			case '#', '\n', '\r':
+				// Blank or comment only, lineEnd deals with both
+			default:
+				rest, err = g.keyval(rest, g.current)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if rest, err = g.lineEnd(rest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lineEnd parses the optional whitespace and comment at the end of a line, and the newline
+// after them unless it's the end of the document.
+func (g *grammar) lineEnd(input string) (string, error) {
+	_, rest, _ := whitespace(input)
+	if rest != "" && rest[0] == '#' {
+		var err error
+		if rest, err = g.comment(rest); err != nil {
+			return "", err
+		}
+	}
+
+	if rest == "" {
+		return "", nil
+	}
+
+	_, remainder, err := newline(rest)
+	if err != nil {
+		return "", g.unexpected(rest, "expected a newline or comment")
+	}
+
+	return remainder, nil
+}
+
+// comment parses a comment up to (but not including) the newline that ends it.
+func (g *grammar) comment(input string) (string, error) {
+	_, rest, _ := commentText(input[1:])
+	if rest != "" && rest[0] != '\n' && !strings.HasPrefix(rest, "\r\n") {
+		return "", g.fail(rest, "invalid control character %q in comment", firstChar(rest))
+	}
+
+	return rest, nil
+}
+
+// header parses a table header like [a.b] or an array of tables header like [[a.b]],
+// making the table it defines the current one.
+func (g *grammar) header(input string) (string, error) {
+	opening, closing := "[", "]"
+	array := strings.HasPrefix(input, "[[")
+	if array {
+		opening, closing = "[[", "]]"
+	}
+
+	_, rest, _ := whitespace(input[len(opening):])
+
+	keys, rest, err := g.key(rest)
+	if err != nil {
+		return "", err
+	}
+
+	_, rest, _ = whitespace(rest)
+	if !strings.HasPrefix(rest, closing) {
+		return "", g.unexpected(rest, fmt.Sprintf("expected %q to close table header", closing))
+	}
+
+	if array {
+		err = g.appendTable(keys)
+	} else {
+		err = g.defineTable(keys)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return rest[len(closing):], nil
+}
+
+// defineTable defines the table for a [header] with the given keys.
+func (g *grammar) defineTable(keys []keyPart) error {
+	parent, err := g.parent(keys)
+	if err != nil {
+		return err
+	}
+
+	key := keys[len(keys)-1]
+	switch existing := parent.values[key.name].(type) {
+	case nil:
+		g.current = newTable(kindHeader, key.offset)
+		parent.set(key, g.current)
+	case *table:
+		if existing.kind != kindImplicit {
+			return g.failAt(key.offset, "duplicate table %s, first defined on line %d", path(keys), g.line(existing.offset))
+		}
+
+		existing.kind = kindHeader
+		g.current = existing
+	default:
+		return g.failAt(key.offset, "key %s already has a value of type %s", path(keys), typeName(existing))
+	}
+
+	return nil
+}
+
+// appendTable adds a new table to the array of tables for a [[header]] with the given keys.
+func (g *grammar) appendTable(keys []keyPart) error {
+	parent, err := g.parent(keys)
+	if err != nil {
+		return err
+	}
+
+	key := keys[len(keys)-1]
+	g.current = newTable(kindHeader, key.offset)
+
+	switch existing := parent.values[key.name].(type) {
+	case nil:
+		parent.set(key, &tableArray{tables: []*table{g.current}, offset: key.offset})
+	case *tableArray:
+		existing.tables = append(existing.tables, g.current)
+	default:
+		return g.failAt(key.offset, "key %s already has a value of type %s", path(keys), typeName(existing))
+	}
+
+	return nil
+}
+
+// parent walks from the root along all but the last of the keys in a table header, creating
+// any tables that don't exist yet, and returns the table the last key belongs in.
+//
+// Arrays of tables are followed into their most recently defined table.
+func (g *grammar) parent(keys []keyPart) (*table, error) {
+	current := g.root
+	for i, key := range keys[:len(keys)-1] {
+		switch child := current.values[key.name].(type) {
+		case nil:
+			next := newTable(kindImplicit, key.offset)
+			current.set(key, next)
+			current = next
+		case *table:
+			if child.kind == kindInline {
+				return nil, g.failAt(key.offset, "inline table %s can't be extended", path(keys[:i+1]))
+			}
+
+			current = child
+		case *tableArray:
+			current = child.tables[len(child.tables)-1]
+		default:
+			return nil, g.failAt(key.offset, "key %s already has a value of type %s", path(keys[:i+1]), typeName(child))
+		}
+	}
+
+	return current, nil
+}
+
+// keyval parses a key/value pair and adds it to target.
+//
+//	keyval = key keyval-sep val
+func (g *grammar) keyval(input string, target *table) (string, error) {
+	keys, rest, err := g.key(input)
+	if err != nil {
+		return "", err
+	}
+
+	_, rest, _ = whitespace(rest)
+	if rest == "" || rest[0] != '=' {
+		return "", g.unexpected(rest, "expected '=' after key")
+	}
+
+	_, rest, _ = whitespace(rest[1:])
+
+	value, rest, err := g.value(rest)
+	if err != nil {
+		return "", err
+	}
+
+	parent, err := g.dotted(target, keys)
+	if err != nil {
+		return "", err
+	}
+
+	key := keys[len(keys)-1]
+	if _, exists := parent.values[key.name]; exists {
+		return "", g.failAt(key.offset, "duplicate key %s, first defined on line %d", path(keys), g.line(parent.offsets[key.name]))
+	}
+
+	parent.set(key, value)
+
+	return rest, nil
+}
+
+// dotted walks from target along all but the last of the keys in a key/value pair,
+// creating any tables that don't exist yet, and returns the table the last key belongs in.
+func (g *grammar) dotted(target *table, keys []keyPart) (*table, error) {
+	current := target
+	for i, key := range keys[:len(keys)-1] {
+		switch child := current.values[key.name].(type) {
+		case nil:
+			next := newTable(kindDotted, key.offset)
+			current.set(key, next)
+			current = next
+		case *table:
+			switch child.kind {
+			case kindImplicit:
+				child.kind = kindDotted
+			case kindHeader:
+				return nil, g.failAt(key.offset, "table %s was defined by a header on line %d, it can't be extended with dotted keys", path(keys[:i+1]), g.line(child.offset))
+			case kindInline:
+				return nil, g.failAt(key.offset, "inline table %s can't be extended", path(keys[:i+1]))
+			case kindDotted:
+				// Can keep adding to it
+			}
+
+			current = child
+		default:
+			return nil, g.failAt(key.offset, "key %s already has a value of type %s", path(keys[:i+1]), typeName(child))
+		}
+	}
+
+	return current, nil
+}
+
+// key parses a simple or dotted key.
+//
+//	key = simple-key / dotted-key
+//	dotted-key = simple-key 1*( dot-sep simple-key )
+func (g *grammar) key(input string) ([]keyPart, string, error) {
+	var keys []keyPart
+
+	rest := input
+	for {
+		if len(keys) == MaxDepth {
+			return nil, "", g.fail(rest, "key has more than %d parts", MaxDepth)
+		}
+
+		name, remainder, err := g.simpleKey(rest)
+		if err != nil {
+			return nil, "", err
+		}
+
+		keys = append(keys, keyPart{name: name, offset: g.offset(rest)})
+
+		_, afterSpace, _ := whitespace(remainder)
+		if afterSpace == "" || afterSpace[0] != '.' {
+			return keys, remainder, nil
+		}
+
+		_, rest, _ = whitespace(afterSpace[1:])
+	}
+}
+
+// simpleKey parses a bare or quoted key.
+//
+//	simple-key = quoted-key / unquoted-key
+func (g *grammar) simpleKey(input string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(input, `"""`), strings.HasPrefix(input, "'''"):
+		return "", "", g.fail(input, "multi-line strings can't be used as keys")
+	case strings.HasPrefix(input, `"`):
+		return g.basicString(input)
+	case strings.HasPrefix(input, "'"):
+		return g.literalString(input)
+	}
+
+	name, rest, _ := bareKey(input)
+	if name == "" {
+		return "", "", g.unexpected(input, "expected a key")
+	}
+
+	return name, rest, nil
+}
+
+// value parses any TOML value.
+func (g *grammar) value(input string) (any, string, error) {
+	if input == "" {
+		return nil, "", g.unexpected(input, "expected a value")
+	}
+
+	// Dispatching on the first char means we get an error from the parser that was
+	// actually relevant
+	switch c := input[0]; {
+	case strings.HasPrefix(input, `"""`):
+		return g.multilineBasicString(input)
+	case c == '"':
+		return g.basicString(input)
+	case strings.HasPrefix(input, "'''"):
+		return g.multilineLiteralString(input)
+	case c == '\'':
+		return g.literalString(input)
+	case c == '[':
+		return g.array(input)
+	case c == '{':
+		return g.inlineTable(input)
+	case c == 't' || c == 'f':
+		return g.boolean(input)
+	case c == '+' || c == '-' || c == 'i' || c == 'n' || isDigit(c):
+		return g.scalar(input)
+	default:
+		return nil, "", g.unexpected(input, "expected a value")
+	}
+}
+
+// boolean parses true or false.
+func (g *grammar) boolean(input string) (any, string, error) {
+	text := token(input)
+
+	word, rest, err := boolLiteral(text)
+	if err != nil || rest != "" {
+		return nil, "", g.fail(input, "invalid value %q, expected true or false", text)
+	}
+
+	return word == "true", input[len(text):], nil
+}
+
+// scalar parses a number or date-time, which all start with a digit or sign (or are
+// inf or nan).
+func (g *grammar) scalar(input string) (any, string, error) {
+	text := token(input)
+
+	switch {
+	case len(text) >= 10 && isDigit(text[0]) && text[4] == '-':
+		return g.dateTime(input, text)
+	case len(text) >= 3 && isDigit(text[0]) && text[2] == ':':
+		clock, rest, err := g.clock(text, g.offset(input))
+		if err != nil {
+			return nil, "", err
+		}
+
+		if rest != "" {
+			return nil, "", g.fail(input[len(text)-len(rest):], "unexpected %q after local time", firstChar(rest))
+		}
+
+		return clock, input[len(text):], nil
+	default:
+		return g.number(input, text)
+	}
+}
+
+// dateTime parses an offset date-time, local date-time or local date. text is the
+// token at the start of input.
+func (g *grammar) dateTime(input, text string) (any, string, error) {
+	start := g.offset(input)
+
+	// text is a prefix of input so we can't take the offset of its suffixes directly
+	at := func(rest string) int { return start + len(text) - len(rest) }
+
+	day, rest, err := datetime.Date(text)
+	if err != nil {
+		var dateErr *datetime.Error
+		if errors.As(err, &dateErr) {
+			return nil, "", g.failAt(start+dateErr.Offset, "%s", dateErr.Msg)
+		}
+
+		return nil, "", g.fail(input, "invalid date %q", text)
+	}
+
+	date := LocalDate{Year: day.Year(), Month: day.Month(), Day: day.Day()}
+
+	if rest == "" {
+		dateLen := len(text)
+
+		// The date and time may be separated by a space, which ends the token, so look
+		// past it for something that looks like a time
+		after := input[len(text):]
+		if len(after) < 4 || after[0] != ' ' || !isDigit(after[1]) || !isDigit(after[2]) || after[3] != ':' {
+			return date, after, nil
+		}
+
+		text = input[:len(text)+1+len(token(after[1:]))]
+		rest = text[dateLen:]
+	}
+
+	switch rest[0] {
+	case 'T', 't', ' ':
+		rest = rest[1:]
+	default:
+		return nil, "", g.failAt(at(rest), "unexpected %q after date", firstChar(rest))
+	}
+
+	clock, rest, err := g.clock(rest, at(rest))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if rest == "" {
+		return LocalDateTime{Date: date, Time: clock}, input[len(text):], nil
+	}
+
+	loc, err := g.zone(rest, at(rest))
+	if err != nil {
+		return nil, "", err
+	}
+
+	t := time.Date(date.Year, date.Month, date.Day, clock.Hour, clock.Minute, clock.Second, clock.Nanosecond, loc)
+
+	return t, input[len(text):], nil
+}
+
+// clock parses a time of day like 07:32:00.999 from the start of text, start is its
+// offset in the source.
+//
+//	partial-time = time-hour ":" time-minute ":" time-second [ time-secfrac ]
+func (g *grammar) clock(text string, start int) (LocalTime, string, error) {
+	at := func(rest string) int { return start + len(text) - len(rest) }
+
+	fields := [...]struct {
+		name  string
+		upper int
+	}{
+		{name: "hour", upper: 23},
+		{name: "minute", upper: 59},
+		{name: "second", upper: 59},
+	}
+
+	var values [len(fields)]int
+
+	rest := text
+	for i, field := range fields {
+		if i > 0 {
+			if rest == "" || rest[0] != ':' {
+				return LocalTime{}, "", g.failAt(at(rest), "expected ':' after %s", fields[i-1].name)
+			}
+
+			rest = rest[1:]
+		}
+
+		digits, remainder, err := twoDigits(rest)
+		if err != nil {
+			return LocalTime{}, "", g.failAt(at(rest), "expected 2 digit %s", field.name)
+		}
+
+		n, _ := strconv.Atoi(digits)
+		if n > field.upper {
+			return LocalTime{}, "", g.failAt(at(rest), "%s %d out of range [0, %d]", field.name, n, field.upper)
+		}
+
+		values[i] = n
+		rest = remainder
+	}
+
+	clock := LocalTime{Hour: values[0], Minute: values[1], Second: values[2]}
+
+	if rest != "" && rest[0] == '.' {
+		fraction, remainder, _ := fractionDigits(rest[1:])
+		if fraction == "" {
+			return LocalTime{}, "", g.failAt(at(rest), "expected digits after decimal point in time")
+		}
+
+		clock.Nanosecond = nanoseconds(fraction)
+		rest = remainder
+	}
+
+	return clock, rest, nil
+}
+
+// zone parses the time zone offset at the end of an offset date-time, which must be all
+// of text, start is its offset in the source.
+//
+//	time-offset = "Z" / time-numoffset
+//	time-numoffset = ( "+" / "-" ) time-hour ":" time-minute
+func (g *grammar) zone(text string, start int) (*time.Location, error) {
+	if text == "Z" || text == "z" {
+		return time.UTC, nil
+	}
+
+	sign := 1
+	switch text[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return nil, g.failAt(start, "unexpected %q after time, expected 'Z' or offset", firstChar(text))
+	}
+
+	if len(text) != len("+00:00") || text[3] != ':' {
+		return nil, g.failAt(start, "invalid time zone offset %q", text)
+	}
+
+	hours, rest, err := twoDigits(text[1:3])
+	if err != nil || rest != "" {
+		return nil, g.failAt(start, "invalid time zone offset %q", text)
+	}
+
+	minutes, rest, err := twoDigits(text[4:])
+	if err != nil || rest != "" {
+		return nil, g.failAt(start, "invalid time zone offset %q", text)
+	}
+
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+
+	switch {
+	case h > 23:
+		return nil, g.failAt(start+1, "offset hour %d out of range [0, 23]", h)
+	case m > 59:
+		return nil, g.failAt(start+4, "offset minute %d out of range [0, 59]", m)
+	case h == 0 && m == 0:
+		return time.UTC, nil
+	default:
+		return time.FixedZone("", sign*(h*60*60+m*60)), nil
+	}
+}
+
+// number parses an integer or float. text is the token at the start of input.
+func (g *grammar) number(input, text string) (any, string, error) {
+	rest := input[len(text):]
+
+	switch text {
+	case "inf", "+inf":
+		return math.Inf(1), rest, nil
+	case "-inf":
+		return math.Inf(-1), rest, nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), rest, nil
+	}
+
+	if len(text) >= 2 && text[0] == '0' {
+		switch text[1] {
+		case 'x':
+			return g.prefixed(input, text, 16, hexDigits)
+		case 'o':
+			return g.prefixed(input, text, 8, octalDigits)
+		case 'b':
+			return g.prefixed(input, text, 2, binaryDigits)
+		}
+	}
+
+	return g.decimal(input, text)
+}
+
+// prefixed parses a hex, octal or binary integer. text is the token at the start of input,
+// and digits parses the digits allowed in base (and underscores).
+func (g *grammar) prefixed(input, text string, base int, digits parser.Parser[string]) (any, string, error) {
+	start := g.offset(input)
+
+	run, rest, _ := digits(text[2:])
+	if rest != "" {
+		return nil, "", g.failAt(start+len(text)-len(rest), "invalid character %q in base %d integer", firstChar(rest), base)
+	}
+
+	clean, err := g.underscores(run, start+2)
+	if err != nil {
+		return nil, "", err
+	}
+
+	n, err := strconv.ParseInt(clean, base, 64)
+	if err != nil {
+		return nil, "", g.failAt(start, "integer %s out of range", text)
+	}
+
+	return n, input[len(text):], nil
+}
+
+// decimal parses a decimal integer or float. text is the token at the start of input.
+//
+//	dec-int = [ minus / plus ] unsigned-dec-int
+//	float = float-int-part ( exp / frac [ exp ] )
+func (g *grammar) decimal(input, text string) (any, string, error) {
+	start := g.offset(input)
+	at := func(rest string) int { return start + len(text) - len(rest) }
+
+	rest := text
+	if rest != "" && (rest[0] == '+' || rest[0] == '-') {
+		rest = rest[1:]
+	}
+
+	whole, remainder, _ := decimalDigits(rest)
+	if whole == "" {
+		return nil, "", g.fail(input, "invalid value %q", text)
+	}
+
+	if _, err := g.underscores(whole, at(rest)); err != nil {
+		return nil, "", err
+	}
+
+	if len(whole) > 1 && whole[0] == '0' {
+		return nil, "", g.failAt(at(rest), "leading zeros aren't allowed in %q", text)
+	}
+
+	rest = remainder
+	float := false
+
+	if rest != "" && rest[0] == '.' {
+		fraction, remainder, _ := decimalDigits(rest[1:])
+		if fraction == "" {
+			return nil, "", g.failAt(at(rest), "expected digits after decimal point")
+		}
+
+		if _, err := g.underscores(fraction, at(rest[1:])); err != nil {
+			return nil, "", err
+		}
+
+		rest = remainder
+		float = true
+	}
+
+	if rest != "" && (rest[0] == 'e' || rest[0] == 'E') {
+		exponent := rest[1:]
+		if exponent != "" && (exponent[0] == '+' || exponent[0] == '-') {
+			exponent = exponent[1:]
+		}
+
+		power, remainder, _ := decimalDigits(exponent)
+		if power == "" {
+			return nil, "", g.failAt(at(exponent), "expected digits in exponent")
+		}
+
+		if _, err := g.underscores(power, at(exponent)); err != nil {
+			return nil, "", err
+		}
+
+		rest = remainder
+		float = true
+	}
+
+	if rest != "" {
+		return nil, "", g.failAt(at(rest), "invalid character %q in number", firstChar(rest))
+	}
+
+	clean := strings.ReplaceAll(text, "_", "")
+
+	if float {
+		f, err := strconv.ParseFloat(clean, 64)
+		if err != nil {
+			return nil, "", g.failAt(start, "float %s out of range", text)
+		}
+
+		return f, input[len(text):], nil
+	}
+
+	n, err := strconv.ParseInt(clean, 10, 64)
+	if err != nil {
+		return nil, "", g.failAt(start, "integer %s out of range", text)
+	}
+
+	return n, input[len(text):], nil
+}
+
+// underscores checks that any underscores in a run of digits are each between two digits,
+// returning the digits without them. start is the offset of the run in the source.
+func (g *grammar) underscores(run string, start int) (string, error) {
+	if run == "" {
+		return "", g.failAt(start, "expected digits")
+	}
+
+	for i := range len(run) {
+		if run[i] != '_' {
+			continue
+		}
+
+		if i == 0 || i == len(run)-1 || run[i-1] == '_' {
+			return "", g.failAt(start+i, "underscores in numbers must be between digits")
+		}
+	}
+
+	return strings.ReplaceAll(run, "_", ""), nil
+}
+
+// basicString parses a single line "basic string", returning its unescaped contents.
+//
+// If the string contains no escapes, the returned contents are a slice of the input
+// so no allocation takes place.
+func (g *grammar) basicString(input string) (string, string, error) {
+	rest := input[1:]
+
+	var builder *strings.Builder // Only allocated if we actually need to unescape
+
+	for {
+		chunk, remainder, _ := basicChars(rest)
+		if builder != nil {
+			builder.WriteString(chunk)
+		}
+		rest = remainder
+
+		if rest == "" {
+			return "", "", g.fail(input, "unterminated string")
+		}
+
+		switch c := rest[0]; c {
+		case '"':
+			if builder == nil {
+				return input[1 : len(input)-len(rest)], rest[1:], nil
+			}
+
+			return builder.String(), rest[1:], nil
+		case '\\':
+			if builder == nil {
+				builder = &strings.Builder{}
+				builder.WriteString(input[1 : len(input)-len(rest)])
+			}
+
+			text, remainder, err := g.escape(rest)
+			if err != nil {
+				return "", "", err
+			}
+
+			builder.WriteString(text)
+			rest = remainder
+		case '\n':
+			return "", "", g.fail(input, "unterminated string, use \"\"\" for a multi-line string")
+		default:
+			return "", "", g.fail(rest, "invalid control character %q in string", c)
+		}
+	}
+}
+
+// multilineBasicString parses a """multi-line basic string""", returning its
+// unescaped contents.
+func (g *grammar) multilineBasicString(input string) (string, string, error) {
+	rest := trimNewline(input[len(`"""`):])
+
+	builder := &strings.Builder{}
+
+	for {
+		chunk, remainder, _ := multilineBasicChars(rest)
+		builder.WriteString(chunk)
+		rest = remainder
+
+		if rest == "" {
+			return "", "", g.fail(input, "unterminated multi-line string")
+		}
+
+		switch c := rest[0]; c {
+		case '"':
+			text, remainder, done, err := g.closing(rest, doubleQuotes)
+			if err != nil {
+				return "", "", err
+			}
+
+			builder.WriteString(text)
+			rest = remainder
+
+			if done {
+				return builder.String(), rest, nil
+			}
+		case '\\':
+			// A backslash at the end of a line trims the newline and any whitespace
+			// or newlines after it
+			if after, ok := trimEscapedNewline(rest[1:]); ok {
+				rest = after
+				continue
+			}
+
+			text, remainder, err := g.escape(rest)
+			if err != nil {
+				return "", "", err
+			}
+
+			builder.WriteString(text)
+			rest = remainder
+		case '\r':
+			if !strings.HasPrefix(rest, "\r\n") {
+				return "", "", g.fail(rest, "invalid control character %q in string", c)
+			}
+
+			builder.WriteByte('\n')
+			rest = rest[2:]
+		default:
+			return "", "", g.fail(rest, "invalid control character %q in string", c)
+		}
+	}
+}
+
+// literalString parses a single line 'literal string', returning its contents.
+func (g *grammar) literalString(input string) (string, string, error) {
+	text, rest, _ := literalChars(input[1:])
+
+	switch {
+	case rest == "":
+		return "", "", g.fail(input, "unterminated string")
+	case rest[0] == '\'':
+		return text, rest[1:], nil
+	case rest[0] == '\n':
+		return "", "", g.fail(input, "unterminated string, use ''' for a multi-line string")
+	default:
+		return "", "", g.fail(rest, "invalid control character %q in string", rest[0])
+	}
+}
+
+// multilineLiteralString parses a multi-line literal string, delimited by three single
+// quotes, returning its contents.
+func (g *grammar) multilineLiteralString(input string) (string, string, error) {
+	rest := trimNewline(input[len("'''"):])
+
+	builder := &strings.Builder{}
+
+	for {
+		chunk, remainder, _ := multilineLiteralChars(rest)
+		builder.WriteString(chunk)
+		rest = remainder
+
+		if rest == "" {
+			return "", "", g.fail(input, "unterminated multi-line string")
+		}
+
+		switch c := rest[0]; c {
+		case '\'':
+			text, remainder, done, err := g.closing(rest, singleQuotes)
+			if err != nil {
+				return "", "", err
+			}
+
+			builder.WriteString(text)
+			rest = remainder
+
+			if done {
+				return builder.String(), rest, nil
+			}
+		case '\r':
+			if !strings.HasPrefix(rest, "\r\n") {
+				return "", "", g.fail(rest, "invalid control character %q in string", c)
+			}
+
+			builder.WriteByte('\n')
+			rest = rest[2:]
+		default:
+			return "", "", g.fail(rest, "invalid control character %q in string", c)
+		}
+	}
+}
+
+// closing parses a run of quotes inside a multi-line string, returning the ones that are
+// part of the string and whether the run closed it. run parses a run of the right quote.
+//
+// One or two quotes are just part of the string, three or more close it, with up to two
+// extra belonging to the string right before the closing delimiter.
+func (g *grammar) closing(input string, run parser.Parser[string]) (string, string, bool, error) {
+	const window = 6 // Enough to spot too many
+
+	quotes, _, _ := run(input[:min(len(input), window)])
+	rest := input[len(quotes):]
+
+	switch {
+	case len(quotes) < 3:
+		return quotes, rest, false, nil
+	case len(quotes) > 5:
+		return "", "", false, g.fail(input[5:], "too many quotes at end of multi-line string")
+	default:
+		return quotes[3:], rest, true, nil
+	}
+}
+
+// escape parses an escape sequence in a basic string, returning the text it stands for.
+func (g *grammar) escape(input string) (string, string, error) {
+	if len(input) < 2 {
+		return "", "", g.fail(input, "unexpected end of input in escape sequence")
+	}
+
+	switch c := input[1]; c {
+	case 'b':
+		return "\b", input[2:], nil
+	case 't':
+		return "\t", input[2:], nil
+	case 'n':
+		return "\n", input[2:], nil
+	case 'f':
+		return "\f", input[2:], nil
+	case 'r':
+		return "\r", input[2:], nil
+	case '"':
+		return `"`, input[2:], nil
+	case '\\':
+		return `\`, input[2:], nil
+	case 'u':
+		return g.unicode(input, hex4)
+	case 'U':
+		return g.unicode(input, hex8)
+	default:
+		return "", "", g.fail(input, "invalid escape sequence \\%c", firstChar(input[1:]))
+	}
+}
+
+// unicode parses a \uXXXX or \UXXXXXXXX escape, digits parses the right number of hex digits.
+func (g *grammar) unicode(input string, digits parser.Parser[string]) (string, string, error) {
+	hex, rest, err := digits(input[2:])
+	if err != nil {
+		return "", "", g.fail(input, "invalid unicode escape sequence")
+	}
+
+	n, _ := strconv.ParseUint(hex, 16, 32)
+	if !utf8.ValidRune(rune(n)) {
+		return "", "", g.fail(input, "escape sequence %s is not a unicode scalar value", input[:len(input)-len(rest)])
+	}
+
+	return string(rune(n)), rest, nil
+}
+
+// array parses an array of values, which may be spread over multiple lines.
+//
+//	array = array-open [ array-values ] ws-comment-newline array-close
+func (g *grammar) array(input string) (any, string, error) {
+	if err := g.enter(input); err != nil {
+		return nil, "", err
+	}
+	defer g.leave()
+
+	elements := []any{}
+
+	rest := input[1:]
+	for {
+		var err error
+		if rest, err = g.arraySpace(rest); err != nil {
+			return nil, "", err
+		}
+
+		if rest != "" && rest[0] == ']' {
+			return elements, rest[1:], nil
+		}
+
+		var element any
+		element, rest, err = g.value(rest)
+		if err != nil {
+			return nil, "", err
+		}
+
+		elements = append(elements, element)
+
+		if rest, err = g.arraySpace(rest); err != nil {
+			return nil, "", err
+		}
+
+		switch {
+		case rest != "" && rest[0] == ',':
+			rest = rest[1:]
+		case rest != "" && rest[0] == ']':
+			return elements, rest[1:], nil
+		default:
+			return nil, "", g.unexpected(rest, "expected ',' or ']' after array element")
+		}
+	}
+}
+
+// arraySpace skips the whitespace, comments and newlines allowed between array elements.
+func (g *grammar) arraySpace(input string) (string, error) {
+	rest := input
+	for {
+		_, rest, _ = whitespace(rest)
+		if rest != "" && rest[0] == '#' {
+			var err error
+			if rest, err = g.comment(rest); err != nil {
+				return "", err
+			}
+		}
+
+		_, remainder, err := newline(rest)
+		if err != nil {
+			return rest, nil
+		}
+
+		rest = remainder
+	}
+}
+
+// inlineTable parses an {inline = "table"}, which must be on a single line.
+//
+//	inline-table = inline-table-open [ inline-table-keyvals ] inline-table-close
+func (g *grammar) inlineTable(input string) (any, string, error) {
+	if err := g.enter(input); err != nil {
+		return nil, "", err
+	}
+	defer g.leave()
+
+	// Dotted keys inside the braces can add to each other's tables, so it's only sealed
+	// once it's complete
+	inline := newTable(kindDotted, g.offset(input))
+
+	_, rest, _ := whitespace(input[1:])
+	if rest != "" && rest[0] == '}' {
+		inline.seal()
+		return inline, rest[1:], nil
+	}
+
+	for {
+		var err error
+		if rest, err = g.keyval(rest, inline); err != nil {
+			return nil, "", err
+		}
+
+		_, rest, _ = whitespace(rest)
+
+		switch {
+		case rest != "" && rest[0] == '}':
+			inline.seal()
+			return inline, rest[1:], nil
+		case rest != "" && rest[0] == ',':
+			_, rest, _ = whitespace(rest[1:])
+			if rest != "" && rest[0] == '}' {
+				return nil, "", g.fail(rest, "trailing commas aren't allowed in inline tables")
+			}
+		default:
+			return nil, "", g.unexpected(rest, "expected ',' or '}' after inline table value")
+		}
+	}
+}
+
+// enter records entering an array or inline table, failing if that's too deep.
+func (g *grammar) enter(input string) error {
+	g.depth++
+	if g.depth > MaxDepth {
+		return g.fail(input, "exceeded max nesting depth of %d", MaxDepth)
+	}
+
+	return nil
+}
+
+// leave records leaving an array or inline table.
+func (g *grammar) leave() {
+	g.depth--
+}
+
+// line returns the 1 indexed line number of offset, for pointing at earlier definitions.
+func (g *grammar) line(offset int) int {
+	line, _ := position(g.src, offset)
+	return line
+}
+
+// set adds a value to the table.
+func (t *table) set(key keyPart, value any) {
+	t.values[key.name] = value
+	t.offsets[key.name] = key.offset
+}
+
+// seal marks an inline table, and all the tables its dotted keys defined, as complete.
+func (t *table) seal() {
+	t.kind = kindInline
+	for _, value := range t.values {
+		// Nested inline tables are already sealed, skipping them keeps deep nesting linear
+		if child, ok := value.(*table); ok && child.kind != kindInline {
+			child.seal()
+		}
+	}
+}
+
+// path formats keys as a dotted key for error messages.
+func path(keys []keyPart) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, quoteKey(key.name))
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// quoteKey quotes name if it can't be a bare key.
+func quoteKey(name string) string {
+	if bare, rest, _ := bareKey(name); bare == "" || rest != "" {
+		return strconv.Quote(name)
+	}
+
+	return name
+}
+
+// controlChars are the chars other than tab and newline that aren't allowed
+// unescaped in strings or comments.
+const controlChars = "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x0b\x0c\x0d\x0e\x0f" +
+	"\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f\x7f"
+
+var (
+	// whitespace parses optional spaces and tabs.
+	whitespace = parser.SkipMany(parser.OneOf(" \t"))
+
+	// commentText parses the text of a comment, which ends at the newline.
+	commentText = parser.SkipMany(parser.NoneOf("\n" + controlChars))
+
+	// bareKey parses an unquoted key.
+	bareKey = parser.SkipMany(parser.OneOf("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_-"))
+
+	// basicChars parses a run of chars in a basic string other than the closing quote,
+	// an escape, a newline or a control char.
+	basicChars = parser.SkipMany(parser.NoneOf("\"\\\n" + controlChars))
+
+	// multilineBasicChars is basicChars, but allowing newlines.
+	multilineBasicChars = parser.SkipMany(parser.NoneOf("\"\\" + controlChars))
+
+	// literalChars parses a run of chars in a literal string other than the closing quote,
+	// a newline or a control char.
+	literalChars = parser.SkipMany(parser.NoneOf("'\n" + controlChars))
+
+	// multilineLiteralChars is literalChars, but allowing newlines.
+	multilineLiteralChars = parser.SkipMany(parser.NoneOf("'" + controlChars))
+
+	// boolLiteral parses true or false.
+	boolLiteral = parser.Try(parser.Exact("true"), parser.Exact("false"))
+
+	// decimalDigits parses decimal digits and the underscores allowed between them.
+	decimalDigits = parser.SkipMany(parser.OneOf("0123456789_"))
+
+	// hexDigits parses hex digits and the underscores allowed between them.
+	hexDigits = parser.SkipMany(parser.OneOf("0123456789abcdefABCDEF_"))
+
+	// octalDigits parses octal digits and the underscores allowed between them.
+	octalDigits = parser.SkipMany(parser.OneOf("01234567_"))
+
+	// binaryDigits parses binary digits and the underscores allowed between them.
+	binaryDigits = parser.SkipMany(parser.OneOf("01_"))
+
+	// doubleQuotes parses a run of '"'.
+	doubleQuotes = parser.SkipMany(parser.Char('"'))
+
+	// singleQuotes parses a run of '\''.
+	singleQuotes = parser.SkipMany(parser.Char('\''))
+
+	// fractionDigits parses the digits of a fraction of a second.
+	fractionDigits = parser.SkipMany(parser.OneOf("0123456789"))
+
+	// twoDigits parses exactly 2 decimal digits.
+	twoDigits = parser.SkipCount(parser.OneOf("0123456789"), 2)
+
+	// hex4 parses the 4 hex digits of a \u escape.
+	hex4 = parser.SkipCount(parser.OneOf("0123456789abcdefABCDEF"), 4)
+
+	// hex8 parses the 8 hex digits of a \U escape.
+	hex8 = parser.SkipCount(parser.OneOf("0123456789abcdefABCDEF"), 8)
+)
+
+// errNoNewline is returned by newline when there isn't one.
+var errNoNewline = errors.New("expected a newline")
+
+// newline parses a LF or CRLF line ending.
+func newline(input string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(input, "\n"):
+		return input[:1], input[1:], nil
+	case strings.HasPrefix(input, "\r\n"):
+		return input[:2], input[2:], nil
+	default:
+		return "", "", errNoNewline
+	}
+}
+
+// trimNewline removes a newline from the start of s if there is one, as one straight after
+// the opening delimiter of a multi-line string isn't part of it.
+func trimNewline(s string) string {
+	if _, rest, err := newline(s); err == nil {
+		return rest
+	}
+
+	return s
+}
+
+// trimEscapedNewline handles the text after a backslash in a multi-line basic string,
+// if it's only whitespace up to the end of the line it returns the text after that line
+// with any more leading whitespace and newlines removed.
+func trimEscapedNewline(s string) (string, bool) {
+	_, rest, _ := whitespace(s)
+
+	_, rest, err := newline(rest)
+	if err != nil {
+		return s, false
+	}
+
+	for {
+		_, rest, _ = whitespace(rest)
+
+		_, remainder, err := newline(rest)
+		if err != nil {
+			return rest, true
+		}
+
+		rest = remainder
+	}
+}
+
+// token returns the run of chars at the start of s up to the next char that can follow
+// a bare value like a number, boolean or date-time.
+func token(s string) string {
+	if end := strings.IndexAny(s, " \t\r\n,]}#"); end >= 0 {
+		return s[:end]
+	}
+
+	return s
+}
+
+// nanoseconds converts the digits of a fraction of a second to nanoseconds, truncating
+// anything beyond nanosecond precision.
+func nanoseconds(digits string) int {
+	const precision = 9
+
+	if len(digits) > precision {
+		digits = digits[:precision]
+	}
+
+	n, _ := strconv.Atoi(digits + strings.Repeat("0", precision-len(digits)))
+
+	return n
+}
+
+// firstChar returns the first utf-8 char in s.
+func firstChar(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// isDigit reports whether c is an ascii digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
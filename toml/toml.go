@@ -0,0 +1,288 @@
+// Package toml implements a [TOML 1.0] parser built on the combinators in [parser].
+//
+// Documents can be parsed into a map with [Parse], or decoded into a struct (or any other
+// Go value) with [Unmarshal]. Either way, every error points at the line and column of the
+// problem, whether that's a syntax error, a key defined twice, or a value of the wrong type
+// for the struct field it's decoded into.
+//
+// TOML values become the following Go values in a map:
+//
+//	string            string
+//	integer           int64
+//	float             float64
+//	boolean           bool
+//	offset date-time  time.Time
+//	local date-time   LocalDateTime
+//	local date        LocalDate
+//	local time        LocalTime
+//	array             []any
+//	table             map[string]any
+//
+// Arrays of tables are an []any of map[string]any.
+//
+// [TOML 1.0]: https://toml.io/en/v1.0.0
+package toml // import "go.followtheprocess.codes/parser/toml"
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxDepth is the maximum nesting depth of arrays and inline tables, and the maximum number
+// of parts in a dotted key, that [Parse] will accept. It stops deeply nested (and likely
+// malicious) input from exhausting the stack.
+const MaxDepth = 10000
+
+// LocalDate is a date without a time or time zone, like 1979-05-27.
+type LocalDate struct {
+	Year  int        // The year, like 1979
+	Month time.Month // The month of the year
+	Day   int        // The day of the month, starting at 1
+}
+
+// String returns the date in RFC 3339 format, like 1979-05-27.
+func (d LocalDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
+}
+
+// In returns the time at the start of the date in loc.
+func (d LocalDate) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// LocalTime is a time of day without a date or time zone, like 07:32:00.999.
+type LocalTime struct {
+	Hour       int // The hour of the day, from 0 to 23
+	Minute     int // The minute of the hour, from 0 to 59
+	Second     int // The second of the minute, from 0 to 59
+	Nanosecond int // Any fraction of the second, precision beyond nanoseconds is truncated
+}
+
+// String returns the time in RFC 3339 format, like 07:32:00.999, with a fraction of a
+// second only if there is one.
+func (t LocalTime) String() string {
+	text := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		text += strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+	}
+
+	return text
+}
+
+// LocalDateTime is a date and time without a time zone, like 1979-05-27T07:32:00.
+type LocalDateTime struct {
+	Date LocalDate // The date
+	Time LocalTime // The time on that date
+}
+
+// String returns the date and time in RFC 3339 format, like 1979-05-27T07:32:00.
+func (dt LocalDateTime) String() string {
+	return dt.Date.String() + "T" + dt.Time.String()
+}
+
+// In returns the date and time in loc.
+func (dt LocalDateTime) In(loc *time.Location) time.Time {
+	return time.Date(
+		dt.Date.Year, dt.Date.Month, dt.Date.Day,
+		dt.Time.Hour, dt.Time.Minute, dt.Time.Second, dt.Time.Nanosecond,
+		loc,
+	)
+}
+
+// SyntaxError is the error returned when the input is not a valid TOML document, including
+// when it's well formed but defines something twice.
+type SyntaxError struct {
+	Msg    string // Description of the problem
+	Offset int    // Byte offset in the input at which the error occurred
+	Line   int    // 1 indexed line number of Offset
+	Column int    // 1 indexed column (in utf-8 chars) of Offset
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("toml: %s at line %d, column %d", e.Msg, e.Line, e.Column)
+}
+
+// Parse parses a complete TOML document into a map, converting values as described in
+// the package documentation. Any error returned will be a [*SyntaxError].
+func Parse(src string) (map[string]any, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return root.toMap(), nil
+}
+
+// parse parses src into its tree of tables.
+func parse(src string) (*table, error) {
+	if !utf8.ValidString(src) {
+		return nil, newSyntaxError(src, invalidUTF8Offset(src), "input not valid utf-8")
+	}
+
+	g := newGrammar(src)
+	if err := g.document(); err != nil {
+		return nil, locate(src, err)
+	}
+
+	return g.root, nil
+}
+
+// tableKind records how a table was defined, which determines how it can be added to later.
+type tableKind int
+
+const (
+	// kindImplicit is a table created because it's part of a table header's key, like a in
+	// [a.b]. It can be defined later by its own header, or added to with dotted keys.
+	kindImplicit tableKind = iota
+
+	// kindHeader is a table defined by a table header. It can have sub-tables defined by
+	// later headers, but can't be defined again or added to with dotted keys.
+	kindHeader
+
+	// kindDotted is a table defined by a dotted key, like a in a.b = 1. It can be added to
+	// by more dotted keys and have sub-tables defined by headers, but can't have a header
+	// of its own.
+	kindDotted
+
+	// kindInline is an inline table, like {b = 1}, which can't be added to at all.
+	kindInline
+)
+
+// table is a table in the tree built while parsing.
+type table struct {
+	values  map[string]any // Values by key, a value is a *table, a *tableArray, an []any or a scalar
+	offsets map[string]int // Where each key was defined
+	kind    tableKind      // How the table was defined
+	offset  int            // Where the table was defined
+}
+
+// newTable returns a new empty table.
+func newTable(kind tableKind, offset int) *table {
+	return &table{
+		values:  make(map[string]any),
+		offsets: make(map[string]int),
+		kind:    kind,
+		offset:  offset,
+	}
+}
+
+// tableArray is an array of tables, defined by [[header]]s.
+type tableArray struct {
+	tables []*table // The tables, one per header
+	offset int      // Where the first header was
+}
+
+// toMap converts the table to the representation described in the package documentation.
+func (t *table) toMap() map[string]any {
+	m := make(map[string]any, len(t.values))
+	for key, value := range t.values {
+		m[key] = toAny(value)
+	}
+
+	return m
+}
+
+// toAny converts a value from the tree to the representation described in the
+// package documentation.
+func toAny(value any) any {
+	switch value := value.(type) {
+	case *table:
+		return value.toMap()
+	case *tableArray:
+		array := make([]any, 0, len(value.tables))
+		for _, table := range value.tables {
+			array = append(array, table.toMap())
+		}
+
+		return array
+	case []any:
+		array := make([]any, 0, len(value))
+		for _, element := range value {
+			array = append(array, toAny(element))
+		}
+
+		return array
+	default:
+		return value
+	}
+}
+
+// typeName returns the TOML name of the type of a value from the tree, for error messages.
+func typeName(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "offset date-time"
+	case LocalDateTime:
+		return "local date-time"
+	case LocalDate:
+		return "local date"
+	case LocalTime:
+		return "local time"
+	case []any:
+		return "array"
+	case *tableArray:
+		return "array of tables"
+	case *table:
+		return "table"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// locate fills in the line and column of a [SyntaxError] from the grammar.
+func locate(src string, err error) error {
+	syntaxErr, ok := err.(*SyntaxError)
+	if !ok {
+		// Shouldn't happen, everything in the grammar returns a SyntaxError
+		return newSyntaxError(src, 0, err.Error())
+	}
+
+	syntaxErr.locate(src)
+
+	return syntaxErr
+}
+
+// newSyntaxError builds a [SyntaxError] at offset into src.
+func newSyntaxError(src string, offset int, msg string) *SyntaxError {
+	err := &SyntaxError{Msg: msg, Offset: offset}
+	err.locate(src)
+
+	return err
+}
+
+// locate fills in the Line and Column of the error from its Offset into src.
+func (e *SyntaxError) locate(src string) {
+	e.Line, e.Column = position(src, e.Offset)
+}
+
+// position returns the 1 indexed line and column of offset into src.
+func position(src string, offset int) (line, column int) {
+	before := src[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	return 1 + strings.Count(before, "\n"), 1 + utf8.RuneCountInString(before[lineStart:])
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid utf-8 sequence in s.
+func invalidUTF8Offset(s string) int {
+	for pos, char := range s {
+		if char == utf8.RuneError {
+			if _, width := utf8.DecodeRuneInString(s[pos:]); width == 1 {
+				return pos
+			}
+		}
+	}
+
+	return len(s)
+}
@@ -0,0 +1,863 @@
+package toml_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/parser/toml"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		want    map[string]any // The expected document
+		name    string         // Identifying test case name
+		input   string         // The document to parse
+		err     string         // The expected error message, if there was one
+		wantErr bool           // Whether or not we wanted an error
+	}{
+		{name: "empty", input: "", want: map[string]any{}},
+		{name: "string", input: `a = "b"`, want: map[string]any{"a": "b"}},
+		{name: "integer", input: "a = -1_000", want: map[string]any{"a": int64(-1000)}},
+		{name: "hex", input: "a = 0xff", want: map[string]any{"a": int64(255)}},
+		{name: "float", input: "a = 6.5e-1", want: map[string]any{"a": 0.65}},
+		{name: "bool", input: "a = false", want: map[string]any{"a": false}},
+		{
+			name:  "offset date-time",
+			input: "a = 1979-05-27T07:32:00.5+01:00",
+			want:  map[string]any{"a": time.Date(1979, 5, 27, 7, 32, 0, 5e8, time.FixedZone("", 3600))},
+		},
+		{
+			name:  "local date-time",
+			input: "a = 1979-05-27 07:32:00",
+			want: map[string]any{"a": toml.LocalDateTime{
+				Date: toml.LocalDate{Year: 1979, Month: time.May, Day: 27},
+				Time: toml.LocalTime{Hour: 7, Minute: 32},
+			}},
+		},
+		{
+			name:  "local date",
+			input: "a = 1979-05-27",
+			want:  map[string]any{"a": toml.LocalDate{Year: 1979, Month: time.May, Day: 27}},
+		},
+		{
+			name:  "local time",
+			input: "a = 00:32:00.999999",
+			want:  map[string]any{"a": toml.LocalTime{Minute: 32, Nanosecond: 999999000}},
+		},
+		{
+			name:  "array",
+			input: "a = [1, 'two', [3]]",
+			want:  map[string]any{"a": []any{int64(1), "two", []any{int64(3)}}},
+		},
+		{
+			name:  "tables",
+			input: "[a]\nb = 1\n[a.c]\nd = 2\n[e]",
+			want:  map[string]any{"a": map[string]any{"b": int64(1), "c": map[string]any{"d": int64(2)}}, "e": map[string]any{}},
+		},
+		{
+			name:  "array of tables",
+			input: "[[a]]\nb = 1\n[[a]]\nb = 2",
+			want:  map[string]any{"a": []any{map[string]any{"b": int64(1)}, map[string]any{"b": int64(2)}}},
+		},
+		{
+			name:  "dotted keys",
+			input: "a.b = 1\na.c = 2",
+			want:  map[string]any{"a": map[string]any{"b": int64(1), "c": int64(2)}},
+		},
+		{
+			name:  "inline table",
+			input: "a = {b = 1, c.d = 2}",
+			want:  map[string]any{"a": map[string]any{"b": int64(1), "c": map[string]any{"d": int64(2)}}},
+		},
+		{
+			name:  "multi-line strings",
+			input: "a = \"\"\"\none \\\n  two\"\"\"\nb = '''\nthree\\n'''",
+			want:  map[string]any{"a": "one two", "b": `three\n`},
+		},
+		{
+			name:    "invalid utf-8",
+			input:   "a = \"\xff\"",
+			wantErr: true,
+			err:     "toml: input not valid utf-8 at line 1, column 6",
+		},
+		{
+			name:    "missing value",
+			input:   "a = 1\nb =\n",
+			wantErr: true,
+			err:     `toml: unexpected '\n', expected a value at line 2, column 4`,
+		},
+		{
+			name:    "missing equals",
+			input:   "a 1",
+			wantErr: true,
+			err:     `toml: unexpected '1', expected '=' after key at line 1, column 3`,
+		},
+		{
+			name:    "two values",
+			input:   "a = 1 2",
+			wantErr: true,
+			err:     `toml: unexpected '2', expected a newline or comment at line 1, column 7`,
+		},
+		{
+			name:    "duplicate key",
+			input:   "a = 1\n\n a = 2",
+			wantErr: true,
+			err:     "toml: duplicate key a, first defined on line 1 at line 3, column 2",
+		},
+		{
+			name:    "duplicate table",
+			input:   "[a]\nb = 1\n[a]",
+			wantErr: true,
+			err:     "toml: duplicate table a, first defined on line 1 at line 3, column 2",
+		},
+		{
+			name:    "dotted key into header table",
+			input:   "[a.b]\n[a]\nb.c = 1",
+			wantErr: true,
+			err:     "toml: table b was defined by a header on line 1, it can't be extended with dotted keys at line 3, column 1",
+		},
+		{
+			name:    "extend inline table",
+			input:   "a = {b = 1}\n[a.c]",
+			wantErr: true,
+			err:     "toml: inline table a can't be extended at line 2, column 2",
+		},
+		{
+			name:    "append to static array",
+			input:   "a = []\n[[a]]",
+			wantErr: true,
+			err:     "toml: key a already has a value of type array at line 2, column 3",
+		},
+		{
+			name:    "quoted key in message",
+			input:   "\"a b\".c = 1\n\"a b\".c = 2",
+			wantErr: true,
+			err:     `toml: duplicate key "a b".c, first defined on line 1 at line 2, column 7`,
+		},
+		{
+			name:    "leading zero",
+			input:   "a = 012",
+			wantErr: true,
+			err:     `toml: leading zeros aren't allowed in "012" at line 1, column 5`,
+		},
+		{
+			name:    "bad underscore",
+			input:   "a = 1__0",
+			wantErr: true,
+			err:     "toml: underscores in numbers must be between digits at line 1, column 7",
+		},
+		{
+			name:    "integer overflow",
+			input:   "a = 9223372036854775808",
+			wantErr: true,
+			err:     "toml: integer 9223372036854775808 out of range at line 1, column 5",
+		},
+		{
+			name:    "bad date",
+			input:   "a = 2023-02-29",
+			wantErr: true,
+			err:     "toml: day 29 out of range [1, 28] for February 2023 at line 1, column 13",
+		},
+		{
+			name:    "bad time",
+			input:   "a = 1979-05-27T07:61:00",
+			wantErr: true,
+			err:     "toml: minute 61 out of range [0, 59] at line 1, column 19",
+		},
+		{
+			name:    "bad offset",
+			input:   "a = 1979-05-27T07:32:00+25:00",
+			wantErr: true,
+			err:     "toml: offset hour 25 out of range [0, 23] at line 1, column 25",
+		},
+		{
+			name:    "bad escape",
+			input:   `a = "x\qy"`,
+			wantErr: true,
+			err:     `toml: invalid escape sequence \q at line 1, column 7`,
+		},
+		{
+			name:    "unterminated string",
+			input:   "a = \"abc\nb = 1",
+			wantErr: true,
+			err:     `toml: unterminated string, use """ for a multi-line string at line 1, column 5`,
+		},
+		{
+			name:    "control char in comment",
+			input:   "# a\x00b",
+			wantErr: true,
+			err:     `toml: invalid control character '\x00' in comment at line 1, column 4`,
+		},
+		{
+			name:    "inline table over lines",
+			input:   "a = {b = 1,\nc = 2}",
+			wantErr: true,
+			err:     `toml: unexpected '\n', expected a key at line 1, column 12`,
+		},
+		{
+			name:    "unclosed header",
+			input:   "[a.b",
+			wantErr: true,
+			err:     `toml: unexpected end of input, expected "]" to close table header at line 1, column 5`,
+		},
+		{
+			name:    "column counts chars",
+			input:   `"ключ" = 1 1`,
+			wantErr: true,
+			err:     `toml: unexpected '1', expected a newline or comment at line 1, column 12`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toml.Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if msg := err.Error(); msg != tt.err {
+					t.Fatalf("\nError message:\t%q\nWanted:\t\t%q\n", msg, tt.err)
+				}
+
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	_, err := toml.Parse("a = 1\nb = [1,\n  2,,\n]")
+
+	var syntaxErr *toml.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Error was not a *toml.SyntaxError, got %T", err)
+	}
+
+	if syntaxErr.Line != 3 || syntaxErr.Column != 5 || syntaxErr.Offset != 18 {
+		t.Errorf("Position = (%d, %d, %d), wanted (3, 5, 18)", syntaxErr.Line, syntaxErr.Column, syntaxErr.Offset)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // The document to parse
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{
+			name:  "arrays at max depth",
+			input: "a = " + strings.Repeat("[", toml.MaxDepth) + strings.Repeat("]", toml.MaxDepth),
+		},
+		{
+			name:    "arrays too deep",
+			input:   "a = " + strings.Repeat("[", toml.MaxDepth+1) + strings.Repeat("]", toml.MaxDepth+1),
+			wantErr: true,
+		},
+		{
+			name:    "inline tables too deep",
+			input:   "a = " + strings.Repeat("{a=", toml.MaxDepth+1) + "1" + strings.Repeat("}", toml.MaxDepth+1),
+			wantErr: true,
+		},
+		{
+			name:    "key too long",
+			input:   strings.Repeat("a.", toml.MaxDepth) + "a = 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := toml.Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocalString(t *testing.T) {
+	tests := []struct {
+		value fmt.Stringer // The value to print
+		name  string       // Identifying test case name
+		want  string       // The expected string
+	}{
+		{name: "date", value: toml.LocalDate{Year: 987, Month: time.July, Day: 5}, want: "0987-07-05"},
+		{name: "time", value: toml.LocalTime{Hour: 7, Minute: 3, Second: 9}, want: "07:03:09"},
+		{name: "time fraction", value: toml.LocalTime{Hour: 23, Nanosecond: 120000000}, want: "23:00:00.12"},
+		{
+			name: "date-time",
+			value: toml.LocalDateTime{
+				Date: toml.LocalDate{Year: 2024, Month: time.February, Day: 29},
+				Time: toml.LocalTime{Hour: 12, Nanosecond: 1},
+			},
+			want: "2024-02-29T12:00:00.000000001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.String(); got != tt.want {
+				t.Errorf("String() = %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConformance runs the cases in testdata/toml-test, which follow the layout and
+// tagged JSON encoding of the toml-test suite (https://github.com/toml-lang/toml-test)
+// and cover a subset of it. Documents under valid must be accepted and decode to the
+// value in the .json file alongside them, documents under invalid must be rejected.
+func TestConformance(t *testing.T) {
+	root := filepath.Join("testdata", "toml-test")
+
+	var files []string
+
+	err := filepath.WalkDir(root, func(path string, _ os.DirEntry, err error) error {
+		if err == nil && filepath.Ext(path) == ".toml" {
+			files = append(files, path)
+		}
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("no conformance test cases found")
+	}
+
+	for _, file := range files {
+		name, _ := filepath.Rel(root, file)
+		t.Run(filepath.ToSlash(name), func(t *testing.T) {
+			contents, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := toml.Parse(string(contents))
+
+			switch {
+			case strings.HasPrefix(name, "valid"):
+				if err != nil {
+					t.Fatalf("Parse rejected valid TOML: %v", err)
+				}
+
+				expected, err := os.ReadFile(strings.TrimSuffix(file, ".toml") + ".json")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var want any
+				if err := stdjson.Unmarshal(expected, &want); err != nil {
+					t.Fatalf("bad expected JSON: %v", err)
+				}
+
+				if !taggedEqual(got, want) {
+					tagged, _ := stdjson.MarshalIndent(tag(got), "", "  ")
+					t.Errorf("\nGot:\t%s\nWanted:\t%s\n", tagged, expected)
+				}
+			case strings.HasPrefix(name, "invalid"):
+				if err == nil {
+					t.Fatalf("Parse accepted invalid TOML: %#v", got)
+				}
+
+				var syntaxErr *toml.SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("Error was not a *toml.SyntaxError, got %T", err)
+				}
+			default:
+				t.Fatalf("conformance case %s must be under valid or invalid", name)
+			}
+		})
+	}
+}
+
+// tag converts a parsed value to the tagged JSON representation used by toml-test.
+func tag(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		tagged := make(map[string]any, len(value))
+		for key, v := range value {
+			tagged[key] = tag(v)
+		}
+
+		return tagged
+	case []any:
+		tagged := make([]any, 0, len(value))
+		for _, v := range value {
+			tagged = append(tagged, tag(v))
+		}
+
+		return tagged
+	case string:
+		return map[string]any{"type": "string", "value": value}
+	case int64:
+		return map[string]any{"type": "integer", "value": strconv.FormatInt(value, 10)}
+	case float64:
+		return map[string]any{"type": "float", "value": strconv.FormatFloat(value, 'g', -1, 64)}
+	case bool:
+		return map[string]any{"type": "bool", "value": strconv.FormatBool(value)}
+	case time.Time:
+		return map[string]any{"type": "datetime", "value": value.Format(time.RFC3339Nano)}
+	case toml.LocalDateTime:
+		return map[string]any{"type": "datetime-local", "value": value.String()}
+	case toml.LocalDate:
+		return map[string]any{"type": "date-local", "value": value.String()}
+	case toml.LocalTime:
+		return map[string]any{"type": "time-local", "value": value.String()}
+	default:
+		return fmt.Sprintf("unexpected %T", value)
+	}
+}
+
+// taggedEqual reports whether a parsed value matches the tagged JSON want, comparing
+// floats and offset date-times by value rather than by how they're written.
+func taggedEqual(got, want any) bool {
+	switch want := want.(type) {
+	case []any:
+		array, ok := got.([]any)
+		if !ok || len(array) != len(want) {
+			return false
+		}
+
+		for i := range want {
+			if !taggedEqual(array[i], want[i]) {
+				return false
+			}
+		}
+
+		return true
+	case map[string]any:
+		if typ, ok := want["type"].(string); ok && len(want) == 2 {
+			if text, ok := want["value"].(string); ok {
+				return scalarEqual(got, typ, text)
+			}
+		}
+
+		table, ok := got.(map[string]any)
+		if !ok || len(table) != len(want) {
+			return false
+		}
+
+		for key, value := range want {
+			if !taggedEqual(table[key], value) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+// scalarEqual reports whether got matches the tagged scalar of type typ written as text.
+func scalarEqual(got any, typ, text string) bool {
+	switch typ {
+	case "float":
+		f, ok := got.(float64)
+		want, err := strconv.ParseFloat(text, 64)
+
+		return ok && err == nil && (f == want || math.IsNaN(f) && math.IsNaN(want)) && math.Signbit(f) == math.Signbit(want)
+	case "datetime":
+		t, ok := got.(time.Time)
+		want, err := time.Parse(time.RFC3339Nano, text)
+
+		return ok && err == nil && t.Equal(want)
+	default:
+		return reflect.DeepEqual(tag(got), map[string]any{"type": typ, "value": text})
+	}
+}
+
+type server struct {
+	Addr    netip.Addr    `toml:"addr"`
+	Name    string        `toml:"name"`
+	Ignored string        `toml:"-"`
+	Tags    []string      `toml:"tags"`
+	Timeout time.Duration `toml:"timeout"`
+	Weight  float32       `toml:"weight"`
+	Port    uint16        `toml:"port"`
+}
+
+type config struct {
+	Started  time.Time            `toml:"started"`
+	Labels   map[string]string    `toml:"labels"`
+	Owner    *owner               `toml:"owner"`
+	Limits   map[string]any       `toml:"limits"`
+	Title    string               // No tag, matched on the field name
+	Servers  []server             `toml:"servers"`
+	Backups  [2]toml.LocalTime    `toml:"backups"`
+	Expires  toml.LocalDate       `toml:"expires"`
+	Nested   map[string]*server   `toml:"nested"`
+	Matrix   [][]int              `toml:"matrix"`
+	Versions map[string][]float64 `toml:"versions"`
+	Debug    bool                 `toml:"debug"`
+}
+
+type owner struct {
+	Name string
+	Born time.Time
+}
+
+func TestUnmarshal(t *testing.T) {
+	src := `
+title = "Example"
+debug = true
+started = 2024-03-15T09:30:00Z
+expires = 2025-01-01
+backups = [01:00:00, 13:30:00]
+matrix = [[1, 2], [3]]
+labels = { env = "prod", "team name" = "core" }
+limits.cpu = 2
+limits.memory = "4Gi"
+versions.go = [1.22, 1]
+
+[owner]
+name = "Tom"
+born = 1979-05-27
+
+[[servers]]
+name = "alpha"
+addr = "10.0.0.1"
+port = 8080
+tags = ["a", "b"]
+timeout = "1.5s"
+weight = 0.5
+Ignored = "yes"
+unknown = "skipped"
+
+[[servers]]
+name = "beta"
+
+[nested.gamma]
+port = 1
+`
+
+	var got config
+	if err := toml.Unmarshal(src, &got); err != nil {
+		t.Fatalf("Unmarshal returned an unexpected error: %v", err)
+	}
+
+	want := config{
+		Title:   "Example",
+		Debug:   true,
+		Started: time.Date(2024, time.March, 15, 9, 30, 0, 0, time.UTC),
+		Expires: toml.LocalDate{Year: 2025, Month: time.January, Day: 1},
+		Backups: [2]toml.LocalTime{{Hour: 1}, {Hour: 13, Minute: 30}},
+		Matrix:  [][]int{{1, 2}, {3}},
+		Labels:  map[string]string{"env": "prod", "team name": "core"},
+		Limits:  map[string]any{"cpu": int64(2), "memory": "4Gi"},
+		Owner: &owner{
+			Name: "Tom",
+			Born: time.Date(1979, time.May, 27, 0, 0, 0, 0, time.Local),
+		},
+		Versions: map[string][]float64{"go": {1.22, 1}},
+		Servers: []server{
+			{
+				Name:    "alpha",
+				Addr:    netip.MustParseAddr("10.0.0.1"),
+				Port:    8080,
+				Tags:    []string{"a", "b"},
+				Timeout: 1500 * time.Millisecond,
+				Weight:  0.5,
+			},
+			{Name: "beta"},
+		},
+		Nested: map[string]*server{"gamma": {Port: 1}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nGot:\t%+v\nWanted:\t%+v\n", got, want)
+	}
+}
+
+func TestUnmarshalAny(t *testing.T) {
+	src := "a = 1\n[b]\nc = [{d = 2}]\n[[e]]"
+
+	var got any
+	if err := toml.Unmarshal(src, &got); err != nil {
+		t.Fatalf("Unmarshal returned an unexpected error: %v", err)
+	}
+
+	want, err := toml.Parse(src)
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, any(want)) {
+		t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got, want)
+	}
+}
+
+type embedded struct {
+	Inner string `toml:"inner"`
+}
+
+// Base is exported so it can be allocated when embedded by pointer.
+type Base struct {
+	Inner string `toml:"inner"`
+}
+
+func TestUnmarshalEmbedded(t *testing.T) {
+	type unexported struct {
+		*embedded
+
+		Outer string `toml:"outer"`
+	}
+
+	var got unexported
+	if err := toml.Unmarshal("inner = 'a'\nouter = 'b'", &got); err != nil {
+		t.Fatalf("Unmarshal returned an unexpected error: %v", err)
+	}
+
+	// The embedded struct is unexported so can't be allocated, and its fields are skipped
+	if got.embedded != nil || got.Outer != "b" {
+		t.Errorf("Got %+v, wanted only Outer set", got)
+	}
+
+	type exported struct {
+		*Base
+
+		Outer string `toml:"outer"`
+	}
+
+	var exp exported
+	if err := toml.Unmarshal("inner = 'a'\nouter = 'b'", &exp); err != nil {
+		t.Fatalf("Unmarshal returned an unexpected error: %v", err)
+	}
+
+	if exp.Base == nil || exp.Inner != "a" || exp.Outer != "b" {
+		t.Errorf("Got %+v, wanted the embedded struct allocated and both fields set", exp)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		target any    // What to unmarshal into
+		name   string // Identifying test case name
+		input  string // Document to parse
+		err    string // The expected error message
+	}{
+		{
+			name:   "not a pointer",
+			input:  "a = 1",
+			target: config{},
+			err:    "toml: Unmarshal requires a non-nil pointer, got toml_test.config",
+		},
+		{
+			name:   "nil pointer",
+			input:  "a = 1",
+			target: (*config)(nil),
+			err:    "toml: Unmarshal requires a non-nil pointer, got *toml_test.config",
+		},
+		{
+			name:   "syntax error",
+			input:  "title = ",
+			target: &config{},
+			err:    "toml: unexpected end of input, expected a value at line 1, column 9",
+		},
+		{
+			name:   "wrong type",
+			input:  "\ntitle = 1",
+			target: &config{},
+			err:    "toml: cannot decode title into string at line 2, column 1: value is of type integer",
+		},
+		{
+			name:   "wrong type in array of tables",
+			input:  "[[servers]]\n[[servers]]\nport = 'x'",
+			target: &config{},
+			err:    "toml: cannot decode servers[1].port into uint16 at line 3, column 1: value is of type string",
+		},
+		{
+			name:   "wrong type in array",
+			input:  "matrix = [[1], ['a']]",
+			target: &config{},
+			err:    "toml: cannot decode matrix[1][0] into int at line 1, column 1: value is of type string",
+		},
+		{
+			name:   "table into scalar",
+			input:  "[title]",
+			target: &config{},
+			err:    "toml: cannot decode title into string at line 1, column 2: value is of type table",
+		},
+		{
+			name:   "scalar into struct",
+			input:  "owner = 1",
+			target: &config{},
+			err:    "toml: cannot decode owner into toml_test.owner at line 1, column 1: value is of type integer",
+		},
+		{
+			name:   "overflow",
+			input:  "[[servers]]\nport = 70000",
+			target: &config{},
+			err:    "toml: cannot decode servers[0].port into uint16 at line 2, column 1: 70000 overflows uint16",
+		},
+		{
+			name:   "negative unsigned",
+			input:  "[[servers]]\nport = -1",
+			target: &config{},
+			err:    "toml: cannot decode servers[0].port into uint16 at line 2, column 1: -1 overflows uint16",
+		},
+		{
+			name:   "array too long",
+			input:  "backups = [01:00:00, 02:00:00, 03:00:00]",
+			target: &config{},
+			err:    "toml: cannot decode backups into [2]toml.LocalTime at line 1, column 1: array has 3 elements",
+		},
+		{
+			name:   "local time into date",
+			input:  "expires = 10:00:00",
+			target: &config{},
+			err:    "toml: cannot decode expires into toml.LocalDate at line 1, column 1: value is of type local time",
+		},
+		{
+			name:   "local time into time",
+			input:  "started = 10:00:00",
+			target: &config{},
+			err:    "toml: cannot decode started into time.Time at line 1, column 1: value is of type local time",
+		},
+		{
+			name:   "bad duration",
+			input:  "[[servers]]\ntimeout = 'soon'",
+			target: &config{},
+			err:    `toml: cannot decode servers[0].timeout into time.Duration at line 2, column 1: time: invalid duration "soon"`,
+		},
+		{
+			name:   "text unmarshaler",
+			input:  "[[servers]]\naddr = 'nope'",
+			target: &config{},
+			err:    `toml: cannot decode servers[0].addr into netip.Addr at line 2, column 1: ParseAddr("nope"): unable to parse IP`,
+		},
+		{
+			name:   "non string map key",
+			input:  "a = {b = 1}",
+			target: &map[string]map[int]int{},
+			err:    "toml: cannot decode a into map[int]int at line 1, column 1: map keys must be strings",
+		},
+		{
+			name:   "document into scalar",
+			input:  "a = 1",
+			target: new(int),
+			err:    "toml: cannot decode document into int at line 1, column 1: value is of type table",
+		},
+		{
+			name:   "unsupported",
+			input:  "a = 1",
+			target: &map[string]chan int{},
+			err:    "toml: cannot decode a into chan int at line 1, column 1: unsupported type",
+		},
+		{
+			name:   "quoted key",
+			input:  "labels.'a.b' = 1",
+			target: &config{},
+			err:    `toml: cannot decode labels."a.b" into string at line 1, column 8: value is of type integer`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := toml.Unmarshal(tt.input, tt.target)
+			if err == nil {
+				t.Fatal("Unmarshal returned nil error, wanted one")
+			}
+
+			if msg := err.Error(); msg != tt.err {
+				t.Errorf("\nError message:\t%q\nWanted:\t\t%q\n", msg, tt.err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrorUnwrap(t *testing.T) {
+	var got config
+
+	err := toml.Unmarshal("[[servers]]\ntimeout = 1", &got)
+
+	var unmarshalErr *toml.UnmarshalError
+	if !errors.As(err, &unmarshalErr) {
+		t.Fatalf("Error was not a *toml.UnmarshalError, got %T", err)
+	}
+
+	if unmarshalErr.Key != "servers[0].timeout" || unmarshalErr.Line != 2 || unmarshalErr.Offset != 12 {
+		t.Errorf("Got %+v, wanted key servers[0].timeout at line 2, offset 12", unmarshalErr)
+	}
+
+	if errors.Unwrap(err) == nil {
+		t.Error("UnmarshalError did not wrap the underlying error")
+	}
+}
+
+func ExampleParse() {
+	doc, err := toml.Parse(`
+[server]
+host = "localhost"
+ports = [8000, 8001]
+`)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Println(doc["server"])
+
+	// Output: map[host:localhost ports:[8000 8001]]
+}
+
+func ExampleUnmarshal() {
+	type Config struct {
+		Name    string        `toml:"name"`
+		Tags    []string      `toml:"tags"`
+		Timeout time.Duration `toml:"timeout"`
+		Retries int           `toml:"retries"`
+	}
+
+	var cfg Config
+
+	err := toml.Unmarshal(`
+name = "build"
+tags = ["ci", "nightly"]
+timeout = "90s"
+retries = 3
+`, &cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Printf("%+v\n", cfg)
+
+	// Output: {Name:build Tags:[ci nightly] Timeout:1m30s Retries:3}
+}
+
+func ExampleSyntaxError() {
+	_, err := toml.Parse(`
+[package]
+name = "parser"
+
+[package]
+version = "1.0.0"
+`)
+
+	var syntaxErr *toml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		fmt.Printf("line %d, column %d: %s\n", syntaxErr.Line, syntaxErr.Column, syntaxErr.Msg)
+	}
+
+	// Output: line 5, column 2: duplicate table package, first defined on line 2
+}
@@ -0,0 +1,479 @@
+package toml
+
+import (
+	"cmp"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UnmarshalError is the error returned by [Unmarshal] when a value cannot be decoded into
+// the Go value for its key.
+type UnmarshalError struct {
+	Err    error        // The underlying error from decoding the value
+	Type   reflect.Type // The type of the Go value
+	Key    string       // The full dotted key of the value, with the index of any array elements, empty for the document itself
+	Offset int          // Byte offset in the input of the key
+	Line   int          // 1 indexed line number of Offset
+	Column int          // 1 indexed column (in utf-8 chars) of Offset
+}
+
+// Error implements the error interface for [UnmarshalError].
+func (e *UnmarshalError) Error() string {
+	key := e.Key
+	if key == "" {
+		key = "document"
+	}
+
+	return fmt.Sprintf("toml: cannot decode %s into %s at line %d, column %d: %v", key, e.Type, e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying decoding error.
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal parses a TOML document and stores the values in the Go value pointed to by v.
+//
+// Tables decode into structs or maps with string keys. Each key/value pair in a table is
+// stored in the exported struct field whose `toml` tag matches the key, fields without a tag
+// match a key equal to the field name ignoring case, and fields tagged `toml:"-"` are ignored.
+// Keys with no matching field are skipped.
+//
+// Arrays, including arrays of tables, decode into slices or arrays. Strings, booleans,
+// integers and floats decode into the Go types of the same kind as long as the value fits,
+// and integers can also decode into floats. Offset date-times decode into a [time.Time], as
+// do local date-times and dates, which are taken to be in [time.Local]. The local types
+// also decode into [LocalDateTime], [LocalDate] and [LocalTime].
+//
+// Strings can also decode into a [time.Duration] (parsed with [time.ParseDuration]), or
+// anything implementing [encoding.TextUnmarshaler]. Anything decoded into an interface{}
+// is converted as described in the package documentation.
+//
+// Syntax errors are a [*SyntaxError], and values that can't be decoded into their Go value
+// are an [*UnmarshalError].
+func Unmarshal(src string, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("toml: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+
+	root, err := parse(src)
+	if err != nil {
+		return err
+	}
+
+	d := decoder{src: src}
+
+	return d.decode(root, target.Elem(), "", 0)
+}
+
+// decoder holds the state needed to decode a particular document.
+type decoder struct {
+	src string // The entire document, for error positions
+}
+
+// element is a value with the offset of its key, for error positions.
+type element struct {
+	value  any // The value
+	offset int // Where its key is in the source
+}
+
+// fail returns a new [UnmarshalError] for the value at key.
+func (d *decoder) fail(key string, offset int, typ reflect.Type, err error) error {
+	unmarshalErr := &UnmarshalError{Err: err, Type: typ, Key: key, Offset: offset}
+	unmarshalErr.Line, unmarshalErr.Column = position(d.src, offset)
+
+	return unmarshalErr
+}
+
+// decode stores value into target, key and offset are used to locate any errors.
+func (d *decoder) decode(value any, target reflect.Value, key string, offset int) error {
+	for target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+
+		target = target.Elem()
+	}
+
+	if text, ok := value.(string); ok && target.CanAddr() {
+		if unmarshaler, ok := target.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := unmarshaler.UnmarshalText([]byte(text)); err != nil {
+				return d.fail(key, offset, target.Type(), err)
+			}
+
+			return nil
+		}
+	}
+
+	var err error
+
+	switch target.Type() {
+	case localDateTimeType, localDateType, localTimeType:
+		err = decodeLocal(value, target)
+	case timeType:
+		err = decodeTime(value, target)
+	case durationType:
+		err = decodeDuration(value, target)
+	default:
+		switch target.Kind() {
+		case reflect.Interface:
+			err = decodeInterface(value, target)
+		case reflect.Struct:
+			return d.decodeStruct(value, target, key, offset)
+		case reflect.Map:
+			return d.decodeMap(value, target, key, offset)
+		case reflect.Slice, reflect.Array:
+			return d.decodeArray(value, target, key, offset)
+		default:
+			err = decodeScalar(value, target)
+		}
+	}
+
+	if err != nil {
+		return d.fail(key, offset, target.Type(), err)
+	}
+
+	return nil
+}
+
+// decodeStruct decodes a table into the fields of a struct.
+func (d *decoder) decodeStruct(value any, target reflect.Value, key string, offset int) error {
+	tbl, ok := value.(*table)
+	if !ok {
+		return d.fail(key, offset, target.Type(), wrongType(value))
+	}
+
+	fields := fieldsFor(target.Type())
+
+	for _, name := range tbl.keys() {
+		index, ok := fields.lookup(name)
+		if !ok {
+			continue
+		}
+
+		field, ok := fieldByIndex(target, index)
+		if !ok {
+			continue
+		}
+
+		if err := d.decode(tbl.values[name], field, join(key, name), tbl.offsets[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeMap decodes a table into a map with string keys.
+func (d *decoder) decodeMap(value any, target reflect.Value, key string, offset int) error {
+	typ := target.Type()
+
+	tbl, ok := value.(*table)
+	switch {
+	case !ok:
+		return d.fail(key, offset, typ, wrongType(value))
+	case typ.Key().Kind() != reflect.String:
+		return d.fail(key, offset, typ, errors.New("map keys must be strings"))
+	}
+
+	if target.IsNil() {
+		target.Set(reflect.MakeMapWithSize(typ, len(tbl.values)))
+	}
+
+	for _, name := range tbl.keys() {
+		elem := reflect.New(typ.Elem()).Elem()
+		if err := d.decode(tbl.values[name], elem, join(key, name), tbl.offsets[name]); err != nil {
+			return err
+		}
+
+		target.SetMapIndex(reflect.ValueOf(name).Convert(typ.Key()), elem)
+	}
+
+	return nil
+}
+
+// decodeArray decodes an array, or an array of tables, into a slice or array.
+func (d *decoder) decodeArray(value any, target reflect.Value, key string, offset int) error {
+	var elements []element
+
+	switch value := value.(type) {
+	case []any:
+		for _, v := range value {
+			elements = append(elements, element{value: v, offset: offset})
+		}
+	case *tableArray:
+		for _, tbl := range value.tables {
+			elements = append(elements, element{value: tbl, offset: tbl.offset})
+		}
+	default:
+		return d.fail(key, offset, target.Type(), wrongType(value))
+	}
+
+	if target.Kind() == reflect.Slice {
+		target.Set(reflect.MakeSlice(target.Type(), len(elements), len(elements)))
+	} else {
+		if len(elements) > target.Len() {
+			return d.fail(key, offset, target.Type(), fmt.Errorf("array has %d elements", len(elements)))
+		}
+
+		target.SetZero()
+	}
+
+	for i, elem := range elements {
+		if err := d.decode(elem.value, target.Index(i), fmt.Sprintf("%s[%d]", key, i), elem.offset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeInterface decodes any value into an interface{}.
+func decodeInterface(value any, target reflect.Value) error {
+	if target.NumMethod() != 0 {
+		return errors.New("unsupported type")
+	}
+
+	target.Set(reflect.ValueOf(toAny(value)))
+
+	return nil
+}
+
+// decodeLocal decodes a local date-time, date or time into the type of the same name.
+func decodeLocal(value any, target reflect.Value) error {
+	if reflect.TypeOf(value) != target.Type() {
+		return wrongType(value)
+	}
+
+	target.Set(reflect.ValueOf(value))
+
+	return nil
+}
+
+// decodeTime decodes a date-time or date into a [time.Time].
+func decodeTime(value any, target reflect.Value) error {
+	var t time.Time
+
+	switch value := value.(type) {
+	case time.Time:
+		t = value
+	case LocalDateTime:
+		t = value.In(time.Local)
+	case LocalDate:
+		t = value.In(time.Local)
+	default:
+		return wrongType(value)
+	}
+
+	target.Set(reflect.ValueOf(t))
+
+	return nil
+}
+
+// decodeDuration decodes a string into a [time.Duration].
+func decodeDuration(value any, target reflect.Value) error {
+	text, ok := value.(string)
+	if !ok {
+		return wrongType(value)
+	}
+
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+
+	target.SetInt(int64(duration))
+
+	return nil
+}
+
+// decodeScalar decodes a string, boolean, integer or float into a Go value of the same kind.
+func decodeScalar(value any, target reflect.Value) error {
+	switch target.Kind() {
+	case reflect.String:
+		text, ok := value.(string)
+		if !ok {
+			return wrongType(value)
+		}
+
+		target.SetString(text)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return wrongType(value)
+		}
+
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(int64)
+		switch {
+		case !ok:
+			return wrongType(value)
+		case target.OverflowInt(n):
+			return fmt.Errorf("%d overflows %s", n, target.Type())
+		}
+
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := value.(int64)
+		switch {
+		case !ok:
+			return wrongType(value)
+		case n < 0 || target.OverflowUint(uint64(n)):
+			return fmt.Errorf("%d overflows %s", n, target.Type())
+		}
+
+		target.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		var f float64
+
+		switch value := value.(type) {
+		case float64:
+			f = value
+		case int64:
+			f = float64(value)
+		default:
+			return wrongType(value)
+		}
+
+		if target.OverflowFloat(f) {
+			return fmt.Errorf("%g overflows %s", f, target.Type())
+		}
+
+		target.SetFloat(f)
+	default:
+		return errors.New("unsupported type")
+	}
+
+	return nil
+}
+
+// wrongType returns the error for a value of a type that can't be decoded.
+func wrongType(value any) error {
+	return fmt.Errorf("value is of type %s", typeName(value))
+}
+
+// fieldByIndex returns the field of target at index, allocating any nil pointers to
+// embedded structs along the way. It reports false if the field can't be set, like
+// one promoted through a nil pointer to an unexported struct.
+func fieldByIndex(target reflect.Value, index []int) (reflect.Value, bool) {
+	field := target
+	for i, n := range index {
+		if i > 0 && field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				if !field.CanSet() {
+					return reflect.Value{}, false
+				}
+
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+
+			field = field.Elem()
+		}
+
+		field = field.Field(n)
+	}
+
+	return field, field.CanSet()
+}
+
+// join appends name to a dotted key.
+func join(key, name string) string {
+	if key == "" {
+		return quoteKey(name)
+	}
+
+	return key + "." + quoteKey(name)
+}
+
+// keys returns the keys of the table in the order they were defined, so decoding
+// reports the first error in the document.
+func (t *table) keys() []string {
+	keys := make([]string, 0, len(t.values))
+	for key := range t.values {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Compare(t.offsets[a], t.offsets[b])
+	})
+
+	return keys
+}
+
+var (
+	// localDateTimeType is the [reflect.Type] of [LocalDateTime].
+	localDateTimeType = reflect.TypeFor[LocalDateTime]()
+
+	// localDateType is the [reflect.Type] of [LocalDate].
+	localDateType = reflect.TypeFor[LocalDate]()
+
+	// localTimeType is the [reflect.Type] of [LocalTime].
+	localTimeType = reflect.TypeFor[LocalTime]()
+
+	// timeType is the [reflect.Type] of [time.Time], which is decoded specially.
+	timeType = reflect.TypeFor[time.Time]()
+
+	// durationType is the [reflect.Type] of [time.Duration], which is decoded specially.
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// fieldSet maps keys to the index of the struct field they decode into.
+type fieldSet struct {
+	tagged map[string][]int // Fields with an explicit tag, matched exactly
+	named  map[string][]int // Untagged fields by lower case name, matched ignoring case
+}
+
+// lookup returns the index of the field for key.
+func (f *fieldSet) lookup(key string) ([]int, bool) {
+	if index, ok := f.tagged[key]; ok {
+		return index, true
+	}
+
+	index, ok := f.named[strings.ToLower(key)]
+
+	return index, ok
+}
+
+// fieldCache caches the fieldSet for each struct type passed to [Unmarshal].
+var fieldCache sync.Map // map[reflect.Type]*fieldSet
+
+// fieldsFor returns the fieldSet for a struct type, building it if this is the first time
+// we've seen it.
+func fieldsFor(typ reflect.Type) *fieldSet {
+	if cached, ok := fieldCache.Load(typ); ok {
+		return cached.(*fieldSet) //nolint:forcetypeassert // Only fieldSets are stored
+	}
+
+	fields := &fieldSet{
+		tagged: make(map[string][]int),
+		named:  make(map[string][]int),
+	}
+
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("toml")
+		switch {
+		case tag == "-":
+			continue
+		case ok && tag != "":
+			fields.tagged[tag] = field.Index
+		default:
+			fields.named[strings.ToLower(field.Name)] = field.Index
+		}
+	}
+
+	cached, _ := fieldCache.LoadOrStore(typ, fields)
+
+	return cached.(*fieldSet) //nolint:forcetypeassert // Only fieldSets are stored
+}
@@ -0,0 +1,85 @@
+package uri
+
+import (
+	"errors"
+	"strings"
+)
+
+// Prose parses a URL as it would typically be written in ordinary text from the start of input.
+//
+// It's more forgiving than [Parse] about where a URL ends, but pickier about what counts
+// as one at all:
+//
+//   - There must be an authority with a non-empty host (as in "https://example.com"), so a
+//     word followed by a colon isn't mistaken for a URI.
+//   - Trailing punctuation like '.', ',', ':', '!' and '?' ends the sentence rather than the
+//     URL, so it's left in the remainder, as is a trailing ')' with no matching '(' in the URL.
+//   - Anything after the authority that [Parse] would reject, like a malformed
+//     percent-encoding, ends the URL instead of being an error.
+//
+// The returned [URI] is exactly what [Parse] returns for the text that was matched.
+func Prose(input string) (URI, string, error) {
+	end, ok := proseEnd(input)
+	if !ok {
+		return URI{}, "", errNoURL
+	}
+
+	candidate := trimPunctuation(input[:end])
+
+	u, rest, err := Parse(candidate)
+	if err != nil || rest != "" || !u.Authority.Defined || u.Host.Start == u.Host.End {
+		return URI{}, "", errNoURL
+	}
+
+	return u, input[len(candidate):], nil
+}
+
+// proseEnd returns where a URL in prose at the start of input ends according to the strict
+// grammar, ignoring errors past the authority.
+func proseEnd(input string) (int, bool) {
+	s := scan{input: input, rest: input}
+
+	// The scheme and the "//" are checked here to keep the failure cheap, this is
+	// called at every position in the input when searching through text
+	name, rest, err := scheme(input)
+	if err != nil || !isAlpha(rune(name[0])) || !strings.HasPrefix(rest, "://") {
+		return 0, false
+	}
+
+	authorityStart := len(name) + len("://")
+
+	u, err := s.uri(true)
+	if err == nil {
+		return len(u.Raw), true
+	}
+
+	var syntaxErr *Error
+	if errors.As(err, &syntaxErr) && syntaxErr.Offset > authorityStart {
+		return syntaxErr.Offset, true
+	}
+
+	return 0, false
+}
+
+// trimPunctuation trims the punctuation from the end of a URL that most likely belongs
+// to the surrounding text.
+func trimPunctuation(url string) string {
+	opened := strings.Count(url, "(")
+	closed := strings.Count(url, ")")
+
+	for {
+		switch last := url[len(url)-1]; {
+		case strings.IndexByte(".,;:!?'*", last) != -1:
+			url = url[:len(url)-1]
+		case last == ')' && opened < closed:
+			url = url[:len(url)-1]
+			closed--
+		default:
+			return url
+		}
+	}
+}
+
+// errNoURL is the preallocated error for when the input doesn't start with a URL, see the
+// others in uri.go.
+var errNoURL = &Error{Msg: "expected a URL", Offset: 0}
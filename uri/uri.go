@@ -0,0 +1,526 @@
+// Package uri implements prefix parsers for URIs following the strict grammar in RFC 3986,
+// built on the combinators in [parser].
+//
+// Unlike [net/url.Parse], which is deliberately lenient and needs to be handed exactly the
+// text of a URL, the parsers in this package recognise a URI at the start of their input and
+// return the remainder, so they can find where a URI ends inside larger text:
+//
+//	for match, err := range parser.FindAll(uri.Prose, "see https://example.com/docs.") {
+//		...
+//	}
+//
+// Each parser is a plain function with the [parser.Parser] signature, so they can be passed
+// directly to any combinator.
+//
+// Rather than copying or decoding anything, a [URI] records where each of its components
+// is as a [Span] of the input, use [URI.Text] to get the raw text of a component and
+// [Decode] to percent-decode it.
+package uri // import "go.followtheprocess.codes/parser/uri"
+
+import (
+	"fmt"
+	"strings"
+
+	"go.followtheprocess.codes/parser"
+	"go.followtheprocess.codes/parser/netaddr"
+)
+
+// Error is the error returned by all the parsers in this package, and by [Decode].
+type Error struct {
+	// Msg describes what went wrong.
+	Msg string
+
+	// Offset is the byte offset into the input where the problem was found.
+	Offset int
+}
+
+// Error implements the error interface for *Error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("uri: offset %d: %s", e.Offset, e.Msg)
+}
+
+// Span is the location of a component of a [URI], as byte offsets into the input it was
+// parsed from (and so also into [URI.Raw]).
+type Span struct {
+	// Start is the offset of the first byte of the component.
+	Start int
+
+	// End is the offset just past the last byte of the component.
+	End int
+
+	// Defined is whether the component is present at all. This is the distinction RFC 3986
+	// makes between an empty component like the query in "http://example.com/?" and a missing
+	// one like the query in "http://example.com/".
+	Defined bool
+}
+
+// HostKind is the kind of host in the authority of a [URI].
+type HostKind int
+
+const (
+	// RegName is a registered name, usually a DNS name like example.com. An empty host is
+	// also a (zero length) registered name.
+	RegName HostKind = iota
+
+	// IPv4 is a dotted decimal IPv4 address like 127.0.0.1.
+	IPv4
+
+	// IPv6 is an IPv6 address in square brackets like [::1].
+	IPv6
+
+	// IPvFuture is an address in square brackets for some future version of IP,
+	// like [v7.fe80::a+en1].
+	IPvFuture
+)
+
+// String implements [fmt.Stringer] for [HostKind].
+func (k HostKind) String() string {
+	switch k {
+	case RegName:
+		return "RegName"
+	case IPv4:
+		return "IPv4"
+	case IPv6:
+		return "IPv6"
+	case IPvFuture:
+		return "IPvFuture"
+	default:
+		return fmt.Sprintf("HostKind(%d)", int(k))
+	}
+}
+
+// URI is a URI (or relative reference) parsed from some input.
+//
+// The components are spans of the input, none of them are decoded. The Authority,
+// UserInfo, Host and Port are only defined if the URI has an authority (the part after
+// "//"), and the Scheme is always defined unless the URI came from [ParseReference].
+// The Path is always defined, although it may be empty.
+type URI struct {
+	// Raw is the text of the whole URI, exactly as it appeared in the input.
+	Raw string
+
+	Scheme    Span
+	Authority Span
+	UserInfo  Span
+
+	// Host is the host in the authority. An IP literal includes the surrounding brackets.
+	Host Span
+	Port Span
+	Path Span
+
+	// Query excludes the leading '?'.
+	Query Span
+
+	// Fragment excludes the leading '#'.
+	Fragment Span
+
+	// HostKind is the kind of Host, if there is one.
+	HostKind HostKind
+}
+
+// Text returns the raw text of a component of the URI, or "" if it isn't defined.
+func (u URI) Text(component Span) string {
+	if !component.Defined {
+		return ""
+	}
+
+	return u.Raw[component.Start:component.End]
+}
+
+// String returns the URI exactly as it appeared in the input.
+func (u URI) String() string {
+	return u.Raw
+}
+
+// Parse parses an absolute URI (the "URI" rule in RFC 3986) from the start of input.
+//
+// A URI ends at the first char that can't continue it, so parsing stops at whitespace, quotes,
+// angle brackets and the like. It's an error for a URI to be followed by a char that could
+// appear elsewhere in a URI but not at that point, like the '@' in http://a@b@c, as are
+// malformed percent-encodings, IP literals and the like.
+//
+// Note that RFC 3986 allows a lot: "note:" on its own is a perfectly valid URI, with a
+// scheme of "note" and an empty path. To pick URLs out of ordinary text, see [Prose].
+func Parse(input string) (URI, string, error) {
+	s := scan{input: input, rest: input}
+
+	u, err := s.uri(true)
+	if err != nil {
+		return URI{}, "", err
+	}
+
+	return u, s.rest, nil
+}
+
+// ParseReference parses a URI reference (the "URI-reference" rule in RFC 3986) from the
+// start of input. This is either an absolute URI as accepted by [Parse], or a relative
+// reference like ../images/logo.png?size=2 whose Scheme is undefined.
+//
+// An empty relative reference is valid, so ParseReference succeeds without consuming
+// anything if input doesn't start with a URI reference at all.
+func ParseReference(input string) (URI, string, error) {
+	s := scan{input: input, rest: input}
+
+	u, err := s.uri(false)
+	if err != nil {
+		return URI{}, "", err
+	}
+
+	return u, s.rest, nil
+}
+
+// Decode returns text with all the percent-encoded octets like %20 decoded.
+//
+// No other transformation is done, notably a '+' is left as it is. A '%' that isn't followed
+// by 2 hex digits is an error, but this can't happen for any component of a parsed [URI].
+// The decoded octets are not guaranteed to be valid utf-8.
+func Decode(text string) (string, error) {
+	if strings.IndexByte(text, '%') == -1 {
+		return text, nil
+	}
+
+	if i := badPercent(text); i != -1 {
+		return "", &Error{Msg: fmt.Sprintf("invalid percent-encoding %q", text[i:min(i+3, len(text))]), Offset: i}
+	}
+
+	var decoded strings.Builder
+	decoded.Grow(len(text))
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '%' {
+			decoded.WriteByte(text[i])
+			continue
+		}
+
+		decoded.WriteByte(unhex(text[i+1])<<4 | unhex(text[i+2]))
+		i += 2
+	}
+
+	return decoded.String(), nil
+}
+
+// scan tracks the progress of parsing a URI through its input, so that errors and spans
+// can report where they are.
+type scan struct {
+	input string // The entire input to the parser
+	rest  string // The input still left to parse
+}
+
+// offset returns the byte offset of the scan into the input.
+func (s *scan) offset() int {
+	return len(s.input) - len(s.rest)
+}
+
+// errorf returns an [*Error] at the given offset.
+func (s *scan) errorf(offset int, format string, args ...any) error {
+	return &Error{Msg: fmt.Sprintf(format, args...), Offset: offset}
+}
+
+// span returns the defined [Span] from start to the current offset.
+func (s *scan) span(start int) Span {
+	return Span{Start: start, End: s.offset(), Defined: true}
+}
+
+// run parses a possibly empty run of chars with the given parser, checking any
+// percent-encodings in it, and returns its span.
+func (s *scan) run(chars parser.Parser[string]) (Span, error) {
+	start := s.offset()
+
+	text, rest, err := chars(s.rest)
+	if err != nil {
+		// Nothing matched, which is just an empty run
+		return s.span(start), nil //nolint:nilerr // An empty run is fine
+	}
+
+	if i := badPercent(text); i != -1 {
+		return Span{}, s.errorf(start+i, "invalid percent-encoding %q", text[i:min(i+3, len(text))])
+	}
+
+	s.rest = rest
+
+	return s.span(start), nil
+}
+
+// skip consumes the given delimiter if the input starts with it, reporting whether it did.
+func (s *scan) skip(delimiter parser.Parser[string]) bool {
+	_, rest, err := delimiter(s.rest)
+	if err != nil {
+		return false
+	}
+
+	s.rest = rest
+
+	return true
+}
+
+// uri parses a URI, or a URI reference if requireScheme is false.
+func (s *scan) uri(requireScheme bool) (URI, error) {
+	var u URI
+
+	if name, rest, err := scheme(s.rest); err == nil && isAlpha(rune(name[0])) {
+		if _, afterColon, err := colon(rest); err == nil {
+			u.Scheme = Span{Start: 0, End: len(name), Defined: true}
+			s.rest = afterColon
+		}
+	}
+
+	if requireScheme && !u.Scheme.Defined {
+		return URI{}, errNoURI
+	}
+
+	var err error
+
+	switch {
+	case s.skip(doubleSlash):
+		if err = s.authority(&u); err != nil {
+			return URI{}, err
+		}
+
+		// path-abempty: nothing, or a '/' and then anything
+		u.Path = s.span(s.offset())
+		if _, _, slashErr := slash(s.rest); slashErr == nil {
+			u.Path, err = s.run(pathChars)
+		}
+	case u.Scheme.Defined:
+		// path-absolute, path-rootless or path-empty, having ruled out "//" these are all
+		// just a run of path chars
+		u.Path, err = s.run(pathChars)
+	default:
+		// path-absolute, path-noscheme or path-empty, so the first segment (if there is
+		// one) can't contain a ':', otherwise it would look like a scheme
+		start := s.offset()
+		if _, err = s.run(firstSegmentChars); err == nil {
+			if _, _, slashErr := slash(s.rest); slashErr == nil {
+				_, err = s.run(pathChars)
+			}
+		}
+		u.Path = s.span(start)
+	}
+
+	if err != nil {
+		return URI{}, err
+	}
+
+	if s.skip(question) {
+		if u.Query, err = s.run(queryChars); err != nil {
+			return URI{}, err
+		}
+	}
+
+	if s.skip(hash) {
+		if u.Fragment, err = s.run(queryChars); err != nil {
+			return URI{}, err
+		}
+	}
+
+	// A URI ends at the first char that can't continue it, if that's a char that could have
+	// been in a URI somewhere else, something is malformed rather than finished
+	if next, _, err := uriChar(s.rest); err == nil {
+		return URI{}, s.errorf(s.offset(), "unexpected %q", next)
+	}
+
+	u.Raw = s.input[:s.offset()]
+
+	return u, nil
+}
+
+// authority parses the authority of a URI, after the "//".
+func (s *scan) authority(u *URI) error {
+	start := s.offset()
+
+	// Userinfo is the only part of the authority that can't be told apart until
+	// the '@' at the end of it
+	info, _, _ := userInfoChars(s.rest)
+	if _, afterAt, err := at(s.rest[len(info):]); err == nil {
+		if u.UserInfo, err = s.run(userInfoChars); err != nil {
+			return err
+		}
+		s.rest = afterAt
+	}
+
+	hostStart := s.offset()
+
+	if s.skip(openBracket) {
+		contents, _, _ := literalChars(s.rest)
+		s.rest = s.rest[len(contents):]
+
+		if !s.skip(closeBracket) {
+			return s.errorf(s.offset(), "expected ']' after IP literal")
+		}
+
+		kind, err := ipLiteral(contents)
+		if err != nil {
+			return s.errorf(hostStart+1, "%v", err)
+		}
+
+		u.HostKind = kind
+		u.Host = s.span(hostStart)
+	} else {
+		host, err := s.run(regNameChars)
+		if err != nil {
+			return err
+		}
+
+		u.Host = host
+
+		// RFC 3986 resolves the ambiguity with a registered name in favour of IPv4,
+		// but only if the whole host is an IPv4 address
+		if _, rest, err := netaddr.IPv4(s.input[host.Start:host.End]); err == nil && rest == "" {
+			u.HostKind = IPv4
+		}
+	}
+
+	if s.skip(colon) {
+		port, _ := s.run(digits)
+		u.Port = port
+	}
+
+	u.Authority = s.span(start)
+
+	return nil
+}
+
+// ipLiteral checks the contents of an IP literal, returning what kind of host it is.
+func ipLiteral(literal string) (HostKind, error) {
+	if literal != "" && (literal[0] == 'v' || literal[0] == 'V') {
+		// IPvFuture: "v" 1*HEXDIG "." 1*( unreserved / sub-delims / ":" )
+		version, rest, err := hexDigits(literal[1:])
+		if err != nil || version == "" || len(rest) < 2 || rest[0] != '.' {
+			return 0, fmt.Errorf("invalid IPvFuture address %q", literal)
+		}
+
+		return IPvFuture, nil
+	}
+
+	// The literal can't contain a '%', so there's no zone to worry about
+	if _, rest, err := netaddr.IPv6(literal); err != nil || rest != "" {
+		return 0, fmt.Errorf("invalid IPv6 address %q", literal)
+	}
+
+	return IPv6, nil
+}
+
+// badPercent returns the index of the first '%' in text that isn't followed by 2 hex
+// digits, or -1 if they're all fine.
+func badPercent(text string) int {
+	for i := 0; i < len(text); i++ {
+		if text[i] != '%' {
+			continue
+		}
+
+		if i+2 >= len(text) || !isHex(rune(text[i+1])) || !isHex(rune(text[i+2])) {
+			return i
+		}
+
+		i += 2
+	}
+
+	return -1
+}
+
+// unhex returns the value of a hex digit.
+func unhex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// isAlpha reports whether r is an ASCII letter.
+func isAlpha(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// isDigit reports whether r is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isHex reports whether r is an ASCII hex digit.
+func isHex(r rune) bool {
+	return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// isUnreserved reports whether r is in the "unreserved" set from RFC 3986.
+func isUnreserved(r rune) bool {
+	return isAlpha(r) || isDigit(r) || r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+// isSubDelim reports whether r is in the "sub-delims" set from RFC 3986.
+func isSubDelim(r rune) bool {
+	return strings.ContainsRune("!$&'()*+,;=", r)
+}
+
+// isSchemeChar reports whether r can be part of a scheme (after the first char, which
+// must be a letter).
+func isSchemeChar(r rune) bool {
+	return isAlpha(r) || isDigit(r) || r == '+' || r == '-' || r == '.'
+}
+
+// isRegNameChar reports whether r can be part of a registered name.
+func isRegNameChar(r rune) bool {
+	return isUnreserved(r) || isSubDelim(r) || r == '%'
+}
+
+// isUserInfoChar reports whether r can be part of the userinfo in an authority.
+func isUserInfoChar(r rune) bool {
+	return isRegNameChar(r) || r == ':'
+}
+
+// isLiteralChar reports whether r can be part of the contents of an IP literal.
+func isLiteralChar(r rune) bool {
+	return isUnreserved(r) || isSubDelim(r) || r == ':'
+}
+
+// isFirstSegmentChar reports whether r can be part of the first segment of a relative
+// reference without an authority, which is any path char except ':'.
+func isFirstSegmentChar(r rune) bool {
+	return isRegNameChar(r) || r == '@'
+}
+
+// isPathChar reports whether r can be part of a path, "pchar" from RFC 3986 plus '/'.
+func isPathChar(r rune) bool {
+	return isFirstSegmentChar(r) || r == ':' || r == '/'
+}
+
+// isQueryChar reports whether r can be part of a query or fragment.
+func isQueryChar(r rune) bool {
+	return isPathChar(r) || r == '?'
+}
+
+// isURIChar reports whether r can appear anywhere in a URI.
+func isURIChar(r rune) bool {
+	return isQueryChar(r) || r == '#' || r == '[' || r == ']'
+}
+
+var (
+	scheme            = parser.TakeWhile(isSchemeChar)
+	userInfoChars     = parser.TakeWhile(isUserInfoChar)
+	regNameChars      = parser.TakeWhile(isRegNameChar)
+	literalChars      = parser.TakeWhile(isLiteralChar)
+	firstSegmentChars = parser.TakeWhile(isFirstSegmentChar)
+	pathChars         = parser.TakeWhile(isPathChar)
+	queryChars        = parser.TakeWhile(isQueryChar)
+	digits            = parser.TakeWhile(isDigit)
+	hexDigits         = parser.TakeWhile(isHex)
+
+	// uriChar parses any single char that can appear in a URI.
+	uriChar = parser.TakeWhileBetween(1, 1, isURIChar)
+
+	doubleSlash  = parser.Exact("//")
+	slash        = parser.Char('/')
+	colon        = parser.Char(':')
+	at           = parser.Char('@')
+	question     = parser.Char('?')
+	hash         = parser.Char('#')
+	openBracket  = parser.Char('[')
+	closeBracket = parser.Char(']')
+)
+
+// errNoURI is the error for when the input doesn't start with anything like a URI, this is
+// the common case when searching through text, so it's preallocated.
+var errNoURI = &Error{Msg: "expected a URI", Offset: 0}
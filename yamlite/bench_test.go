@@ -0,0 +1,80 @@
+package yamlite_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/yamlite"
+)
+
+// benchConfig is a deployment config with many similar services.
+var benchConfig = func() string {
+	builder := &strings.Builder{}
+	builder.WriteString(`# A deployment config
+version: "3.8"
+name: parser
+settings:
+  parallel: true
+  jobs: 8
+services:
+`)
+
+	for i := range 200 {
+		fmt.Fprintf(builder, `  - name: service-%d
+    image: 'registry.example.com/service:%d'
+    ports:
+      - 80%02d:80
+      - "443"
+    environment:
+      LOG_LEVEL: debug   # noisy
+      REGION: eu-west-1
+    command: >
+      serve --port 80
+      --verbose
+    healthcheck:
+      test: |
+        curl -f http://localhost/health
+        exit 0
+      retries: 3
+
+`, i, i, i%100)
+	}
+
+	return builder.String()
+}()
+
+// benchDeep is a single sequence nested to the max depth.
+var benchDeep = strings.Repeat("- ", yamlite.MaxDepth) + "x"
+
+func BenchmarkParse(b *testing.B) {
+	b.SetBytes(int64(len(benchConfig)))
+
+	for b.Loop() {
+		if _, err := yamlite.Parse(benchConfig); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseAny(b *testing.B) {
+	b.SetBytes(int64(len(benchConfig)))
+
+	for b.Loop() {
+		doc, err := yamlite.Parse(benchConfig)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = doc.Any()
+	}
+}
+
+func BenchmarkParseDeep(b *testing.B) {
+	b.SetBytes(int64(len(benchDeep)))
+
+	for b.Loop() {
+		if _, err := yamlite.Parse(benchDeep); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package yamlite_test
+
+// The fuzz tests in here check that the parser never panics, and that any document it
+// accepts comes out the same after writing it back out as YAML and parsing it again.
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/yamlite"
+)
+
+var corpus = [...]string{
+	"",
+	"scalar",
+	"a: 1\nb:\n  - x\n  - 'y'\n",
+	"- name: a\n  size: 1\n- - nested\n  - \"seq\"\n",
+	"key:\n- same column\nother: |\n  literal\n  text\n",
+	"folded: >-\n  one\n  two\n\n  three\n",
+	"# comment\n---\n日本: 語 # trailing\n",
+	"a: \"\\u00e9\\t\\x41\"\r\nb: 'it''s'\r\n",
+	"a:\n    b: 1\n  c: 2\n",
+	"a: 1\n\tb: 2\n",
+}
+
+func FuzzParse(f *testing.F) {
+	for _, item := range corpus {
+		f.Add(item)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, err := yamlite.Parse(input)
+		if err != nil {
+			return
+		}
+
+		encoded := encode(got.Any())
+
+		again, err := yamlite.Parse(encoded)
+		if err != nil {
+			t.Fatalf("Parse failed on the encoding of %q\nEncoded:\t%q\nError:\t%v\n", input, encoded, err)
+		}
+
+		if !reflect.DeepEqual(again.Any(), got.Any()) {
+			t.Fatalf("Round trip of %q changed it\nEncoded:\t%q\nBefore:\t%#v\nAfter:\t%#v\n", input, encoded, got.Any(), again.Any())
+		}
+	})
+}
+
+// encode writes a value from Node.Any back out as YAML, with every scalar double quoted.
+func encode(value any) string {
+	builder := &strings.Builder{}
+	if scalar, ok := value.(string); ok {
+		builder.WriteString(strconv.Quote(scalar))
+	} else {
+		encodeBlock(builder, value, 0)
+	}
+
+	return builder.String()
+}
+
+// encodeBlock writes a mapping or sequence as a block at indent.
+func encodeBlock(builder *strings.Builder, value any, indent int) {
+	switch value := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			builder.WriteString(strings.Repeat(" ", indent) + strconv.Quote(key) + ":")
+			encodeValue(builder, value[key], indent)
+		}
+	case []any:
+		for _, item := range value {
+			builder.WriteString(strings.Repeat(" ", indent) + "-")
+			encodeValue(builder, item, indent)
+		}
+	}
+}
+
+// encodeValue writes the value of a mapping entry or sequence entry at indent.
+func encodeValue(builder *strings.Builder, value any, indent int) {
+	switch value := value.(type) {
+	case nil:
+		builder.WriteString("\n")
+	case string:
+		builder.WriteString(" " + strconv.Quote(value) + "\n")
+	default:
+		builder.WriteString("\n")
+		encodeBlock(builder, value, indent+2)
+	}
+}
@@ -0,0 +1,782 @@
+package yamlite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// grammar holds the state needed to parse a particular document.
+type grammar struct {
+	indent *parser.Indentation // The columns of the enclosing blocks
+	src    string              // The entire document
+	depth  int                 // Current nesting depth of mappings and sequences
+}
+
+// offset returns the byte offset in the source of a suffix of it.
+func (g *grammar) offset(rest string) int {
+	return len(g.src) - len(rest)
+}
+
+// column returns the 0 indexed column in the source of a suffix of it.
+func (g *grammar) column(rest string) int {
+	// Can't fail, everything the grammar looks at is a suffix of the source
+	column, _ := g.indent.Column(rest)
+	return column
+}
+
+// fail returns a new [SyntaxError] at the start of rest.
+func (g *grammar) fail(rest, format string, args ...any) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Offset: g.offset(rest)}
+}
+
+// unexpected returns a [SyntaxError] describing the unexpected char at the start
+// of rest (or the unexpected end of input).
+func (g *grammar) unexpected(rest, context string) error {
+	if rest == "" {
+		return g.fail(rest, "unexpected end of input, %s", context)
+	}
+
+	return g.fail(rest, "unexpected %q, %s", firstChar(rest), context)
+}
+
+// document parses the whole document.
+func (g *grammar) document() (Node, error) {
+	rest := skipBlank(g.src)
+
+	if isMarker(rest, "---") {
+		var err error
+		if rest, err = g.lineEnd(rest[len("---"):]); err != nil {
+			return Node{}, err
+		}
+		rest = skipBlank(rest)
+	}
+
+	content, err := g.indentation(rest)
+	if err != nil {
+		return Node{}, err
+	}
+
+	if content == "" {
+		return Node{Kind: KindNull, Span: Span{Start: g.offset(content), End: g.offset(content)}}, nil
+	}
+
+	top := content // Start of the top level node
+
+	node, rest, err := g.node(top)
+	if err != nil {
+		return Node{}, err
+	}
+
+	if content, err = g.indentation(rest); err != nil {
+		return Node{}, err
+	}
+
+	switch {
+	case content == "":
+		return node, nil
+	case isMarker(content, "---") || isMarker(content, "..."):
+		return Node{}, g.fail(content, "multiple documents aren't supported")
+	case g.column(content) > g.column(top):
+		return Node{}, g.fail(content, "unexpected indentation")
+	default:
+		return Node{}, g.unexpected(content, "expected end of document")
+	}
+}
+
+// node parses a block node, input must start at its first char rather than any indentation.
+func (g *grammar) node(input string) (Node, string, error) {
+	switch {
+	case isSequenceEntry(input):
+		return g.sequence(input)
+	case input[0] == '|' || input[0] == '>':
+		return g.blockScalar(input)
+	}
+
+	if _, _, err := g.key(input); err == nil {
+		return g.mapping(input)
+	}
+
+	return g.scalar(input)
+}
+
+// sequence parses a block sequence, one entry per line in the same column.
+func (g *grammar) sequence(input string) (Node, string, error) {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if g.depth > MaxDepth {
+		return Node{}, "", g.fail(input, "exceeded max nesting depth of %d", MaxDepth)
+	}
+
+	column := g.column(input)
+
+	var failed error // Why the block ended, if it was because an entry failed
+
+	items, rest, err := parser.Block(g.indent, recording(g.sequenceEntry, &failed))(input)
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	if err := g.blockEnd(rest, column, isSequenceEntry, failed); err != nil {
+		return Node{}, "", err
+	}
+
+	node := Node{
+		Kind:  KindSequence,
+		Items: items,
+		Span:  Span{Start: g.offset(input), End: items[len(items)-1].Span.End},
+	}
+
+	return node, rest, nil
+}
+
+// sequenceEntry parses a single entry in a block sequence, the - and its value.
+func (g *grammar) sequenceEntry(input string) (Node, string, error) {
+	if !isSequenceEntry(input) {
+		return Node{}, "", g.unexpected(input, "expected '-' starting a sequence entry")
+	}
+
+	return g.value(input[1:], false)
+}
+
+// mapping parses a block mapping, one entry per line in the same column.
+func (g *grammar) mapping(input string) (Node, string, error) {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if g.depth > MaxDepth {
+		return Node{}, "", g.fail(input, "exceeded max nesting depth of %d", MaxDepth)
+	}
+
+	column := g.column(input)
+
+	var failed error // Why the block ended, if it was because an entry failed
+
+	entries, rest, err := parser.Block(g.indent, recording(g.entry, &failed))(input)
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	isEntry := func(s string) bool {
+		return !isSequenceEntry(s) && !isMarker(s, "---") && !isMarker(s, "...")
+	}
+	if err := g.blockEnd(rest, column, isEntry, failed); err != nil {
+		return Node{}, "", err
+	}
+
+	seen := make(map[string]Span, len(entries))
+	for _, entry := range entries {
+		if first, ok := seen[entry.Key]; ok {
+			line, _ := position(g.src, first.Start)
+			return Node{}, "", g.fail(g.src[entry.KeySpan.Start:], "duplicate key %q, first defined on line %d", entry.Key, line)
+		}
+		seen[entry.Key] = entry.KeySpan
+	}
+
+	node := Node{
+		Kind:    KindMapping,
+		Entries: entries,
+		Span:    Span{Start: g.offset(input), End: entries[len(entries)-1].Value.Span.End},
+	}
+
+	return node, rest, nil
+}
+
+// entry parses a single entry in a block mapping, the key, the : and its value.
+func (g *grammar) entry(input string) (Entry, string, error) {
+	key, rest, err := g.key(input)
+	if err != nil {
+		return Entry{}, "", err
+	}
+
+	keySpan := Span{Start: g.offset(input), End: g.offset(rest)}
+
+	// Skip the ':', key has checked it's there
+	rest = strings.TrimLeft(rest, " \t")[1:]
+
+	value, rest, err := g.value(rest, true)
+	if err != nil {
+		return Entry{}, "", err
+	}
+
+	return Entry{Key: key, Value: value, KeySpan: keySpan}, rest, nil
+}
+
+// blockEnd checks what's left after a block in column has ended. If the next line is indented
+// further, or in the same column and looks like another item, it's an error, otherwise the
+// line belongs to an enclosing block. failed is the error from the item that ended the block,
+// if there was one.
+func (g *grammar) blockEnd(rest string, column int, isItem func(string) bool, failed error) error {
+	content, err := g.indentation(rest)
+	if err != nil || content == "" {
+		return err
+	}
+
+	switch next := g.column(content); {
+	case next > column:
+		return g.fail(content, "unexpected indentation")
+	case next == column && isItem(content) && failed != nil:
+		// The block stopped because the item on this line failed
+		return failed
+	}
+
+	return nil
+}
+
+// recording wraps the item parser of a block, keeping the last error it returned in failed,
+// as [parser.Block] ends quietly when an item fails.
+func recording[T any](item parser.Parser[T], failed *error) parser.Parser[T] {
+	return func(input string) (T, string, error) {
+		value, rest, err := item(input)
+		if err != nil {
+			*failed = err
+		}
+
+		return value, rest, err
+	}
+}
+
+// key parses a mapping key, quoted or plain, returning the rest of the input from just after
+// the key, up to and including any whitespace before its ':'.
+func (g *grammar) key(input string) (string, string, error) {
+	var (
+		key  string
+		rest string
+		err  error
+	)
+
+	switch input[0] {
+	case '"':
+		key, rest, err = g.doubleQuoted(input)
+	case '\'':
+		key, rest, err = g.singleQuoted(input)
+	default:
+		if err := g.plainStart(input); err != nil {
+			return "", "", err
+		}
+
+		end := keyEnd(input)
+		if end < 0 {
+			return "", "", g.fail(input, "expected a mapping key followed by ':'")
+		}
+
+		key = strings.TrimRight(input[:end], " \t")
+		return key, input[len(key):], nil
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if after := strings.TrimLeft(rest, " \t"); !isIndicator(after, ':') {
+		return "", "", g.unexpected(after, "expected ':' after mapping key")
+	}
+
+	return key, rest, nil
+}
+
+// value parses the value after a sequence entry's - or a mapping key's :, which is either on
+// the same line or nested under it on the lines that follow.
+//
+// inMapping is whether it's the value of a mapping entry, which can't be a collection on the
+// same line, but can be a sequence in the same column as its key on the lines that follow.
+func (g *grammar) value(input string, inMapping bool) (Node, string, error) {
+	start := g.offset(input)
+	rest := strings.TrimLeft(input, " \t")
+
+	if !isLineEnd(rest) && rest[0] != '#' {
+		switch {
+		case !inMapping:
+			return g.node(rest)
+		case isSequenceEntry(rest):
+			return Node{}, "", g.fail(rest, "block sequences can't start on the same line as their key")
+		case rest[0] == '|' || rest[0] == '>':
+			return g.blockScalar(rest)
+		default:
+			return g.scalar(rest)
+		}
+	}
+
+	rest, err := g.lineEnd(input)
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	rest = skipBlank(rest)
+
+	content, err := g.indentation(rest)
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	if content != "" {
+		switch column, level := g.column(content), g.indent.Level(); {
+		case column > level:
+			return parser.Indented(g.indent, g.node)(rest)
+		case inMapping && column == level && isSequenceEntry(content):
+			return g.sequence(content)
+		}
+	}
+
+	return Node{Kind: KindNull, Span: Span{Start: start, End: start}}, rest, nil
+}
+
+// scalar parses a single line quoted or plain scalar, and the end of its line.
+func (g *grammar) scalar(input string) (Node, string, error) {
+	var (
+		text  string
+		rest  string
+		style Style
+		err   error
+	)
+
+	switch input[0] {
+	case '"':
+		style = StyleDoubleQuoted
+		text, rest, err = g.doubleQuoted(input)
+	case '\'':
+		style = StyleSingleQuoted
+		text, rest, err = g.singleQuoted(input)
+	default:
+		style = StylePlain
+		text, rest, err = g.plain(input)
+	}
+
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	node := Node{
+		Kind:  KindScalar,
+		Value: text,
+		Style: style,
+		Span:  Span{Start: g.offset(input), End: g.offset(rest)},
+	}
+
+	if rest, err = g.lineEnd(rest); err != nil {
+		return Node{}, "", err
+	}
+
+	return node, skipBlank(rest), nil
+}
+
+// plainStart checks input can start a plain scalar, which can't start with most of the
+// indicator chars.
+func (g *grammar) plainStart(input string) error {
+	switch c := input[0]; {
+	case c == '[' || c == '{':
+		return g.fail(input, "flow collections aren't supported")
+	case c == '&' || c == '*' || c == '!':
+		return g.fail(input, "anchors, aliases and tags aren't supported")
+	case c == '%':
+		return g.fail(input, "directives aren't supported")
+	case c == '\t':
+		return g.fail(input, "tabs aren't allowed in indentation")
+	case c == '?' && isIndicator(input, '?'):
+		return g.fail(input, "complex keys aren't supported")
+	case c == ':' && isIndicator(input, ':'),
+		c == '-' && isIndicator(input, '-'),
+		strings.IndexByte("]},#|>'\"@`", c) >= 0:
+		return g.unexpected(input, "expected a scalar")
+	}
+
+	return nil
+}
+
+// plain parses a plain scalar, which runs up to the end of the line or a comment.
+func (g *grammar) plain(input string) (string, string, error) {
+	if err := g.plainStart(input); err != nil {
+		return "", "", err
+	}
+
+	end := 0
+	for ; end < len(input); end++ {
+		c := input[end]
+		if c == '\n' || c == '\r' || (c == '#' && (input[end-1] == ' ' || input[end-1] == '\t')) {
+			break
+		}
+
+		if isIndicator(input[end:], ':') {
+			return "", "", g.fail(input[end:], "mapping values aren't allowed here")
+		}
+	}
+
+	text := strings.TrimRight(input[:end], " \t")
+
+	return text, input[len(text):], nil
+}
+
+// doubleQuoted parses a double quoted scalar on a single line, unescaping it.
+func (g *grammar) doubleQuoted(input string) (string, string, error) {
+	builder := &strings.Builder{}
+	rest := input[1:] // Skip the opening quote
+
+	for {
+		chunk, after, _ := doubleQuotedChars(rest)
+		builder.WriteString(chunk)
+		rest = after
+
+		switch {
+		case rest == "" || rest[0] == '\n' || rest[0] == '\r':
+			return "", "", g.fail(input, "unterminated double quoted scalar")
+		case rest[0] == '"':
+			return builder.String(), rest[1:], nil
+		default:
+			char, after, err := g.escape(rest)
+			if err != nil {
+				return "", "", err
+			}
+			builder.WriteRune(char)
+			rest = after
+		}
+	}
+}
+
+// escapes maps the chars after a backslash to the char they stand for, the unicode escapes
+// are handled separately.
+var escapes = map[byte]rune{
+	'0':  0,
+	'a':  '\a',
+	'b':  '\b',
+	't':  '\t',
+	'\t': '\t',
+	'n':  '\n',
+	'v':  '\v',
+	'f':  '\f',
+	'r':  '\r',
+	'e':  0x1b,
+	' ':  ' ',
+	'"':  '"',
+	'/':  '/',
+	'\\': '\\',
+	'N':  0x85,
+	'_':  0xa0,
+	'L':  0x2028,
+	'P':  0x2029,
+}
+
+// escape parses an escape sequence in a double quoted scalar, starting at the backslash.
+func (g *grammar) escape(input string) (rune, string, error) {
+	if len(input) < 2 {
+		return 0, "", g.fail(input, "unterminated escape")
+	}
+
+	if char, ok := escapes[input[1]]; ok {
+		return char, input[2:], nil
+	}
+
+	var digits int
+	switch input[1] {
+	case 'x':
+		digits = 2
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	default:
+		return 0, "", g.fail(input, "invalid escape %q", "\\"+string(firstChar(input[1:])))
+	}
+
+	hex, rest, err := parser.Take(digits)(input[2:])
+	if err != nil || strings.Trim(hex, hexDigits) != "" {
+		return 0, "", g.fail(input, "invalid escape, expected %d hex digits after \\%c", digits, input[1])
+	}
+
+	n, _ := strconv.ParseUint(hex, 16, 32)
+	if char := rune(n); utf8.ValidRune(char) {
+		return char, rest, nil
+	}
+
+	return 0, "", g.fail(input, "invalid escape, %s is not a unicode char", input[:2+digits])
+}
+
+// singleQuoted parses a single quoted scalar on a single line, where ” stands for a single quote.
+func (g *grammar) singleQuoted(input string) (string, string, error) {
+	builder := &strings.Builder{}
+	rest := input[1:] // Skip the opening quote
+
+	for {
+		chunk, after, _ := singleQuotedChars(rest)
+		builder.WriteString(chunk)
+		rest = after
+
+		switch {
+		case rest == "" || rest[0] == '\n' || rest[0] == '\r':
+			return "", "", g.fail(input, "unterminated single quoted scalar")
+		case strings.HasPrefix(rest, "''"):
+			builder.WriteByte('\'')
+			rest = rest[2:]
+		default:
+			return builder.String(), rest[1:], nil
+		}
+	}
+}
+
+// chomping is what a block scalar does with the line breaks at its end.
+type chomping int
+
+const (
+	clip  chomping = iota // Keep a single line break, the default
+	strip                 // Remove them all, with a - indicator
+	keep                  // Keep them all, with a + indicator
+)
+
+// blockScalar parses a literal (|) or folded (>) block scalar, from its indicator to the last
+// line of its content.
+func (g *grammar) blockScalar(input string) (Node, string, error) {
+	style := StyleLiteral
+	if input[0] == '>' {
+		style = StyleFolded
+	}
+
+	rest := input[1:]
+
+	chomp := clip
+	if rest != "" {
+		switch c := rest[0]; {
+		case c == '-':
+			chomp, rest = strip, rest[1:]
+		case c == '+':
+			chomp, rest = keep, rest[1:]
+		case isDigit(c):
+			return Node{}, "", g.fail(rest, "explicit block scalar indentation isn't supported")
+		}
+	}
+
+	end := g.offset(rest) // End of the last line with content, or the indicators if there isn't one
+
+	rest, err := g.lineEnd(rest)
+	if err != nil {
+		return Node{}, "", err
+	}
+
+	parent := g.indent.Level() // Content must be indented beyond the enclosing block
+	indentation := -1          // Indentation of the content, set by its first line
+	var lines []string         // The lines of content, with the indentation removed
+	for rest != "" {
+		line, after := cutLine(rest)
+
+		spaces := len(line) - len(strings.TrimLeft(line, " "))
+		if spaces == len(line) {
+			lines = append(lines, "")
+			rest = after
+			continue
+		}
+
+		if indentation < 0 {
+			if spaces <= parent {
+				break
+			}
+			indentation = spaces
+		}
+
+		if spaces < indentation {
+			break
+		}
+
+		lines = append(lines, line[indentation:])
+		end = g.offset(rest) + len(line)
+		rest = after
+	}
+
+	// Trailing blank lines are only kept with the keep indicator
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+	lines = lines[:len(lines)-trailing]
+
+	var text string
+	if style == StyleLiteral {
+		text = strings.Join(lines, "\n")
+	} else {
+		text = fold(lines)
+	}
+
+	switch {
+	case chomp == keep:
+		if len(lines) != 0 {
+			trailing++
+		}
+		text += strings.Repeat("\n", trailing)
+	case chomp == clip && len(lines) != 0:
+		text += "\n"
+	}
+
+	node := Node{
+		Kind:  KindScalar,
+		Value: text,
+		Style: style,
+		Span:  Span{Start: g.offset(input), End: end},
+	}
+
+	return node, skipBlank(rest), nil
+}
+
+// fold joins the lines of a folded block scalar, a single line break between two lines becomes a
+// space, but a run of them keeps all but the first. Lines that are indented further than the rest
+// aren't folded.
+func fold(lines []string) string {
+	builder := &strings.Builder{}
+
+	blank := 0 // Blank lines since the last line with content
+	for i, line := range lines {
+		if line == "" {
+			blank++
+			continue
+		}
+
+		switch {
+		case i == blank:
+			// Leading blank lines are all kept
+			builder.WriteString(strings.Repeat("\n", blank))
+		case blank > 0:
+			builder.WriteString(strings.Repeat("\n", blank))
+		case line[0] == ' ' || lines[i-1][0] == ' ':
+			builder.WriteByte('\n')
+		default:
+			builder.WriteByte(' ')
+		}
+
+		builder.WriteString(line)
+		blank = 0
+	}
+
+	return builder.String()
+}
+
+// lineEnd parses the end of a line after a value, any whitespace, an optional comment, and the
+// line break (or the end of the input).
+func (g *grammar) lineEnd(input string) (string, error) {
+	rest := strings.TrimLeft(input, " \t")
+
+	if rest != "" && rest[0] == '#' {
+		if len(rest) == len(input) {
+			return "", g.fail(rest, "comments must be separated from values by whitespace")
+		}
+		_, rest = cutLine(rest)
+		return rest, nil
+	}
+
+	switch {
+	case rest == "":
+		return "", nil
+	case rest[0] == '\n':
+		return rest[1:], nil
+	case strings.HasPrefix(rest, "\r\n"):
+		return rest[2:], nil
+	default:
+		return "", g.unexpected(rest, "expected end of line")
+	}
+}
+
+// indentation skips the spaces at the start of a line, returning the content after them.
+func (g *grammar) indentation(line string) (string, error) {
+	content := strings.TrimLeft(line, " ")
+	if content != "" && content[0] == '\t' {
+		return "", g.fail(content, "tabs aren't allowed in indentation")
+	}
+
+	return content, nil
+}
+
+// hexDigits are the digits allowed in a unicode escape.
+const hexDigits = "0123456789abcdefABCDEF"
+
+var (
+	// doubleQuotedChars parses a run of chars inside a double quoted scalar other than the
+	// closing quote, an escape or a line break.
+	doubleQuotedChars = parser.SkipMany(parser.NoneOf("\"\\\r\n"))
+
+	// singleQuotedChars parses a run of chars inside a single quoted scalar other than a quote
+	// or a line break.
+	singleQuotedChars = parser.SkipMany(parser.NoneOf("'\r\n"))
+)
+
+// skipBlank skips any lines that are empty or hold only whitespace and comments, returning
+// the input from the start of the next line with content on it.
+func skipBlank(input string) string {
+	for input != "" {
+		rest := strings.TrimLeft(input, " \t")
+		if rest != "" && rest[0] == '#' {
+			rest = rest[strings.IndexByte(rest+"\n", '\n'):]
+		}
+
+		switch {
+		case rest == "":
+			return rest
+		case rest[0] == '\n':
+			input = rest[1:]
+		case strings.HasPrefix(rest, "\r\n"):
+			input = rest[2:]
+		default:
+			return input
+		}
+	}
+
+	return input
+}
+
+// cutLine splits input into its first line, without the line break, and everything after
+// the line break.
+func cutLine(input string) (line, rest string) {
+	line, rest, _ = strings.Cut(input, "\n")
+	return strings.TrimSuffix(line, "\r"), rest
+}
+
+// keyEnd returns the byte offset of the : ending the plain mapping key at the start of input,
+// or -1 if the line doesn't have one.
+func keyEnd(input string) int {
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; {
+		case c == '\n' || c == '\r':
+			return -1
+		case c == '#' && i > 0 && (input[i-1] == ' ' || input[i-1] == '\t'):
+			return -1
+		case isIndicator(input[i:], ':'):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isIndicator reports whether s starts with the indicator char c, followed by whitespace or
+// the end of the line, like the - of a sequence entry or the : after a mapping key.
+func isIndicator(s string, c byte) bool {
+	if s == "" || s[0] != c {
+		return false
+	}
+
+	return len(s) == 1 || s[1] == ' ' || s[1] == '\t' || isLineEnd(s[1:])
+}
+
+// isSequenceEntry reports whether s starts with the - of a sequence entry.
+func isSequenceEntry(s string) bool {
+	return isIndicator(s, '-')
+}
+
+// isMarker reports whether s starts with a document marker, like --- or ..., on its own.
+func isMarker(s, marker string) bool {
+	return strings.HasPrefix(s, marker) && (len(s) == len(marker) || strings.IndexByte(" \t\r\n", s[len(marker)]) >= 0)
+}
+
+// isLineEnd reports whether s is at the end of a line.
+func isLineEnd(s string) bool {
+	return s == "" || s[0] == '\n' || strings.HasPrefix(s, "\r\n")
+}
+
+// firstChar returns the first utf-8 char in s.
+func firstChar(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// isDigit reports whether c is an ascii digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,227 @@
+// Package yamlite implements a parser for a small subset of [YAML], block mappings and sequences
+// of scalars, built on the indentation combinators in [parser].
+//
+// It exists as a reference grammar showing how [parser.Block] and [parser.Indented] handle an
+// indentation sensitive format, and is enough for the simple configuration files YAML is mostly
+// used for:
+//
+//	name: parser
+//	tags:
+//	  - go
+//	  - "parsing"
+//	authors:
+//	  - name: Tom
+//	    email: tom@example.com
+//	description: |
+//	  Parser combinators
+//	  for Go.
+//
+// Supported are block mappings and sequences (including sequences in the same column as their
+// mapping key, and mappings that start on the same line as their sequence entry), plain, single
+// quoted and double quoted scalars on a single line, literal (|) and folded (>) block scalars,
+// comments and a leading --- document marker.
+//
+// Flow collections ([a, b] and {a: b}), anchors, aliases, tags, directives, complex keys,
+// multi-line plain and quoted scalars, explicit block scalar indentation and multiple documents
+// aren't supported and are reported as errors, as are duplicate keys and tabs in indentation.
+//
+// Scalars are kept as the strings they were written as, with their [Style], as resolving them
+// into numbers, booleans and so on is left to the caller.
+//
+// [YAML]: https://yaml.org/spec/1.2.2/
+package yamlite // import "go.followtheprocess.codes/parser/yamlite"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/parser"
+)
+
+// MaxDepth is the maximum nesting depth of mappings and sequences that [Parse] will accept, this
+// stops deeply nested (and likely malicious) input from exhausting the stack.
+const MaxDepth = 10000
+
+// Kind is the kind of a YAML [Node].
+type Kind int
+
+const (
+	KindNull     Kind = iota // An empty value, like the value of a key with nothing after it
+	KindScalar               // A scalar, in any Style
+	KindSequence             // A block sequence
+	KindMapping              // A block mapping
+)
+
+// String implements [fmt.Stringer] for [Kind].
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindScalar:
+		return "scalar"
+	case KindSequence:
+		return "sequence"
+	case KindMapping:
+		return "mapping"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Style is the way a scalar was written.
+type Style int
+
+const (
+	StylePlain        Style = iota // Unquoted, like key: value
+	StyleSingleQuoted              // In single quotes, like key: 'value'
+	StyleDoubleQuoted              // In double quotes, with escapes, like key: "value\n"
+	StyleLiteral                   // A literal block scalar, like key: |
+	StyleFolded                    // A folded block scalar, like key: >
+)
+
+// String implements [fmt.Stringer] for [Style].
+func (s Style) String() string {
+	switch s {
+	case StylePlain:
+		return "plain"
+	case StyleSingleQuoted:
+		return "single quoted"
+	case StyleDoubleQuoted:
+		return "double quoted"
+	case StyleLiteral:
+		return "literal"
+	case StyleFolded:
+		return "folded"
+	default:
+		return fmt.Sprintf("Style(%d)", int(s))
+	}
+}
+
+// Span is a half open range of byte offsets [Start, End) into the parsed input.
+type Span struct {
+	Start int // Byte offset of the first byte
+	End   int // Byte offset one past the last byte
+}
+
+// Node is a single parsed YAML node.
+//
+// Only the fields relevant to the node's [Kind] are populated, e.g. a [KindScalar] will only
+// have Value and Style set.
+type Node struct {
+	Value   string  // The text of a scalar, after unescaping, folding and chomping
+	Items   []Node  // The entries of a sequence
+	Entries []Entry // The entries of a mapping, in the order they appeared
+	Span    Span    // Where in the input the node came from
+	Kind    Kind    // The kind of node
+	Style   Style   // How a scalar was written
+}
+
+// Entry is a single key value pair in a YAML mapping.
+type Entry struct {
+	Key     string // The key, after unescaping
+	Value   Node   // The value
+	KeySpan Span   // Where in the input the key came from, including any quotes
+}
+
+// Any converts the [Node] into plain Go values, i.e. nil, string, []any or map[string]any.
+//
+// Scalars become strings whatever their [Style], so "1" and 1 are both the string 1.
+func (n Node) Any() any {
+	switch n.Kind {
+	case KindNull:
+		return nil
+	case KindScalar:
+		return n.Value
+	case KindSequence:
+		items := make([]any, 0, len(n.Items))
+		for _, item := range n.Items {
+			items = append(items, item.Any())
+		}
+		return items
+	case KindMapping:
+		mapping := make(map[string]any, len(n.Entries))
+		for _, entry := range n.Entries {
+			mapping[entry.Key] = entry.Value.Any()
+		}
+		return mapping
+	default:
+		return nil
+	}
+}
+
+// SyntaxError is the error returned when the input is not a valid document, or uses part of YAML
+// this package doesn't support.
+type SyntaxError struct {
+	Msg    string // Description of the problem
+	Offset int    // Byte offset in the input at which the error occurred
+	Line   int    // 1 indexed line number of Offset
+	Column int    // 1 indexed column (in utf-8 chars) of Offset
+}
+
+// Error implements the error interface for [SyntaxError].
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("yamlite: %s at line %d, column %d", e.Msg, e.Line, e.Column)
+}
+
+// Parse parses a complete YAML document.
+//
+// An empty document (or one with only comments) is a [KindNull] node. Any error returned will
+// be a [*SyntaxError].
+func Parse(src string) (Node, error) {
+	if !utf8.ValidString(src) {
+		return Node{}, newSyntaxError(src, invalidUTF8Offset(src), "input not valid utf-8")
+	}
+
+	g := &grammar{indent: parser.NewIndentation(src), src: src}
+
+	node, err := g.document()
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			// Shouldn't happen, everything in the grammar returns a SyntaxError
+			syntaxErr = &SyntaxError{Msg: err.Error()}
+		}
+
+		syntaxErr.locate(src)
+
+		return Node{}, syntaxErr
+	}
+
+	return node, nil
+}
+
+// newSyntaxError builds a [SyntaxError] at offset into src.
+func newSyntaxError(src string, offset int, msg string) *SyntaxError {
+	err := &SyntaxError{Msg: msg, Offset: offset}
+	err.locate(src)
+
+	return err
+}
+
+// locate fills in the Line and Column of the error from its Offset into src.
+func (e *SyntaxError) locate(src string) {
+	e.Line, e.Column = position(src, e.Offset)
+}
+
+// position returns the 1 indexed line and column of offset into src.
+func position(src string, offset int) (line, column int) {
+	before := src[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	return 1 + strings.Count(before, "\n"), 1 + utf8.RuneCountInString(before[lineStart:])
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid utf-8 sequence in s.
+func invalidUTF8Offset(s string) int {
+	for pos, char := range s {
+		if char == utf8.RuneError {
+			if _, width := utf8.DecodeRuneInString(s[pos:]); width == 1 {
+				return pos
+			}
+		}
+	}
+
+	return len(s)
+}
@@ -0,0 +1,491 @@
+package yamlite_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/parser/yamlite"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string // Identifying test case name
+		input   string // The YAML document to parse
+		want    any    // The expected document, as returned by Node.Any
+		err     string // The expected error message, if there was one
+		wantErr bool   // Whether or not we wanted an error
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "only comments",
+			input:   "# nothing here\n\n  # or here\n",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "plain scalar",
+			input:   "hello world  # a comment\n",
+			want:    "hello world",
+			wantErr: false,
+		},
+		{
+			name:    "document marker",
+			input:   "--- # start\nkey: value\n",
+			want:    map[string]any{"key": "value"},
+			wantErr: false,
+		},
+		{
+			name:    "mapping",
+			input:   "name: parser\nversion: 1.2.3\nurl: https://example.com:8080/a#b\n",
+			want:    map[string]any{"name": "parser", "version": "1.2.3", "url": "https://example.com:8080/a#b"},
+			wantErr: false,
+		},
+		{
+			name:    "mapping with spaces in keys and crlf",
+			input:   "first name: Tom\r\nlast name :  Fleet \r\n",
+			want:    map[string]any{"first name": "Tom", "last name": "Fleet"},
+			wantErr: false,
+		},
+		{
+			name:    "quoted keys",
+			input:   "\"a: b\": 1\n'c # d' : 2\n",
+			want:    map[string]any{"a: b": "1", "c # d": "2"},
+			wantErr: false,
+		},
+		{
+			name:    "null values",
+			input:   "a:\nb: # nothing\nc: 1",
+			want:    map[string]any{"a": nil, "b": nil, "c": "1"},
+			wantErr: false,
+		},
+		{
+			name:    "nested mappings",
+			input:   "a:\n  b:\n    c: 1\n  d: 2\ne: 3\n",
+			want:    map[string]any{"a": map[string]any{"b": map[string]any{"c": "1"}, "d": "2"}, "e": "3"},
+			wantErr: false,
+		},
+		{
+			name:    "sequence",
+			input:   "- one\n- two\n-\n- 'four'\n",
+			want:    []any{"one", "two", nil, "four"},
+			wantErr: false,
+		},
+		{
+			name:    "indented sequence in mapping",
+			input:   "tags:\n  - go\n  - parsing\nname: parser\n",
+			want:    map[string]any{"tags": []any{"go", "parsing"}, "name": "parser"},
+			wantErr: false,
+		},
+		{
+			name:    "sequence in same column as key",
+			input:   "tags:\n- go\n- parsing\nname: parser\n",
+			want:    map[string]any{"tags": []any{"go", "parsing"}, "name": "parser"},
+			wantErr: false,
+		},
+		{
+			name:    "mapping in sequence entry",
+			input:   "- name: a\n  size: 1\n-   name: b\n    size: 2\n- c\n",
+			want:    []any{map[string]any{"name": "a", "size": "1"}, map[string]any{"name": "b", "size": "2"}, "c"},
+			wantErr: false,
+		},
+		{
+			name:    "nested sequences",
+			input:   "- - a\n  - b\n-\n  - c\n",
+			want:    []any{[]any{"a", "b"}, []any{"c"}},
+			wantErr: false,
+		},
+		{
+			name:    "blank lines and comments between entries",
+			input:   "a: 1\n\n# comment\n   # indented comment\nb:\n\n  - x # trailing\n\n  # between\n  - y\n\nc: 3\n",
+			want:    map[string]any{"a": "1", "b": []any{"x", "y"}, "c": "3"},
+			wantErr: false,
+		},
+		{
+			name:    "indented document",
+			input:   "  a: 1\n  b: 2\n",
+			want:    map[string]any{"a": "1", "b": "2"},
+			wantErr: false,
+		},
+		{
+			name:    "utf8",
+			input:   "日本: 語\n- ð: þ\n",
+			want:    nil,
+			wantErr: true,
+			err:     `yamlite: unexpected '-', expected end of document at line 2, column 1`,
+		},
+		{
+			name:    "utf8 nested",
+			input:   "日本:\n  - ð: þ\n    ç: Ê\n",
+			want:    map[string]any{"日本": []any{map[string]any{"ð": "þ", "ç": "Ê"}}},
+			wantErr: false,
+		},
+		{
+			name:    "double quoted escapes",
+			input:   `a: "tab\there \"quoted\" \\ \x41\u00e9\U0001F600 \/"`,
+			want:    map[string]any{"a": "tab\there \"quoted\" \\ Aé😀 /"},
+			wantErr: false,
+		},
+		{
+			name:    "single quoted",
+			input:   `a: 'it''s # not a comment'`,
+			want:    map[string]any{"a": "it's # not a comment"},
+			wantErr: false,
+		},
+		{
+			name:    "literal block scalar",
+			input:   "a: |\n  line one\n    indented\n\n  line three\n\nb: 1\n",
+			want:    map[string]any{"a": "line one\n  indented\n\nline three\n", "b": "1"},
+			wantErr: false,
+		},
+		{
+			name:    "folded block scalar",
+			input:   "a: >\n  folded\n  onto one line\n\n  new paragraph\n    kept\n  end\n",
+			want:    map[string]any{"a": "folded onto one line\nnew paragraph\n  kept\nend\n"},
+			wantErr: false,
+		},
+		{
+			name:    "block scalar chomping",
+			input:   "strip: |-\n  text\n\nkeep: |+\n  text\n\n\nclip: >\n  text\n\n",
+			want:    map[string]any{"strip": "text", "keep": "text\n\n\n", "clip": "text\n"},
+			wantErr: false,
+		},
+		{
+			name:    "block scalar in sequence",
+			input:   "- |\n  a\n  b\n- c\n",
+			want:    []any{"a\nb\n", "c"},
+			wantErr: false,
+		},
+		{
+			name:    "empty block scalar",
+			input:   "a: |\nb: 1\n",
+			want:    map[string]any{"a": "", "b": "1"},
+			wantErr: false,
+		},
+		{
+			name:    "block scalar with comment after",
+			input:   "a:\n  b: |\n    text\n# comment\n  c: 1\n",
+			want:    map[string]any{"a": map[string]any{"b": "text\n", "c": "1"}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid utf8",
+			input:   "a: \xf8\xa1\xa1\xa1\xa1",
+			want:    nil,
+			wantErr: true,
+			err:     "yamlite: input not valid utf-8 at line 1, column 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlite.Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("\nGot error:\t%v\nWanted error:\t%v\n", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("\nGot:\t%q\nWanted:\t%q\n", err.Error(), tt.err)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(got.Any(), tt.want) {
+				t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got.Any(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNode(t *testing.T) {
+	input := "key: 'value'\nlist:\n  - |\n    text\n  -\n"
+
+	got, err := yamlite.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	want := yamlite.Node{
+		Kind: yamlite.KindMapping,
+		Span: yamlite.Span{Start: 0, End: 37},
+		Entries: []yamlite.Entry{
+			{
+				Key:     "key",
+				KeySpan: yamlite.Span{Start: 0, End: 3},
+				Value: yamlite.Node{
+					Kind:  yamlite.KindScalar,
+					Style: yamlite.StyleSingleQuoted,
+					Value: "value",
+					Span:  yamlite.Span{Start: 5, End: 12},
+				},
+			},
+			{
+				Key:     "list",
+				KeySpan: yamlite.Span{Start: 13, End: 17},
+				Value: yamlite.Node{
+					Kind: yamlite.KindSequence,
+					Span: yamlite.Span{Start: 21, End: 37},
+					Items: []yamlite.Node{
+						{
+							Kind:  yamlite.KindScalar,
+							Style: yamlite.StyleLiteral,
+							Value: "text\n",
+							Span:  yamlite.Span{Start: 23, End: 33},
+						},
+						{
+							Kind: yamlite.KindNull,
+							Span: yamlite.Span{Start: 37, End: 37},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nGot:\t%#v\nWanted:\t%#v\n", got, want)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		name  string // Identifying test case name
+		input string // The invalid document
+		err   string // The expected error message
+	}{
+		{
+			name:  "unexpected indentation",
+			input: "a: 1\n  b: 2\n",
+			err:   "yamlite: unexpected indentation at line 2, column 3",
+		},
+		{
+			name:  "unexpected indentation in nested block",
+			input: "a:\n    b: 1\n  c: 2\n",
+			err:   "yamlite: unexpected indentation at line 3, column 3",
+		},
+		{
+			name:  "multi-line plain scalar",
+			input: "a: one\n  two\n",
+			err:   "yamlite: unexpected indentation at line 2, column 3",
+		},
+		{
+			name:  "dedent below document",
+			input: "  a: 1\nb: 2\n",
+			err:   "yamlite: unexpected 'b', expected end of document at line 2, column 1",
+		},
+		{
+			name:  "sequence after mapping",
+			input: "a: 1\n- b\n",
+			err:   "yamlite: unexpected '-', expected end of document at line 2, column 1",
+		},
+		{
+			name:  "not a mapping entry",
+			input: "a: 1\njust text\n",
+			err:   "yamlite: expected a mapping key followed by ':' at line 2, column 1",
+		},
+		{
+			name:  "error in later entry",
+			input: "a: 1\nb: \"unterminated\n",
+			err:   "yamlite: unterminated double quoted scalar at line 2, column 4",
+		},
+		{
+			name:  "error in later sequence entry",
+			input: "- a\n- [b]\n",
+			err:   "yamlite: flow collections aren't supported at line 2, column 3",
+		},
+		{
+			name:  "duplicate key",
+			input: "a: 1\nb: 2\na: 3\n",
+			err:   `yamlite: duplicate key "a", first defined on line 1 at line 3, column 1`,
+		},
+		{
+			name:  "nested mapping on key line",
+			input: "a: b: c\n",
+			err:   "yamlite: mapping values aren't allowed here at line 1, column 5",
+		},
+		{
+			name:  "sequence on key line",
+			input: "a: - b\n",
+			err:   "yamlite: block sequences can't start on the same line as their key at line 1, column 4",
+		},
+		{
+			name:  "tab indentation",
+			input: "a:\n\tb: 1\n",
+			err:   "yamlite: tabs aren't allowed in indentation at line 2, column 1",
+		},
+		{
+			name:  "tab indentation in block",
+			input: "a: 1\n\tb: 1\n",
+			err:   "yamlite: tabs aren't allowed in indentation at line 2, column 1",
+		},
+		{
+			name:  "flow mapping",
+			input: "a: {b: 1}\n",
+			err:   "yamlite: flow collections aren't supported at line 1, column 4",
+		},
+		{
+			name:  "anchor",
+			input: "a: &anchor 1\n",
+			err:   "yamlite: anchors, aliases and tags aren't supported at line 1, column 4",
+		},
+		{
+			name:  "directive",
+			input: "%YAML 1.2\n---\na: 1\n",
+			err:   "yamlite: directives aren't supported at line 1, column 1",
+		},
+		{
+			name:  "complex key",
+			input: "? a\n: b\n",
+			err:   "yamlite: complex keys aren't supported at line 1, column 1",
+		},
+		{
+			name:  "multiple documents",
+			input: "a: 1\n---\nb: 2\n",
+			err:   "yamlite: multiple documents aren't supported at line 2, column 1",
+		},
+		{
+			name:  "invalid escape",
+			input: `a: "\q"`,
+			err:   `yamlite: invalid escape "\\q" at line 1, column 5`,
+		},
+		{
+			name:  "short unicode escape",
+			input: `a: "\u12"`,
+			err:   `yamlite: invalid escape, expected 4 hex digits after \u at line 1, column 5`,
+		},
+		{
+			name:  "surrogate escape",
+			input: `a: "\ud800"`,
+			err:   `yamlite: invalid escape, \ud800 is not a unicode char at line 1, column 5`,
+		},
+		{
+			name:  "unterminated single quote",
+			input: "a: 'b\n  c'\n",
+			err:   "yamlite: unterminated single quoted scalar at line 1, column 4",
+		},
+		{
+			name:  "text after quoted scalar",
+			input: `a: "b" c`,
+			err:   "yamlite: unexpected 'c', expected end of line at line 1, column 8",
+		},
+		{
+			name:  "comment without whitespace",
+			input: `a: "b"#c`,
+			err:   "yamlite: comments must be separated from values by whitespace at line 1, column 7",
+		},
+		{
+			name:  "block scalar indentation indicator",
+			input: "a: |2\n  text\n",
+			err:   "yamlite: explicit block scalar indentation isn't supported at line 1, column 5",
+		},
+		{
+			name:  "text after block scalar indicator",
+			input: "a: |x\n",
+			err:   "yamlite: unexpected 'x', expected end of line at line 1, column 5",
+		},
+		{
+			name:  "sequence entry without space",
+			input: "- a\n-b\n",
+			err:   "yamlite: unexpected '-', expected end of document at line 2, column 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := yamlite.Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) returned no error", tt.input)
+			}
+
+			var syntaxErr *yamlite.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse returned a %T, wanted a *yamlite.SyntaxError", err)
+			}
+
+			if err.Error() != tt.err {
+				t.Errorf("\nGot:\t%q\nWanted:\t%q\n", err.Error(), tt.err)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	deep := strings.Repeat("- ", yamlite.MaxDepth) + "x"
+	if _, err := yamlite.Parse(deep); err != nil {
+		t.Fatalf("Parse returned an unexpected error at max depth: %v", err)
+	}
+
+	tooDeep := strings.Repeat("- ", yamlite.MaxDepth+1) + "x"
+
+	_, err := yamlite.Parse(tooDeep)
+	if err == nil || !strings.Contains(err.Error(), "exceeded max nesting depth of 10000") {
+		t.Errorf("Parse beyond max depth returned %v", err)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	kinds := map[yamlite.Kind]string{
+		yamlite.KindNull:     "null",
+		yamlite.KindScalar:   "scalar",
+		yamlite.KindSequence: "sequence",
+		yamlite.KindMapping:  "mapping",
+		yamlite.Kind(42):     "Kind(42)",
+	}
+
+	for kind, want := range kinds {
+		if got := kind.String(); got != want {
+			t.Errorf("Kind(%d).String() = %q, wanted %q", int(kind), got, want)
+		}
+	}
+}
+
+func ExampleParse() {
+	src := `name: parser
+tags:
+  - go
+  - "parsing"
+authors:
+  - name: Tom
+    email: tom@example.com
+description: |
+  Parser combinators
+  for Go.
+`
+
+	doc, err := yamlite.Parse(src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, entry := range doc.Entries {
+		fmt.Printf("%s: %s %q\n", entry.Key, entry.Value.Kind, entry.Value.Any())
+	}
+
+	// Output: name: scalar "parser"
+	// tags: sequence ["go" "parsing"]
+	// authors: sequence [map["email":"tom@example.com" "name":"Tom"]]
+	// description: scalar "Parser combinators\nfor Go.\n"
+}
+
+func ExampleSyntaxError() {
+	_, err := yamlite.Parse("name: parser\n  version: 1\n")
+
+	var syntaxErr *yamlite.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		fmt.Println(syntaxErr.Msg)
+		fmt.Println(syntaxErr.Line, syntaxErr.Column)
+	}
+
+	// Output: unexpected indentation
+	// 2 3
+}